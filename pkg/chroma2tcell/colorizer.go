@@ -6,6 +6,7 @@ import (
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/rivo/tview"
 )
 
 var getStyle = styles.Get
@@ -51,3 +52,44 @@ func ColorizeYAMLForTview(yamlStr string, getLexer func(string) chroma.Lexer) (s
 	}
 	return Colorize(yamlStr, "dracula", lexer)
 }
+
+// ColorizeSpans colorizes a single line of text with escaped content & sets the background color
+// of byte ranges in spans, e.g. to highlight changed parts of a line.
+func ColorizeSpans(text, styleName string, lexer chroma.Lexer, spans [][2]int, background string) (string, error) {
+	iterator, err := lexer.Tokenise(nil, text)
+	if err != nil {
+		return "", err
+	}
+
+	style := getStyle(styleName)
+	if style == nil {
+		style = getFallbackStyle()
+	}
+
+	var sb strings.Builder
+	offset := 0
+	for _, token := range iterator.Tokens() {
+		fg := "-"
+		if color := style.Get(token.Type); color.Colour.IsSet() {
+			fg = color.Colour.String()
+		}
+		// A lexer can add a trailing new line that is not a part of the text.
+		end := min(offset+len(token.Value), len(text))
+		for offset < end {
+			bg, segmentEnd := "-", end
+			for _, span := range spans {
+				if offset >= span[0] && offset < span[1] {
+					bg, segmentEnd = background, min(segmentEnd, span[1])
+				} else if span[0] > offset && span[0] < segmentEnd {
+					segmentEnd = span[0]
+				}
+			}
+			// Every segment starts with a tag so escaped brackets can't pair across segments.
+			sb.WriteString("[" + fg + ":" + bg + "]")
+			sb.WriteString(tview.Escape(text[offset:segmentEnd]))
+			offset = segmentEnd
+		}
+	}
+	sb.WriteString("[-:-]")
+	return sb.String(), nil
+}
@@ -149,3 +149,40 @@ func (m *mockLexer) SetAnalyser(analyser func(text string) float32) chroma.Lexer
 func (m *mockLexer) AnalyseText(_ string) float32 {
 	return 0
 }
+
+func TestColorizeSpans(t *testing.T) {
+	lexer := &mockLexer{
+		tokens: []chroma.Token{
+			{Type: chroma.Keyword, Value: "if"},
+			{Type: chroma.Text, Value: " [x] "},
+			{Type: chroma.Name, Value: "abc\n"},
+		},
+	}
+	zeroStyle, err := chroma.NewStyle("test", chroma.StyleEntries{chroma.Keyword: "#ff0000"})
+	assert.NoError(t, err)
+	oldGetStyle := getStyle
+	defer func() {
+		getStyle = oldGetStyle
+	}()
+	getStyle = func(name string) *chroma.Style {
+		return zeroStyle
+	}
+
+	s, err := ColorizeSpans("if [x] abc", "test", lexer, [][2]int{{1, 4}, {8, 9}}, "red")
+	assert.NoError(t, err)
+	assert.Equal(t, "[#ff0000:-]i[#ff0000:red]f[-:red] [[-:-]x] [-:-]a[-:red]b[-:-]c[-:-]", s)
+
+	t.Run("tokenise_error", func(t *testing.T) {
+		_, err := ColorizeSpans("text", "dracula", &mockLexer{err: fmt.Errorf("tokenise error")}, nil, "red")
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown_style", func(t *testing.T) {
+		getStyle = func(name string) *chroma.Style {
+			return nil
+		}
+		s, err := ColorizeSpans("x", "unknown", lexers.Get("go"), nil, "red")
+		assert.NoError(t, err)
+		assert.Contains(t, s, "x")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"strings"
@@ -15,6 +16,8 @@ import (
 )
 
 var _ files.Store = (*Store)(nil)
+var _ files.FileReader = (*Store)(nil)
+var _ files.FileWriter = (*Store)(nil)
 
 type Store struct {
 	root     url.URL
@@ -23,9 +26,16 @@ type Store struct {
 	implicit bool
 }
 
+// Delete removes a file or an empty dir. FTP has different commands for them,
+// so removing a dir is tried if deleting a file fails.
 func (s *Store) Delete(ctx context.Context, path string) error {
-	_, _ = ctx, path
-	return files.ErrNotImplemented
+	return s.do(ctx, func(c FtpClient) error {
+		err := c.Delete(path)
+		if err != nil && c.RemoveDir(path) == nil {
+			return nil
+		}
+		return err
+	})
 }
 
 func (s *Store) RootURL() url.URL {
@@ -46,9 +56,23 @@ func (s *Store) RootTitle() string {
 type FtpClient interface {
 	Login(user, password string) error
 	List(path string) (entries []*ftp.Entry, err error)
+	Retr(path string) (io.ReadCloser, error)
+	Stor(path string, r io.Reader) error
+	MakeDir(path string) error
+	Delete(path string) error
+	RemoveDir(path string) error
 	Quit() error
 }
 
+// serverConn adapts *ftp.ServerConn to FtpClient.
+type serverConn struct {
+	*ftp.ServerConn
+}
+
+func (c serverConn) Retr(path string) (io.ReadCloser, error) {
+	return c.ServerConn.Retr(path)
+}
+
 type StoreOption func(*Store)
 
 func NewStore(root url.URL, options ...StoreOption) *Store {
@@ -78,14 +102,20 @@ func (s *Store) SetTLS(explicit, implicit bool) {
 
 // We can't do simply `ftpDial = ftp.Dial` as we change return type from `*ftp.ServerConn` to `FtpClient`
 var ftpDial = func(addr string, options ...ftp.DialOption) (FtpClient, error) {
-	return ftp.Dial(addr, options...)
+	conn, err := ftp.Dial(addr, options...)
+	if err != nil {
+		return nil, err
+	}
+	return serverConn{ServerConn: conn}, nil
 }
 
 func (*Store) GetDirReader(_ context.Context, _ string) (files.DirReader, error) {
 	return nil, files.ErrNotSupported
 }
 
-func (s *Store) ReadDir(ctx context.Context, name string) ([]os.DirEntry, error) {
+// connect dials the server and logs in if the root URL has credentials.
+// The caller is responsible to Quit the returned client.
+func (s *Store) connect(ctx context.Context) (FtpClient, error) {
 	root := s.root
 	host := root.Hostname()
 	if port := root.Port(); port == "" {
@@ -137,14 +167,11 @@ func (s *Store) ReadDir(ctx context.Context, name string) ([]os.DirEntry, error)
 		return nil, ctx.Err()
 	}
 
-	defer func() {
-		_ = c.Quit()
-	}()
-
 	if root.User != nil {
 		username := root.User.Username()
 		password, hasPassword := root.User.Password()
 		if !hasPassword {
+			_ = c.Quit()
 			return nil, errors.New("missing password")
 		}
 
@@ -156,12 +183,25 @@ func (s *Store) ReadDir(ctx context.Context, name string) ([]os.DirEntry, error)
 		select {
 		case err := <-loginChan:
 			if err != nil {
+				_ = c.Quit()
 				return nil, fmt.Errorf("failed to login to ftp server: %w", err)
 			}
 		case <-ctx.Done():
+			_ = c.Quit()
 			return nil, ctx.Err()
 		}
 	}
+	return c, nil
+}
+
+func (s *Store) ReadDir(ctx context.Context, name string) ([]os.DirEntry, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = c.Quit()
+	}()
 
 	type listResult struct {
 		entries []*ftp.Entry
@@ -201,11 +241,77 @@ func (s *Store) ReadDir(ctx context.Context, name string) ([]os.DirEntry, error)
 }
 
 func (s *Store) CreateDir(ctx context.Context, path string) error {
-	_, _ = ctx, path
-	return errors.New("CreateDir not implemented for FTP")
+	return s.do(ctx, func(c FtpClient) error {
+		return c.MakeDir(path)
+	})
 }
 
 func (s *Store) CreateFile(ctx context.Context, path string) error {
-	_, _ = ctx, path
-	return errors.New("CreateFile not implemented for FTP")
+	return s.do(ctx, func(c FtpClient) error {
+		return c.Stor(path, strings.NewReader(""))
+	})
+}
+
+// do runs a command over a dedicated connection.
+func (s *Store) do(ctx context.Context, command func(c FtpClient) error) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	return errors.Join(command(c), c.Quit())
+}
+
+// OpenReader streams a file over a dedicated connection that is closed with the reader.
+func (s *Store) OpenReader(ctx context.Context, name string) (io.ReadCloser, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.Retr(name)
+	if err != nil {
+		_ = c.Quit()
+		return nil, fmt.Errorf("failed to retrieve file: %w", err)
+	}
+	return &retrReader{ReadCloser: r, c: c}, nil
+}
+
+type retrReader struct {
+	io.ReadCloser
+	c FtpClient
+}
+
+func (r *retrReader) Close() error {
+	err := r.ReadCloser.Close()
+	return errors.Join(err, r.c.Quit())
+}
+
+// OpenWriter uploads a file over a dedicated connection. The upload is complete once the writer is closed.
+func (s *Store) OpenWriter(ctx context.Context, name string) (io.WriteCloser, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	w := &storWriter{PipeWriter: pw, c: c, done: make(chan error, 1)}
+	go func() {
+		err := c.Stor(name, pr)
+		// Unblocks writes if the server fails the upload before reading everything.
+		_ = pr.CloseWithError(errors.Join(err, io.ErrClosedPipe))
+		w.done <- err
+	}()
+	return w, nil
+}
+
+type storWriter struct {
+	*io.PipeWriter
+	c    FtpClient
+	done chan error
+}
+
+func (w *storWriter) Close() error {
+	err := w.PipeWriter.Close()
+	if storErr := <-w.done; storErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to store file: %w", storErr))
+	}
+	return errors.Join(err, w.c.Quit())
 }
@@ -32,6 +32,7 @@ func WithHttpClient(client *http.Client) StoreOption {
 }
 
 var _ files.Store = (*HttpStore)(nil)
+var _ files.FileReader = (*HttpStore)(nil)
 
 type HttpStore struct {
 	Root   url.URL
@@ -121,3 +122,26 @@ func (h HttpStore) CreateFile(ctx context.Context, path string) error {
 	_, _ = ctx, path
 	return fmt.Errorf("CreateFile not implemented for HTTP")
 }
+
+// OpenReader streams a file with a GET request.
+func (h HttpStore) OpenReader(ctx context.Context, name string) (io.ReadCloser, error) {
+	u := h.Root
+	u.Path = name
+	client := h.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch file: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
@@ -217,3 +217,37 @@ func TestHttpStore_GetDirReader(t *testing.T) {
 	_, err := store.GetDirReader(ctx, "/pub/")
 	assert.ErrorIs(t, err, files.ErrNotImplemented)
 }
+
+func TestHttpStore_OpenReader(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := &http.Client{
+		Transport: &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				switch req.URL.Path {
+				case "/pub/file.txt":
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("content"))}, nil
+				case "/error":
+					return nil, fmt.Errorf("mock error")
+				default:
+					return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+				}
+			},
+		},
+	}
+	root, _ := url.Parse("https://example.com/")
+	store := NewStore(*root, WithHttpClient(client))
+
+	r, err := store.OpenReader(ctx, "/pub/file.txt")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "content", string(data))
+
+	_, err = store.OpenReader(ctx, "/missing")
+	assert.EqualError(t, err, "unexpected status code: 404")
+
+	_, err = store.OpenReader(ctx, "/error")
+	assert.ErrorContains(t, err, "failed to fetch file")
+}
@@ -0,0 +1,79 @@
+package osfile
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Copy copies a file or a directory recursively preserving permissions.
+// It fails if the destination already exists.
+func (s Store) Copy(ctx context.Context, srcPath, dstPath string) error {
+	info, err := os.Lstat(srcPath)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Lstat(dstPath); err == nil {
+		return &os.PathError{Op: "copy", Path: dstPath, Err: os.ErrExist}
+	}
+	return copyEntry(ctx, srcPath, dstPath, info)
+}
+
+func copyEntry(ctx context.Context, srcPath, dstPath string, info os.FileInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch {
+	case info.IsDir():
+		return copyDir(ctx, srcPath, dstPath, info)
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(srcPath)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dstPath)
+	default:
+		return copyFile(srcPath, dstPath, info)
+	}
+}
+
+func copyDir(ctx context.Context, srcPath, dstPath string, info os.FileInfo) error {
+	if err := osMkdir(dstPath, info.Mode().Perm()); err != nil {
+		return err
+	}
+	entries, err := osReadDir(srcPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		childInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if err = copyEntry(ctx, filepath.Join(srcPath, name), filepath.Join(dstPath, name), childInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(srcPath, dstPath string, info os.FileInfo) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
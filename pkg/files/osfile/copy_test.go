@@ -0,0 +1,61 @@
+package osfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Copy(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "src")
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("b"), 0o644))
+	assert.NoError(t, os.Symlink("a.txt", filepath.Join(src, "link")))
+	s := NewStore("/")
+
+	t.Run("dir", func(t *testing.T) {
+		dst := filepath.Join(tempDir, "dst")
+		assert.NoError(t, s.Copy(ctx, src, dst))
+		data, err := os.ReadFile(filepath.Join(dst, "sub", "b.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "b", string(data))
+		info, err := os.Stat(filepath.Join(dst, "a.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		target, err := os.Readlink(filepath.Join(dst, "link"))
+		assert.NoError(t, err)
+		assert.Equal(t, "a.txt", target)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		dst := filepath.Join(tempDir, "a-copy.txt")
+		assert.NoError(t, s.Copy(ctx, filepath.Join(src, "a.txt"), dst))
+		data, err := os.ReadFile(dst)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", string(data))
+	})
+
+	t.Run("destination_exists", func(t *testing.T) {
+		err := s.Copy(ctx, filepath.Join(src, "a.txt"), filepath.Join(src, "sub", "b.txt"))
+		assert.ErrorIs(t, err, os.ErrExist)
+	})
+
+	t.Run("source_missing", func(t *testing.T) {
+		err := s.Copy(ctx, filepath.Join(src, "missing"), filepath.Join(tempDir, "x"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		err := s.Copy(cancelled, src, filepath.Join(tempDir, "cancelled"))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -3,3 +3,8 @@ package osfile
 import "os"
 
 var osOpen = os.Open
+var osOpenFile = os.OpenFile
+var osChmod = os.Chmod
+var osChtimes = os.Chtimes
+var osLink = os.Link
+var osSymlink = os.Symlink
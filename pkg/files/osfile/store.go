@@ -2,10 +2,14 @@ package osfile
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/filetug/filetug/pkg/files"
 )
@@ -14,9 +18,21 @@ var osReadDir = os.ReadDir
 var osHostname = os.Hostname
 var osMkdir = os.Mkdir
 var osCreate = os.Create
+var osCreateTemp = os.CreateTemp
 var osRemove = os.Remove
+var osRename = os.Rename
+var osLstat = os.Lstat
 
 var _ files.Store = (*Store)(nil)
+var _ files.Renamer = (*Store)(nil)
+var _ files.Copier = (*Store)(nil)
+var _ files.FileReader = (*Store)(nil)
+var _ files.FileWriter = (*Store)(nil)
+var _ files.AttrSetter = (*Store)(nil)
+var _ files.Linker = (*Store)(nil)
+var _ files.Shredder = (*Store)(nil)
+var _ files.TempFileCreator = (*Store)(nil)
+var _ files.Lstater = (*Store)(nil)
 
 type Store struct {
 	title string
@@ -80,3 +96,110 @@ func (s Store) CreateFile(ctx context.Context, path string) error {
 	}
 	return f.Close()
 }
+
+func (s Store) Rename(ctx context.Context, oldPath, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return osRename(oldPath, newPath)
+}
+
+func (s Store) Lstat(ctx context.Context, path string) (os.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return osLstat(path)
+}
+
+func (s Store) OpenReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return osOpen(path)
+}
+
+func (s Store) OpenWriter(ctx context.Context, path string) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return osCreate(path)
+}
+
+// CreateTemp creates a new file readable only by the owner with a unique name in the dir, see os.CreateTemp.
+func (s Store) CreateTemp(ctx context.Context, dir, pattern string) (string, io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	f, err := osCreateTemp(dir, pattern)
+	if err != nil {
+		return "", nil, err
+	}
+	return f.Name(), f, nil
+}
+
+func (s Store) Chmod(ctx context.Context, path string, mode os.FileMode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return osChmod(path, mode)
+}
+
+// Chtimes sets modification time of an entry, the access time is set to the same value.
+func (s Store) Chtimes(ctx context.Context, path string, modTime time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return osChtimes(path, modTime, modTime)
+}
+
+// Link creates newPath as a hard link to oldPath.
+func (s Store) Link(ctx context.Context, oldPath, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return osLink(oldPath, newPath)
+}
+
+// Symlink creates newPath as a symbolic link to oldPath.
+func (s Store) Symlink(ctx context.Context, oldPath, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return osSymlink(oldPath, newPath)
+}
+
+// Shred overwrites content of a file with random bytes, flushes it to the disk & deletes the file.
+func (s Store) Shred(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f, err := osOpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	err = overwrite(ctx, f)
+	if err = errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+	return osRemove(path)
+}
+
+func overwrite(ctx context.Context, f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 64*1024)
+	for remaining := info.Size(); remaining > 0; {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		n := min(remaining, int64(len(buf)))
+		_, _ = rand.Read(buf[:n])
+		if _, err = f.Write(buf[:n]); err != nil {
+			return err
+		}
+		remaining -= n
+	}
+	return f.Sync()
+}
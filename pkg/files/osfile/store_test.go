@@ -1,10 +1,14 @@
 package osfile
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -177,3 +181,183 @@ func TestStore_CreateDir_CreateFile_Delete(t *testing.T) {
 		osRemove = origRemove
 	})
 }
+
+func TestStore_Rename(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStore(tempDir)
+	ctx := context.Background()
+
+	oldPath := tempDir + "/old.txt"
+	newPath := tempDir + "/new.txt"
+	assert.NoError(t, os.WriteFile(oldPath, []byte("test"), 0644))
+
+	assert.NoError(t, s.Rename(ctx, oldPath, newPath))
+	_, err := os.Stat(oldPath)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(newPath)
+	assert.NoError(t, err)
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctxC, cancel := context.WithCancel(ctx)
+		cancel()
+		err := s.Rename(ctxC, newPath, oldPath)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("not_exists", func(t *testing.T) {
+		err := s.Rename(ctx, tempDir+"/missing.txt", oldPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestStore_OpenReaderWriter(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStore(tempDir)
+	ctx := context.Background()
+	filePath := tempDir + "/file.txt"
+
+	w, err := s.OpenWriter(ctx, filePath)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("content"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	r, err := s.OpenReader(ctx, filePath)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "content", string(data))
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctxC, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := s.OpenReader(ctxC, filePath)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = s.OpenWriter(ctxC, filePath)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestStore_CreateTemp(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStore(tempDir)
+	ctx := context.Background()
+	existing := filepath.Join(tempDir, ".file.tmp")
+	assert.NoError(t, os.WriteFile(existing, []byte("kept"), 0o644))
+
+	p, w, err := s.CreateTemp(ctx, tempDir, ".file*.tmp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, existing, p)
+	assert.Equal(t, tempDir, filepath.Dir(p))
+	_, err = w.Write([]byte("content"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	info, err := os.Stat(p)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	data, err := os.ReadFile(existing)
+	assert.NoError(t, err)
+	assert.Equal(t, "kept", string(data))
+
+	_, _, err = s.CreateTemp(ctx, filepath.Join(tempDir, "missing"), "x*")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	ctxC, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = s.CreateTemp(ctxC, tempDir, "x*")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStore_Lstat(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStore(tempDir)
+	ctx := context.Background()
+	link := filepath.Join(tempDir, "link")
+	assert.NoError(t, os.Symlink(filepath.Join(tempDir, "missing"), link))
+
+	info, err := s.Lstat(ctx, link)
+	assert.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+	_, err = s.Lstat(ctx, filepath.Join(tempDir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	ctxC, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.Lstat(ctxC, link)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStore_ChmodChtimes(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStore(tempDir)
+	ctx := context.Background()
+	filePath := tempDir + "/file.txt"
+	assert.NoError(t, os.WriteFile(filePath, nil, 0o644))
+
+	modTime := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	assert.NoError(t, s.Chmod(ctx, filePath, 0o600))
+	assert.NoError(t, s.Chtimes(ctx, filePath, modTime))
+	info, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.True(t, modTime.Equal(info.ModTime()))
+
+	ctxC, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, s.Chmod(ctxC, filePath, 0o644), context.Canceled)
+	assert.ErrorIs(t, s.Chtimes(ctxC, filePath, modTime), context.Canceled)
+}
+
+func TestStore_Links(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStore(tempDir)
+	ctx := context.Background()
+	filePath := tempDir + "/file.txt"
+	assert.NoError(t, os.WriteFile(filePath, []byte("x"), 0o644))
+
+	assert.NoError(t, s.Link(ctx, filePath, tempDir+"/hard.txt"))
+	assert.NoError(t, s.Symlink(ctx, filePath, tempDir+"/soft.txt"))
+	fileInfo, _ := os.Stat(filePath)
+	hardInfo, err := os.Stat(tempDir + "/hard.txt")
+	assert.NoError(t, err)
+	assert.True(t, os.SameFile(fileInfo, hardInfo))
+	target, err := os.Readlink(tempDir + "/soft.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, filePath, target)
+	assert.Error(t, s.Link(ctx, filePath, tempDir+"/hard.txt"), "existing target")
+
+	ctxC, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, s.Link(ctxC, filePath, tempDir+"/x"), context.Canceled)
+	assert.ErrorIs(t, s.Symlink(ctxC, filePath, tempDir+"/x"), context.Canceled)
+}
+
+func TestStore_Shred(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStore(tempDir)
+	ctx := context.Background()
+	filePath := tempDir + "/secret.txt"
+	secret := bytes.Repeat([]byte("secret"), 20000)
+	assert.NoError(t, os.WriteFile(filePath, secret, 0o644))
+	hardLink := tempDir + "/hard.txt"
+	assert.NoError(t, os.Link(filePath, hardLink))
+
+	assert.NoError(t, s.Shred(ctx, filePath))
+	_, err := os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+	overwritten, err := os.ReadFile(hardLink)
+	assert.NoError(t, err)
+	assert.Len(t, overwritten, len(secret), "size is kept")
+	assert.False(t, bytes.Contains(overwritten, []byte("secret")), "content is overwritten")
+
+	assert.Error(t, s.Shred(ctx, tempDir+"/missing"))
+	assert.Error(t, s.Shred(ctx, tempDir), "a dir can't be opened for writing")
+
+	ctxC, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, s.Shred(ctxC, hardLink), context.Canceled)
+	f, err := os.OpenFile(hardLink, os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, overwrite(ctxC, f), context.Canceled)
+	assert.NoError(t, f.Close())
+	assert.Error(t, overwrite(ctx, f), "closed file")
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"time"
 )
 
 // noinspection GoUnusedGlobalVariable // used by other packages
@@ -24,6 +25,62 @@ type Store interface {
 	CreateFile(ctx context.Context, path string) error
 }
 
+// Renamer is an optional interface implemented by stores that can rename entries.
+// Use a type assertion to check if a store supports renaming.
+type Renamer interface {
+	Rename(ctx context.Context, oldPath, newPath string) error
+}
+
+// Copier is an optional interface implemented by stores that can copy entries within the store.
+// Directories are copied recursively.
+type Copier interface {
+	Copy(ctx context.Context, srcPath, dstPath string) error
+}
+
+// FileReader is an optional interface implemented by stores that can stream content of files.
+type FileReader interface {
+	OpenReader(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// FileWriter is an optional interface implemented by stores that can write content of files.
+// OpenWriter creates a file or truncates an existing one. The content is committed on Close.
+type FileWriter interface {
+	OpenWriter(ctx context.Context, path string) (io.WriteCloser, error)
+}
+
+// TempFileCreator is an optional interface implemented by stores that can create a new file with a unique name
+// & permissions for the owner only, e.g. to write content that is renamed into place once complete.
+// The pattern is used as in os.CreateTemp, the path of the created file is returned.
+type TempFileCreator interface {
+	CreateTemp(ctx context.Context, dir, pattern string) (string, io.WriteCloser, error)
+}
+
+// AttrSetter is an optional interface implemented by stores that can set permissions
+// and modification time of entries, e.g. to preserve them when extracting archives.
+type AttrSetter interface {
+	Chmod(ctx context.Context, path string, mode os.FileMode) error
+	Chtimes(ctx context.Context, path string, modTime time.Time) error
+}
+
+// Lstater is an optional interface implemented by stores that can get info of an entry without following links.
+// The error matches os.ErrNotExist if the entry doesn't exist.
+type Lstater interface {
+	Lstat(ctx context.Context, path string) (os.FileInfo, error)
+}
+
+// Linker is an optional interface implemented by stores that can create hard & symbolic links.
+// Both fail if newPath already exists.
+type Linker interface {
+	Link(ctx context.Context, oldPath, newPath string) error
+	Symlink(ctx context.Context, oldPath, newPath string) error
+}
+
+// Shredder is an optional interface implemented by stores that can overwrite content of a file
+// before deleting it. Copy-on-write file systems & SSDs can still keep the original content.
+type Shredder interface {
+	Shred(ctx context.Context, path string) error
+}
+
 type DirReader interface {
 	io.Closer
 	Readdir() ([]os.FileInfo, error)
@@ -0,0 +1,294 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const archiveOperation OperationType = "archive"
+
+var errArchiveNotSupported = fmt.Errorf("writing archive: %w", files.ErrNotSupported)
+
+// archivePanel asks for a name, format, compression level & masks of an archive to be created
+// in the current directory from the selected entries or the basket.
+type archivePanel struct {
+	*sneatv.Boxed
+	nav     *Navigator
+	flex    *tview.Flex
+	form    *tview.Form
+	name    *tview.InputField
+	format  *tview.DropDown
+	level   *tview.DropDown
+	include *tview.DropDown
+	exclude *tview.DropDown
+	status  *tview.TextView
+	sources []ftarchive.Source
+	masks   []masks.Mask
+}
+
+var archiveLevels = []string{"Default", "0 (store)", "1 (fastest)", "2", "3", "4", "5", "6", "7", "8", "9 (best)"}
+
+// archiveSources returns entries to be archived: all items if the basket or a list is shown,
+// otherwise the selected entries of the current store.
+func (nav *Navigator) archiveSources() ([]ftarchive.Source, error) {
+	if items, ok := nav.shownItems(); ok {
+		sources := make([]ftarchive.Source, 0, len(items))
+		for _, item := range items {
+			store, err := nav.storeFor(item.Store)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, ftarchive.Source{Store: store, Path: item.Path, IsDir: item.IsDir})
+		}
+		return sources, nil
+	}
+	entries := nav.selectedEntries()
+	sources := make([]ftarchive.Source, 0, len(entries))
+	for _, entry := range entries {
+		if entry.FullName() == "/" {
+			continue
+		}
+		src := ftarchive.Source{Store: nav.store, Path: entry.FullName(), IsDir: entry.IsDir()}
+		if info, err := entry.Info(); err == nil {
+			src.Info = info
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+func (nav *Navigator) showArchivePanel() {
+	sources, err := nav.archiveSources()
+	if err != nil {
+		nav.showError(err)
+		return
+	}
+	if len(sources) == 0 {
+		return
+	}
+	p := newArchivePanel(nav, sources)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newArchivePanel(nav *Navigator, sources []ftarchive.Source) *archivePanel {
+	p := &archivePanel{
+		nav:     nav,
+		flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		form:    tview.NewForm(),
+		status:  tview.NewTextView().SetDynamicColors(true),
+		sources: sources,
+		masks:   nav.allMasks(),
+	}
+	name := "archive"
+	if len(sources) == 1 {
+		name = path.Base(sources[0].Path)
+	}
+	p.name = tview.NewInputField().SetLabel("Name").SetText(name)
+	formats := make([]string, len(ftarchive.Formats))
+	for i, f := range ftarchive.Formats {
+		formats[i] = string(f)
+	}
+	p.format = tview.NewDropDown().SetLabel("Format").SetOptions(formats, nil).SetCurrentOption(0)
+	p.level = tview.NewDropDown().SetLabel("Compression").SetOptions(archiveLevels, nil).SetCurrentOption(0)
+	p.include = newMaskDropDown("Include mask", p.masks)
+	p.exclude = newMaskDropDown("Exclude mask", p.masks)
+	p.form.AddFormItem(p.name)
+	p.form.AddFormItem(p.format)
+	p.form.AddFormItem(p.level)
+	p.form.AddFormItem(p.include)
+	p.form.AddFormItem(p.exclude)
+	p.form.AddButton("Create", p.create)
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+
+	p.flex.AddItem(p.form, 13, 0, true)
+	p.flex.AddItem(p.status, 0, 1, false)
+
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1))
+	p.SetTitle(fmt.Sprintf("Archive %d item(s)", len(sources)))
+	p.setStatus("Archive is created in " + tview.Escape(nav.currentDirPath()))
+	return p
+}
+
+// newMaskDropDown returns a drop-down with the "(none)" option followed by the masks.
+func newMaskDropDown(label string, list []masks.Mask) *tview.DropDown {
+	names := []string{"(none)"}
+	for _, m := range list {
+		names = append(names, m.Name)
+	}
+	return tview.NewDropDown().SetLabel(label).SetOptions(names, nil).SetCurrentOption(0)
+}
+
+// selectedMask returns the mask selected in a drop-down created by newMaskDropDown or nil for "(none)".
+func selectedMask(list []masks.Mask, dd *tview.DropDown) *masks.Mask {
+	i, _ := dd.GetCurrentOption()
+	if i <= 0 || i > len(list) {
+		return nil
+	}
+	return &list[i-1]
+}
+
+func (p *archivePanel) options() ftarchive.Options {
+	opts := ftarchive.Options{
+		Format:  ftarchive.FormatZip,
+		Level:   ftarchive.DefaultLevel,
+		Include: selectedMask(p.masks, p.include),
+		Exclude: selectedMask(p.masks, p.exclude),
+	}
+	if i, _ := p.format.GetCurrentOption(); i >= 0 && i < len(ftarchive.Formats) {
+		opts.Format = ftarchive.Formats[i]
+	}
+	if i, _ := p.level.GetCurrentOption(); i > 0 {
+		opts.Level = i - 1
+	}
+	return opts
+}
+
+// archivePath returns the full path of the archive adding the format extension if it's missing.
+func (p *archivePanel) archivePath(format ftarchive.Format) (string, error) {
+	name := strings.TrimSpace(p.name.GetText())
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid archive name: %q", name)
+	}
+	if !strings.HasSuffix(name, format.Ext()) {
+		name += format.Ext()
+	}
+	return path.Join(p.nav.currentDirPath(), name), nil
+}
+
+func (p *archivePanel) create() {
+	opts := p.options()
+	archivePath, err := p.archivePath(opts.Format)
+	if err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	if _, ok := p.nav.store.(files.FileWriter); !ok {
+		p.setStatus("[red]" + errArchiveNotSupported.Error() + "[-]")
+		return
+	}
+	if p.exists(archivePath) {
+		p.setStatus("[red]Already exists: " + tview.Escape(path.Base(archivePath)) + "[-]")
+		return
+	}
+	p.close()
+	p.nav.createArchive(p.sources, archivePath, opts)
+}
+
+func (p *archivePanel) exists(fullPath string) bool {
+	children, err := p.nav.store.ReadDir(context.Background(), path.Dir(fullPath))
+	if err != nil {
+		return false
+	}
+	name := path.Base(fullPath)
+	for _, child := range children {
+		if child.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *archivePanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+func (p *archivePanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *archivePanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
+
+// createArchive writes an archive into the current store in background.
+// A partially written archive is deleted if the operation fails or is cancelled.
+func (nav *Navigator) createArchive(sources []ftarchive.Source, archivePath string, opts ftarchive.Options) *Operation {
+	store := nav.store
+	writer, ok := store.(files.FileWriter)
+	if !ok {
+		nav.showError(errArchiveNotSupported)
+		return nil
+	}
+	title := fmt.Sprintf("Archive %d item(s) to %s", len(sources), path.Base(archivePath))
+	return nav.operations.Start(archiveOperation, title, []string{path.Dir(archivePath)},
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			entries, err := ftarchive.Collect(ctx, sources, opts)
+			if err != nil {
+				return err
+			}
+			if err = writeArchive(ctx, store, writer, archivePath, entries, opts, reportProgress); err != nil {
+				return err
+			}
+			nav.recordHistory(store, ftjournal.Record{Action: ftjournal.ActionArchive, Paths: []string{archivePath}})
+			return nil
+		},
+	)
+}
+
+func writeArchive(
+	ctx context.Context,
+	store files.Store,
+	writer files.FileWriter,
+	archivePath string,
+	entries []ftarchive.Entry,
+	opts ftarchive.Options,
+	reportProgress ProgressReporter,
+) error {
+	progress := OperationProgress{Total: len(entries)}
+	for _, e := range entries {
+		if !e.IsDir && !e.SizeKnown {
+			progress.BytesTotal = 0 // the progress is shown by the number of entries
+			break
+		}
+		progress.BytesTotal += e.Size
+	}
+	reportProgress(progress)
+	w, err := writer.OpenWriter(ctx, archivePath)
+	if err != nil {
+		return err
+	}
+	var current string
+	err = ftarchive.Write(ctx, w, entries, opts, func(e ftarchive.Entry, bytesDone int64) {
+		if e.Name != current {
+			if current != "" {
+				progress.Done++
+			}
+			current = e.Name
+		}
+		progress.Processing = []string{e.Path}
+		progress.BytesDone = bytesDone
+		if progress.BytesTotal > 0 {
+			// A file may have grown since its size was collected.
+			progress.BytesDone = min(bytesDone, progress.BytesTotal)
+		}
+		reportProgress(progress)
+	})
+	if err = errors.Join(err, w.Close()); err != nil {
+		if deleteErr := store.Delete(context.WithoutCancel(ctx), archivePath); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to delete incomplete archive: %w", deleteErr))
+		}
+		return err
+	}
+	progress.Done = progress.Total
+	progress.Processing = nil
+	reportProgress(progress)
+	return nil
+}
@@ -0,0 +1,216 @@
+package filetug
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func lastOperation(nav *Navigator) *Operation {
+	operations := nav.operations.Operations()
+	if len(operations) == 0 {
+		return nil
+	}
+	return operations[len(operations)-1]
+}
+
+func TestArchivePanel(t *testing.T) {
+	withRenameJournal(t)
+	journal := withHistoryJournal(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.cpp")
+
+	nav.files.inputCapture(tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModCtrl))
+	p, ok := nav.right.content.(*archivePanel)
+	assert.True(t, ok)
+	assert.Equal(t, "a.txt", p.name.GetText())
+	assert.Len(t, p.sources, 1)
+	assert.Equal(t, ftarchive.Options{Format: ftarchive.FormatZip, Level: ftarchive.DefaultLevel}, p.options())
+
+	t.Run("ctrl_a_edits_name", func(t *testing.T) {
+		event := tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModCtrl)
+		assert.Equal(t, event, nav.inputCapture(event), "Ctrl+A moves the cursor in the focused name input")
+		assert.Equal(t, p, nav.right.content)
+	})
+
+	t.Run("invalid_name", func(t *testing.T) {
+		p.name.SetText("a/b")
+		p.create()
+		assert.Contains(t, p.status.GetText(true), `invalid archive name: "a/b"`)
+		assert.Equal(t, p, nav.right.content)
+	})
+
+	t.Run("already_exists", func(t *testing.T) {
+		p.name.SetText("b.cpp")
+		p.format.SetCurrentOption(0)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.cpp.zip"), nil, 0o644))
+		p.create()
+		assert.Contains(t, p.status.GetText(true), "Already exists: b.cpp.zip")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		p.name.SetText("out")
+		p.level.SetCurrentOption(2)
+		p.include.SetCurrentOption(1) // Coding
+		assert.Equal(t, 1, p.options().Level)
+		p.sources[0] = ftarchive.Source{Store: nav.store, Path: dir, IsDir: true}
+		p.create()
+		assert.Equal(t, nav.previewer, nav.right.content)
+		o := lastOperation(nav)
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, archiveOperation, o.Type)
+		progress := o.Progress()
+		assert.Equal(t, 1, progress.Done)
+		assert.Equal(t, int64(len("b.cpp")), progress.BytesDone)
+
+		archivePath := filepath.Join(dir, "out.zip")
+		zr, err := zip.OpenReader(archivePath)
+		assert.NoError(t, err)
+		if assert.Len(t, zr.File, 1) {
+			assert.Equal(t, filepath.Base(dir)+"/b.cpp", zr.File[0].Name)
+		}
+		assert.NoError(t, zr.Close())
+
+		history, err := journal.History()
+		assert.NoError(t, err)
+		assert.Equal(t, "Archive "+archivePath, history.Entries[len(history.Entries)-1].Title())
+
+		waitOperation(t, nav.undo())
+		_, err = os.Stat(archivePath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		nav.showArchivePanel()
+		p = nav.right.content.(*archivePanel)
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+		event := tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+	})
+}
+
+func TestArchivePanel_FromTree(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t, "a.txt")
+	assert.Nil(t, nav.dirsTree.inputCapture(tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModCtrl)))
+	_, ok := nav.right.content.(*archivePanel)
+	assert.True(t, ok)
+}
+
+func TestWriteArchive_Progress(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "a.txt")
+	assert.NoError(t, os.WriteFile(filePath, []byte("grown since collected"), 0o644))
+	store := osfile.NewStore("/")
+	opts := ftarchive.Options{Format: ftarchive.FormatZip, Level: ftarchive.DefaultLevel}
+	write := func(entries ...ftarchive.Entry) (reported []OperationProgress) {
+		archivePath := filepath.Join(t.TempDir(), "out.zip")
+		err := writeArchive(context.Background(), store, store, archivePath, entries, opts,
+			func(p OperationProgress) {
+				reported = append(reported, p)
+			})
+		assert.NoError(t, err)
+		return reported
+	}
+	entry := ftarchive.Entry{Store: store, Path: filePath, Name: "a.txt", Mode: 0o644}
+	known := entry
+	known.Name, known.Size, known.SizeKnown = "b.txt", 21, true
+
+	t.Run("size_unknown", func(t *testing.T) {
+		for _, p := range write(known, entry) {
+			assert.Zero(t, p.BytesTotal)
+		}
+	})
+
+	t.Run("grown", func(t *testing.T) {
+		entry := entry
+		entry.Size, entry.SizeKnown = 5, true
+		reported := write(entry)
+		for _, p := range reported {
+			assert.LessOrEqual(t, p.BytesDone, p.BytesTotal)
+		}
+		assert.Equal(t, int64(5), reported[len(reported)-1].BytesDone)
+	})
+}
+
+func TestNavigator_CreateArchive_Errors(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t, "a.txt")
+
+	t.Run("removes_incomplete_archive", func(t *testing.T) {
+		archivePath := filepath.Join(dir, "a.tar.gz")
+		source := ftarchive.Source{Store: newMockStore(t), Path: "/remote.txt"}
+		o := nav.createArchive([]ftarchive.Source{source}, archivePath, ftarchive.Options{Format: ftarchive.FormatTarGz})
+		if assert.NotNil(t, o) {
+			assert.ErrorIs(t, o.Wait(), ftarchive.ErrFileReadNotSupported)
+		}
+		_, err := os.Stat(archivePath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("collect_error", func(t *testing.T) {
+		source := ftarchive.Source{Store: nav.store, Path: filepath.Join(dir, "missing"), IsDir: true}
+		o := nav.createArchive([]ftarchive.Source{source}, filepath.Join(dir, "m.zip"), ftarchive.Options{Format: ftarchive.FormatZip})
+		if assert.NotNil(t, o) {
+			assert.Error(t, o.Wait())
+		}
+	})
+
+	t.Run("not_supported", func(t *testing.T) {
+		var shownErr error
+		nav.showError = func(err error) {
+			shownErr = err
+		}
+		nav.store = newMockStore(t)
+		assert.Nil(t, nav.createArchive(nil, "/x.zip", ftarchive.Options{}))
+		assert.ErrorIs(t, shownErr, errArchiveNotSupported)
+
+		p := newArchivePanel(nav, []ftarchive.Source{{Path: "/x"}})
+		p.create()
+		assert.Contains(t, p.status.GetText(true), errArchiveNotSupported.Error())
+	})
+}
+
+func TestNavigator_ArchiveSources_Basket(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	withBasketFile(t)
+	nav, _, dir := newNavigatorWithLocalDir(t, "a.txt")
+	nav.basket.AddToBasket(BasketItem{Store: "file:", Path: filepath.Join(dir, "a.txt")})
+	nav.basket.AddToBasket(BasketItem{Store: "file:", Path: dir, IsDir: true})
+	nav.showBasket()
+
+	sources, err := nav.archiveSources()
+	assert.NoError(t, err)
+	assert.Equal(t, []ftarchive.Source{
+		{Store: nav.store, Path: filepath.Join(dir, "a.txt")},
+		{Store: nav.store, Path: dir, IsDir: true},
+	}, sources)
+
+	nav.showArchivePanel()
+	p := nav.right.content.(*archivePanel)
+	assert.Equal(t, "archive", p.name.GetText())
+
+	nav.basket.AddToBasket(BasketItem{Store: "%", Path: "/x"})
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+	nav.showArchivePanel()
+	assert.Error(t, shownErr)
+}
+
+func TestInverseRecord_Archive(t *testing.T) {
+	t.Parallel()
+	r := ftjournal.Record{Action: ftjournal.ActionArchive, Paths: []string{"/a.zip"}}
+	assert.Equal(t, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: []string{"/a.zip"}}, inverseRecord(r))
+}
@@ -1,15 +1,199 @@
 package filetug
 
-import "os"
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+	"gopkg.in/yaml.v3"
+)
+
+// BasketItem is an entry put into the basket. Items can come from any directory of any store.
+type BasketItem struct {
+	Store string `yaml:"store"`
+	Path  string `yaml:"path"`
+	IsDir bool   `yaml:"dir,omitempty"`
+}
+
+func (i BasketItem) key() string {
+	return i.Store + i.Path
+}
+
+// name returns the base name of the item path.
+func (i BasketItem) name() string {
+	return path.Base(i.Path)
+}
+
+// Basket is a set of entries selected across directories & stores to be processed together.
+// It is safe for concurrent use as operations update it from background goroutines.
 type Basket struct {
-	entries []os.DirEntry
+	mu    sync.Mutex
+	items []BasketItem
+}
+
+const basketFileName = "basket.yaml"
+
+var getBasketFilePath = func() (string, error) {
+	dir, err := ftsettings.GetDatatugUserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, basketFileName), nil
+}
+
+// AddToBasket adds an item if it is not in the basket yet.
+func (b *Basket) AddToBasket(item BasketItem) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.indexOf(item) >= 0 {
+		return false
+	}
+	b.items = append(b.items, item)
+	return true
+}
+
+// Remove removes an item from the basket.
+func (b *Basket) Remove(item BasketItem) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remove(item)
+}
+
+// Toggle adds an item if it is not in the basket, otherwise removes it.
+// Returns true if the item has been added.
+func (b *Basket) Toggle(item BasketItem) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remove(item) {
+		return false
+	}
+	b.items = append(b.items, item)
+	return true
 }
 
-func (b *Basket) AddToBasket(entry os.DirEntry) {
-	b.entries = append(b.entries, entry)
+func (b *Basket) Contains(item BasketItem) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.indexOf(item) >= 0
+}
+
+func (b *Basket) indexOf(item BasketItem) int {
+	key := item.key()
+	for i, existing := range b.items {
+		if existing.key() == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *Basket) remove(item BasketItem) bool {
+	i := b.indexOf(item)
+	if i < 0 {
+		return false
+	}
+	b.items = append(b.items[:i:i], b.items[i+1:]...)
+	return true
+}
+
+// Items returns a copy of the basket items in the order they were added.
+func (b *Basket) Items() []BasketItem {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := make([]BasketItem, len(b.items))
+	copy(items, b.items)
+	return items
+}
+
+func (b *Basket) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.items)
 }
 
 func (b *Basket) Clear() {
-	b.entries = []os.DirEntry{}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items = []BasketItem{}
+}
+
+// applyRecord keeps items pointing to their entries after they are deleted, renamed or moved.
+// Returns true if the basket has been changed.
+func (b *Basket) applyRecord(r ftjournal.Record) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var changed bool
+	switch r.Action {
+	case ftjournal.ActionDelete:
+		items := b.items[:0:0]
+		for _, item := range b.items {
+			if item.Store == r.Store && isPathOrDescendant(item.Path, r.Paths...) {
+				changed = true
+				continue
+			}
+			items = append(items, item)
+		}
+		b.items = items
+	case ftjournal.ActionRename, ftjournal.ActionMove:
+		// Moves of a batch are applied simultaneously, e.g. a swap of names.
+		items := make([]BasketItem, len(b.items))
+		copy(items, b.items)
+		for i, item := range b.items {
+			if item.Store != r.Store {
+				continue
+			}
+			for _, m := range r.Moves {
+				if isPathOrDescendant(item.Path, m.From) {
+					items[i].Path = m.To + strings.TrimPrefix(item.Path, m.From)
+					changed = true
+					break
+				}
+			}
+		}
+		b.items = items
+	}
+	return changed
+}
+
+func isPathOrDescendant(p string, parents ...string) bool {
+	for _, parent := range parents {
+		if p == parent || strings.HasPrefix(p, strings.TrimSuffix(parent, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// loadBasket reads the basket persisted by saveBasket. A missing file is an empty basket.
+func loadBasket(filePath string) (*Basket, error) {
+	b := &Basket{}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return b, nil
+		}
+		return b, err
+	}
+	if err = yaml.Unmarshal(data, &b.items); err != nil {
+		return &Basket{}, err
+	}
+	return b, nil
+}
+
+func saveBasket(filePath string, b *Basket) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, err := yaml.Marshal(b.items)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
 }
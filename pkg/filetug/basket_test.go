@@ -4,68 +4,115 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
-)
 
-// createTestFile is a test helper that creates a file with the given content.
-func createTestFile(t *testing.T, dir, filename, content string) {
-	t.Helper()
-	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
-		t.Fatalf("write file %s: %v", filename, err)
-	}
-}
-
-// readTestDir is a test helper that reads the directory and returns its entries.
-func readTestDir(t *testing.T, dir string) []os.DirEntry {
-	t.Helper()
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		t.Fatalf("read dir: %v", err)
-	}
-	return entries
-}
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/stretchr/testify/assert"
+)
 
 func TestBasket_AddToBasket(t *testing.T) {
 	t.Parallel()
-	dir := t.TempDir()
-	createTestFile(t, dir, "a.txt", "a")
-	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
-	}
-
-	entries := readTestDir(t, dir)
-	if len(entries) < 2 {
-		t.Fatalf("expected at least 2 entries, got %d", len(entries))
-	}
+	item1 := BasketItem{Store: "file:", Path: "/a.txt"}
+	item2 := BasketItem{Store: "file:", Path: "/subdir", IsDir: true}
 
 	var b Basket
-	b.AddToBasket(entries[0])
-	if got := len(b.entries); got != 1 {
+	if !b.AddToBasket(item1) {
+		t.Fatal("expected item to be added")
+	}
+	if got := b.Len(); got != 1 {
 		t.Fatalf("expected 1 entry after first add, got %d", got)
 	}
 
-	b.AddToBasket(entries[1])
-	if got := len(b.entries); got != 2 {
+	b.AddToBasket(item2)
+	if got := b.Len(); got != 2 {
 		t.Fatalf("expected 2 entries after second add, got %d", got)
 	}
-	if b.entries[0] != entries[0] || b.entries[1] != entries[1] {
+	if items := b.Items(); items[0] != item1 || items[1] != item2 {
 		t.Error("expected entries to be appended in order")
 	}
+
+	if b.AddToBasket(item1) {
+		t.Error("expected duplicate not to be added")
+	}
+	if !b.AddToBasket(BasketItem{Store: "ftp://example.com", Path: "/a.txt"}) {
+		t.Error("expected same path from another store to be added")
+	}
 }
 
 func TestBasket_Clear(t *testing.T) {
 	t.Parallel()
-	dir := t.TempDir()
-	createTestFile(t, dir, "a.txt", "a")
-
-	entries := readTestDir(t, dir)
-	if len(entries) == 0 {
-		t.Fatal("expected at least 1 entry")
-	}
-
-	b := Basket{entries: []os.DirEntry{entries[0]}}
+	b := Basket{items: []BasketItem{{Store: "file:", Path: "/a.txt"}}}
 	b.Clear()
 
-	if got := len(b.entries); got != 0 {
+	if got := b.Len(); got != 0 {
 		t.Fatalf("expected empty basket after Clear, got %d", got)
 	}
 }
+
+func TestBasket_ToggleRemoveContains(t *testing.T) {
+	t.Parallel()
+	item := BasketItem{Store: "file:", Path: "/a.txt"}
+	var b Basket
+	assert.True(t, b.Toggle(item))
+	assert.True(t, b.Contains(item))
+	assert.False(t, b.Toggle(item))
+	assert.False(t, b.Contains(item))
+	assert.False(t, b.Remove(item))
+}
+
+func TestLoadSaveBasket(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", basketFileName)
+
+	b, err := loadBasket(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, b.Len())
+
+	b.AddToBasket(BasketItem{Store: "file:", Path: "/a.txt"})
+	b.AddToBasket(BasketItem{Store: "ftp://example.com", Path: "/dir", IsDir: true})
+	assert.NoError(t, saveBasket(filePath, b))
+
+	loaded, err := loadBasket(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, b.Items(), loaded.Items())
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("not: [a list"), 0o644))
+	loaded, err = loadBasket(filePath)
+	assert.Error(t, err)
+	assert.Equal(t, 0, loaded.Len())
+
+	_, err = loadBasket(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestBasket_ApplyRecord(t *testing.T) {
+	t.Parallel()
+	b := Basket{items: []BasketItem{
+		{Store: "file:", Path: "/a"},
+		{Store: "file:", Path: "/dir/b"},
+		{Store: "file:", Path: "/dirty"},
+		{Store: "ftp://x", Path: "/a"},
+	}}
+	assert.False(t, b.applyRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionCreateDir, Paths: []string{"/a"}}))
+
+	assert.True(t, b.applyRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionRename, Moves: []ftjournal.Move{
+		{From: "/a", To: "/dirty"},
+		{From: "/dirty", To: "/a"},
+	}}))
+	assert.Equal(t, []BasketItem{
+		{Store: "file:", Path: "/dirty"},
+		{Store: "file:", Path: "/dir/b"},
+		{Store: "file:", Path: "/a"},
+		{Store: "ftp://x", Path: "/a"},
+	}, b.Items())
+
+	assert.True(t, b.applyRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionMove, Moves: []ftjournal.Move{
+		{From: "/dir", To: "/new/dir"},
+	}}))
+	assert.Equal(t, "/new/dir/b", b.Items()[1].Path)
+
+	assert.True(t, b.applyRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionDelete, Paths: []string{"/new", "/a"}}))
+	assert.Equal(t, []BasketItem{
+		{Store: "file:", Path: "/dirty"},
+		{Store: "ftp://x", Path: "/a"},
+	}, b.Items())
+}
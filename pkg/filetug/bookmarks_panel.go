@@ -0,0 +1,190 @@
+package filetug
+
+import (
+	"fmt"
+
+	"github.com/filetug/filetug/pkg/filetug/ftmarks"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// bookmarksPanel lists marks set by m<letter> in the left container, marks can be renamed & deleted there.
+type bookmarksPanel struct {
+	*sneatv.Boxed
+	flex      *tview.Flex
+	nav       *Navigator
+	list      *tview.List
+	footer    *tview.TextView
+	nameInput *tview.InputField
+	renaming  rune // the letter of a renamed mark, 0 if the name input is hidden
+	marks     []ftmarks.Mark
+}
+
+func newBookmarksPanel(nav *Navigator) *bookmarksPanel {
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	list := tview.NewList()
+	list.SetSecondaryTextColor(tcell.ColorGray)
+	footer := tview.NewTextView().SetDynamicColors(true).SetTextColor(tcell.ColorGray)
+	p := &bookmarksPanel{
+		flex:      flex,
+		nav:       nav,
+		list:      list,
+		footer:    footer,
+		nameInput: tview.NewInputField().SetLabel("Name: "),
+		Boxed: sneatv.NewBoxed(
+			flex,
+			sneatv.WithLeftBorder(1, -1),
+			sneatv.WithFooter(footer),
+		),
+	}
+	p.SetTitle("Bookmarks")
+	flex.AddItem(list, 0, 1, true)
+	list.SetInputCapture(p.inputCapture)
+	p.nameInput.SetInputCapture(p.nameInputCapture)
+	return p
+}
+
+func (nav *Navigator) showBookmarks() {
+	if nav.bookmarksPanel == nil {
+		nav.bookmarksPanel = newBookmarksPanel(nav)
+	}
+	p := nav.bookmarksPanel
+	p.hideNameInput()
+	p.setMarks(nav.userMarks())
+	nav.left.SetContent(p)
+	nav.app.SetFocus(p.list)
+}
+
+func (nav *Navigator) refreshBookmarksIfVisible() {
+	if nav.bookmarksPanel != nil && nav.left.content == nav.bookmarksPanel {
+		nav.bookmarksPanel.setMarks(nav.userMarks())
+	}
+}
+
+func (p *bookmarksPanel) setMarks(marks []ftmarks.Mark) {
+	current := p.currentLetter()
+	p.marks = marks
+	p.list.Clear()
+	selected := 0
+	for i, m := range marks {
+		if m.Letter == current {
+			selected = i
+		}
+		main := fmt.Sprintf("[yellow]%c[-] %s", m.Letter, tview.Escape(m.Path()))
+		if m.Name != "" {
+			main = fmt.Sprintf("[yellow]%c[-] %s", m.Letter, tview.Escape(m.Name))
+		}
+		p.list.AddItem(main, tview.Escape(m.Store), 0, nil)
+	}
+	if len(marks) > 0 {
+		p.list.SetCurrentItem(selected)
+	}
+	text := "m<letter> to set a mark, upper-case marks are kept across sessions"
+	if len(marks) > 0 {
+		text = "⏎ jump · r rename · d delete"
+	}
+	p.footer.SetText(text + " · esc back")
+}
+
+// currentLetter returns the letter of the mark under the cursor, 0 if there are no marks.
+func (p *bookmarksPanel) currentLetter() rune {
+	i := p.list.GetCurrentItem()
+	if i < 0 || i >= len(p.marks) {
+		return 0
+	}
+	return p.marks[i].Letter
+}
+
+func (p *bookmarksPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEnter, tcell.KeyRight:
+		if letter := p.currentLetter(); letter != 0 {
+			p.close()
+			p.nav.jumpToMark(letter)
+		}
+		return nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete:
+		p.deleteCurrent()
+		return nil
+	case tcell.KeyEscape:
+		p.close()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'r':
+			p.showNameInput()
+		case 'd':
+			p.deleteCurrent()
+		default:
+			return event
+		}
+		return nil
+	default:
+		return event
+	}
+}
+
+func (p *bookmarksPanel) close() {
+	p.nav.left.SetContent(p.nav.dirsTree)
+	p.nav.app.SetFocus(p.nav.dirsTree)
+}
+
+func (p *bookmarksPanel) deleteCurrent() {
+	letter := p.currentLetter()
+	if letter == 0 {
+		return
+	}
+	if err := p.nav.updateMarks(func(marks []ftmarks.Mark) []ftmarks.Mark {
+		return ftmarks.Delete(marks, letter)
+	}); err != nil {
+		p.nav.notifyError("Marks", err)
+		return
+	}
+	p.setMarks(p.nav.userMarks())
+}
+
+func (p *bookmarksPanel) showNameInput() {
+	letter := p.currentLetter()
+	if letter == 0 {
+		return
+	}
+	m, _ := ftmarks.Find(p.marks, letter)
+	p.nameInput.SetText(m.Name)
+	if p.renaming == 0 {
+		p.flex.AddItem(p.nameInput, 1, 0, false)
+	}
+	p.renaming = letter
+	p.nav.app.SetFocus(p.nameInput)
+}
+
+func (p *bookmarksPanel) hideNameInput() {
+	if p.renaming != 0 {
+		p.flex.RemoveItem(p.nameInput)
+		p.renaming = 0
+	}
+}
+
+// nameInputCapture saves the name of a mark on Enter, an empty name resets it to the marked path.
+func (p *bookmarksPanel) nameInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEnter:
+		letter, name := p.renaming, p.nameInput.GetText()
+		if err := p.nav.updateMarks(func(marks []ftmarks.Mark) []ftmarks.Mark {
+			return ftmarks.Rename(marks, letter, name)
+		}); err != nil {
+			p.nav.notifyError("Marks", err)
+			return nil
+		}
+		p.hideNameInput()
+		p.setMarks(p.nav.userMarks())
+		p.nav.app.SetFocus(p.list)
+		return nil
+	case tcell.KeyEscape:
+		p.hideNameInput()
+		p.nav.app.SetFocus(p.list)
+		return nil
+	default:
+		return event
+	}
+}
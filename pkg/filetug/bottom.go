@@ -17,6 +17,7 @@ type bottom struct {
 	altMenuItems []ftui.MenuItem
 	fkMenuItems  []ftui.MenuItem
 	isCtrl       bool
+	toast        *notification // shown instead of the menu while set
 }
 
 func newBottom(nav *Navigator) *bottom {
@@ -39,6 +40,10 @@ func newBottom(nav *Navigator) *bottom {
 }
 
 func (b *bottom) render() {
+	if b.toast != nil {
+		b.SetText(b.renderToast())
+		return
+	}
 
 	var sb strings.Builder
 
@@ -107,7 +112,8 @@ func (b *bottom) highlighted(added, _, _ []string) {
 	}
 }
 
-var archiveAction = func() {
+var archiveAction = func(nav *Navigator) {
+	nav.showArchivePanel()
 }
 
 var osExit = os.Exit
@@ -119,7 +125,19 @@ func (b *bottom) getCtrlMenuItems() []ftui.MenuItem {
 		{
 			Title:       "Archive",
 			HotKeys:     []string{"A"},
-			Action:      archiveAction,
+			Action:      func() { archiveAction(b.nav) },
+			IsAltHotkey: true,
+		},
+		{
+			Title:       "Extract",
+			HotKeys:     []string{"E"},
+			Action:      func() { b.nav.showExtractPanel() },
+			IsAltHotkey: true,
+		},
+		{
+			Title:       "Diff",
+			HotKeys:     []string{"D"},
+			Action:      func() { b.nav.markForDiff() },
 			IsAltHotkey: true,
 		},
 		{
@@ -147,7 +165,7 @@ func (b *bottom) getFKMenuItems() []ftui.MenuItem {
 		{Title: "F3·View", HotKeys: []string{"F3"}, Action: func() {}},
 		{Title: "F4·Edit", HotKeys: []string{"F4"}, Action: func() {}},
 		{Title: "F5·Copy", HotKeys: []string{"F5"}, Action: func() {}},
-		{Title: "F6·Rename", HotKeys: []string{"F6"}, Action: func() {}},
+		{Title: "F6·Rename", HotKeys: []string{"F6"}, Action: func() { b.nav.showBulkRename() }},
 		{Title: "F7·Create", HotKeys: []string{"F7"}, Action: func() {}},
 		{Title: "F8·Delete", HotKeys: []string{"F8"}, Action: func() {}},
 	}
@@ -156,12 +174,18 @@ func (b *bottom) getFKMenuItems() []ftui.MenuItem {
 func (b *bottom) getAltMenuItems() []ftui.MenuItem {
 	return []ftui.MenuItem{
 		{Title: "Exit", HotKeys: []string{"x"}, Action: func() { b.nav.app.Stop(); osExit(0) }, IsAltHotkey: true},
-		{Title: "Go", HotKeys: []string{"o"}, Action: func() {}, IsAltHotkey: true},
+		{Title: "Go", HotKeys: []string{"o"}, Action: func() { b.nav.showJumpPanel() }, IsAltHotkey: true},
 		{Title: "/root", HotKeys: []string{"/"}, Action: func() {}, IsAltHotkey: true},
 		{Title: "~Home", HotKeys: []string{"~"}, Action: func() {}, IsAltHotkey: true},
 		{Title: "Favorites", HotKeys: []string{"F"}, Action: func() {}, IsAltHotkey: true},
-		{Title: "Bookmarks", HotKeys: []string{"B"}, Action: func() {}, IsAltHotkey: true},
-		{Title: "Lists", HotKeys: []string{"L"}, Action: func() {}, IsAltHotkey: true},
+		{Title: "Volumes", HotKeys: []string{"V"}, Action: func() { b.nav.showVolumes() }, IsAltHotkey: true},
+		{Title: "Tasks", HotKeys: []string{"T"}, Action: func() { b.nav.showOperationsPanel() }, IsAltHotkey: true},
+		{Title: "History", HotKeys: []string{"y"}, Action: func() { b.nav.showHistoryPanel() }, IsAltHotkey: true},
+		{Title: "Notifications", HotKeys: []string{"N"}, Action: func() { b.nav.showNotificationsPanel() }, IsAltHotkey: true},
+		{Title: "Basket", HotKeys: []string{"K"}, Action: func() { b.nav.showBasket() }, IsAltHotkey: true},
+		{Title: "Bookmarks", HotKeys: []string{"B"}, Action: func() { b.nav.showBookmarks() }, IsAltHotkey: true},
+		{Title: "Lists", HotKeys: []string{"L"}, Action: func() { b.nav.showLists() }, IsAltHotkey: true},
+		{Title: "Tags", HotKeys: []string{"A"}, Action: func() { b.nav.showTagsBrowser() }, IsAltHotkey: true},
 		{Title: "Masks", HotKeys: []string{"M"}, Action: func() {}, IsAltHotkey: true},
 		{Title: "±Size", HotKeys: []string{"±"}, Action: func() {}, IsAltHotkey: true},
 		{Title: "Git", HotKeys: []string{"G"}, Action: func() {}, IsAltHotkey: true},
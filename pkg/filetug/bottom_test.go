@@ -13,7 +13,7 @@ func Test_bottom_getCtrlMenuItems(t *testing.T) {
 	t.Parallel()
 	b := &bottom{}
 	menuItems := b.getCtrlMenuItems()
-	assert.Len(t, menuItems, 4)
+	assert.Len(t, menuItems, 6)
 }
 
 func TestNewBottom(t *testing.T) {
@@ -160,12 +160,11 @@ func TestBottom_Highlighted_Ctrl(t *testing.T) {
 	b := newBottom(nav)
 	b.isCtrl = true
 
-	archiveAction()
-
 	actionCalled := false
 	oldArchiveAction := archiveAction
 	defer func() { archiveAction = oldArchiveAction }()
-	archiveAction = func() {
+	archiveAction = func(n *Navigator) {
+		assert.Same(t, nav, n)
 		actionCalled = true
 	}
 	// We need to set a mock item in CTRL menu if we wanted to test action,
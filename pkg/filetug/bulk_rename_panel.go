@@ -0,0 +1,326 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftrename"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// bulkRenamePanel renames selected entries using regex find/replace & name templates
+// with a live preview of the result.
+type bulkRenamePanel struct {
+	*sneatv.Boxed
+	nav      *Navigator
+	flex     *tview.Flex
+	form     *tview.Form
+	find     *tview.InputField
+	replace  *tview.InputField
+	template *tview.InputField
+	start    *tview.InputField
+	preview  *tview.Table
+	status   *tview.TextView
+	items    []ftrename.Item
+	existing map[string]map[string]bool // dir => names
+	plan     ftrename.Plan
+}
+
+const renameOperation OperationType = "rename"
+
+var getRenameJournal = ftrename.DefaultJournal
+
+var errRenameNotSupported = fmt.Errorf("rename: %w", files.ErrNotSupported)
+
+// selectedEntries returns entries an action should be applied to.
+// It is the basket or a list if it is shown in the files panel, the selection of the files panel if it's active
+// & there is one, otherwise the current entry of the active browser.
+func (nav *Navigator) selectedEntries() []files.EntryWithDirPath {
+	if items, ok := nav.shownItems(); ok {
+		return nav.itemEntries(items)
+	}
+	if selected := nav.filesSelection(); len(selected) > 0 {
+		return selected
+	}
+	b := nav.getCurrentBrowser()
+	if b == nil {
+		return nil
+	}
+	entry := b.GetCurrentEntry()
+	if entry == nil {
+		return nil
+	}
+	return []files.EntryWithDirPath{entry}
+}
+
+func (nav *Navigator) showBulkRename() {
+	entries := nav.selectedEntries()
+	if len(entries) == 0 {
+		return
+	}
+	p := newBulkRenamePanel(nav, entries)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newBulkRenamePanel(nav *Navigator, entries []files.EntryWithDirPath) *bulkRenamePanel {
+	p := &bulkRenamePanel{
+		nav:      nav,
+		flex:     tview.NewFlex().SetDirection(tview.FlexRow),
+		form:     tview.NewForm(),
+		preview:  tview.NewTable(),
+		status:   tview.NewTextView().SetDynamicColors(true),
+		existing: make(map[string]map[string]bool),
+	}
+	p.items = make([]ftrename.Item, len(entries))
+	for i, entry := range entries {
+		item := ftrename.Item{Dir: entry.DirPath(), Name: entry.Name()}
+		if info, err := entry.Info(); err == nil && info != nil {
+			item.ModTime = info.ModTime()
+		}
+		p.items[i] = item
+	}
+	p.loadExistingNames()
+
+	onChanged := func(string) {
+		p.updatePreview()
+	}
+	p.find = tview.NewInputField().SetLabel("Find (regex)").SetChangedFunc(onChanged)
+	p.replace = tview.NewInputField().SetLabel("Replace").SetChangedFunc(onChanged)
+	p.template = tview.NewInputField().SetLabel("Template").
+		SetPlaceholder("{name}{ext}, {n:03}, {mtime:2006-01-02}").
+		SetChangedFunc(onChanged)
+	p.start = tview.NewInputField().SetLabel("Start #").SetText("1").
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetChangedFunc(onChanged)
+	p.form.AddFormItem(p.find)
+	p.form.AddFormItem(p.replace)
+	p.form.AddFormItem(p.template)
+	p.form.AddFormItem(p.start)
+	p.form.AddButton("Rename", p.apply)
+	p.form.AddButton("Undo last", func() {
+		p.close()
+		nav.undoLastRename()
+	})
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+
+	p.preview.SetFixed(1, 0)
+	p.preview.SetSelectable(false, false)
+
+	p.flex.AddItem(p.form, 11, 0, true)
+	p.flex.AddItem(p.status, 1, 0, false)
+	p.flex.AddItem(p.preview, 0, 1, false)
+
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1))
+	p.SetTitle(fmt.Sprintf("Rename %d item(s)", len(p.items)))
+	p.updatePreview()
+	return p
+}
+
+// loadExistingNames reads directories of the items to detect collisions with not renamed entries.
+func (p *bulkRenamePanel) loadExistingNames() {
+	ctx := context.Background()
+	for _, item := range p.items {
+		if _, ok := p.existing[item.Dir]; ok {
+			continue
+		}
+		names := make(map[string]bool)
+		p.existing[item.Dir] = names
+		children, err := p.nav.store.ReadDir(ctx, item.Dir)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			names[child.Name()] = true
+		}
+	}
+}
+
+func (p *bulkRenamePanel) exists(dir, name string) bool {
+	return p.existing[dir][name]
+}
+
+func (p *bulkRenamePanel) options() ftrename.Options {
+	start, _ := strconv.Atoi(p.start.GetText())
+	return ftrename.Options{
+		Find:     p.find.GetText(),
+		Replace:  p.replace.GetText(),
+		Template: p.template.GetText(),
+		Start:    start,
+	}
+}
+
+func (p *bulkRenamePanel) updatePreview() {
+	plan, err := ftrename.NewPlan(p.items, p.options(), p.exists)
+	if err != nil {
+		p.plan = ftrename.Plan{}
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	p.plan = plan
+	p.preview.Clear()
+	for col, header := range []string{"Before", "After", "Status"} {
+		cell := tview.NewTableCell(header).SetTextColor(tcell.ColorGray).SetExpansion(1)
+		p.preview.SetCell(0, col, cell)
+	}
+	var changed, problems int
+	for i, r := range plan.Renames {
+		statusText := r.Status.String()
+		color := tcell.ColorGray
+		switch r.Status {
+		case ftrename.StatusOK:
+			color = tcell.ColorLightGreen
+			changed++
+		case ftrename.StatusInvalid, ftrename.StatusCollision:
+			color = tcell.ColorOrangeRed
+			statusText += ": " + r.Problem
+			problems++
+		}
+		p.preview.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(r.Name)).SetExpansion(1))
+		p.preview.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(r.NewName)).SetTextColor(color).SetExpansion(1))
+		p.preview.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(statusText)).SetTextColor(color).SetExpansion(1))
+	}
+	if problems > 0 {
+		p.setStatus(fmt.Sprintf("[orangered]%d problem(s)[-], %d to rename", problems, changed))
+		return
+	}
+	p.setStatus(fmt.Sprintf("%d to rename", changed))
+}
+
+func (p *bulkRenamePanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+func (p *bulkRenamePanel) apply() {
+	if p.plan.Renames == nil {
+		return // invalid options, error is already shown
+	}
+	if p.plan.HasProblems() {
+		p.setStatus("[red]Resolve problems before renaming[-]")
+		return
+	}
+	changes := p.plan.Changes()
+	if len(changes) == 0 {
+		p.setStatus("Nothing to rename")
+		return
+	}
+	if _, ok := p.nav.store.(files.Renamer); !ok {
+		p.setStatus("[red]" + errRenameNotSupported.Error() + "[-]")
+		return
+	}
+	p.close()
+	p.nav.applyRenameBatch(ftrename.NewBatch(changes))
+}
+
+func (p *bulkRenamePanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *bulkRenamePanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
+
+// applyRenameBatch renames entries in background & records the batch in the rename journal.
+func (nav *Navigator) applyRenameBatch(batch ftrename.Batch) *Operation {
+	store := nav.store
+	rootURL := store.RootURL()
+	batch.Store = rootURL.String()
+	title := fmt.Sprintf("Rename %d item(s)", len(batch.Changes))
+	return nav.operations.Start(renameOperation, title, batch.Dirs(),
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			renamer, ok := store.(files.Renamer)
+			if !ok {
+				return errRenameNotSupported
+			}
+			if err := ftrename.Apply(ctx, renamer, batch.Changes, renameProgress(reportProgress)); err != nil {
+				return err
+			}
+			journal, err := getRenameJournal()
+			if err != nil {
+				return fmt.Errorf("renamed but failed to record rollback journal: %w", err)
+			}
+			if err = journal.Append(batch); err != nil {
+				return err
+			}
+			nav.recordHistory(store, ftjournal.Record{
+				Action:  ftjournal.ActionRename,
+				Moves:   renameBatchMoves(batch),
+				BatchID: batch.ID,
+			})
+			return nil
+		},
+	)
+}
+
+// undoLastRename rolls back the most recent rename batch recorded in the journal.
+func (nav *Navigator) undoLastRename() *Operation {
+	journal, err := getRenameJournal()
+	if err != nil {
+		nav.showError(err)
+		return nil
+	}
+	batch, err := journal.LastUndoable()
+	if err != nil {
+		nav.showError(err)
+		return nil
+	}
+	store := nav.store
+	rootURL := store.RootURL()
+	if batch.Store != rootURL.String() {
+		nav.showError(fmt.Errorf("last rename was made in another store: %s", batch.Store))
+		return nil
+	}
+	renamer, ok := store.(files.Renamer)
+	if !ok {
+		nav.showError(errRenameNotSupported)
+		return nil
+	}
+	title := fmt.Sprintf("Undo rename of %d item(s)", len(batch.Changes))
+	return nav.operations.Start(renameOperation, title, batch.Dirs(),
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			if err := ftrename.Undo(ctx, renamer, batch, renameProgress(reportProgress)); err != nil {
+				return err
+			}
+			if err := journal.SetUndone(batch.ID, true); err != nil {
+				return errors.Join(errors.New("rename is undone but journal is not updated"), err)
+			}
+			return recordUndoOfRenameBatch(batch.ID)
+		},
+	)
+}
+
+func renameProgress(reportProgress ProgressReporter) func(done, total int) {
+	return func(done, total int) {
+		reportProgress(OperationProgress{Total: total, Done: done})
+	}
+}
+
+// recordUndoOfRenameBatch marks the history record of a batch undone via the rename panel.
+func recordUndoOfRenameBatch(batchID string) error {
+	historyJournal, err := getHistoryJournal()
+	if err != nil {
+		return err
+	}
+	history, err := historyJournal.History()
+	if err != nil {
+		return err
+	}
+	entry, ok := history.FindByBatchID(batchID)
+	if !ok || entry.Undone {
+		return nil
+	}
+	_, err = historyJournal.Append(ftjournal.Record{Action: ftjournal.ActionUndo, Store: entry.Store, Target: entry.ID})
+	return err
+}
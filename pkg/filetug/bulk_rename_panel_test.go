@@ -0,0 +1,150 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/filetug/filetug/pkg/filetug/ftrename"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func withRenameJournal(t *testing.T) *ftrename.Journal {
+	t.Helper()
+	withTestGlobalLock(t)
+	journal := ftrename.NewJournal(filepath.Join(t.TempDir(), "rename-journal.jsonl"))
+	orig := getRenameJournal
+	getRenameJournal = func() (*ftrename.Journal, error) {
+		return journal, nil
+	}
+	t.Cleanup(func() {
+		getRenameJournal = orig
+	})
+	return journal
+}
+
+// newNavigatorWithLocalDir creates a navigator showing a temp dir with the given files
+// and the first file selected in the files panel.
+func newNavigatorWithLocalDir(t *testing.T, names ...string) (*Navigator, chan func(), string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644))
+	}
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+	nav.store = osfile.NewStore("/")
+	children, err := nav.store.ReadDir(context.Background(), dir)
+	assert.NoError(t, err)
+	dirContext := files.NewDirContext(nav.store, dir, children)
+	nav.current.SetDir(dirContext)
+	nav.files.SetRows(NewFileRows(dirContext), true)
+	nav.files.table.Select(1, 0)
+	nav.activeCol = 1
+	return nav, updates, dir
+}
+
+func TestBulkRenamePanel(t *testing.T) {
+	journal := withRenameJournal(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "IMG_1.jpg", "IMG_2.jpg")
+
+	nav.showBulkRename()
+	p, ok := nav.right.content.(*bulkRenamePanel)
+	assert.True(t, ok)
+	assert.Len(t, p.items, 1)
+	assert.Equal(t, "IMG_1.jpg", p.items[0].Name)
+	assert.Contains(t, p.status.GetText(true), "0 to rename")
+
+	t.Run("invalid_regex", func(t *testing.T) {
+		p.find.SetText("(")
+		assert.Contains(t, p.status.GetText(true), "invalid regular expression")
+		p.apply() // no-op
+		assert.Equal(t, p, nav.right.content)
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		p.find.SetText("1")
+		p.replace.SetText("2")
+		assert.Equal(t, "IMG_2.jpg", p.preview.GetCell(1, 1).Text)
+		assert.Contains(t, p.preview.GetCell(1, 2).Text, "already exists")
+		p.apply()
+		assert.Contains(t, p.status.GetText(true), "Resolve problems")
+	})
+
+	t.Run("apply_and_undo", func(t *testing.T) {
+		p.find.SetText(`^IMG_(\d)`)
+		p.replace.SetText("photo-$1")
+		p.template.SetText("{name}_{n:02}{ext}")
+		assert.Equal(t, "photo-1_01.jpg", p.preview.GetCell(1, 1).Text)
+		assert.Contains(t, p.status.GetText(true), "1 to rename")
+
+		p.apply()
+		assert.Equal(t, nav.previewer, nav.right.content)
+		operations := nav.operations.Operations()
+		assert.Len(t, operations, 1)
+		assert.NoError(t, operations[0].Wait())
+		drainQueuedUpdates(updates)
+
+		_, err := os.Stat(filepath.Join(dir, "photo-1_01.jpg"))
+		assert.NoError(t, err)
+		batch, err := journal.LastUndoable()
+		assert.NoError(t, err)
+		assert.Equal(t, []ftrename.Change{{Dir: dir, From: "IMG_1.jpg", To: "photo-1_01.jpg"}}, batch.Changes)
+		assert.Equal(t, "file:", batch.Store)
+
+		o := nav.undoLastRename()
+		assert.NotNil(t, o)
+		assert.NoError(t, o.Wait())
+		_, err = os.Stat(filepath.Join(dir, "IMG_1.jpg"))
+		assert.NoError(t, err)
+		_, err = journal.LastUndoable()
+		assert.ErrorIs(t, err, ftrename.ErrNothingToUndo)
+	})
+
+	t.Run("nothing_to_rename", func(t *testing.T) {
+		nav.showBulkRename()
+		p = nav.right.content.(*bulkRenamePanel)
+		p.apply()
+		assert.Equal(t, "Nothing to rename", p.status.GetText(true))
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+		event := tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+	})
+}
+
+func TestBulkRename_NoSelection(t *testing.T) {
+	nav, _ := newNavigatorWithQueuedUpdates(t)
+	nav.activeCol = 2
+	nav.showBulkRename()
+	assert.Equal(t, nav.previewer, nav.right.content)
+}
+
+func TestUndoLastRename_Errors(t *testing.T) {
+	journal := withRenameJournal(t)
+	nav, _ := newNavigatorWithQueuedUpdates(t)
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+
+	assert.Nil(t, nav.undoLastRename())
+	assert.ErrorIs(t, shownErr, ftrename.ErrNothingToUndo)
+
+	assert.NoError(t, journal.Append(ftrename.Batch{ID: "1", Store: "ftp://example.com"}))
+	assert.Nil(t, nav.undoLastRename())
+	assert.ErrorContains(t, shownErr, "another store")
+
+	getRenameJournal = func() (*ftrename.Journal, error) {
+		return nil, errors.New("no home")
+	}
+	assert.Nil(t, nav.undoLastRename())
+	assert.EqualError(t, shownErr, "no home")
+}
@@ -0,0 +1,533 @@
+package filetug
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/filetug/filetug/pkg/filetug/ftchecksum"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	checksumOperation       OperationType = "checksum"
+	verifyChecksumOperation OperationType = "verify_checksum"
+	writeManifestOperation  OperationType = "write_manifest"
+)
+
+// maxManifestSize limits size of a manifest read into memory.
+const maxManifestSize = 1024 * 1024
+
+var (
+	errNoChecksumFiles          = errors.New("select files or show the basket to compute checksums")
+	errNoChecksumAlgorithm      = errors.New("select at least one algorithm")
+	errNoManifestAlgorithm      = errors.New("select MD5, SHA-1, SHA-256 or SHA-512 to write a manifest")
+	errManifestFilesNotTogether = errors.New("files of a manifest must be in the same dir of the same store")
+)
+
+// copyToClipboard puts text into the system clipboard using the OSC 52 terminal escape sequence.
+var copyToClipboard = func(text string) error {
+	_, err := fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+// checksumResult is computed checksums of a file or an error.
+type checksumResult struct {
+	source ftarchive.Source
+	sums   ftchecksum.Sums
+	err    error
+}
+
+// checksumPanel computes checksums of the selected files, verifies manifests of the current dir
+// & writes manifests for the selection.
+type checksumPanel struct {
+	*sneatv.Boxed
+	nav        *Navigator
+	flex       *tview.Flex
+	form       *tview.Form
+	algorithms []*tview.Checkbox
+	manifest   *tview.DropDown // nil if there are no manifests in the current dir
+	table      *tview.Table
+	status     *tview.TextView
+	sources    []ftarchive.Source
+	manifests  []string // full paths of manifests in the current dir
+}
+
+// checksumSources returns files of the selection or the basket, dirs are skipped.
+func (nav *Navigator) checksumSources() ([]ftarchive.Source, error) {
+	sources, err := nav.archiveSources()
+	if err != nil {
+		return nil, err
+	}
+	result := sources[:0]
+	for _, src := range sources {
+		if !src.IsDir {
+			result = append(result, src)
+		}
+	}
+	return result, nil
+}
+
+// currentManifests returns checksum manifests listed in the files panel.
+func (nav *Navigator) currentManifests() []string {
+	if _, ok := nav.shownItems(); ok || nav.files.rows == nil || nav.files.rows.Dir == nil {
+		return nil
+	}
+	var manifests []string
+	for _, entry := range nav.files.rows.AllEntries {
+		if _, ok := ftchecksum.ManifestAlgorithm(entry.Name()); ok && !entry.IsDir() {
+			manifests = append(manifests, path.Join(nav.files.rows.Dir.Path(), entry.Name()))
+		}
+	}
+	return manifests
+}
+
+func (nav *Navigator) showChecksumPanel() {
+	sources, err := nav.checksumSources()
+	if err != nil {
+		nav.showError(err)
+		return
+	}
+	p := newChecksumPanel(nav, sources, nav.currentManifests())
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newChecksumPanel(nav *Navigator, sources []ftarchive.Source, manifests []string) *checksumPanel {
+	p := &checksumPanel{
+		nav:       nav,
+		flex:      tview.NewFlex().SetDirection(tview.FlexRow),
+		form:      tview.NewForm().SetItemPadding(0),
+		table:     tview.NewTable().SetSelectable(true, true).SetFixed(1, 1),
+		status:    tview.NewTextView().SetDynamicColors(true),
+		sources:   sources,
+		manifests: manifests,
+	}
+	for _, algorithm := range ftchecksum.Algorithms {
+		checkbox := tview.NewCheckbox().SetLabel(string(algorithm)).SetChecked(algorithm == ftchecksum.SHA256)
+		p.algorithms = append(p.algorithms, checkbox)
+		p.form.AddFormItem(checkbox)
+	}
+	p.form.AddButton("Compute", func() { p.compute() })
+	p.form.AddButton("Write manifest", func() { p.writeManifest() })
+	if len(manifests) > 0 {
+		names := make([]string, len(manifests))
+		for i, manifest := range manifests {
+			names[i] = path.Base(manifest)
+		}
+		p.manifest = tview.NewDropDown().SetLabel("Manifest").SetOptions(names, nil).SetCurrentOption(0)
+		p.form.AddFormItem(p.manifest)
+		p.form.AddButton("Verify", func() { p.verify() })
+	}
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+	p.table.SetInputCapture(p.tableInputCapture)
+
+	p.flex.AddItem(p.form, p.form.GetFormItemCount()+2, 0, true)
+	p.flex.AddItem(p.status, 1, 0, false)
+	p.flex.AddItem(p.table, 0, 1, false)
+
+	footer := tview.NewTextView().
+		SetText("Enter/c: copy checksum · Esc: back to the form").
+		SetTextColor(tcell.ColorGray)
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1), sneatv.WithFooter(footer))
+	p.SetTitle(fmt.Sprintf("Checksums of %d file(s)", len(sources)))
+	if len(sources) == 0 {
+		p.setStatus("[yellow]" + errNoChecksumFiles.Error() + "[-]")
+	}
+	return p
+}
+
+func (p *checksumPanel) selectedAlgorithms() []ftchecksum.Algorithm {
+	var algorithms []ftchecksum.Algorithm
+	for i, checkbox := range p.algorithms {
+		if checkbox.IsChecked() {
+			algorithms = append(algorithms, ftchecksum.Algorithms[i])
+		}
+	}
+	return algorithms
+}
+
+func (p *checksumPanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+func (p *checksumPanel) setError(err error) {
+	p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+}
+
+func (p *checksumPanel) compute() *Operation {
+	algorithms := p.selectedAlgorithms()
+	switch {
+	case len(p.sources) == 0:
+		p.setError(errNoChecksumFiles)
+		return nil
+	case len(algorithms) == 0:
+		p.setError(errNoChecksumAlgorithm)
+		return nil
+	}
+	p.setStatus("Computing…")
+	return p.nav.computeChecksums(p.sources, algorithms, func(results []checksumResult) {
+		p.showSums(algorithms, results)
+	})
+}
+
+// computeChecksums streams files through the stores in background & passes results to onDone in the UI goroutine.
+// Failures of individual files are reported in results.
+func (nav *Navigator) computeChecksums(
+	sources []ftarchive.Source, algorithms []ftchecksum.Algorithm, onDone func(results []checksumResult),
+) *Operation {
+	title := fmt.Sprintf("Checksums of %d file(s)", len(sources))
+	return nav.operations.Start(checksumOperation, title, nil,
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			results, err := checksumsOf(ctx, sources, algorithms, reportProgress)
+			if err != nil {
+				return err
+			}
+			nav.app.QueueUpdateDraw(func() {
+				onDone(results)
+			})
+			return nil
+		},
+	)
+}
+
+func checksumsOf(
+	ctx context.Context, sources []ftarchive.Source, algorithms []ftchecksum.Algorithm, reportProgress ProgressReporter,
+) ([]checksumResult, error) {
+	progress := OperationProgress{Total: len(sources)}
+	for _, src := range sources {
+		if src.Info != nil {
+			progress.BytesTotal += src.Info.Size()
+		}
+	}
+	results := make([]checksumResult, len(sources))
+	for i, src := range sources {
+		progress.Processing = []string{src.Path}
+		reportProgress(progress)
+		sums, err := ftchecksum.Compute(ctx, src.Store, src.Path, algorithms, func(n int64) {
+			progress.BytesDone += n
+			reportProgress(progress)
+		})
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		results[i] = checksumResult{source: src, sums: sums, err: err}
+		progress.Done++
+		if err != nil {
+			progress.Failed++
+			progress.Errors = append(progress.Errors, OperationError{Path: src.Path, Err: err})
+		}
+	}
+	progress.Processing = nil
+	reportProgress(progress)
+	return results, nil
+}
+
+func (p *checksumPanel) setHeader(titles ...string) {
+	p.table.Clear()
+	for col, title := range titles {
+		p.table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+}
+
+func (p *checksumPanel) showSums(algorithms []ftchecksum.Algorithm, results []checksumResult) {
+	titles := []string{"Name"}
+	for _, algorithm := range algorithms {
+		titles = append(titles, string(algorithm))
+	}
+	p.setHeader(titles...)
+	var failed int
+	for i, result := range results {
+		row := i + 1
+		p.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(path.Base(result.source.Path))).SetSelectable(false))
+		if result.err != nil {
+			failed++
+			p.table.SetCell(row, 1, tview.NewTableCell(tview.Escape(result.err.Error())).
+				SetTextColor(tcell.ColorRed).SetSelectable(false))
+			continue
+		}
+		for col, algorithm := range algorithms {
+			p.table.SetCell(row, col+1, tview.NewTableCell(result.sums[algorithm]))
+		}
+	}
+	status := fmt.Sprintf("Computed %s for %d file(s)", joinAlgorithms(algorithms), len(results)-failed)
+	if failed > 0 {
+		status += fmt.Sprintf(", [red]%d failed[-]", failed)
+	}
+	p.setStatus(status)
+	p.focusTable()
+}
+
+func joinAlgorithms(algorithms []ftchecksum.Algorithm) string {
+	names := make([]string, len(algorithms))
+	for i, algorithm := range algorithms {
+		names[i] = string(algorithm)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (p *checksumPanel) focusTable() {
+	if p.table.GetRowCount() > 1 {
+		p.table.Select(1, 1)
+	}
+	p.nav.app.SetFocus(p.table)
+}
+
+func (p *checksumPanel) selectedManifest() string {
+	if p.manifest == nil {
+		return ""
+	}
+	i, _ := p.manifest.GetCurrentOption()
+	return p.manifests[i]
+}
+
+func (p *checksumPanel) verify() *Operation {
+	manifestPath := p.selectedManifest()
+	if manifestPath == "" {
+		return nil
+	}
+	p.setStatus("Verifying…")
+	return p.nav.verifyManifest(p.nav.store, manifestPath, p.showVerification)
+}
+
+func readManifest(ctx context.Context, store files.Store, manifestPath string) (ftchecksum.Manifest, error) {
+	reader, ok := store.(files.FileReader)
+	if !ok {
+		return ftchecksum.Manifest{}, ftchecksum.ErrFileReadNotSupported
+	}
+	r, err := reader.OpenReader(ctx, manifestPath)
+	if err != nil {
+		return ftchecksum.Manifest{}, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize))
+	if err != nil {
+		return ftchecksum.Manifest{}, err
+	}
+	return ftchecksum.ParseManifest(path.Base(manifestPath), data)
+}
+
+// verifyManifest checks files listed in a manifest in background & passes results to onDone in the UI goroutine.
+func (nav *Navigator) verifyManifest(
+	store files.Store, manifestPath string, onDone func(results []ftchecksum.Result),
+) *Operation {
+	title := "Verify " + path.Base(manifestPath)
+	return nav.operations.Start(verifyChecksumOperation, title, nil,
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			manifest, err := readManifest(ctx, store, manifestPath)
+			if err != nil {
+				return err
+			}
+			progress := OperationProgress{Total: len(manifest.Entries)}
+			results, err := ftchecksum.Verify(ctx, store, path.Dir(manifestPath), manifest, func(i int, name string) {
+				progress.Done, progress.Processing = i, []string{name}
+				reportProgress(progress)
+			})
+			if err != nil {
+				return err
+			}
+			for _, result := range results {
+				if result.Status != ftchecksum.StatusPass {
+					progress.Failed++
+				}
+			}
+			progress.Done, progress.Processing = len(results), nil
+			reportProgress(progress)
+			nav.app.QueueUpdateDraw(func() {
+				onDone(results)
+			})
+			return nil
+		},
+	)
+}
+
+var checksumStatusColors = map[ftchecksum.Status]tcell.Color{
+	ftchecksum.StatusPass:    tcell.ColorGreen,
+	ftchecksum.StatusFail:    tcell.ColorRed,
+	ftchecksum.StatusMissing: tcell.ColorYellow,
+	ftchecksum.StatusError:   tcell.ColorRed,
+}
+
+func (p *checksumPanel) showVerification(results []ftchecksum.Result) {
+	p.setHeader("Name", "Status", "Expected", "Actual")
+	counts := make(map[ftchecksum.Status]int)
+	for i, result := range results {
+		row := i + 1
+		counts[result.Status]++
+		actual := result.Actual
+		if result.Err != nil {
+			actual = result.Err.Error()
+		}
+		p.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(result.Name)).SetSelectable(false))
+		p.table.SetCell(row, 1, tview.NewTableCell(string(result.Status)).
+			SetTextColor(checksumStatusColors[result.Status]).SetSelectable(false))
+		p.table.SetCell(row, 2, tview.NewTableCell(result.Sum))
+		p.table.SetCell(row, 3, tview.NewTableCell(tview.Escape(actual)))
+	}
+	status := fmt.Sprintf("[green]%d passed[-]", counts[ftchecksum.StatusPass])
+	for _, s := range []ftchecksum.Status{ftchecksum.StatusFail, ftchecksum.StatusMissing, ftchecksum.StatusError} {
+		if counts[s] > 0 {
+			status += fmt.Sprintf(", [red]%d %s[-]", counts[s], s)
+		}
+	}
+	p.setStatus(status)
+	p.focusTable()
+}
+
+// manifestTarget returns the common dir & store of the selected files.
+func (p *checksumPanel) manifestTarget() (files.Store, string, error) {
+	if len(p.sources) == 0 {
+		return nil, "", errNoChecksumFiles
+	}
+	first := p.sources[0]
+	dir := path.Dir(first.Path)
+	for _, src := range p.sources[1:] {
+		if src.Store != first.Store || path.Dir(src.Path) != dir {
+			return nil, "", errManifestFilesNotTogether
+		}
+	}
+	return first.Store, dir, nil
+}
+
+func (p *checksumPanel) writeManifest() *Operation {
+	var algorithm ftchecksum.Algorithm
+	var name string
+	for _, a := range p.selectedAlgorithms() {
+		if n, ok := ftchecksum.ManifestName(a); ok {
+			algorithm, name = a, n
+			break
+		}
+	}
+	if name == "" {
+		p.setError(errNoManifestAlgorithm)
+		return nil
+	}
+	store, dir, err := p.manifestTarget()
+	if err != nil {
+		p.setError(err)
+		return nil
+	}
+	if _, ok := store.(files.FileWriter); !ok {
+		p.setError(fmt.Errorf("writing %s: %w", name, files.ErrNotSupported))
+		return nil
+	}
+	manifestPath := path.Join(dir, name)
+	p.setStatus("Writing " + name + "…")
+	return p.nav.writeManifest(p.sources, algorithm, store, manifestPath, func(results []checksumResult) {
+		p.showSums([]ftchecksum.Algorithm{algorithm}, results)
+		p.setStatus(fmt.Sprintf("Written %s with %d file(s)", tview.Escape(manifestPath), len(results)))
+	})
+}
+
+// writeManifest computes checksums & writes them to a manifest that replaces an existing one.
+// A new manifest is recorded in history so it can be undone.
+func (nav *Navigator) writeManifest(
+	sources []ftarchive.Source,
+	algorithm ftchecksum.Algorithm,
+	store files.Store,
+	manifestPath string,
+	onDone func(results []checksumResult),
+) *Operation {
+	title := "Write " + path.Base(manifestPath)
+	return nav.operations.Start(writeManifestOperation, title, []string{path.Dir(manifestPath)},
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			results, err := checksumsOf(ctx, sources, []ftchecksum.Algorithm{algorithm}, reportProgress)
+			if err != nil {
+				return err
+			}
+			manifest := ftchecksum.Manifest{Algorithm: algorithm}
+			for _, result := range results {
+				if result.err != nil {
+					return fmt.Errorf("%s: %w", result.source.Path, result.err)
+				}
+				manifest.Entries = append(manifest.Entries, ftchecksum.Entry{
+					Name: path.Base(result.source.Path),
+					Sum:  result.sums[algorithm],
+				})
+			}
+			existed := storeEntryExists(ctx, store, manifestPath)
+			w, err := store.(files.FileWriter).OpenWriter(ctx, manifestPath)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(manifest.Format())
+			if err = errors.Join(err, w.Close()); err != nil {
+				return err
+			}
+			if !existed {
+				nav.recordHistory(store, ftjournal.Record{Action: ftjournal.ActionWriteFile, Paths: []string{manifestPath}})
+			}
+			nav.app.QueueUpdateDraw(func() {
+				onDone(results)
+			})
+			return nil
+		},
+	)
+}
+
+func storeEntryExists(ctx context.Context, store files.Store, fullPath string) bool {
+	children, err := store.ReadDir(ctx, path.Dir(fullPath))
+	if err != nil {
+		return false
+	}
+	name := path.Base(fullPath)
+	for _, child := range children {
+		if child.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// copySelectedChecksum copies a checksum in the selected cell to the clipboard.
+func (p *checksumPanel) copySelectedChecksum() {
+	row, col := p.table.GetSelection()
+	cell := p.table.GetCell(row, col)
+	if row < 1 || cell == nil || cell.Text == "" || cell.NotSelectable {
+		return
+	}
+	if err := copyToClipboard(cell.Text); err != nil {
+		p.setError(err)
+		return
+	}
+	p.setStatus("Copied " + cell.Text)
+}
+
+func (p *checksumPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *checksumPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
+
+func (p *checksumPanel) tableInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyEscape:
+		p.nav.app.SetFocus(p.form)
+		return nil
+	case event.Key() == tcell.KeyEnter, event.Key() == tcell.KeyRune && event.Rune() == 'c':
+		p.copySelectedChecksum()
+		return nil
+	default:
+		return event
+	}
+}
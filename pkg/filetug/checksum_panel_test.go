@@ -0,0 +1,180 @@
+package filetug
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/filetug/filetug/pkg/filetug/ftchecksum"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func withClipboard(t *testing.T) *string {
+	t.Helper()
+	var copied string
+	orig := copyToClipboard
+	copyToClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	t.Cleanup(func() {
+		copyToClipboard = orig
+	})
+	return &copied
+}
+
+func showChecksumsPanel(t *testing.T, nav *Navigator) *checksumPanel {
+	t.Helper()
+	nav.showChecksumPanel()
+	p, ok := nav.right.content.(*checksumPanel)
+	assert.True(t, ok)
+	return p
+}
+
+func TestChecksumPanel(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	copied := withClipboard(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.txt", "SHA256SUMS")
+	sums, err := ftchecksum.Compute(t.Context(), nav.store, filepath.Join(dir, "a.txt"), []ftchecksum.Algorithm{ftchecksum.SHA256}, nil)
+	assert.NoError(t, err)
+	manifest := sums[ftchecksum.SHA256] + "  a.txt\n" + sums[ftchecksum.SHA256] + "  b.txt\n" + sums[ftchecksum.SHA256] + "  c.txt\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(manifest), 0o644))
+
+	nav.showScriptsPanel()
+	scripts := nav.right.content.(*scriptsPanel)
+	main, _ := scripts.list.GetItemText(4)
+	assert.Equal(t, "Checksums", main)
+	scripts.list.SetCurrentItem(4)
+	scripts.list.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	p, ok := nav.right.content.(*checksumPanel)
+	assert.True(t, ok)
+	assert.Equal(t, []string{filepath.Join(dir, "SHA256SUMS")}, p.manifests)
+	if assert.Len(t, p.sources, 1) {
+		assert.Equal(t, filepath.Join(dir, "SHA256SUMS"), p.sources[0].Path, "entries are sorted upper case first")
+	}
+
+	t.Run("compute", func(t *testing.T) {
+		p.sources = []ftarchive.Source{{Store: nav.store, Path: filepath.Join(dir, "a.txt")}, {Store: nav.store, Path: filepath.Join(dir, "missing")}}
+		p.algorithms[0].SetChecked(true) // MD5
+		o := p.compute()
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, 1, o.Progress().Failed)
+		assert.Equal(t, "MD5", p.table.GetCell(0, 1).Text)
+		assert.Equal(t, "SHA-256", p.table.GetCell(0, 2).Text)
+		assert.Equal(t, sums[ftchecksum.SHA256], p.table.GetCell(1, 2).Text)
+		assert.Contains(t, p.table.GetCell(2, 1).Text, "no such file")
+		assert.Contains(t, p.status.GetText(true), "Computed MD5, SHA-256 for 1 file(s), 1 failed")
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		p.table.Select(1, 2)
+		assert.Nil(t, p.tableInputCapture(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)))
+		assert.Equal(t, sums[ftchecksum.SHA256], *copied)
+		assert.Contains(t, p.status.GetText(true), "Copied")
+
+		clipboardErr := errors.New("no clipboard")
+		copyToClipboard = func(string) error {
+			return clipboardErr
+		}
+		p.tableInputCapture(keyRune('c'))
+		assert.Contains(t, p.status.GetText(true), clipboardErr.Error())
+
+		p.table.Select(2, 1) // an error is not copied
+		p.copySelectedChecksum()
+		assert.Contains(t, p.status.GetText(true), clipboardErr.Error())
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		o := p.verify()
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, 2, o.Progress().Failed)
+		assert.Equal(t, "pass", p.table.GetCell(1, 1).Text)
+		assert.Equal(t, "fail", p.table.GetCell(2, 1).Text)
+		assert.Equal(t, "missing", p.table.GetCell(3, 1).Text)
+		assert.Equal(t, "1 passed, 1 fail, 1 missing", p.status.GetText(true))
+	})
+
+	t.Run("write_manifest", func(t *testing.T) {
+		p.sources = []ftarchive.Source{{Store: nav.store, Path: filepath.Join(dir, "a.txt")}, {Store: nav.store, Path: filepath.Join(dir, "b.txt")}}
+		p.algorithms[2].SetChecked(false) // SHA-256
+		o := p.writeManifest()
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Contains(t, p.status.GetText(true), "MD5SUMS with 2 file(s)")
+		data, err := os.ReadFile(filepath.Join(dir, "MD5SUMS"))
+		assert.NoError(t, err)
+		m, err := ftchecksum.ParseManifest("MD5SUMS", data)
+		assert.NoError(t, err)
+		assert.Len(t, m.Entries, 2)
+
+		waitOperation(t, nav.undo())
+		_, err = os.Stat(filepath.Join(dir, "MD5SUMS"))
+		assert.True(t, os.IsNotExist(err), "a new manifest can be undone")
+		assert.Nil(t, nav.redo(), "an undone manifest can't be redone as an empty file")
+		_, err = os.Stat(filepath.Join(dir, "MD5SUMS"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("write_errors", func(t *testing.T) {
+		p.algorithms[0].SetChecked(false)
+		p.algorithms[4].SetChecked(true) // CRC32 only
+		assert.Nil(t, p.writeManifest())
+		assert.Contains(t, p.status.GetText(true), errNoManifestAlgorithm.Error())
+
+		p.algorithms[4].SetChecked(false)
+		assert.Nil(t, p.compute())
+		assert.Contains(t, p.status.GetText(true), errNoChecksumAlgorithm.Error())
+
+		p.algorithms[1].SetChecked(true)
+		p.sources = append(p.sources, ftarchive.Source{Store: nav.store, Path: filepath.Join(dir, "sub", "c.txt")})
+		assert.Nil(t, p.writeManifest())
+		assert.Contains(t, p.status.GetText(true), errManifestFilesNotTogether.Error())
+
+		p.sources = nil
+		assert.Nil(t, p.writeManifest())
+		assert.Contains(t, p.status.GetText(true), errNoChecksumFiles.Error())
+		assert.Nil(t, p.compute())
+	})
+
+	t.Run("write_existing_failed_file", func(t *testing.T) {
+		p.sources = []ftarchive.Source{{Store: nav.store, Path: filepath.Join(dir, "missing")}}
+		o := p.writeManifest()
+		assert.Error(t, o.Wait())
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		assert.Nil(t, p.tableInputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		event := keyRune('z')
+		assert.Equal(t, event, p.tableInputCapture(event))
+		assert.Equal(t, event, p.inputCapture(event))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+}
+
+func TestChecksumPanel_NoFiles(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t)
+	nav.files.table.Select(0, 0) // parent dir
+	p := showChecksumsPanel(t, nav)
+	assert.Empty(t, p.sources)
+	assert.Nil(t, p.manifest)
+	assert.Nil(t, p.verify())
+	assert.Contains(t, p.status.GetText(true), errNoChecksumFiles.Error())
+}
+
+func TestNavigator_VerifyManifest_Errors(t *testing.T) {
+	nav, _, dir := newNavigatorWithLocalDir(t, "SHA256SUMS")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte("invalid\n"), 0o644))
+	onDone := func([]ftchecksum.Result) {
+		t.Error("unexpected call")
+	}
+	assert.Error(t, nav.verifyManifest(nav.store, filepath.Join(dir, "SHA256SUMS"), onDone).Wait())
+	assert.Error(t, nav.verifyManifest(nav.store, filepath.Join(dir, "missing"), onDone).Wait())
+	assert.ErrorIs(t, nav.verifyManifest(noReaderStore{}, "/SHA256SUMS", onDone).Wait(), ftchecksum.ErrFileReadNotSupported)
+}
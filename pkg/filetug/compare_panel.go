@@ -0,0 +1,449 @@
+package filetug
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/filetug/ftcompare"
+	"github.com/filetug/filetug/pkg/filetug/ftsync"
+	"github.com/filetug/filetug/pkg/fsutils"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const compareOperation OperationType = "compare"
+
+// compareFormPanel asks for 2 dirs to be compared, they can be in different stores.
+type compareFormPanel struct {
+	*sneatv.Boxed
+	nav     *Navigator
+	flex    *tview.Flex
+	form    *tview.Form
+	left    *tview.InputField
+	right   *tview.InputField
+	content *tview.Checkbox
+	status  *tview.TextView
+}
+
+func (nav *Navigator) showCompareForm() {
+	p := newCompareFormPanel(nav)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newCompareFormPanel(nav *Navigator) *compareFormPanel {
+	p := &compareFormPanel{
+		nav:    nav,
+		flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		form:   tview.NewForm(),
+		status: tview.NewTextView().SetDynamicColors(true),
+	}
+	p.left = tview.NewInputField().SetLabel("Left").SetText(nav.currentDirPath())
+	p.right = tview.NewInputField().SetLabel("Right")
+	p.content = tview.NewCheckbox().SetLabel("Compare content")
+	p.form.AddFormItem(p.left)
+	p.form.AddFormItem(p.right)
+	p.form.AddFormItem(p.content)
+	p.form.AddButton("Compare", p.compare)
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+
+	p.flex.AddItem(p.form, 9, 0, true)
+	p.flex.AddItem(p.status, 0, 1, false)
+
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1))
+	p.SetTitle("Compare directories")
+	p.status.SetText("Dirs can be paths in the current store or URLs, e.g. ftp://host/dir")
+	return p
+}
+
+func (p *compareFormPanel) compare() {
+	left, err := p.resolve("left", p.left.GetText())
+	var right ftcompare.Endpoint
+	if err == nil {
+		right, err = p.resolve("right", p.right.GetText())
+	}
+	if err != nil {
+		p.status.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	p.close()
+	nav := p.nav
+	labels := [2]string{redactURL(strings.TrimSpace(p.left.GetText())), redactURL(strings.TrimSpace(p.right.GetText()))}
+	opts := ftcompare.Options{Content: p.content.IsChecked()}
+	nav.compareDirs(left, right, opts, func(root *ftcompare.Node) {
+		cp := newComparePanel(nav, left, right, labels, opts, root)
+		nav.right.SetContent(cp)
+		nav.app.SetFocus(cp.table)
+	})
+}
+
+func (p *compareFormPanel) resolve(side, dir string) (ftcompare.Endpoint, error) {
+	store, dirPath, err := p.nav.resolveTarget(dir)
+	if err != nil {
+		return ftcompare.Endpoint{}, fmt.Errorf("%s: %w", side, err)
+	}
+	return ftcompare.Endpoint{Store: store, Dir: dirPath}, nil
+}
+
+func (p *compareFormPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *compareFormPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
+
+// compareDirs compares dirs in background & passes the merged tree to onDone in the UI goroutine.
+func (nav *Navigator) compareDirs(left, right ftcompare.Endpoint, opts ftcompare.Options, onDone func(root *ftcompare.Node)) *Operation {
+	title := fmt.Sprintf("Compare %s with %s", left.Dir, right.Dir)
+	return nav.operations.Start(compareOperation, title, nil,
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			root, err := ftcompare.Compare(ctx, left, right, opts, func(dir string) {
+				reportProgress(OperationProgress{Processing: []string{dir}})
+			})
+			if err != nil {
+				return err
+			}
+			nav.app.QueueUpdateDraw(func() {
+				onDone(root)
+			})
+			return nil
+		},
+	)
+}
+
+// comparePanel shows a merged tree of 2 dirs with sizes & modification times of entries on both sides.
+type comparePanel struct {
+	*sneatv.Boxed
+	nav       *Navigator
+	flex      *tview.Flex
+	table     *tview.Table
+	status    *tview.TextView
+	left      ftcompare.Endpoint
+	right     ftcompare.Endpoint
+	opts      ftcompare.Options
+	root      *ftcompare.Node
+	rows      []*ftcompare.Node // rows of the table after the header
+	expanded  map[string]bool   // relative paths of expanded dirs
+	diffsOnly bool
+	// overwritePending is set once > or < is pressed for a copy that overwrites files, pressing it again copies.
+	overwritePending string
+}
+
+var compareStatusSymbols = map[ftcompare.Status]string{
+	ftcompare.StatusSame:      "=",
+	ftcompare.StatusDifferent: "≠",
+	ftcompare.StatusOnlyLeft:  "◀",
+	ftcompare.StatusOnlyRight: "▶",
+}
+
+var compareStatusColors = map[ftcompare.Status]tcell.Color{
+	ftcompare.StatusSame:      tcell.ColorWhite,
+	ftcompare.StatusDifferent: tcell.ColorOrangeRed,
+	ftcompare.StatusOnlyLeft:  tcell.ColorDodgerBlue,
+	ftcompare.StatusOnlyRight: tcell.ColorMediumPurple,
+}
+
+func newComparePanel(
+	nav *Navigator,
+	left, right ftcompare.Endpoint,
+	labels [2]string,
+	opts ftcompare.Options,
+	root *ftcompare.Node,
+) *comparePanel {
+	p := &comparePanel{
+		nav:      nav,
+		flex:     tview.NewFlex().SetDirection(tview.FlexRow),
+		table:    tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		status:   tview.NewTextView().SetDynamicColors(true),
+		left:     left,
+		right:    right,
+		opts:     opts,
+		root:     root,
+		expanded: make(map[string]bool),
+	}
+	p.flex.AddItem(p.table, 0, 1, true)
+	p.flex.AddItem(p.status, 1, 0, false)
+	footer := tview.NewTextView().
+		SetText("Enter: expand · f: differences only · >: copy to right · <: copy to left · r: refresh").
+		SetTextColor(tcell.ColorGray)
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1), sneatv.WithFooter(footer))
+	p.SetTitle(fmt.Sprintf("%s ⇄ %s", labels[0], labels[1]))
+	p.table.SetInputCapture(p.inputCapture)
+	p.render()
+	return p
+}
+
+func (p *comparePanel) selectedNode() *ftcompare.Node {
+	row, _ := p.table.GetSelection()
+	if row < 1 || row > len(p.rows) {
+		return nil
+	}
+	return p.rows[row-1]
+}
+
+func compareSideCells(side *ftcompare.Side) (sizeCell, timeCell *tview.TableCell) {
+	var sizeText, timeText string
+	if side != nil {
+		if side.IsDir {
+			sizeText = "<dir>"
+		} else {
+			sizeText = fsutils.GetSizeShortText(side.Size)
+		}
+		timeText = side.ModTime.Local().Format(historyTimeLayout)
+	}
+	sizeCell = tview.NewTableCell(sizeText).SetAlign(tview.AlignRight).SetTextColor(tcell.ColorGray)
+	timeCell = tview.NewTableCell(timeText).SetTextColor(tcell.ColorGray)
+	return sizeCell, timeCell
+}
+
+func (p *comparePanel) render() {
+	var selectedPath string
+	if n := p.selectedNode(); n != nil {
+		selectedPath = n.Path
+	}
+	p.table.Clear()
+	p.rows = p.rows[:0]
+	for col, text := range []string{"Name", "Left size", "Left modified", "", "Right size", "Right modified"} {
+		p.table.SetCell(0, col, tview.NewTableCell(text).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+	var addRows func(n *ftcompare.Node, depth int)
+	addRows = func(n *ftcompare.Node, depth int) {
+		for _, child := range n.Children {
+			if p.diffsOnly && child.Status == ftcompare.StatusSame {
+				continue
+			}
+			p.rows = append(p.rows, child)
+			p.setRow(len(p.rows), child, depth)
+			if child.IsDir() && p.expanded[child.Path] {
+				addRows(child, depth+1)
+			}
+		}
+	}
+	addRows(p.root, 0)
+	if len(p.rows) == 0 {
+		text := "[::i]Both dirs are empty[::-]"
+		if p.diffsOnly {
+			text = "[::i]No differences[::-]"
+		}
+		p.table.SetCell(1, 0, tview.NewTableCell(text).SetTextColor(tcell.ColorGray).SetSelectable(false))
+	}
+	row := 1
+	for i, n := range p.rows {
+		if n.Path == selectedPath {
+			row = i + 1
+		}
+	}
+	p.table.Select(row, 0)
+	p.setStatus(string(p.root.Status))
+}
+
+func (p *comparePanel) setRow(row int, n *ftcompare.Node, depth int) {
+	marker := "  "
+	if n.IsDir() {
+		marker = "▸ "
+		if p.expanded[n.Path] {
+			marker = "▾ "
+		}
+	}
+	color := compareStatusColors[n.Status]
+	name := strings.Repeat("  ", depth) + marker + tview.Escape(n.Name)
+	p.table.SetCell(row, 0, tview.NewTableCell(name).SetTextColor(color).SetExpansion(1))
+	leftSize, leftTime := compareSideCells(n.Left)
+	p.table.SetCell(row, 1, leftSize)
+	p.table.SetCell(row, 2, leftTime)
+	p.table.SetCell(row, 3, tview.NewTableCell(compareStatusSymbols[n.Status]).SetTextColor(color))
+	rightSize, rightTime := compareSideCells(n.Right)
+	p.table.SetCell(row, 4, rightSize)
+	p.table.SetCell(row, 5, rightTime)
+}
+
+func (p *comparePanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+// showSelectedStatus explains the status of the selected entry.
+func (p *comparePanel) showSelectedStatus() {
+	n := p.selectedNode()
+	if n == nil {
+		return
+	}
+	text := tview.Escape(n.Path) + ": " + string(n.Status)
+	if n.Reason != "" {
+		text += " by " + string(n.Reason)
+	}
+	p.setStatus(text)
+}
+
+func (p *comparePanel) toggleExpanded() {
+	n := p.selectedNode()
+	if n == nil || !n.IsDir() {
+		return
+	}
+	p.expanded[n.Path] = !p.expanded[n.Path]
+	p.render()
+}
+
+func (p *comparePanel) toggleDiffsOnly() {
+	p.diffsOnly = !p.diffsOnly
+	p.render()
+}
+
+func (p *comparePanel) setRoot(root *ftcompare.Node) {
+	p.root = root
+	p.render()
+}
+
+func (p *comparePanel) refresh() *Operation {
+	return p.nav.compareDirs(p.left, p.right, p.opts, p.setRoot)
+}
+
+// copyPlan returns a plan to make the other side of a node match the given one.
+// Missing parent dirs are created, entries that are the same on both sides are skipped.
+func (p *comparePanel) copyPlan(n *ftcompare.Node, toRight bool) (*ftsync.Plan, error) {
+	from, to := p.left, p.right
+	side := func(n *ftcompare.Node) (src, dst *ftcompare.Side) {
+		if toRight {
+			return n.Left, n.Right
+		}
+		return n.Right, n.Left
+	}
+	if !toRight {
+		from, to = to, from
+	}
+	if src, _ := side(n); src == nil {
+		return nil, fmt.Errorf("%s is missing on the source side", n.Path)
+	}
+	plan := &ftsync.Plan{
+		Source:  ftsync.Endpoint{Store: from.Store, Dir: from.Dir},
+		Target:  ftsync.Endpoint{Store: to.Store, Dir: to.Dir},
+		Compare: ftsync.CompareSizeModTime,
+	}
+	for dir := path.Dir(n.Path); dir != "."; dir = path.Dir(dir) {
+		if _, dst := side(p.root.Find(dir)); dst == nil {
+			plan.Items = append([]ftsync.Item{{Path: dir, IsDir: true, Status: ftsync.StatusNew}}, plan.Items...)
+		}
+	}
+	var err error
+	n.Walk(func(node *ftcompare.Node) bool {
+		src, dst := side(node)
+		switch {
+		case err != nil || src == nil || node.Status == ftcompare.StatusSame:
+			return false
+		case dst != nil && src.IsDir != dst.IsDir:
+			err = fmt.Errorf("can't replace a file with a dir or vice versa: %s", node.Path)
+			return false
+		case src.IsDir:
+			if dst == nil {
+				plan.Items = append(plan.Items, ftsync.Item{Path: node.Path, IsDir: true, Status: ftsync.StatusNew})
+			}
+			return true
+		}
+		item := ftsync.Item{Path: node.Path, Status: ftsync.StatusNew, Size: src.Size, ModTime: src.ModTime}
+		if dst != nil {
+			item.Status = ftsync.StatusChanged
+		}
+		plan.Items = append(plan.Items, item)
+		return false
+	})
+	return plan, err
+}
+
+// copyAcross copies the selected entry to the other side & compares dirs again.
+func (p *comparePanel) copyAcross(toRight bool) *Operation {
+	n := p.selectedNode()
+	if n == nil {
+		return nil
+	}
+	plan, err := p.copyPlan(n, toRight)
+	if err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return nil
+	}
+	if plan.IsEmpty() {
+		p.setStatus("Nothing to copy")
+		return nil
+	}
+	key := "<"
+	if toRight {
+		key = ">"
+	}
+	if s := plan.Summary(); s.Changed > 0 && p.overwritePending != key+n.Path {
+		p.overwritePending = key + n.Path
+		p.setStatus(fmt.Sprintf("[orange]%d different file(s) will be overwritten, press %s again to copy[-]", s.Changed, key))
+		return nil
+	}
+	p.overwritePending = ""
+	nav, left, right, opts := p.nav, p.left, p.right, p.opts
+	title := fmt.Sprintf("Copy %s to %s", n.Path, plan.Target.Dir)
+	return nav.operations.Start(copyOperation, title, []string{plan.Target.Dir},
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			applied, err := plan.Apply(ctx, func(progress ftsync.Progress) {
+				reportProgress(OperationProgress{
+					Total:      progress.Total,
+					Done:       progress.Done,
+					BytesTotal: progress.BytesTotal,
+					BytesDone:  progress.BytesDone,
+				})
+			})
+			nav.recordSync(plan, applied)
+			if err != nil {
+				return err
+			}
+			root, err := ftcompare.Compare(ctx, left, right, opts, nil)
+			if err != nil {
+				return err
+			}
+			nav.app.QueueUpdateDraw(func() {
+				p.setRoot(root)
+			})
+			return nil
+		},
+	)
+}
+
+func (p *comparePanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *comparePanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Rune() != '>' && event.Rune() != '<' {
+		p.overwritePending = ""
+	}
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.close()
+		return nil
+	case tcell.KeyEnter:
+		p.toggleExpanded()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case ' ':
+			p.toggleExpanded()
+		case 'f':
+			p.toggleDiffsOnly()
+		case '>':
+			p.copyAcross(true)
+		case '<':
+			p.copyAcross(false)
+		case 'r':
+			p.refresh()
+		default:
+			return event
+		}
+		return nil
+	default:
+		return event
+	}
+}
@@ -0,0 +1,222 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/filetug/ftcompare"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCompareTestDirs(t *testing.T) (nav *Navigator, updates chan func(), left, right string) {
+	t.Helper()
+	nav, updates, dir := newNavigatorWithLocalDir(t)
+	left, right = filepath.Join(dir, "left"), filepath.Join(dir, "right")
+	old := time.Now().Add(-time.Hour)
+	for _, f := range []struct{ path, content string }{
+		{filepath.Join(left, "same.txt"), "same"},
+		{filepath.Join(right, "same.txt"), "same"},
+		{filepath.Join(left, "diff.txt"), "left"},
+		{filepath.Join(right, "diff.txt"), "right!"},
+		{filepath.Join(left, "sub", "deep", "l.txt"), "l"},
+		{filepath.Join(right, "r.txt"), "r"},
+		{filepath.Join(left, "kind"), "file"},
+		{filepath.Join(right, "kind", "x.txt"), "x"},
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(f.path), 0o755))
+		assert.NoError(t, os.WriteFile(f.path, []byte(f.content), 0o644))
+		assert.NoError(t, os.Chtimes(f.path, old, old))
+	}
+	return nav, updates, left, right
+}
+
+func showComparePanel(t *testing.T, nav *Navigator, updates chan func(), o *Operation) *comparePanel {
+	t.Helper()
+	waitOperation(t, o)
+	drainQueuedUpdates(updates)
+	p, ok := nav.right.content.(*comparePanel)
+	assert.True(t, ok)
+	return p
+}
+
+func selectComparePath(t *testing.T, p *comparePanel, rel string) {
+	t.Helper()
+	for i, n := range p.rows {
+		if n.Path == rel {
+			p.table.Select(i+1, 0)
+			return
+		}
+	}
+	t.Fatalf("no row for %s", rel)
+}
+
+func TestCompareFormPanel(t *testing.T) {
+	nav, updates, left, right := newCompareTestDirs(t)
+
+	nav.showScriptsPanel()
+	scripts := nav.right.content.(*scriptsPanel)
+	main, _ := scripts.list.GetItemText(3)
+	assert.Equal(t, "Compare directories", main)
+	scripts.list.SetCurrentItem(3)
+	scripts.list.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	form, ok := nav.right.content.(*compareFormPanel)
+	assert.True(t, ok)
+	assert.Equal(t, nav.currentDirPath(), form.left.GetText())
+
+	form.right.SetText("unknown://host/dir")
+	form.compare()
+	assert.Contains(t, form.status.GetText(true), "right:")
+
+	form.left.SetText(left)
+	form.right.SetText(right)
+	form.content.SetChecked(true)
+	form.compare()
+	o := lastOperation(nav)
+	assert.Equal(t, compareOperation, o.Type)
+	p := showComparePanel(t, nav, updates, o)
+	assert.True(t, p.opts.Content)
+	assert.Contains(t, p.GetTitle(), "⇄")
+
+	nav.showCompareForm()
+	form = nav.right.content.(*compareFormPanel)
+	assert.Nil(t, form.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+	assert.Equal(t, nav.previewer, nav.right.content)
+	event := keyRune('a')
+	assert.Equal(t, event, form.inputCapture(event))
+}
+
+func TestComparePanel(t *testing.T) {
+	journal := withHistoryJournal(t)
+	nav, updates, left, right := newCompareTestDirs(t)
+	leftEndpoint := ftcompare.Endpoint{Store: nav.store, Dir: left}
+	rightEndpoint := ftcompare.Endpoint{Store: nav.store, Dir: right}
+	var p *comparePanel
+	o := nav.compareDirs(leftEndpoint, rightEndpoint, ftcompare.Options{}, func(root *ftcompare.Node) {
+		p = newComparePanel(nav, leftEndpoint, rightEndpoint, [2]string{left, right}, ftcompare.Options{}, root)
+		nav.right.SetContent(p)
+	})
+	p = showComparePanel(t, nav, updates, o)
+	assert.Equal(t, ftcompare.StatusDifferent, p.root.Status)
+	names := func() (names []string) {
+		for _, n := range p.rows {
+			names = append(names, n.Path)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"kind", "sub", "diff.txt", "r.txt", "same.txt"}, names())
+
+	t.Run("expand", func(t *testing.T) {
+		selectComparePath(t, p, "sub")
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)))
+		assert.Contains(t, names(), "sub/deep")
+		assert.Equal(t, "sub", p.selectedNode().Path, "keeps selection")
+		assert.Contains(t, p.table.GetCell(2, 0).Text, "▾")
+		selectComparePath(t, p, "sub/deep")
+		p.inputCapture(keyRune(' '))
+		assert.Contains(t, names(), "sub/deep/l.txt")
+		selectComparePath(t, p, "diff.txt")
+		p.toggleExpanded() // files are not expandable
+		p.showSelectedStatus()
+		assert.Equal(t, "diff.txt: different by size", p.status.GetText(true))
+	})
+
+	t.Run("diffs_only", func(t *testing.T) {
+		p.inputCapture(keyRune('f'))
+		assert.NotContains(t, names(), "same.txt")
+		p.inputCapture(keyRune('f'))
+		assert.Contains(t, names(), "same.txt")
+	})
+
+	t.Run("type_mismatch", func(t *testing.T) {
+		selectComparePath(t, p, "kind")
+		assert.Nil(t, p.copyAcross(true))
+		assert.Contains(t, p.status.GetText(true), "can't replace")
+	})
+
+	t.Run("missing_on_source", func(t *testing.T) {
+		selectComparePath(t, p, "r.txt")
+		assert.Nil(t, p.copyAcross(true))
+		assert.Contains(t, p.status.GetText(true), "missing on the source side")
+	})
+
+	t.Run("nothing_to_copy", func(t *testing.T) {
+		selectComparePath(t, p, "same.txt")
+		assert.Nil(t, p.copyAcross(false))
+		assert.Equal(t, "Nothing to copy", p.status.GetText(true))
+	})
+
+	t.Run("copy_to_right", func(t *testing.T) {
+		selectComparePath(t, p, "sub/deep/l.txt")
+		p.inputCapture(keyRune('>'))
+		waitOperation(t, lastOperation(nav))
+		drainQueuedUpdates(updates)
+		assertFileContent(t, filepath.Join(right, "sub", "deep", "l.txt"), "l")
+		assert.Equal(t, ftcompare.StatusSame, p.root.Find("sub").Status)
+		assert.Equal(t, "sub/deep/l.txt", p.selectedNode().Path, "keeps expanded dirs & selection")
+		records, err := journal.Load()
+		assert.NoError(t, err)
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, ftjournal.ActionCopy, records[0].Action)
+			assert.Equal(t, []ftjournal.Move{{From: filepath.Join(left, "sub"), To: filepath.Join(right, "sub")}}, records[0].Moves,
+				"the created dir is undone with its content")
+		}
+	})
+
+	t.Run("copy_to_left", func(t *testing.T) {
+		selectComparePath(t, p, "diff.txt")
+		assert.Nil(t, p.copyAcross(false))
+		assert.Contains(t, p.status.GetText(true), "1 different file(s) will be overwritten, press < again")
+		p.inputCapture(keyRune('f'))
+		p.inputCapture(keyRune('f'))
+		selectComparePath(t, p, "diff.txt")
+		assert.Nil(t, p.copyAcross(false), "another key asks again")
+		assert.Nil(t, p.copyAcross(true), "the other direction asks again")
+		assertFileContent(t, filepath.Join(left, "diff.txt"), "left")
+
+		assert.Nil(t, p.inputCapture(keyRune('<')))
+		assert.Nil(t, p.inputCapture(keyRune('<')))
+		waitOperation(t, lastOperation(nav))
+		drainQueuedUpdates(updates)
+		assertFileContent(t, filepath.Join(left, "diff.txt"), "right!")
+		assert.Equal(t, ftcompare.StatusSame, p.root.Find("diff.txt").Status)
+		records, err := journal.Load()
+		assert.NoError(t, err)
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, []ftjournal.Move{{From: filepath.Join(right, "diff.txt"), To: filepath.Join(left, "diff.txt")}}, records[1].Moves)
+		}
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filepath.Join(right, "r.txt")))
+		p.inputCapture(keyRune('r'))
+		waitOperation(t, lastOperation(nav))
+		drainQueuedUpdates(updates)
+		assert.Nil(t, p.root.Find("r.txt"))
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		event := keyRune('z')
+		assert.Equal(t, event, p.inputCapture(event))
+		event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+}
+
+func TestComparePanel_Empty(t *testing.T) {
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	endpoint := ftcompare.Endpoint{Store: nav.store, Dir: dir}
+	root := &ftcompare.Node{Path: ".", Status: ftcompare.StatusSame}
+	p := newComparePanel(nav, endpoint, endpoint, [2]string{dir, dir}, ftcompare.Options{}, root)
+	assert.Contains(t, p.table.GetCell(1, 0).Text, "Both dirs are empty")
+	assert.Nil(t, p.selectedNode())
+	assert.Nil(t, p.copyAcross(true))
+	p.showSelectedStatus()
+	p.toggleDiffsOnly()
+	assert.Contains(t, p.table.GetCell(1, 0).Text, "No differences")
+}
@@ -1251,8 +1251,9 @@ func TestNavigator_DeleteEntries_Error(t *testing.T) {
 	ctx := context.Background()
 	store := newMockStore(t)
 	store.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("fail")).AnyTimes()
-	err := deleteEntries(ctx, store, []string{"/tmp/file"}, func(progress OperationProgress) {})
+	deleted, err := deleteEntries(ctx, store, []string{"/tmp/file"}, func(progress OperationProgress) {})
 	assert.Error(t, err)
+	assert.Equal(t, 0, deleted)
 }
 
 func TestNavigator_GitStatusText_HasChanges(t *testing.T) {
@@ -1723,8 +1724,9 @@ func TestNavigator_DeleteEntries_Success(t *testing.T) {
 	ctx := context.Background()
 	store := newMockStore(t)
 	store.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	err := deleteEntries(ctx, store, []string{"/tmp/file"}, func(progress OperationProgress) {})
+	deleted, err := deleteEntries(ctx, store, []string{"/tmp/file"}, func(progress OperationProgress) {})
 	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
 }
 
 func TestNavigator_GitStatusText_IsRepoRoot(t *testing.T) {
@@ -2185,7 +2187,7 @@ func TestGeneratedNestedDirs_SubdirError(t *testing.T) {
 	).AnyTimes()
 	ctx := context.Background()
 	err := GeneratedNestedDirs(ctx, store, "/tmp", "", 2, 1)
-	assert.NoError(t, err)
+	assert.EqualError(t, err, "fail")
 	mu.Lock()
 	assert.Greater(t, calls, 1)
 	mu.Unlock()
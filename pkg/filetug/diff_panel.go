@@ -0,0 +1,400 @@
+package filetug
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/filetug/filetug/pkg/chroma2tcell"
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftdiff"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const diffOperation OperationType = "diff"
+
+// maxDiffFileSize limits size of files to be compared as the whole content is kept in memory.
+const maxDiffFileSize = 1024 * 1024
+
+var (
+	errDiffNotFile    = errors.New("select a file to compare")
+	errDiffBinaryFile = errors.New("binary files can't be compared")
+	errDiffFileTooBig = fmt.Errorf("files over %d KB can't be compared", maxDiffFileSize/1024)
+)
+
+// diffSource is a file to be compared, files can be in different stores.
+type diffSource struct {
+	store files.Store
+	path  string
+}
+
+func (s diffSource) key() string {
+	rootURL := s.store.RootURL()
+	return rootURL.String() + s.path
+}
+
+// diffSourceOf returns the current entry of the files panel, the basket or a list.
+func (nav *Navigator) diffSourceOf() (src diffSource, err error) {
+	var entry files.EntryWithDirPath
+	if _, ok := nav.shownItems(); ok {
+		entry = nav.files.GetCurrentEntry()
+	} else if b := nav.getCurrentBrowser(); b != nil {
+		entry = b.GetCurrentEntry()
+	}
+	if entry == nil || entry.IsDir() {
+		return src, errDiffNotFile
+	}
+	src.store, src.path = nav.store, entry.FullName()
+	if e, ok := entry.(basketEntry); ok {
+		if src.store, err = nav.storeFor(e.item.Store); err != nil {
+			return src, err
+		}
+	}
+	return src, nil
+}
+
+// markForDiff marks the current file to be compared with a file selected next.
+// When a file is marked already, it's compared with the current file.
+func (nav *Navigator) markForDiff() *Operation {
+	src, err := nav.diffSourceOf()
+	if err != nil {
+		nav.showError(err)
+		return nil
+	}
+	if nav.diffMark == nil || nav.diffMark.key() == src.key() {
+		nav.diffMark = &src
+		nav.previewer.SetText(fmt.Sprintf("Marked %s for diff, select another file & press Ctrl+D", src.path))
+		return nil
+	}
+	oldFile := *nav.diffMark
+	nav.diffMark = nil
+	return nav.diffFiles(oldFile, src)
+}
+
+func readDiffFile(ctx context.Context, src diffSource) (string, error) {
+	reader, ok := src.store.(files.FileReader)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", src.path, files.ErrNotSupported)
+	}
+	r, err := reader.OpenReader(ctx, src.path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(r, maxDiffFileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxDiffFileSize {
+		return "", fmt.Errorf("%s: %w", src.path, errDiffFileTooBig)
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "", fmt.Errorf("%s: %w", src.path, errDiffBinaryFile)
+	}
+	return string(data), nil
+}
+
+// diffFiles reads both files in background & shows differences in the right panel.
+func (nav *Navigator) diffFiles(oldFile, newFile diffSource) *Operation {
+	title := fmt.Sprintf("Diff %s with %s", oldFile.path, newFile.path)
+	return nav.operations.Start(diffOperation, title, nil,
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			var texts [2]string
+			for i, src := range []diffSource{oldFile, newFile} {
+				reportProgress(OperationProgress{Total: 2, Done: i, Processing: []string{src.path}})
+				text, err := readDiffFile(ctx, src)
+				if err != nil {
+					return err
+				}
+				texts[i] = text
+			}
+			nav.app.QueueUpdateDraw(func() {
+				p := newDiffPanel(nav, oldFile, newFile, texts[0], texts[1])
+				nav.right.SetContent(p)
+				nav.app.SetFocus(p.table)
+			})
+			return nil
+		},
+	)
+}
+
+// diffPanel shows differences of 2 text files as a unified or a side-by-side view.
+type diffPanel struct {
+	*sneatv.Boxed
+	nav        *Navigator
+	flex       *tview.Flex
+	table      *tview.Table
+	status     *tview.TextView
+	oldText    string
+	newText    string
+	lexer      chroma.Lexer
+	opts       ftdiff.Options
+	sideBySide bool
+	diff       *ftdiff.Diff
+	hunkRows   []int // table rows of hunk headers
+}
+
+const (
+	diffStyle               = "dracula"
+	diffDeletedChangeColor  = "#5f0000" // background of changed parts of deleted lines
+	diffInsertedChangeColor = "#005f00"
+	diffTabWidth            = 4
+)
+
+var (
+	diffDeletedLineColor  = tcell.NewHexColor(0x2f0000)
+	diffInsertedLineColor = tcell.NewHexColor(0x002f00)
+)
+
+func newDiffPanel(nav *Navigator, oldFile, newFile diffSource, oldText, newText string) *diffPanel {
+	p := &diffPanel{
+		nav:     nav,
+		flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		table:   tview.NewTable().SetSelectable(true, false),
+		status:  tview.NewTextView().SetDynamicColors(true),
+		oldText: oldText,
+		newText: newText,
+		lexer:   lexers.Match(path.Base(newFile.path)),
+	}
+	if p.lexer == nil {
+		p.lexer = lexers.Fallback
+	}
+	p.flex.AddItem(p.table, 0, 1, true)
+	p.flex.AddItem(p.status, 1, 0, false)
+	footer := tview.NewTextView().
+		SetText("n/p: next/previous hunk · s: side-by-side · w: ignore whitespace").
+		SetTextColor(tcell.ColorGray)
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1), sneatv.WithFooter(footer))
+	p.SetTitle(fmt.Sprintf("%s ⇄ %s", oldFile.path, newFile.path))
+	p.table.SetInputCapture(p.inputCapture)
+	p.table.SetSelectionChangedFunc(func(_, _ int) {
+		p.updateStatus()
+	})
+	p.compute()
+	return p
+}
+
+func (p *diffPanel) compute() {
+	p.diff = ftdiff.Compute(p.oldText, p.newText, p.opts)
+	p.render()
+}
+
+// expandTabs replaces tabs with spaces as table cells do not render them & shifts spans accordingly.
+func expandTabs(text string, changes []ftdiff.Span) (string, [][2]int) {
+	offsets := make([]int, len(text)+1)
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		offsets[i] = sb.Len()
+		if text[i] == '\t' {
+			sb.WriteString(strings.Repeat(" ", diffTabWidth))
+		} else {
+			sb.WriteByte(text[i])
+		}
+	}
+	offsets[len(text)] = sb.Len()
+	spans := make([][2]int, len(changes))
+	for i, change := range changes {
+		spans[i] = [2]int{offsets[change.Start], offsets[change.End]}
+	}
+	return sb.String(), spans
+}
+
+func (p *diffPanel) lineText(line ftdiff.Line, background string) string {
+	text, spans := expandTabs(line.Text, line.Changes)
+	colorized, err := chroma2tcell.ColorizeSpans(text, diffStyle, p.lexer, spans, background)
+	if err != nil {
+		return tview.Escape(text)
+	}
+	return colorized
+}
+
+func lineNumCell(num int) *tview.TableCell {
+	var text string
+	if num > 0 {
+		text = fmt.Sprint(num)
+	}
+	return tview.NewTableCell(text).SetAlign(tview.AlignRight).SetTextColor(tcell.ColorGray)
+}
+
+func (p *diffPanel) render() {
+	selectedRow, _ := p.table.GetSelection()
+	p.table.Clear()
+	p.hunkRows = p.hunkRows[:0]
+	if p.diff.IsEmpty() {
+		text := "[::i]Files are identical[::-]"
+		if p.opts.IgnoreWhitespace {
+			text = "[::i]Files are identical ignoring whitespace[::-]"
+		}
+		p.table.SetCell(0, 0, tview.NewTableCell(text).SetTextColor(tcell.ColorGray).SetSelectable(false))
+		p.updateStatus()
+		return
+	}
+	textCol := 3
+	if p.sideBySide {
+		textCol = 1
+	}
+	row := 0
+	for _, hunk := range p.diff.Hunks {
+		p.hunkRows = append(p.hunkRows, row)
+		p.table.SetCell(row, textCol, tview.NewTableCell(hunk.Header()).SetTextColor(tcell.ColorDarkCyan))
+		row++
+		if p.sideBySide {
+			row = p.renderSideBySide(row, hunk.Rows)
+		} else {
+			row = p.renderUnified(row, hunk.Rows)
+		}
+	}
+	p.table.Select(min(selectedRow, row-1), 0)
+	p.updateStatus()
+}
+
+func (p *diffPanel) renderSideBySide(row int, rows []ftdiff.Row) int {
+	for _, r := range rows {
+		oldCell, newCell := tview.NewTableCell(""), tview.NewTableCell("")
+		switch r.Kind {
+		case ftdiff.KindEqual:
+			oldCell.SetText(p.lineText(r.Old, ""))
+			newCell.SetText(p.lineText(r.New, ""))
+		default:
+			if r.Kind != ftdiff.KindInsert {
+				oldCell.SetText(p.lineText(r.Old, diffDeletedChangeColor)).SetBackgroundColor(diffDeletedLineColor)
+			}
+			if r.Kind != ftdiff.KindDelete {
+				newCell.SetText(p.lineText(r.New, diffInsertedChangeColor)).SetBackgroundColor(diffInsertedLineColor)
+			}
+		}
+		p.table.SetCell(row, 0, lineNumCell(r.Old.Num))
+		p.table.SetCell(row, 1, oldCell.SetExpansion(1))
+		p.table.SetCell(row, 2, lineNumCell(r.New.Num))
+		p.table.SetCell(row, 3, newCell.SetExpansion(1))
+		row++
+	}
+	return row
+}
+
+func (p *diffPanel) renderUnified(row int, rows []ftdiff.Row) int {
+	setLine := func(line ftdiff.Line, oldNum, newNum int, sign string, color tcell.Color, background string) {
+		p.table.SetCell(row, 0, lineNumCell(oldNum))
+		p.table.SetCell(row, 1, lineNumCell(newNum))
+		p.table.SetCell(row, 2, tview.NewTableCell(sign).SetTextColor(color))
+		p.table.SetCell(row, 3, tview.NewTableCell(p.lineText(line, background)).SetExpansion(1))
+		row++
+	}
+	// Lines of a block of changes are listed as all deleted lines followed by all inserted ones.
+	for i := 0; i < len(rows); {
+		if rows[i].Kind == ftdiff.KindEqual {
+			setLine(rows[i].New, rows[i].Old.Num, rows[i].New.Num, " ", tcell.ColorGray, "")
+			i++
+			continue
+		}
+		end := i
+		for end < len(rows) && rows[end].Kind != ftdiff.KindEqual {
+			end++
+		}
+		for _, r := range rows[i:end] {
+			if r.Kind != ftdiff.KindInsert {
+				setLine(r.Old, r.Old.Num, 0, "-", tcell.ColorRed, diffDeletedChangeColor)
+			}
+		}
+		for _, r := range rows[i:end] {
+			if r.Kind != ftdiff.KindDelete {
+				setLine(r.New, 0, r.New.Num, "+", tcell.ColorGreen, diffInsertedChangeColor)
+			}
+		}
+		i = end
+	}
+	return row
+}
+
+// currentHunk returns index of a hunk the selected row belongs to or -1.
+func (p *diffPanel) currentHunk() int {
+	row, _ := p.table.GetSelection()
+	current := -1
+	for i, hunkRow := range p.hunkRows {
+		if hunkRow <= row {
+			current = i
+		}
+	}
+	return current
+}
+
+func (p *diffPanel) updateStatus() {
+	status := fmt.Sprintf("[green]+%d[-] [red]-%d[-]", p.diff.Insertions, p.diff.Deletions)
+	if len(p.hunkRows) > 0 {
+		status += fmt.Sprintf(" · hunk %d/%d", p.currentHunk()+1, len(p.hunkRows))
+	}
+	if p.sideBySide {
+		status += " · side-by-side"
+	} else {
+		status += " · unified"
+	}
+	if p.opts.IgnoreWhitespace {
+		status += " · ignoring whitespace"
+	}
+	p.status.SetText(status)
+}
+
+// moveHunk selects a header of the next or the previous hunk.
+func (p *diffPanel) moveHunk(delta int) {
+	if len(p.hunkRows) == 0 {
+		return
+	}
+	row, _ := p.table.GetSelection()
+	i := p.currentHunk()
+	if delta < 0 && i >= 0 && row > p.hunkRows[i] {
+		i++ // inside a hunk previous goes to its header
+	}
+	i = max(0, min(i+delta, len(p.hunkRows)-1))
+	p.table.Select(p.hunkRows[i], 0)
+}
+
+func (p *diffPanel) toggleSideBySide() {
+	p.sideBySide = !p.sideBySide
+	p.table.Select(0, 0)
+	p.render()
+}
+
+func (p *diffPanel) toggleIgnoreWhitespace() {
+	p.opts.IgnoreWhitespace = !p.opts.IgnoreWhitespace
+	p.table.Select(0, 0)
+	p.compute()
+}
+
+func (p *diffPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *diffPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.close()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'n', ']':
+			p.moveHunk(1)
+		case 'p', '[':
+			p.moveHunk(-1)
+		case 's':
+			p.toggleSideBySide()
+		case 'w':
+			p.toggleIgnoreWhitespace()
+		default:
+			return event
+		}
+		return nil
+	default:
+		return event
+	}
+}
@@ -0,0 +1,202 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftdiff"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	diffTestOld = "package main\n\nfunc a() {\n\treturn 1\n}\n\nfunc b() {}\n\nfunc c() {}\n\nfunc d() {}\n\nfunc e() {}\n"
+	diffTestNew = "package main\n\nfunc a() {\n\treturn 2\n}\n\nfunc b() {}\n\nfunc c() {}\n\nfunc d() {}\n\nfunc e() {}\nfunc f() {}\n"
+)
+
+func newDiffTestDir(t *testing.T) (*Navigator, chan func(), string) {
+	t.Helper()
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.go", "b.go")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte(diffTestOld), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.go"), []byte(diffTestNew), 0o644))
+	return nav, updates, dir
+}
+
+func showDiffPanel(t *testing.T, nav *Navigator, updates chan func(), o *Operation) *diffPanel {
+	t.Helper()
+	waitOperation(t, o)
+	drainQueuedUpdates(updates)
+	p, ok := nav.right.content.(*diffPanel)
+	assert.True(t, ok)
+	return p
+}
+
+func TestNavigator_MarkForDiff(t *testing.T) {
+	nav, updates, dir := newDiffTestDir(t)
+
+	nav.files.table.Select(0, 0) // parent dir
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+	assert.Nil(t, nav.markForDiff())
+	assert.ErrorIs(t, shownErr, errDiffNotFile)
+
+	nav.files.table.Select(1, 0)
+	nav.files.inputCapture(tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl))
+	if assert.NotNil(t, nav.diffMark) {
+		assert.Equal(t, filepath.Join(dir, "a.go"), nav.diffMark.path)
+	}
+	assert.Nil(t, nav.markForDiff(), "marking the same file again")
+	assert.NotNil(t, nav.diffMark)
+
+	nav.files.table.Select(2, 0)
+	o := nav.markForDiff()
+	assert.Nil(t, nav.diffMark)
+	if assert.NotNil(t, o) {
+		assert.Equal(t, diffOperation, o.Type)
+		p := showDiffPanel(t, nav, updates, o)
+		assert.Contains(t, p.GetTitle(), "b.go")
+	}
+}
+
+func TestDiffPanel(t *testing.T) {
+	nav, updates, dir := newDiffTestDir(t)
+	store := nav.store
+	o := nav.diffFiles(diffSource{store, filepath.Join(dir, "a.go")}, diffSource{store, filepath.Join(dir, "b.go")})
+	p := showDiffPanel(t, nav, updates, o)
+	assert.Equal(t, []int{0, 9}, p.hunkRows)
+	assert.Contains(t, p.status.GetText(true), "+2 -1 · hunk 1/2 · unified")
+
+	t.Run("unified", func(t *testing.T) {
+		assert.Equal(t, "@@ -1,7 +1,7 @@", p.table.GetCell(0, 3).Text)
+		assert.Equal(t, "-", p.table.GetCell(4, 2).Text)
+		assert.Equal(t, "4", p.table.GetCell(4, 0).Text)
+		assert.Equal(t, "", p.table.GetCell(4, 1).Text)
+		assert.Equal(t, "+", p.table.GetCell(5, 2).Text)
+		assert.Contains(t, p.table.GetCell(5, 3).Text, ":"+diffInsertedChangeColor+"]2", "intra-line change")
+		assert.Contains(t, p.table.GetCell(5, 3).Text, "    ", "tabs are expanded")
+	})
+
+	t.Run("hunks", func(t *testing.T) {
+		p.inputCapture(keyRune('n'))
+		row, _ := p.table.GetSelection()
+		assert.Equal(t, 9, row)
+		assert.Contains(t, p.status.GetText(true), "hunk 2/2")
+		p.inputCapture(keyRune('n'))
+		row, _ = p.table.GetSelection()
+		assert.Equal(t, 9, row, "stays at the last hunk")
+		p.table.Select(11, 0)
+		p.inputCapture(keyRune('p'))
+		row, _ = p.table.GetSelection()
+		assert.Equal(t, 9, row, "goes to header of the current hunk")
+		p.inputCapture(keyRune('['))
+		row, _ = p.table.GetSelection()
+		assert.Equal(t, 0, row)
+	})
+
+	t.Run("side_by_side", func(t *testing.T) {
+		p.inputCapture(keyRune('s'))
+		assert.Contains(t, p.status.GetText(true), "side-by-side")
+		assert.Equal(t, "@@ -1,7 +1,7 @@", p.table.GetCell(0, 1).Text)
+		assert.Equal(t, "4", p.table.GetCell(4, 0).Text)
+		assert.Equal(t, "4", p.table.GetCell(4, 2).Text)
+		_, background, _ := p.table.GetCell(4, 1).Style.Decompose()
+		assert.Equal(t, diffDeletedLineColor, background)
+		_, background, _ = p.table.GetCell(4, 3).Style.Decompose()
+		assert.Equal(t, diffInsertedLineColor, background)
+		lastRow := p.table.GetRowCount() - 1
+		assert.Equal(t, "", p.table.GetCell(lastRow, 0).Text, "inserted line has no old line")
+		assert.Equal(t, "14", p.table.GetCell(lastRow, 2).Text)
+		p.inputCapture(keyRune('s'))
+		assert.Contains(t, p.status.GetText(true), "unified")
+	})
+
+	t.Run("ignore_whitespace", func(t *testing.T) {
+		p.oldText = strings.Replace(diffTestNew, "\treturn", "  return", 1)
+		p.inputCapture(keyRune('w'))
+		assert.Contains(t, p.status.GetText(true), "ignoring whitespace")
+		assert.Contains(t, p.table.GetCell(0, 0).Text, "identical ignoring whitespace")
+		assert.Empty(t, p.hunkRows)
+		p.moveHunk(1)
+		p.inputCapture(keyRune('w'))
+		assert.Len(t, p.hunkRows, 1)
+		p.oldText = diffTestNew
+		p.compute()
+		assert.Contains(t, p.table.GetCell(0, 0).Text, "Files are identical")
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		event := keyRune('z')
+		assert.Equal(t, event, p.inputCapture(event))
+		event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+}
+
+func TestDiffPanel_PlainText(t *testing.T) {
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	src := diffSource{nav.store, filepath.Join(dir, "unknown-type")}
+	p := newDiffPanel(nav, src, src, "a [red]\n", "b [red]\n")
+	assert.Contains(t, p.table.GetCell(1, 3).Text, "[red[]")
+}
+
+func TestExpandTabs(t *testing.T) {
+	text, spans := expandTabs("\ta\tb", []ftdiff.Span{{Start: 1, End: 2}, {Start: 3, End: 4}})
+	assert.Equal(t, "    a    b", text)
+	assert.Equal(t, [][2]int{{4, 5}, {9, 10}}, spans)
+}
+
+type noReaderStore struct {
+	files.Store
+}
+
+type contentReaderStore struct {
+	files.Store
+	err     error
+	content string
+}
+
+func (s contentReaderStore) OpenReader(_ context.Context, _ string) (io.ReadCloser, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return io.NopCloser(strings.NewReader(s.content)), nil
+}
+
+func TestReadDiffFile(t *testing.T) {
+	ctx := context.Background()
+	_, err := readDiffFile(ctx, diffSource{noReaderStore{}, "/a"})
+	assert.ErrorIs(t, err, files.ErrNotSupported)
+
+	openErr := errors.New("open failed")
+	_, err = readDiffFile(ctx, diffSource{contentReaderStore{err: openErr}, "/a"})
+	assert.ErrorIs(t, err, openErr)
+
+	_, err = readDiffFile(ctx, diffSource{contentReaderStore{content: "a\x00b"}, "/a"})
+	assert.ErrorIs(t, err, errDiffBinaryFile)
+
+	_, err = readDiffFile(ctx, diffSource{contentReaderStore{content: strings.Repeat("a", maxDiffFileSize+1)}, "/a"})
+	assert.ErrorIs(t, err, errDiffFileTooBig)
+
+	text, err := readDiffFile(ctx, diffSource{contentReaderStore{content: "text"}, "/a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "text", text)
+}
+
+func TestNavigator_DiffFiles_Error(t *testing.T) {
+	nav, updates, dir := newNavigatorWithLocalDir(t)
+	src := diffSource{nav.store, filepath.Join(dir, "missing.txt")}
+	o := nav.diffFiles(src, src)
+	assert.Error(t, o.Wait())
+	drainQueuedUpdates(updates)
+	assert.Equal(t, nav.previewer, nav.right.content)
+}
@@ -2,23 +2,21 @@ package filetug
 
 import (
 	"fmt"
-	"os"
 
 	"golang.org/x/sys/windows"
 )
 
 var getLogicalDriveStrings = windows.GetLogicalDriveStrings
 
-func getWindowsDrives() []string {
+func getWindowsDrives() ([]string, error) {
 	buf := make([]uint16, 254)
 	n, err := getLogicalDriveStrings(uint32(len(buf)), &buf[0])
 
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to read windows drives: %v\n", err)
-		return nil
+		return nil, fmt.Errorf("failed to read windows drives: %w", err)
 	}
 
 	// Convert UTF-16 buffer to Go string list
 	drives := windows.UTF16ToString(buf[:n])
-	return splitNull(drives)
+	return splitNull(drives), nil
 }
@@ -0,0 +1,420 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftdupes"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/filetug/filetug/pkg/fsutils"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	findDupesOperation OperationType = "findDuplicates"
+	linkDupesOperation OperationType = "linkDuplicates"
+)
+
+var errNothingMarked = errors.New("no files are marked")
+
+// dupesFormPanel asks for masks & a min size of files to be checked for duplicates in the current dir.
+type dupesFormPanel struct {
+	*sneatv.Boxed
+	nav     *Navigator
+	flex    *tview.Flex
+	form    *tview.Form
+	include *tview.DropDown
+	exclude *tview.DropDown
+	minSize *tview.InputField
+	status  *tview.TextView
+	masks   []masks.Mask
+}
+
+func (nav *Navigator) showFindDuplicates() {
+	p := newDupesFormPanel(nav)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newDupesFormPanel(nav *Navigator) *dupesFormPanel {
+	p := &dupesFormPanel{
+		nav:    nav,
+		flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		form:   tview.NewForm(),
+		status: tview.NewTextView().SetDynamicColors(true),
+		masks:  nav.allMasks(),
+	}
+	p.include = newMaskDropDown("Include mask", p.masks)
+	p.exclude = newMaskDropDown("Exclude mask", p.masks)
+	p.minSize = tview.NewInputField().SetLabel("Min size (bytes)").SetText("1").
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	p.form.AddFormItem(p.include)
+	p.form.AddFormItem(p.exclude)
+	p.form.AddFormItem(p.minSize)
+	p.form.AddButton("Find", p.find)
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+
+	p.flex.AddItem(p.form, 9, 0, true)
+	p.flex.AddItem(p.status, 0, 1, false)
+
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1))
+	p.SetTitle("Find duplicates")
+	p.status.SetText("Files are searched recursively in " + tview.Escape(nav.currentDirPath()))
+	return p
+}
+
+func (p *dupesFormPanel) options() (ftdupes.Options, error) {
+	opts := ftdupes.Options{
+		Include: selectedMask(p.masks, p.include),
+		Exclude: selectedMask(p.masks, p.exclude),
+	}
+	var err error
+	if opts.MinSize, err = strconv.ParseInt(p.minSize.GetText(), 10, 64); err != nil {
+		return opts, fmt.Errorf("%s: %w", p.minSize.GetLabel(), err)
+	}
+	return opts, nil
+}
+
+func (p *dupesFormPanel) find() {
+	opts, err := p.options()
+	if err != nil {
+		p.status.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	p.close()
+	p.nav.findDuplicates(p.nav.currentDirPath(), opts)
+}
+
+func (p *dupesFormPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *dupesFormPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
+
+// findDuplicates searches dir for duplicates in background & shows them once found.
+func (nav *Navigator) findDuplicates(dir string, opts ftdupes.Options) *Operation {
+	store := nav.store
+	return nav.operations.Start(findDupesOperation, "Find duplicates in "+dir, nil,
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			groups, err := ftdupes.Find(ctx, store, dir, opts, func(p ftdupes.Progress) {
+				progress := OperationProgress{
+					Total:      p.Total,
+					Done:       p.Done,
+					BytesTotal: p.BytesTotal,
+					BytesDone:  p.BytesDone,
+				}
+				if p.Current != "" {
+					progress.Processing = []string{string(p.Phase) + ": " + p.Current}
+				}
+				reportProgress(progress)
+			})
+			if err != nil {
+				return err
+			}
+			nav.app.QueueUpdateDraw(func() {
+				p := newDupesPanel(nav, store, dir, groups)
+				nav.right.SetContent(p)
+				nav.app.SetFocus(p.table)
+			})
+			return nil
+		},
+	)
+}
+
+// dupesRow is a file or a group header (file < 0) in the dupes table.
+type dupesRow struct {
+	group int
+	file  int
+}
+
+// dupesPanel lists sets of duplicates & lets a user mark redundant copies to be deleted or replaced with links.
+type dupesPanel struct {
+	*sneatv.Boxed
+	nav    *Navigator
+	flex   *tview.Flex
+	table  *tview.Table
+	status *tview.TextView
+	store  files.Store // the store that was scanned, it's kept if the navigator switches to another one
+	dir    string
+	groups []ftdupes.Group
+	rows   []dupesRow
+	marked map[string]bool
+}
+
+func newDupesPanel(nav *Navigator, store files.Store, dir string, groups []ftdupes.Group) *dupesPanel {
+	p := &dupesPanel{
+		nav:    nav,
+		flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		table:  tview.NewTable().SetSelectable(true, false),
+		status: tview.NewTextView().SetDynamicColors(true),
+		store:  store,
+		dir:    dir,
+		groups: groups,
+		marked: make(map[string]bool),
+	}
+	p.flex.AddItem(p.table, 0, 1, true)
+	p.flex.AddItem(p.status, 1, 0, false)
+	footer := tview.NewTextView().
+		SetText("Space: mark · n/o/p: keep newest/oldest/shortest path · d: delete · h/l: hard/sym link").
+		SetTextColor(tcell.ColorGray)
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1), sneatv.WithFooter(footer))
+	p.table.SetInputCapture(p.inputCapture)
+	p.render()
+	return p
+}
+
+func (p *dupesPanel) render() {
+	p.table.Clear()
+	p.rows = p.rows[:0]
+	var wasted int64
+	for gi, g := range p.groups {
+		wasted += g.Wasted()
+		header := fmt.Sprintf("%d × %s, %s wasted",
+			len(g.Files), fsutils.GetSizeShortText(g.Size), fsutils.GetSizeShortText(g.Wasted()))
+		row := len(p.rows)
+		p.rows = append(p.rows, dupesRow{group: gi, file: -1})
+		p.table.SetCell(row, 0, tview.NewTableCell("").SetSelectable(false))
+		p.table.SetCell(row, 1, tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+		for fi, f := range g.Files {
+			row = len(p.rows)
+			p.rows = append(p.rows, dupesRow{group: gi, file: fi})
+			mark := " "
+			color := tcell.ColorWhite
+			if p.marked[f.Path] {
+				mark = "✗"
+				color = tcell.ColorOrangeRed
+			}
+			name := strings.TrimPrefix(strings.TrimPrefix(f.Path, p.dir), "/")
+			p.table.SetCell(row, 0, tview.NewTableCell(mark).SetTextColor(color))
+			p.table.SetCell(row, 1, tview.NewTableCell(tview.Escape(name)).SetTextColor(color).SetExpansion(1))
+			p.table.SetCell(row, 2, tview.NewTableCell(fsutils.GetSizeShortText(f.Size)).
+				SetAlign(tview.AlignRight).SetTextColor(tcell.ColorGray))
+			p.table.SetCell(row, 3, tview.NewTableCell(f.ModTime.Local().Format(historyTimeLayout)).
+				SetTextColor(tcell.ColorGray))
+		}
+	}
+	if len(p.groups) == 0 {
+		p.table.SetCell(0, 1, tview.NewTableCell("[::i]No duplicates found[::-]").SetTextColor(tcell.ColorGray))
+	}
+	p.SetTitle(fmt.Sprintf("Duplicates: %d set(s), %s wasted", len(p.groups), fsutils.GetSizeShortText(wasted)))
+	if row, _ := p.table.GetSelection(); row <= 0 && len(p.rows) > 1 {
+		p.table.Select(1, 0)
+	}
+	p.setStatus(fmt.Sprintf("%d file(s) marked", len(p.marked)))
+}
+
+func (p *dupesPanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+func (p *dupesPanel) toggleCurrent() {
+	row, _ := p.table.GetSelection()
+	if row < 0 || row >= len(p.rows) || p.rows[row].file < 0 {
+		return
+	}
+	r := p.rows[row]
+	filePath := p.groups[r.group].Files[r.file].Path
+	if p.marked[filePath] {
+		delete(p.marked, filePath)
+	} else {
+		p.marked[filePath] = true
+	}
+	p.render()
+	if row+1 < len(p.rows) && p.rows[row+1].file >= 0 {
+		p.table.Select(row+1, 0)
+	}
+}
+
+// autoSelect marks all files but the one a strategy keeps in each group.
+func (p *dupesPanel) autoSelect(keep ftdupes.Keep) {
+	clear(p.marked)
+	for _, g := range p.groups {
+		kept := g.Kept(keep).Path
+		for _, f := range g.Files {
+			if f.Path != kept {
+				p.marked[f.Path] = true
+			}
+		}
+	}
+	p.render()
+}
+
+// markedFiles returns marked paths & for each of them a path of an unmarked copy.
+// It fails if all files of a group are marked, so at least one copy of the content is always kept.
+func (p *dupesPanel) markedFiles() (marked, kept []string, err error) {
+	for _, g := range p.groups {
+		var groupKept string
+		var groupMarked []string
+		for _, f := range g.Files {
+			if p.marked[f.Path] {
+				groupMarked = append(groupMarked, f.Path)
+			} else if groupKept == "" {
+				groupKept = f.Path
+			}
+		}
+		if len(groupMarked) == 0 {
+			continue
+		}
+		if groupKept == "" {
+			return nil, nil, fmt.Errorf("all copies are marked, keep at least one: %s", g.Files[0].Path)
+		}
+		for _, m := range groupMarked {
+			marked = append(marked, m)
+			kept = append(kept, groupKept)
+		}
+	}
+	if len(marked) == 0 {
+		return nil, nil, errNothingMarked
+	}
+	return marked, kept, nil
+}
+
+// removeFiles drops processed files from the results, groups with a single file left are dropped too.
+func (p *dupesPanel) removeFiles(paths []string) {
+	for _, filePath := range paths {
+		delete(p.marked, filePath)
+	}
+	groups := p.groups[:0]
+	for _, g := range p.groups {
+		g.Files = slices.DeleteFunc(g.Files, func(f ftdupes.File) bool {
+			return slices.Contains(paths, f.Path)
+		})
+		if len(g.Files) > 1 {
+			groups = append(groups, g)
+		}
+	}
+	p.groups = groups
+	p.render()
+}
+
+func affectedDirs(paths []string) []string {
+	var dirs []string
+	for _, p := range paths {
+		if dir := path.Dir(p); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func (p *dupesPanel) deleteMarked() *Operation {
+	marked, _, err := p.markedFiles()
+	if err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return nil
+	}
+	nav, store := p.nav, p.store
+	title := fmt.Sprintf("Delete %d duplicate(s)", len(marked))
+	return nav.operations.Start(deleteOperation, title, affectedDirs(marked),
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			deleted, err := deleteEntries(ctx, store, marked, reportProgress)
+			if deleted > 0 {
+				nav.recordHistory(store, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: marked[:deleted]})
+				nav.app.QueueUpdateDraw(func() {
+					p.removeFiles(marked[:deleted])
+				})
+			}
+			return err
+		},
+	)
+}
+
+// linkMarked replaces marked files with links to an unmarked copy of the same content.
+func (p *dupesPanel) linkMarked(kind ftdupes.LinkKind) *Operation {
+	marked, kept, err := p.markedFiles()
+	if err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return nil
+	}
+	nav, store := p.nav, p.store
+	title := fmt.Sprintf("Replace %d duplicate(s) with %ss", len(marked), kind)
+	return nav.operations.Start(linkDupesOperation, title, affectedDirs(marked),
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			progress := OperationProgress{Total: len(marked)}
+			var err error
+			for i, dup := range marked {
+				if err = ctx.Err(); err != nil {
+					break
+				}
+				progress.Processing = []string{dup}
+				reportProgress(progress)
+				if err = ftdupes.ReplaceWithLink(ctx, store, kind, kept[i], dup); err != nil {
+					progress.Failed++
+					progress.Errors = append(progress.Errors, OperationError{Path: dup, Err: err})
+					break
+				}
+				progress.Done++
+			}
+			progress.Processing = nil
+			reportProgress(progress)
+			if progress.Done > 0 {
+				linked := marked[:progress.Done]
+				action := ftjournal.ActionHardLink
+				if kind == ftdupes.SymLink {
+					action = ftjournal.ActionSymlink
+				}
+				moves := make([]ftjournal.Move, len(linked))
+				for i, dup := range linked {
+					moves[i] = ftjournal.Move{From: kept[i], To: dup}
+				}
+				nav.recordHistory(store, ftjournal.Record{Action: action, Moves: moves})
+				nav.app.QueueUpdateDraw(func() {
+					p.removeFiles(linked)
+				})
+			}
+			return err
+		},
+	)
+}
+
+func (p *dupesPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *dupesPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.close()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case ' ':
+			p.toggleCurrent()
+		case 'n':
+			p.autoSelect(ftdupes.KeepNewest)
+		case 'o':
+			p.autoSelect(ftdupes.KeepOldest)
+		case 'p':
+			p.autoSelect(ftdupes.KeepShortestPath)
+		case 'd':
+			p.deleteMarked()
+		case 'h':
+			p.linkMarked(ftdupes.HardLink)
+		case 'l':
+			p.linkMarked(ftdupes.SymLink)
+		default:
+			return event
+		}
+		return nil
+	default:
+		return event
+	}
+}
@@ -0,0 +1,222 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/filetug/ftdupes"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDupesTestDir(t *testing.T) (*Navigator, chan func(), string) {
+	t.Helper()
+	nav, updates, dir := newNavigatorWithLocalDir(t)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	now := time.Now()
+	for i, name := range []string{"a.txt", "sub/a.txt", "sub/long-name.txt", "b.cpp", "sub/b.cpp", "u.txt"} {
+		content := "same text"
+		if filepath.Ext(name) == ".cpp" {
+			content = "int main(){}"
+		}
+		if name == "u.txt" {
+			content = "unique"
+		}
+		filePath := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+		modTime := now.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, os.Chtimes(filePath, modTime, modTime))
+	}
+	return nav, updates, dir
+}
+
+func findDupesPanel(t *testing.T, nav *Navigator, updates chan func(), o *Operation) *dupesPanel {
+	t.Helper()
+	waitOperation(t, o)
+	drainQueuedUpdates(updates)
+	p, ok := nav.right.content.(*dupesPanel)
+	assert.True(t, ok)
+	return p
+}
+
+func keyRune(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func TestDupesFormPanel(t *testing.T) {
+	nav, updates, dir := newDupesTestDir(t)
+
+	nav.showScriptsPanel()
+	scripts := nav.right.content.(*scriptsPanel)
+	_, secondary := scripts.list.GetItemText(1)
+	assert.Empty(t, secondary)
+	scripts.list.SetCurrentItem(1)
+	scripts.list.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	form, ok := nav.right.content.(*dupesFormPanel)
+	assert.True(t, ok)
+
+	form.minSize.SetText("x")
+	form.find()
+	assert.Contains(t, form.status.GetText(true), "Min size")
+
+	form.minSize.SetText("1")
+	form.include.SetCurrentOption(1) // Coding
+	form.find()
+	assert.Equal(t, nav.previewer, nav.right.content)
+	o := lastOperation(nav)
+	assert.Equal(t, findDupesOperation, o.Type)
+	p := findDupesPanel(t, nav, updates, o)
+	if assert.Len(t, p.groups, 1) {
+		assert.Equal(t, filepath.Join(dir, "b.cpp"), p.groups[0].Files[0].Path)
+	}
+
+	nav.showFindDuplicates()
+	form = nav.right.content.(*dupesFormPanel)
+	assert.Nil(t, form.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+	assert.Equal(t, nav.previewer, nav.right.content)
+	event := keyRune('a')
+	assert.Equal(t, event, form.inputCapture(event))
+}
+
+func TestDupesPanel(t *testing.T) {
+	withRenameJournal(t)
+	journal := withHistoryJournal(t)
+	nav, updates, dir := newDupesTestDir(t)
+	p := findDupesPanel(t, nav, updates, nav.findDuplicates(dir, ftdupes.Options{}))
+	assert.Len(t, p.groups, 2)
+	assert.Contains(t, p.GetTitle(), "2 set(s)")
+	assert.Equal(t, len(p.rows), p.table.GetRowCount())
+
+	t.Run("nothing_marked", func(t *testing.T) {
+		assert.Nil(t, p.deleteMarked())
+		assert.Contains(t, p.status.GetText(true), errNothingMarked.Error())
+	})
+
+	t.Run("toggle", func(t *testing.T) {
+		p.table.Select(0, 0) // a group header is ignored
+		p.inputCapture(keyRune(' '))
+		assert.Empty(t, p.marked)
+		p.table.Select(1, 0)
+		p.inputCapture(keyRune(' '))
+		assert.Len(t, p.marked, 1)
+		row, _ := p.table.GetSelection()
+		assert.Equal(t, 2, row, "moves to the next file")
+		p.table.Select(1, 0)
+		p.inputCapture(keyRune(' '))
+		assert.Empty(t, p.marked)
+	})
+
+	t.Run("all_copies_marked", func(t *testing.T) {
+		for _, g := range p.groups {
+			for _, f := range g.Files {
+				p.marked[f.Path] = true
+			}
+		}
+		assert.Nil(t, p.linkMarked(ftdupes.HardLink))
+		assert.Contains(t, p.status.GetText(true), "keep at least one")
+	})
+
+	t.Run("auto_select", func(t *testing.T) {
+		p.inputCapture(keyRune('n'))
+		assert.False(t, p.marked[filepath.Join(dir, "sub/long-name.txt")])
+		assert.False(t, p.marked[filepath.Join(dir, "sub/b.cpp")])
+		assert.Len(t, p.marked, 3)
+		p.inputCapture(keyRune('o'))
+		assert.False(t, p.marked[filepath.Join(dir, "a.txt")])
+		assert.False(t, p.marked[filepath.Join(dir, "b.cpp")])
+		p.inputCapture(keyRune('p'))
+		assert.False(t, p.marked[filepath.Join(dir, "a.txt")])
+		assert.Len(t, p.marked, 3)
+	})
+
+	t.Run("hard_link", func(t *testing.T) {
+		p.marked = map[string]bool{filepath.Join(dir, "sub/b.cpp"): true}
+		p.inputCapture(keyRune('h'))
+		o := lastOperation(nav)
+		assert.Equal(t, linkDupesOperation, o.Type)
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		keptInfo, _ := os.Stat(filepath.Join(dir, "b.cpp"))
+		linkInfo, _ := os.Stat(filepath.Join(dir, "sub/b.cpp"))
+		assert.True(t, os.SameFile(keptInfo, linkInfo))
+		assert.Len(t, p.groups, 1, "a group with a single file left is dropped")
+		history, err := journal.History()
+		assert.NoError(t, err)
+		last := history.Entries[len(history.Entries)-1]
+		assert.Equal(t, ftjournal.ActionHardLink, last.Action)
+		assert.Equal(t, []ftjournal.Move{{From: filepath.Join(dir, "b.cpp"), To: filepath.Join(dir, "sub/b.cpp")}}, last.Moves)
+	})
+
+	t.Run("symlink", func(t *testing.T) {
+		p.marked = map[string]bool{filepath.Join(dir, "sub/long-name.txt"): true}
+		p.inputCapture(keyRune('l'))
+		waitOperation(t, lastOperation(nav))
+		drainQueuedUpdates(updates)
+		target, err := os.Readlink(filepath.Join(dir, "sub/long-name.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "a.txt"), target)
+		assert.Len(t, p.groups[0].Files, 2)
+
+		linkPath := filepath.Join(dir, "sub/long-name.txt")
+		waitOperation(t, nav.undo())
+		info, err := os.Lstat(linkPath)
+		assert.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular(), "undo replaces the link with a copy")
+		assertFileContent(t, linkPath, "same text")
+		waitOperation(t, nav.redo())
+		target, err = os.Readlink(linkPath)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "a.txt"), target)
+		drainQueuedUpdates(updates)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		scanned := nav.store
+		nav.store = nil // the navigator has switched to another store, the scanned one is still used
+		p.inputCapture(keyRune('p'))
+		p.inputCapture(keyRune('d'))
+		o := lastOperation(nav)
+		assert.Equal(t, deleteOperation, o.Type)
+		waitOperation(t, o)
+		nav.store = scanned
+		drainQueuedUpdates(updates)
+		_, err := os.Stat(filepath.Join(dir, "sub/a.txt"))
+		assert.True(t, os.IsNotExist(err))
+		assertFileContent(t, filepath.Join(dir, "a.txt"), "same text")
+		assert.Empty(t, p.groups)
+		assert.Contains(t, p.table.GetCell(0, 1).Text, "No duplicates")
+		history, err := journal.History()
+		assert.NoError(t, err)
+		last := history.Entries[len(history.Entries)-1]
+		assert.Equal(t, []string{filepath.Join(dir, "sub/a.txt")}, last.Paths)
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		event := keyRune('x')
+		assert.Equal(t, event, p.inputCapture(event))
+		down := tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		assert.Equal(t, down, p.inputCapture(down))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+}
+
+func TestDupesPanel_LinkError(t *testing.T) {
+	nav, updates, dir := newDupesTestDir(t)
+	p := findDupesPanel(t, nav, updates, nav.findDuplicates(dir, ftdupes.Options{}))
+	missing := filepath.Join(dir, "sub/b.cpp")
+	assert.NoError(t, os.Remove(filepath.Join(dir, "b.cpp")))
+	p.marked = map[string]bool{missing: true}
+	o := p.linkMarked(ftdupes.HardLink)
+	if assert.NotNil(t, o) {
+		assert.ErrorIs(t, o.Wait(), os.ErrNotExist)
+		errs := o.Errors()
+		if assert.Len(t, errs, 1) {
+			assert.Equal(t, missing, errs[0].Path)
+		}
+	}
+	assertFileContent(t, missing, "int main(){}")
+}
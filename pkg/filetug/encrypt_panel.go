@@ -0,0 +1,441 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/filetug/filetug/pkg/filetug/ftenc"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	encryptOperation OperationType = "encrypt"
+	decryptOperation OperationType = "decrypt"
+)
+
+var (
+	errNoEncryptFiles      = errors.New("select files or show the basket to encrypt or decrypt")
+	errNoFilesToEncrypt    = errors.New("all selected files are already encrypted")
+	errNoFilesToDecrypt    = errors.New("no " + ftenc.Extension + " files are selected")
+	errPassphraseMismatch  = errors.New("passphrases do not match")
+	errEncryptNotSupported = fmt.Errorf("reading & writing files: %w", files.ErrNotSupported)
+	errShredNotSupported   = fmt.Errorf("secure delete: %w", files.ErrNotSupported)
+)
+
+// encryptParams are scrypt parameters of new encrypted files, tests use cheaper ones.
+var encryptParams = ftenc.DefaultParams
+
+// cryptResult is an outcome of encryption, decryption or verification of a file.
+type cryptResult struct {
+	source ftarchive.Source
+	output string // path of a written file, empty on verification or failure
+	err    error
+}
+
+// encryptPanel encrypts the selected files into .ftenc files with a passphrase,
+// decrypts .ftenc files back & verifies their integrity.
+type encryptPanel struct {
+	*sneatv.Boxed
+	nav        *Navigator
+	flex       *tview.Flex
+	form       *tview.Form
+	passphrase *tview.InputField
+	confirm    *tview.InputField
+	shred      *tview.Checkbox
+	table      *tview.Table
+	status     *tview.TextView
+	sources    []ftarchive.Source
+}
+
+func isEncryptedFile(name string) bool {
+	return strings.HasSuffix(name, ftenc.Extension)
+}
+
+func (nav *Navigator) showEncryptPanel() {
+	sources, err := nav.checksumSources()
+	if err != nil {
+		nav.showError(err)
+		return
+	}
+	p := newEncryptPanel(nav, sources)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newEncryptPanel(nav *Navigator, sources []ftarchive.Source) *encryptPanel {
+	p := &encryptPanel{
+		nav:        nav,
+		flex:       tview.NewFlex().SetDirection(tview.FlexRow),
+		form:       tview.NewForm().SetItemPadding(0),
+		passphrase: tview.NewInputField().SetLabel("Passphrase").SetMaskCharacter('*'),
+		confirm:    tview.NewInputField().SetLabel("Confirm").SetMaskCharacter('*'),
+		shred:      tview.NewCheckbox().SetLabel("Securely delete originals"),
+		table:      tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		status:     tview.NewTextView().SetDynamicColors(true),
+		sources:    sources,
+	}
+	p.form.AddFormItem(p.passphrase)
+	p.form.AddFormItem(p.confirm)
+	p.form.AddFormItem(p.shred)
+	p.form.AddButton("Encrypt", func() { p.encrypt() })
+	p.form.AddButton("Decrypt", func() { p.decrypt(false) })
+	p.form.AddButton("Verify", func() { p.decrypt(true) })
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+	p.table.SetInputCapture(p.tableInputCapture)
+
+	p.flex.AddItem(p.form, p.form.GetFormItemCount()+2, 0, true)
+	p.flex.AddItem(p.status, 1, 0, false)
+	p.flex.AddItem(p.table, 0, 1, false)
+
+	footer := tview.NewTextView().
+		SetText("Confirm is needed to encrypt only · Esc: back").
+		SetTextColor(tcell.ColorGray)
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1), sneatv.WithFooter(footer))
+	p.SetTitle(fmt.Sprintf("Encrypt / decrypt %d file(s)", len(sources)))
+	if len(sources) == 0 {
+		p.setStatus("[yellow]" + errNoEncryptFiles.Error() + "[-]")
+	} else {
+		encrypted := len(p.filterSources(true))
+		p.setStatus(fmt.Sprintf("%d to encrypt, %d encrypted", len(sources)-encrypted, encrypted))
+	}
+	return p
+}
+
+func (p *encryptPanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+func (p *encryptPanel) setError(err error) {
+	p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+}
+
+// filterSources returns either encrypted or plain files.
+func (p *encryptPanel) filterSources(encrypted bool) []ftarchive.Source {
+	var sources []ftarchive.Source
+	for _, src := range p.sources {
+		if isEncryptedFile(src.Path) == encrypted {
+			sources = append(sources, src)
+		}
+	}
+	return sources
+}
+
+// checkSources returns an error if a store of the files can't do what is asked.
+func checkSources(sources []ftarchive.Source, shred bool) error {
+	for _, src := range sources {
+		_, canRead := src.Store.(files.FileReader)
+		_, canWrite := src.Store.(files.FileWriter)
+		if !canRead || !canWrite {
+			return errEncryptNotSupported
+		}
+		if _, ok := src.Store.(files.Shredder); shred && !ok {
+			return errShredNotSupported
+		}
+	}
+	return nil
+}
+
+// takePassphrase returns the entered passphrase & clears the fields so it's not kept on screen.
+func (p *encryptPanel) takePassphrase() []byte {
+	passphrase := []byte(p.passphrase.GetText())
+	p.passphrase.SetText("")
+	p.confirm.SetText("")
+	return passphrase
+}
+
+func (p *encryptPanel) encrypt() *Operation {
+	sources := p.filterSources(false)
+	var err error
+	switch {
+	case len(p.sources) == 0:
+		err = errNoEncryptFiles
+	case len(sources) == 0:
+		err = errNoFilesToEncrypt
+	case p.passphrase.GetText() == "":
+		err = ftenc.ErrNoPassphrase
+	case p.passphrase.GetText() != p.confirm.GetText():
+		err = errPassphraseMismatch
+	default:
+		err = checkSources(sources, p.shred.IsChecked())
+	}
+	if err != nil {
+		p.setError(err)
+		return nil
+	}
+	p.setStatus("Encrypting…")
+	return p.nav.encryptFiles(sources, p.takePassphrase(), p.shred.IsChecked(), p.showResults)
+}
+
+func (p *encryptPanel) decrypt(verifyOnly bool) *Operation {
+	sources := p.filterSources(true)
+	var err error
+	switch {
+	case len(p.sources) == 0:
+		err = errNoEncryptFiles
+	case len(sources) == 0:
+		err = errNoFilesToDecrypt
+	case p.passphrase.GetText() == "":
+		err = ftenc.ErrNoPassphrase
+	default:
+		err = checkSources(sources, false)
+	}
+	if err != nil {
+		p.setError(err)
+		return nil
+	}
+	if verifyOnly {
+		p.setStatus("Verifying…")
+	} else {
+		p.setStatus("Decrypting…")
+	}
+	return p.nav.decryptFiles(sources, p.takePassphrase(), verifyOnly, p.showResults)
+}
+
+func (p *encryptPanel) showResults(results []cryptResult) {
+	p.table.Clear()
+	for col, title := range []string{"Name", "Result"} {
+		p.table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+	var failed int
+	for i, result := range results {
+		row := i + 1
+		p.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(path.Base(result.source.Path))))
+		cell := tview.NewTableCell("[green]OK[-]")
+		switch {
+		case result.err != nil:
+			failed++
+			cell = tview.NewTableCell(tview.Escape(result.err.Error())).SetTextColor(tcell.ColorRed)
+		case result.output != "":
+			cell = tview.NewTableCell("→ " + tview.Escape(path.Base(result.output))).SetTextColor(tcell.ColorGreen)
+		}
+		p.table.SetCell(row, 1, cell)
+	}
+	status := fmt.Sprintf("%d file(s) done", len(results)-failed)
+	if failed > 0 {
+		status += fmt.Sprintf(", [red]%d failed[-]", failed)
+	}
+	p.setStatus(status)
+	if len(results) > 0 {
+		p.table.Select(1, 0)
+	}
+	p.nav.app.SetFocus(p.table)
+}
+
+func (p *encryptPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *encryptPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
+
+func (p *encryptPanel) tableInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.nav.app.SetFocus(p.form)
+		return nil
+	}
+	return event
+}
+
+// cryptFunc streams content of a file from src to dst.
+type cryptFunc func(ctx context.Context, dst io.Writer, src io.Reader) error
+
+// encryptFiles writes an encrypted copy of every file next to it in background
+// & passes results to onDone in the UI goroutine. If shred is set, an original is securely deleted
+// only after its encrypted copy is written.
+func (nav *Navigator) encryptFiles(
+	sources []ftarchive.Source, passphrase []byte, shred bool, onDone func(results []cryptResult),
+) *Operation {
+	params := encryptParams
+	encrypt := func(ctx context.Context, dst io.Writer, src io.Reader) error {
+		return ftenc.Encrypt(ctx, dst, src, passphrase, params)
+	}
+	title := fmt.Sprintf("Encrypt %d file(s)", len(sources))
+	return nav.operations.Start(encryptOperation, title, sourceDirs(sources),
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			results, err := cryptFiles(ctx, sources, reportProgress, func(ctx context.Context, src ftarchive.Source, report func(n int64)) (string, error) {
+				output := src.Path + ftenc.Extension
+				if err := writeCrypted(ctx, src, output, "", encrypt, report); err != nil {
+					return "", err
+				}
+				nav.recordHistory(src.Store, ftjournal.Record{Action: ftjournal.ActionWriteFile, Paths: []string{output}})
+				if shred {
+					if err := src.Store.(files.Shredder).Shred(ctx, src.Path); err != nil {
+						return output, fmt.Errorf("encrypted but not deleted: %w", err)
+					}
+					nav.recordHistory(src.Store, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: []string{src.Path}})
+				}
+				return output, nil
+			})
+			if err != nil {
+				return err
+			}
+			nav.app.QueueUpdateDraw(func() {
+				onDone(results)
+			})
+			return nil
+		},
+	)
+}
+
+// decryptFiles writes decrypted files without the .ftenc extension in background,
+// or only checks the files can be decrypted if verifyOnly is set. Every chunk is authenticated,
+// so a file that is modified, truncated or encrypted with another passphrase is reported as failed.
+func (nav *Navigator) decryptFiles(
+	sources []ftarchive.Source, passphrase []byte, verifyOnly bool, onDone func(results []cryptResult),
+) *Operation {
+	decrypt := func(ctx context.Context, dst io.Writer, src io.Reader) error {
+		return ftenc.Decrypt(ctx, dst, src, passphrase)
+	}
+	operationType, title, dirs := decryptOperation, fmt.Sprintf("Decrypt %d file(s)", len(sources)), sourceDirs(sources)
+	if verifyOnly {
+		title, dirs = fmt.Sprintf("Verify %d encrypted file(s)", len(sources)), nil
+	}
+	return nav.operations.Start(operationType, title, dirs,
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			results, err := cryptFiles(ctx, sources, reportProgress, func(ctx context.Context, src ftarchive.Source, report func(n int64)) (string, error) {
+				if verifyOnly {
+					return "", streamCrypted(ctx, src, io.Discard, decrypt, report)
+				}
+				output := strings.TrimSuffix(src.Path, ftenc.Extension)
+				// Content is written to a temp file first, so a failed decryption leaves no partial plaintext
+				// & the plaintext is readable only by the owner.
+				if err := writeCrypted(ctx, src, output, "."+path.Base(output)+".*.decrypting", decrypt, report); err != nil {
+					return "", err
+				}
+				nav.recordHistory(src.Store, ftjournal.Record{Action: ftjournal.ActionWriteFile, Paths: []string{output}})
+				return output, nil
+			})
+			if err != nil {
+				return err
+			}
+			nav.app.QueueUpdateDraw(func() {
+				onDone(results)
+			})
+			return nil
+		},
+	)
+}
+
+func sourceDirs(sources []ftarchive.Source) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		if dir := path.Dir(src.Path); !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// cryptFiles processes files one by one, failures of individual files are reported in results.
+func cryptFiles(
+	ctx context.Context,
+	sources []ftarchive.Source,
+	reportProgress ProgressReporter,
+	process func(ctx context.Context, src ftarchive.Source, report func(n int64)) (string, error),
+) ([]cryptResult, error) {
+	progress := OperationProgress{Total: len(sources)}
+	for _, src := range sources {
+		if src.Info != nil {
+			progress.BytesTotal += src.Info.Size()
+		}
+	}
+	results := make([]cryptResult, len(sources))
+	for i, src := range sources {
+		progress.Processing = []string{src.Path}
+		reportProgress(progress)
+		output, err := process(ctx, src, func(n int64) {
+			progress.BytesDone += n
+			reportProgress(progress)
+		})
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		results[i] = cryptResult{source: src, output: output, err: err}
+		progress.Done++
+		if err != nil {
+			progress.Failed++
+			progress.Errors = append(progress.Errors, OperationError{Path: src.Path, Err: err})
+		}
+	}
+	progress.Processing = nil
+	reportProgress(progress)
+	return results, nil
+}
+
+// writeCrypted streams a file through crypt into a new file at output, an existing file is not replaced.
+// If tempPattern is set & the store can create temp files & rename, content is written to a temp file
+// in the output dir that only the owner can read & renamed to output on success. An incomplete file is deleted on failure.
+func writeCrypted(
+	ctx context.Context, src ftarchive.Source, output, tempPattern string, crypt cryptFunc, report func(n int64),
+) error {
+	if storeEntryExists(ctx, src.Store, output) {
+		return fmt.Errorf("already exists: %s", path.Base(output))
+	}
+	renamer, canRename := src.Store.(files.Renamer)
+	tempCreator, canCreateTemp := src.Store.(files.TempFileCreator)
+	temp := output
+	var w io.WriteCloser
+	var err error
+	if tempPattern != "" && canRename && canCreateTemp {
+		temp, w, err = tempCreator.CreateTemp(ctx, path.Dir(output), tempPattern)
+	} else {
+		w, err = src.Store.(files.FileWriter).OpenWriter(ctx, output)
+	}
+	if err != nil {
+		return err
+	}
+	err = streamCrypted(ctx, src, w, crypt, report)
+	err = errors.Join(err, w.Close())
+	if err == nil && temp != output {
+		err = renamer.Rename(ctx, temp, output)
+	}
+	if err != nil {
+		if deleteErr := src.Store.Delete(context.WithoutCancel(ctx), temp); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to delete incomplete file: %w", deleteErr))
+		}
+	}
+	return err
+}
+
+func streamCrypted(ctx context.Context, src ftarchive.Source, dst io.Writer, crypt cryptFunc, report func(n int64)) error {
+	r, err := src.Store.(files.FileReader).OpenReader(ctx, src.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	return crypt(ctx, dst, &reportingReader{r: r, report: report})
+}
+
+// reportingReader reports the number of bytes read.
+type reportingReader struct {
+	r      io.Reader
+	report func(n int64)
+}
+
+func (r *reportingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.report(int64(n))
+	}
+	return n, err
+}
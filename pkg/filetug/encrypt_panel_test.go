@@ -0,0 +1,253 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/filetug/filetug/pkg/filetug/ftenc"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func withCheapEncryption(t *testing.T) {
+	t.Helper()
+	orig := encryptParams
+	encryptParams = ftenc.Params{LogN: 10, R: 8, P: 1}
+	t.Cleanup(func() {
+		encryptParams = orig
+	})
+}
+
+func setPassphrase(p *encryptPanel, passphrase, confirm string) {
+	p.passphrase.SetText(passphrase)
+	p.confirm.SetText(confirm)
+}
+
+// noShredStore hides the Shredder capability of a store.
+type noShredStore struct {
+	files.Store
+	files.FileReader
+	files.FileWriter
+}
+
+func TestEncryptPanel(t *testing.T) {
+	withCheapEncryption(t)
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.txt")
+	aPath, bPath := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+
+	nav.showScriptsPanel()
+	scripts := nav.right.content.(*scriptsPanel)
+	main, _ := scripts.list.GetItemText(5)
+	assert.Equal(t, "Encrypt / decrypt", main)
+	scripts.list.SetCurrentItem(5)
+	scripts.list.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	p, ok := nav.right.content.(*encryptPanel)
+	assert.True(t, ok)
+	assert.Len(t, p.sources, 1)
+	assert.Equal(t, "1 to encrypt, 0 encrypted", p.status.GetText(true))
+	p.sources = []ftarchive.Source{{Store: nav.store, Path: aPath}, {Store: nav.store, Path: bPath}}
+
+	t.Run("validation", func(t *testing.T) {
+		assert.Nil(t, p.encrypt())
+		assert.Contains(t, p.status.GetText(true), ftenc.ErrNoPassphrase.Error())
+		setPassphrase(p, "secret", "typo")
+		assert.Nil(t, p.encrypt())
+		assert.Contains(t, p.status.GetText(true), errPassphraseMismatch.Error())
+		assert.Nil(t, p.decrypt(false))
+		assert.Contains(t, p.status.GetText(true), errNoFilesToDecrypt.Error())
+	})
+
+	t.Run("encrypt_and_shred", func(t *testing.T) {
+		setPassphrase(p, "secret", "secret")
+		p.shred.SetChecked(true)
+		o := p.encrypt()
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Empty(t, p.passphrase.GetText(), "passphrase is cleared")
+		assert.Equal(t, "2 file(s) done", p.status.GetText(true))
+		assert.Equal(t, "→ a.txt.ftenc", p.table.GetCell(1, 1).Text)
+		for _, name := range []string{"a.txt", "b.txt"} {
+			_, err := os.Stat(filepath.Join(dir, name))
+			assert.True(t, os.IsNotExist(err), "original is deleted")
+			data, err := os.ReadFile(filepath.Join(dir, name+ftenc.Extension))
+			assert.NoError(t, err)
+			assert.NotContains(t, string(data), name)
+		}
+	})
+
+	p.sources = []ftarchive.Source{{Store: nav.store, Path: aPath + ftenc.Extension}, {Store: nav.store, Path: bPath + ftenc.Extension}}
+
+	t.Run("encrypt_encrypted", func(t *testing.T) {
+		setPassphrase(p, "secret", "secret")
+		assert.Nil(t, p.encrypt())
+		assert.Contains(t, p.status.GetText(true), errNoFilesToEncrypt.Error())
+	})
+
+	t.Run("verify_wrong_passphrase", func(t *testing.T) {
+		setPassphrase(p, "wrong", "")
+		o := p.decrypt(true)
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, 2, o.Progress().Failed)
+		assert.Equal(t, ftenc.ErrWrongPassphrase.Error(), p.table.GetCell(1, 1).Text)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		setPassphrase(p, "secret", "")
+		o := p.decrypt(true)
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, "[green]OK[-]", p.table.GetCell(1, 1).Text)
+		_, err := os.Stat(aPath)
+		assert.True(t, os.IsNotExist(err), "nothing is written on verification")
+	})
+
+	t.Run("decrypt_corrupted", func(t *testing.T) {
+		data, err := os.ReadFile(bPath + ftenc.Extension)
+		assert.NoError(t, err)
+		data[len(data)-1]++
+		assert.NoError(t, os.WriteFile(bPath+ftenc.Extension, data, 0o644))
+
+		setPassphrase(p, "secret", "")
+		o := p.decrypt(false)
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, "1 file(s) done, 1 failed", p.status.GetText(true))
+		assertFileContent(t, aPath, "a.txt")
+		info, err := os.Stat(aPath)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "plaintext is readable only by the owner")
+		assert.Equal(t, ftenc.ErrCorrupted.Error(), p.table.GetCell(2, 1).Text)
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 3, "no partial plaintext is left")
+	})
+
+	t.Run("decrypt_existing", func(t *testing.T) {
+		setPassphrase(p, "secret", "")
+		o := p.decrypt(false)
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, "already exists: a.txt", p.table.GetCell(1, 1).Text)
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		assert.Nil(t, p.tableInputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		event := keyRune('z')
+		assert.Equal(t, event, p.tableInputCapture(event))
+		assert.Equal(t, event, p.inputCapture(event))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+}
+
+func TestEncryptPanel_NoFiles(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t)
+	nav.files.table.Select(0, 0) // parent dir
+	nav.showEncryptPanel()
+	p := nav.right.content.(*encryptPanel)
+	assert.Contains(t, p.status.GetText(true), errNoEncryptFiles.Error())
+	assert.Nil(t, p.encrypt())
+	assert.Nil(t, p.decrypt(false))
+	assert.Contains(t, p.status.GetText(true), errNoEncryptFiles.Error())
+}
+
+func TestEncryptPanel_NotSupported(t *testing.T) {
+	nav, _, dir := newNavigatorWithLocalDir(t, "a.txt")
+	osStore := nav.store
+	p := newEncryptPanel(nav, []ftarchive.Source{{Store: noReaderStore{osStore}, Path: filepath.Join(dir, "a.txt")}})
+	setPassphrase(p, "secret", "secret")
+	assert.Nil(t, p.encrypt())
+	assert.Contains(t, p.status.GetText(true), errEncryptNotSupported.Error())
+
+	store := noShredStore{Store: osStore, FileReader: osStore.(files.FileReader), FileWriter: osStore.(files.FileWriter)}
+	p.sources = []ftarchive.Source{{Store: store, Path: filepath.Join(dir, "a.txt")}}
+	p.shred.SetChecked(true)
+	assert.Nil(t, p.encrypt())
+	assert.Contains(t, p.status.GetText(true), errShredNotSupported.Error())
+
+	p.sources[0].Path += ftenc.Extension
+	p.sources[0].Store = noReaderStore{osStore}
+	assert.Nil(t, p.decrypt(false))
+	assert.Contains(t, p.status.GetText(true), errEncryptNotSupported.Error())
+}
+
+func TestNavigator_DecryptFiles_WithoutRename(t *testing.T) {
+	withCheapEncryption(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t, "a.txt")
+	osStore := nav.store
+	store := noShredStore{Store: osStore, FileReader: osStore.(files.FileReader), FileWriter: osStore.(files.FileWriter)}
+	aPath := filepath.Join(dir, "a.txt")
+	onDone := func([]cryptResult) {}
+	o := nav.encryptFiles([]ftarchive.Source{{Store: store, Path: aPath}}, []byte("secret"), false, onDone)
+	assert.NoError(t, o.Wait())
+	assert.NoError(t, os.Remove(aPath))
+
+	o = nav.decryptFiles([]ftarchive.Source{{Store: store, Path: aPath + ftenc.Extension}}, []byte("secret"), false, onDone)
+	assert.NoError(t, o.Wait())
+	assertFileContent(t, aPath, "a.txt")
+
+	o = nav.decryptFiles([]ftarchive.Source{{Store: store, Path: filepath.Join(dir, "missing.ftenc")}}, []byte("secret"), false, onDone)
+	assert.NoError(t, o.Wait())
+	assert.Equal(t, 1, o.Progress().Failed)
+	_, err := os.Stat(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err), "an incomplete file is deleted")
+}
+
+func TestNavigator_DecryptFiles_KeepsExistingTempName(t *testing.T) {
+	withCheapEncryption(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t, "a.txt")
+	aPath := filepath.Join(dir, "a.txt")
+	onDone := func([]cryptResult) {}
+	o := nav.encryptFiles([]ftarchive.Source{{Store: nav.store, Path: aPath}}, []byte("secret"), false, onDone)
+	assert.NoError(t, o.Wait())
+	assert.NoError(t, os.Remove(aPath))
+	userFile := filepath.Join(dir, ".a.txt.decrypting")
+	assert.NoError(t, os.WriteFile(userFile, []byte("mine"), 0o644))
+
+	o = nav.decryptFiles([]ftarchive.Source{{Store: nav.store, Path: aPath + ftenc.Extension}}, []byte("secret"), false, onDone)
+	assert.NoError(t, o.Wait())
+	assertFileContent(t, aPath, "a.txt")
+	assertFileContent(t, userFile, "mine")
+}
+
+type failingShredStore struct {
+	files.Store
+	err error
+}
+
+func (s failingShredStore) OpenReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	return s.Store.(files.FileReader).OpenReader(ctx, path)
+}
+
+func (s failingShredStore) OpenWriter(ctx context.Context, path string) (io.WriteCloser, error) {
+	return s.Store.(files.FileWriter).OpenWriter(ctx, path)
+}
+
+func (s failingShredStore) Shred(context.Context, string) error {
+	return s.err
+}
+
+func TestNavigator_EncryptFiles_ShredError(t *testing.T) {
+	withCheapEncryption(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t, "a.txt")
+	shredErr := errors.New("shred failed")
+	store := failingShredStore{Store: nav.store, err: shredErr}
+	o := nav.encryptFiles([]ftarchive.Source{{Store: store, Path: filepath.Join(dir, "a.txt")}}, []byte("secret"), true, func([]cryptResult) {})
+	assert.NoError(t, o.Wait())
+	if assert.Len(t, o.Progress().Errors, 1) {
+		assert.ErrorIs(t, o.Progress().Errors[0].Err, shredErr)
+	}
+	assertFileContent(t, filepath.Join(dir, "a.txt"), "a.txt")
+}
@@ -0,0 +1,212 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const extractOperation OperationType = "extract"
+
+var errNotArchive = errors.New("not a supported archive (zip, tar, tar.gz, gz)")
+
+// extractSource is an archive to be extracted, it can be in a store other than the current one if picked from the basket.
+type extractSource struct {
+	store files.Store
+	path  string
+	size  int64
+}
+
+// extractPanel asks where to extract an archive & what to do with existing files.
+type extractPanel struct {
+	*sneatv.Boxed
+	nav      *Navigator
+	flex     *tview.Flex
+	form     *tview.Form
+	target   *tview.InputField
+	existing *tview.DropDown
+	status   *tview.TextView
+	source   extractSource
+}
+
+// extractSourceOf returns the current entry as an archive to be extracted.
+func (nav *Navigator) extractSourceOf() (src extractSource, err error) {
+	var entry files.EntryWithDirPath
+	if _, ok := nav.shownItems(); ok {
+		entry = nav.files.GetCurrentEntry()
+	} else if b := nav.getCurrentBrowser(); b != nil {
+		entry = b.GetCurrentEntry()
+	}
+	if entry == nil || entry.IsDir() {
+		return src, errNotArchive
+	}
+	if _, ok := ftarchive.DetectFormat(entry.Name()); !ok {
+		return src, fmt.Errorf("%s: %w", entry.Name(), errNotArchive)
+	}
+	src.store, src.path = nav.store, entry.FullName()
+	if e, ok := entry.(basketEntry); ok {
+		if src.store, err = nav.storeFor(e.item.Store); err != nil {
+			return src, err
+		}
+	}
+	if info, infoErr := entry.Info(); infoErr == nil && info != nil {
+		src.size = info.Size()
+	}
+	return src, nil
+}
+
+func (nav *Navigator) showExtractPanel() {
+	src, err := nav.extractSourceOf()
+	if err != nil {
+		nav.showError(err)
+		return
+	}
+	p := newExtractPanel(nav, src)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newExtractPanel(nav *Navigator, src extractSource) *extractPanel {
+	p := &extractPanel{
+		nav:    nav,
+		flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		form:   tview.NewForm(),
+		status: tview.NewTextView().SetDynamicColors(true),
+		source: src,
+	}
+	name := path.Base(src.path)
+	p.target = tview.NewInputField().SetLabel("Extract to").
+		SetText(path.Join(nav.currentDirPath(), ftarchive.TrimExt(name)))
+	p.existing = tview.NewDropDown().SetLabel("Existing files").
+		SetOptions([]string{"Skip", "Overwrite"}, nil).
+		SetCurrentOption(0)
+	p.form.AddFormItem(p.target)
+	p.form.AddFormItem(p.existing)
+	p.form.AddButton("Extract here", p.extractHere)
+	p.form.AddButton("Extract to", p.extractTo)
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+
+	p.flex.AddItem(p.form, 7, 0, true)
+	p.flex.AddItem(p.status, 0, 1, false)
+
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1))
+	p.SetTitle("Extract " + name)
+	p.setStatus("Target can be a path in the current store or a URL, e.g. file:///tmp")
+	return p
+}
+
+func (p *extractPanel) overwrite() bool {
+	i, _ := p.existing.GetCurrentOption()
+	return i == 1
+}
+
+func (p *extractPanel) extractHere() {
+	p.close()
+	p.nav.extractArchive(p.source, p.nav.store, p.nav.currentDirPath(), p.overwrite())
+}
+
+func (p *extractPanel) extractTo() {
+	store, dir, err := p.nav.resolveTarget(p.target.GetText())
+	if err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	if _, ok := store.(files.FileWriter); !ok {
+		p.setStatus("[red]" + ftarchive.ErrFileWriteNotSupported.Error() + "[-]")
+		return
+	}
+	p.close()
+	p.nav.extractArchive(p.source, store, dir, p.overwrite())
+}
+
+// resolveTarget parses a dir entered by a user. It's either a URL of any supported store,
+// or a path in the current store - relative paths are resolved against the current dir.
+func (nav *Navigator) resolveTarget(target string) (files.Store, string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, "", errors.New("target dir is required")
+	}
+	if !strings.Contains(target, "://") {
+		if !path.IsAbs(target) {
+			target = path.Join(nav.currentDirPath(), target)
+		}
+		return nav.store, path.Clean(target), nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, "", err
+	}
+	dir := path.Clean("/" + u.Path)
+	u.Path = ""
+	store, err := nav.storeFor(u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	return store, dir, nil
+}
+
+func (p *extractPanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+func (p *extractPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *extractPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
+
+// extractArchive extracts an archive into a dir of the target store in background.
+// Created entries are recorded in the history, so the extraction can be undone.
+func (nav *Navigator) extractArchive(src extractSource, store files.Store, dir string, overwrite bool) *Operation {
+	title := fmt.Sprintf("Extract %s to %s", path.Base(src.path), dir)
+	opts := ftarchive.ExtractOptions{Overwrite: overwrite, Size: src.size}
+	return nav.operations.Start(extractOperation, title, []string{dir, path.Dir(dir)},
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			var progress OperationProgress
+			result, err := ftarchive.Extract(ctx, src.store, src.path, store, dir, opts,
+				func(p ftarchive.ExtractProgress) {
+					progress = OperationProgress{
+						Total:      p.Total,
+						Done:       p.Done,
+						Skipped:    p.Skipped,
+						BytesTotal: p.BytesTotal,
+						BytesDone:  p.BytesDone,
+					}
+					if p.Current != "" {
+						progress.Processing = []string{p.Current}
+					}
+					reportProgress(progress)
+				},
+			)
+			if len(result.Rejected) > 0 {
+				progress.Processing = nil
+				for _, name := range result.Rejected {
+					progress.Errors = append(progress.Errors, OperationError{Path: name, Err: ftarchive.ErrUnsafePath})
+				}
+				reportProgress(progress)
+			}
+			if len(result.Created) > 0 {
+				nav.recordHistory(store, ftjournal.Record{Action: ftjournal.ActionExtract, Paths: result.Created})
+			}
+			return err
+		},
+	)
+}
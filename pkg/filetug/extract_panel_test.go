@@ -0,0 +1,158 @@
+package filetug
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftarchive"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestZip(t *testing.T, filePath string, content map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range content {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, os.WriteFile(filePath, buf.Bytes(), 0o644))
+}
+
+func TestExtractPanel(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.zip")
+	writeTestZip(t, filepath.Join(dir, "a.zip"), map[string]string{"x/y.txt": "y"})
+
+	nav.files.inputCapture(tcell.NewEventKey(tcell.KeyCtrlE, 0, tcell.ModCtrl))
+	p, ok := nav.right.content.(*extractPanel)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "a"), p.target.GetText())
+	assert.False(t, p.overwrite())
+
+	t.Run("extract_to", func(t *testing.T) {
+		p.extractTo()
+		assert.Equal(t, nav.previewer, nav.right.content)
+		o := lastOperation(nav)
+		waitOperation(t, o)
+		drainQueuedUpdates(updates)
+		assert.Equal(t, extractOperation, o.Type)
+		assertFileContent(t, filepath.Join(dir, "a", "x", "y.txt"), "y")
+
+		waitOperation(t, nav.undo())
+		_, err := os.Stat(filepath.Join(dir, "a"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("extract_here", func(t *testing.T) {
+		p.extractHere()
+		waitOperation(t, lastOperation(nav))
+		assertFileContent(t, filepath.Join(dir, "x", "y.txt"), "y")
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "x", "y.txt"), []byte("changed"), 0o644))
+		p.extractHere() // existing files are skipped by default
+		o := lastOperation(nav)
+		waitOperation(t, o)
+		assert.Equal(t, 1, o.Progress().Skipped)
+		assertFileContent(t, filepath.Join(dir, "x", "y.txt"), "changed")
+
+		p.existing.SetCurrentOption(1)
+		p.extractHere()
+		waitOperation(t, lastOperation(nav))
+		assertFileContent(t, filepath.Join(dir, "x", "y.txt"), "y")
+	})
+
+	t.Run("target_not_writable", func(t *testing.T) {
+		p.target.SetText("https://example.com/pub")
+		p.extractTo()
+		assert.Contains(t, p.status.GetText(true), ftarchive.ErrFileWriteNotSupported.Error())
+		p.target.SetText("")
+		p.extractTo()
+		assert.Contains(t, p.status.GetText(true), "target dir is required")
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		nav.showExtractPanel()
+		p = nav.right.content.(*extractPanel)
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+		event := tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+	})
+}
+
+func TestNavigator_ShowExtractPanel_NotArchive(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t, "a.txt")
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+	nav.showExtractPanel()
+	assert.ErrorIs(t, shownErr, errNotArchive)
+	assert.Equal(t, nav.previewer, nav.right.content)
+}
+
+func TestNavigator_ExtractArchive_Rejected(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0o644}))
+	assert.NoError(t, tw.Close())
+	archivePath := filepath.Join(dir, "evil.tar")
+	assert.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0o644))
+
+	target := filepath.Join(dir, "out")
+	o := nav.extractArchive(extractSource{store: nav.store, path: archivePath}, nav.store, target, false)
+	if assert.NotNil(t, o) {
+		assert.ErrorIs(t, o.Wait(), ftarchive.ErrUnsafePath)
+		errs := o.Errors()
+		if assert.Len(t, errs, 1) {
+			assert.Equal(t, "../evil.txt", errs[0].Path)
+		}
+	}
+	_, err := os.Stat(filepath.Join(dir, "evil.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNavigator_ResolveTarget(t *testing.T) {
+	nav, _, dir := newNavigatorWithLocalDir(t)
+
+	store, p, err := nav.resolveTarget("sub")
+	assert.NoError(t, err)
+	assert.Same(t, nav.store, store)
+	assert.Equal(t, filepath.Join(dir, "sub"), p)
+
+	store, p, err = nav.resolveTarget(" /tmp/x/ ")
+	assert.NoError(t, err)
+	assert.Same(t, nav.store, store)
+	assert.Equal(t, "/tmp/x", p)
+
+	store, p, err = nav.resolveTarget("file:///var/tmp")
+	assert.NoError(t, err)
+	assert.Same(t, nav.store, store)
+	assert.Equal(t, "/var/tmp", p)
+
+	store, p, err = nav.resolveTarget("ftp://example.com/pub")
+	assert.NoError(t, err)
+	rootURL := store.RootURL()
+	assert.Equal(t, "ftp://example.com", rootURL.String())
+	assert.Equal(t, "/pub", p)
+
+	_, _, err = nav.resolveTarget("s3://bucket/x")
+	assert.Error(t, err)
+	_, _, err = nav.resolveTarget("ftp://%zz/")
+	assert.Error(t, err)
+}
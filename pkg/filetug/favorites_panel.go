@@ -4,14 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"os"
 	"strings"
 
 	"github.com/filetug/filetug/pkg/files"
-	"github.com/filetug/filetug/pkg/files/ftpfile"
-	"github.com/filetug/filetug/pkg/files/httpfile"
-	"github.com/filetug/filetug/pkg/files/osfile"
 	"github.com/filetug/filetug/pkg/filetug/ftfav"
+	"github.com/filetug/filetug/pkg/filetug/ftfrecency"
 	"github.com/filetug/filetug/pkg/fsutils"
 	"github.com/filetug/filetug/pkg/sneatv"
 	"github.com/gdamore/tcell/v2"
@@ -27,8 +24,14 @@ type favoritesPanel struct {
 	addContainer   *tview.Flex
 	addFormVisible bool
 	addButton      *tview.Button
+
+	recentSection *tview.Flex // the most frecent dirs, hidden if no dirs have been visited yet
+	recent        *tview.List
+	recentEntries []ftfrecency.Entry
 }
 
+const maxRecentDirs = 10
+
 var (
 	addFavorite    = ftfav.AddFavorite
 	deleteFavorite = ftfav.DeleteFavorite
@@ -39,6 +42,7 @@ func (nav *Navigator) ShowFavorites() {
 	nav.prev = nav.current
 	nav.left.SetContent(nav.favorites)
 	nav.favorites.updateAddCurrentForm()
+	nav.favorites.setRecent(nav.recentDirs())
 	nav.app.SetFocus(nav.favorites.list)
 }
 
@@ -58,13 +62,20 @@ func newFavoritesPanel(nav *Navigator) *favoritesPanel {
 	addButton := tview.NewButton("Add Current dir to favorites")
 	addContainer := tview.NewFlex().SetDirection(tview.FlexRow)
 	addContainer.AddItem(addButton, 1, 0, false)
+	recent := tview.NewList()
+	recent.ShowSecondaryText(false)
+	recentSection := tview.NewFlex().SetDirection(tview.FlexRow)
+	recentSection.AddItem(tview.NewTextView().SetText("Recent").SetTextColor(tcell.ColorGray), 1, 0, false)
+	recentSection.AddItem(recent, 0, 1, false)
 	f := &favoritesPanel{
-		flex:         flex,
-		list:         list,
-		nav:          nav,
-		items:        builtInFavorites(),
-		addContainer: addContainer,
-		addButton:    addButton,
+		flex:          flex,
+		list:          list,
+		nav:           nav,
+		items:         builtInFavorites(),
+		addContainer:  addContainer,
+		addButton:     addButton,
+		recentSection: recentSection,
+		recent:        recent,
 		Boxed: sneatv.NewBoxed(
 			flex,
 			sneatv.WithLeftBorder(1, -1),
@@ -84,6 +95,8 @@ func newFavoritesPanel(nav *Navigator) *favoritesPanel {
 		}
 	})
 	f.flex.AddItem(f.list, 0, 1, true)
+	f.flex.AddItem(f.recentSection, 0, 0, false)
+	f.recent.SetInputCapture(f.recentInputCapture)
 
 	//f.flex.AddItem(hint, 1, 0, false)
 	f.setItems()
@@ -98,6 +111,9 @@ func (f *favoritesPanel) loadUserFavorites() {
 	go func() {
 		userFavorites, err := getter()
 		if err != nil {
+			if f.nav != nil && f.nav.app != nil {
+				f.nav.notifyError("Favorites", fmt.Errorf("failed to load favorites: %w", err))
+			}
 			return
 		}
 		items := make([]ftfav.Favorite, 0, len(f.items)+len(userFavorites))
@@ -192,16 +208,18 @@ func (f *favoritesPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
 		f.deleteCurrentFavorite()
 		return nil
 	case tcell.KeyEscape:
-		if dir := f.nav.prev.Dir(); dir != nil {
-			f.nav.goDir(dir)
-		}
-		f.nav.left.SetContent(f.nav.dirsTree)
-		f.nav.app.SetFocus(f.nav.dirsTree)
+		f.close()
 		return nil
 	case tcell.KeyLeft:
 		f.nav.app.SetFocus(f.nav.files.table)
 		return nil
-	case tcell.KeyUp, tcell.KeyDown:
+	case tcell.KeyDown:
+		if f.list.GetCurrentItem() == f.list.GetItemCount()-1 && len(f.recentEntries) > 0 {
+			f.nav.app.SetFocus(f.recent)
+			return nil
+		}
+		return event
+	case tcell.KeyUp:
 		return event
 	default:
 		return event
@@ -290,7 +308,7 @@ func (f *favoritesPanel) deleteCurrentFavorite() {
 	itemKey := item.Key()
 	err := deleteFavorite(item)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "delete favorite failed: %v\n", err)
+		f.nav.notifyError("Favorites", fmt.Errorf("failed to delete favorite: %w", err))
 	}
 	updated := make([]ftfav.Favorite, 0, len(f.items))
 	for _, entry := range f.items {
@@ -329,21 +347,71 @@ func (f *favoritesPanel) setStore(item ftfav.Favorite) (dirPath string) {
 	dirPath = item.Path
 	root := item.Store
 	if storeRootUrl := f.nav.store.RootURL(); storeRootUrl.String() != root.String() {
-		var store files.Store
-		switch strings.ToLower(root.Scheme) {
-		case "http", "https":
-			store = httpfile.NewStore(root)
-		case "ftp", "ftps":
-			store = ftpfile.NewStore(root)
-		case "file":
-			if root.Path == "" {
-				root.Path = "/"
-			}
-			store = osfile.NewStore(root.Path)
-		}
-		if store != nil {
+		if store := newStoreForURL(root); store != nil {
 			f.nav.SetStore(store)
 		}
 	}
 	return
 }
+
+// close returns to the dir that was current when favorites were opened as they preview dirs under the cursor.
+func (f *favoritesPanel) close() {
+	if dir := f.nav.prev.Dir(); dir != nil {
+		f.nav.goDir(dir)
+	}
+	f.nav.left.SetContent(f.nav.dirsTree)
+	f.nav.app.SetFocus(f.nav.dirsTree)
+}
+
+// setRecent shows the most frecent dirs under favorites.
+func (f *favoritesPanel) setRecent(entries []ftfrecency.Entry) {
+	f.recentEntries = entries
+	f.recent.Clear()
+	for _, e := range entries {
+		text := tview.Escape(e.Path)
+		if root, err := url.Parse(e.Store); err != nil || root.Scheme != "file" {
+			text = "[gray]" + tview.Escape(e.Store) + "[-] " + text
+		}
+		f.recent.AddItem(text, "", 0, nil)
+	}
+	height := 0
+	if len(entries) > 0 {
+		height = len(entries) + 1
+	}
+	f.flex.ResizeItem(f.recentSection, height, 0)
+}
+
+// recentInputCapture goes to a recent dir on Enter, Delete forgets it.
+func (f *favoritesPanel) recentInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	i := f.recent.GetCurrentItem()
+	switch event.Key() {
+	case tcell.KeyEnter:
+		if i >= 0 && i < len(f.recentEntries) && f.nav.openVisited(f.recentEntries[i]) {
+			f.nav.left.SetContent(f.nav.dirsTree)
+			f.nav.app.SetFocus(f.nav.dirsTree)
+			return nil
+		}
+		f.setRecent(f.nav.recentDirs())
+		return nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete:
+		if i >= 0 && i < len(f.recentEntries) {
+			f.nav.forgetVisited(f.recentEntries[i])
+			f.setRecent(f.nav.recentDirs())
+		}
+		if len(f.recentEntries) == 0 {
+			f.nav.app.SetFocus(f.list)
+		}
+		return nil
+	case tcell.KeyUp:
+		if i == 0 {
+			f.nav.app.SetFocus(f.list)
+			return nil
+		}
+		return event
+	case tcell.KeyEscape:
+		f.close()
+		return nil
+	default:
+		return event
+	}
+}
@@ -1,6 +1,7 @@
 package filetug
 
 import (
+	"github.com/filetug/filetug/pkg/filetug/ftquery"
 	"github.com/filetug/filetug/pkg/filetug/ftui"
 	"github.com/filetug/filetug/pkg/sneatv"
 	"github.com/rivo/tview"
@@ -12,11 +13,17 @@ var _ browser = (*filesPanel)(nil)
 // It integrates with git status display, filtering, and preview functionality.
 type filesPanel struct {
 	*sneatv.Boxed
-	table *tview.Table
-	rows  *FileRows
-	nav   *Navigator
+	table  *tview.Table
+	flex   *tview.Flex
+	rows   *FileRows
+	nav    *Navigator
+	footer *tview.TextView // summary of selected entries
 	filterTabs
 	filter          ftui.Filter
+	query           *ftquery.Query // an active filter query, nil if none
+	queryBar        *queryBar
+	searching       bool // a search pattern is being typed
+	searchIndex     int  // index of the current search match cycled by n/N
 	currentFileName string
 	loadingProgress int
 }
@@ -42,22 +49,27 @@ func newFilterTabs(nav *Navigator) filterTabs {
 // newFiles creates a new files panel with the given navigator.
 func newFiles(nav *Navigator) *filesPanel {
 	table := tview.NewTable()
-	flex := tview.NewFlex()
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 	flex.AddItem(table, 0, 1, true)
 
 	tabs := newFilterTabs(nav)
+	footer := tview.NewTextView().SetTextColor(selectedColor)
 
 	f := &filesPanel{
-		nav:   nav,
-		table: table,
+		nav:    nav,
+		table:  table,
+		flex:   flex,
+		footer: footer,
 		Boxed: sneatv.NewBoxed(
 			flex,
 			sneatv.WithLeftBorder(0, -1),
 			sneatv.WithRightBorder(0, +1),
 			sneatv.WithTabs(tabs.filesTab, tabs.dirsTab, tabs.hiddenTab),
+			sneatv.WithFooter(footer),
 		),
 		filterTabs: tabs,
 	}
+	f.queryBar = newQueryBar(f)
 	table.SetSelectable(true, false)
 	table.SetInputCapture(f.inputCapture)
 	table.SetFocusFunc(f.focus)
@@ -0,0 +1,195 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftquery"
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const queryHistoryFileName = "query-history.yaml"
+
+var getQueryHistoryFilePath = func() (string, error) {
+	dir, err := ftsettings.GetDatatugUserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, queryHistoryFileName), nil
+}
+
+const queryBarHints = "[gray]Enter apply · Esc cancel · ↑/↓ recent queries[-]"
+
+// queryBar is shown under files by "/" to type a filter query, see ftquery for its syntax.
+// Valid queries are applied while typing, syntax errors are shown under the input.
+type queryBar struct {
+	*tview.Flex
+	input   *tview.InputField
+	status  *tview.TextView
+	history []string // recent queries, the most recent first
+	// historyIndex is an index of a recalled query, -1 while a new query is typed
+	historyIndex int
+	typed        string         // a new query kept while recalling history
+	initial      *ftquery.Query // a query active when the bar was opened, restored by Esc
+	shown        bool
+}
+
+func newQueryBar(f *filesPanel) *queryBar {
+	bar := &queryBar{
+		Flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		input:  tview.NewInputField().SetLabel("/").SetPlaceholder("ext:go size>1M modified<3d name~test !hidden"),
+		status: tview.NewTextView().SetDynamicColors(true),
+	}
+	bar.input.SetChangedFunc(f.onQueryChanged)
+	bar.input.SetDoneFunc(f.onQueryDone)
+	bar.input.SetInputCapture(f.queryInputCapture)
+	bar.AddItem(bar.input, 1, 0, true)
+	bar.AddItem(bar.status, 1, 0, false)
+	return bar
+}
+
+// showQueryBar opens the filter bar with the active query.
+func (f *filesPanel) showQueryBar() {
+	bar := f.queryBar
+	if !bar.shown {
+		history, err := loadQueryHistory()
+		if err != nil {
+			f.nav.notifyError("Filter", err)
+		}
+		bar.history = history
+		bar.historyIndex = -1
+		bar.initial = f.query
+		bar.shown = true
+		var text string
+		if f.query != nil {
+			text = f.query.Text
+		}
+		bar.input.SetText(text)
+		bar.status.SetText(queryBarHints)
+		f.flex.AddItem(bar, 2, 0, true)
+	}
+	f.nav.app.SetFocus(bar.input)
+}
+
+func (f *filesPanel) hideQueryBar() {
+	f.queryBar.shown = false
+	f.flex.RemoveItem(f.queryBar)
+	f.nav.app.SetFocus(f.table)
+}
+
+// onQueryChanged applies a valid query while it's typed or shows where it's invalid.
+func (f *filesPanel) onQueryChanged(text string) {
+	q, err := ftquery.Parse(text)
+	if err != nil {
+		f.queryBar.status.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	f.queryBar.status.SetText(queryBarHints)
+	f.setQuery(q)
+}
+
+func (f *filesPanel) onQueryDone(key tcell.Key) {
+	switch key {
+	case tcell.KeyEnter:
+		text := f.queryBar.input.GetText()
+		q, err := ftquery.Parse(text)
+		if err != nil {
+			return // the error is already shown
+		}
+		f.setQuery(q)
+		if err = rememberQuery(text); err != nil {
+			f.nav.notifyError("Filter", err)
+		}
+		f.hideQueryBar()
+	case tcell.KeyEscape:
+		f.setQuery(f.queryBar.initial)
+		f.hideQueryBar()
+	}
+}
+
+// queryInputCapture recalls recent queries by Up & Down.
+func (f *filesPanel) queryInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	bar := f.queryBar
+	switch event.Key() {
+	case tcell.KeyUp:
+		if bar.historyIndex+1 < len(bar.history) {
+			if bar.historyIndex < 0 {
+				bar.typed = bar.input.GetText()
+			}
+			bar.historyIndex++
+			bar.input.SetText(bar.history[bar.historyIndex])
+		}
+		return nil
+	case tcell.KeyDown:
+		if bar.historyIndex >= 0 {
+			bar.historyIndex--
+			if bar.historyIndex < 0 {
+				bar.input.SetText(bar.typed)
+			} else {
+				bar.input.SetText(bar.history[bar.historyIndex])
+			}
+		}
+		return nil
+	default:
+		return event
+	}
+}
+
+// setQuery filters files by a query & shows it in the title, an empty query shows all files.
+// Dirs are kept unless the query selects them by type, so the tree can be navigated while filtered.
+// Paths are relative to the dir of an entry, git states are read once per dir.
+func (f *filesPanel) setQuery(q *ftquery.Query) {
+	if q.IsEmpty() {
+		f.query = nil
+		f.filter.QueryFilter = nil
+	} else {
+		f.query = q
+		matchesDirs, needsGitState := q.MatchesDirs(), q.NeedsGitState()
+		var entries *maskEntries
+		f.filter.QueryFilter = func(entry os.DirEntry) bool {
+			if entry.IsDir() && !matchesDirs {
+				return true
+			}
+			dir := f.nav.currentDirPath()
+			if e, ok := entry.(files.EntryWithDirPath); ok && e.DirPath() != "" {
+				dir = e.DirPath()
+			}
+			if entries == nil || entries.root != dir {
+				entries = newMaskEntries(dir, needsGitState)
+			}
+			return q.Match(entries.entry(dir, entry), nil)
+		}
+	}
+	if f.rows != nil {
+		f.SetFilter(f.filter)
+	}
+	f.updateTitle()
+}
+
+func loadQueryHistory() ([]string, error) {
+	filePath, err := getQueryHistoryFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return ftquery.LoadHistory(filePath)
+}
+
+// rememberQuery puts a query first in the history of recent queries, empty queries are not remembered.
+func rememberQuery(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	filePath, err := getQueryHistoryFilePath()
+	if err != nil {
+		return err
+	}
+	history, err := ftquery.LoadHistory(filePath)
+	if err != nil {
+		return err
+	}
+	return ftquery.SaveHistory(filePath, ftquery.AddToHistory(history, text))
+}
@@ -0,0 +1,128 @@
+package filetug
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftquery"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func visibleNames(nav *Navigator) []string {
+	var names []string
+	for _, entry := range nav.files.rows.VisibleEntries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestFilesPanel_QueryBar(t *testing.T) {
+	withTestGlobalLock(t)
+	filePath, err := getQueryHistoryFilePath()
+	require.NoError(t, err)
+	_ = os.Remove(filePath)
+	t.Cleanup(func() {
+		_ = os.Remove(filePath)
+	})
+
+	nav, _, dir := newNavigatorWithLocalDir(t, "a.go", "b.md", "c.txt")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "docs"), 0o755))
+	children, err := nav.store.ReadDir(context.Background(), dir)
+	require.NoError(t, err)
+	nav.files.SetRows(NewFileRows(files.NewDirContext(nav.store, dir, children)), true)
+	f := nav.files
+	bar := f.queryBar
+	key := func(k tcell.Key) {
+		bar.input.InputHandler()(tcell.NewEventKey(k, 0, tcell.ModNone), nil)
+	}
+
+	assert.Nil(t, f.inputCapture(keyRune('/')))
+	assert.True(t, bar.shown)
+	assert.Equal(t, "", bar.input.GetText())
+
+	bar.input.SetText("ext:go")
+	assert.ElementsMatch(t, []string{"docs", "a.go"}, visibleNames(nav), "dirs are kept")
+	assert.Equal(t, queryBarHints, bar.status.GetText(false))
+
+	bar.input.SetText("ext:go OR")
+	assert.Contains(t, bar.status.GetText(true), "col 10: expected a term")
+	assert.ElementsMatch(t, []string{"docs", "a.go"}, visibleNames(nav), "the last valid query is kept")
+	key(tcell.KeyEnter)
+	assert.True(t, bar.shown, "an invalid query is not applied")
+
+	bar.input.SetText("ext:go,md")
+	key(tcell.KeyEnter)
+	assert.False(t, bar.shown)
+	assert.ElementsMatch(t, []string{"docs", "a.go", "b.md"}, visibleNames(nav))
+	assert.Equal(t, "[DarkGray]/[-]ext:go,md", f.GetTitle())
+	history, err := ftquery.LoadHistory(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ext:go,md"}, history)
+
+	nav.files.SetRows(NewFileRows(nav.files.rows.Dir), true)
+	assert.ElementsMatch(t, []string{"docs", "a.go", "b.md"}, visibleNames(nav), "the query is kept for other rows")
+
+	f.showQueryBar()
+	assert.Equal(t, "ext:go,md", bar.input.GetText())
+	bar.input.SetText("type:dir")
+	assert.Equal(t, []string{"docs"}, visibleNames(nav))
+	key(tcell.KeyEscape)
+	assert.False(t, bar.shown)
+	assert.ElementsMatch(t, []string{"docs", "a.go", "b.md"}, visibleNames(nav), "Esc restores the query")
+	assert.Equal(t, "[DarkGray]/[-]ext:go,md", f.GetTitle())
+
+	f.showQueryBar()
+	bar.input.SetText("c")
+	key(tcell.KeyUp)
+	assert.Equal(t, "ext:go,md", bar.input.GetText())
+	key(tcell.KeyUp)
+	assert.Equal(t, "ext:go,md", bar.input.GetText(), "there are no older queries")
+	key(tcell.KeyDown)
+	assert.Equal(t, "c", bar.input.GetText(), "the typed query is restored")
+	key(tcell.KeyDown)
+	assert.Equal(t, "c", bar.input.GetText())
+	key(tcell.KeyEnter)
+	assert.ElementsMatch(t, []string{"docs", "c.txt"}, visibleNames(nav))
+	history, err = ftquery.LoadHistory(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "ext:go,md"}, history)
+
+	f.showQueryBar()
+	bar.input.SetText("  ")
+	key(tcell.KeyEnter)
+	assert.Nil(t, f.query)
+	assert.Nil(t, f.filter.QueryFilter)
+	assert.Equal(t, "", f.GetTitle())
+	assert.Len(t, visibleNames(nav), 4)
+	history, err = ftquery.LoadHistory(filePath)
+	require.NoError(t, err)
+	assert.Len(t, history, 2, "empty queries are not remembered")
+}
+
+func TestFilesPanel_QueryBar_HistoryErrors(t *testing.T) {
+	withTestGlobalLock(t)
+	filePath := filepath.Join(t.TempDir(), queryHistoryFileName)
+	require.NoError(t, os.WriteFile(filePath, []byte("a: [b"), 0o644))
+	orig := getQueryHistoryFilePath
+	getQueryHistoryFilePath = func() (string, error) {
+		return filePath, nil
+	}
+	t.Cleanup(func() {
+		getQueryHistoryFilePath = orig
+	})
+
+	nav, _, _ := newNavigatorWithLocalDir(t, "a.go")
+	f := nav.files
+	f.showQueryBar()
+	assert.Equal(t, notificationError, lastNotification(nav).level)
+	f.queryBar.input.SetText("ext:go")
+	f.queryBar.input.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	assert.False(t, f.queryBar.shown, "the query is applied even if it can't be remembered")
+	assert.Equal(t, []string{"a.go"}, visibleNames(nav))
+	assert.Equal(t, 2, len(nav.notifications.all()))
+}
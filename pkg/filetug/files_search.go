@@ -0,0 +1,126 @@
+package filetug
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// isSearchStartRune reports whether a typed rune starts a search in the files panel.
+// Other runes are kept for selection & navigation keys, e.g. Space, +, -, * & `.
+func isSearchStartRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_'
+}
+
+// searchInputCapture handles type-to-search in the files panel. Typed runes fuzzy match names of entries
+// & jump to the best match, Tab switches to the filter mode that shows only matches ranked by score.
+// Enter ends typing, then n/N cycle matches. Esc ends the search.
+// Like the selection it's not available in virtual lists, their keys are handled by onKey.
+func (f *filesPanel) searchInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if f.rows == nil || f.rows.virtual || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 && event.Key() == tcell.KeyRune {
+		return event
+	}
+	search := f.rows.search
+	if f.searching {
+		switch event.Key() {
+		case tcell.KeyRune:
+			f.setSearch(search.pattern+string(event.Rune()), search.filter)
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			runes := []rune(search.pattern)
+			f.setSearch(string(runes[:len(runes)-1]), search.filter)
+		case tcell.KeyTab:
+			f.setSearch(search.pattern, !search.filter)
+		case tcell.KeyEnter:
+			f.searching = false
+			f.updateTitle()
+		case tcell.KeyEscape:
+			f.setSearch("", false)
+		default:
+			return event
+		}
+		return nil
+	}
+	if event.Key() != tcell.KeyRune {
+		if event.Key() == tcell.KeyEscape && search.pattern != "" {
+			f.setSearch("", false)
+			return nil
+		}
+		return event
+	}
+	switch r := event.Rune(); {
+	case search.pattern != "" && r == 'n':
+		f.nextMatch(1)
+	case search.pattern != "" && r == 'N':
+		f.nextMatch(-1)
+	case r == 'm':
+		return event // m<letter> sets a mark, names starting with m are searched by typing M
+	case isSearchStartRune(r):
+		f.searching = true
+		f.setSearch(string(r), false)
+	default:
+		return event
+	}
+	return nil
+}
+
+// setSearch searches entries & selects the best match, an empty pattern ends the search.
+func (f *filesPanel) setSearch(pattern string, filter bool) {
+	if pattern == "" {
+		f.searching = false
+	}
+	f.rows.SetSearch(pattern, filter)
+	f.searchIndex = 0
+	f.selectMatch()
+	f.updateFooter()
+	f.updateTitle()
+}
+
+// nextMatch selects the next match in order of scores, delta is -1 for the previous one.
+func (f *filesPanel) nextMatch(delta int) {
+	n := len(f.rows.search.matches)
+	if n == 0 {
+		return
+	}
+	f.searchIndex = (f.searchIndex + delta + n) % n
+	f.selectMatch()
+	f.updateTitle()
+}
+
+func (f *filesPanel) selectMatch() {
+	matches := f.rows.search.matches
+	if len(matches) == 0 {
+		return
+	}
+	if row := f.rows.rowOf(matches[f.searchIndex].entry); row >= 0 {
+		f.table.Select(row, 0)
+	}
+}
+
+// updateTitle shows the active filter query & search next to the tabs of the panel.
+func (f *filesPanel) updateTitle() {
+	var parts []string
+	if f.query != nil {
+		parts = append(parts, "[DarkGray]/[-]"+tview.Escape(strings.TrimSpace(f.query.Text)))
+	}
+	if f.rows != nil && f.rows.search.pattern != "" {
+		search := f.rows.search
+		mode := "Find"
+		if search.filter {
+			mode = "Filter"
+		}
+		text := fmt.Sprintf("[DarkGray]%s:[-]%s", mode, tview.Escape(search.pattern))
+		if f.searching {
+			text += "▏"
+		}
+		if n := len(search.matches); n == 0 {
+			text += " [red]0[-]"
+		} else {
+			text += fmt.Sprintf(" [DarkGray]%d/%d[-]", f.searchIndex+1, n)
+		}
+		parts = append(parts, text)
+	}
+	f.SetTitle(strings.Join(parts, " "))
+}
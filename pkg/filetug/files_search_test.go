@@ -0,0 +1,85 @@
+package filetug
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilesPanel_Search(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t, "main.go", "Makefile", "README.md", "model.go")
+	f := nav.files
+	press := func(event *tcell.EventKey) *tcell.EventKey {
+		return f.inputCapture(event)
+	}
+	key := func(k tcell.Key) *tcell.EventKey {
+		return tcell.NewEventKey(k, 0, tcell.ModNone)
+	}
+	current := func() string {
+		row, _ := f.table.GetSelection()
+		return f.entryFromRow(row).Name()
+	}
+
+	m := keyRune('m')
+	assert.Equal(t, m, f.searchInputCapture(m), "a bare m doesn't start a search")
+	assert.False(t, f.searching)
+	assert.Nil(t, press(keyRune('m')), "m starts a mark")
+	assert.Nil(t, press(key(tcell.KeyEscape)))
+	assert.False(t, f.searching)
+	assert.Nil(t, press(keyRune('M')), "M starts a search of names starting with m")
+	assert.Nil(t, press(keyRune('a')))
+	assert.True(t, f.searching)
+	assert.Equal(t, "Makefile", current(), "the best match is selected, a matching case ranks higher")
+	assert.Equal(t, "[DarkGray]Find:[-]Ma▏ [DarkGray]1/2[-]", f.GetTitle())
+	assert.Len(t, f.rows.VisibleEntries, 4, "jump mode doesn't hide entries")
+	row := f.rows.rowOf(f.rows.SearchMatches()[0])
+	assert.Equal(t, "📄 [black:lightgreen]M[-:-][black:lightgreen]a[-:-]kefile", f.rows.GetCell(row, nameColIndex).Text)
+
+	assert.Nil(t, press(keyRune('n')), "n is typed while searching")
+	assert.Len(t, f.rows.SearchMatches(), 1)
+	assert.Nil(t, press(key(tcell.KeyBackspace2)))
+	assert.Len(t, f.rows.SearchMatches(), 2)
+
+	assert.Nil(t, press(key(tcell.KeyEnter)))
+	assert.False(t, f.searching)
+	assert.Equal(t, "[DarkGray]Find:[-]Ma [DarkGray]1/2[-]", f.GetTitle())
+	assert.Nil(t, press(keyRune('n')))
+	assert.Equal(t, "main.go", current())
+	assert.Nil(t, press(keyRune('N')))
+	assert.Equal(t, "Makefile", current())
+	assert.Nil(t, press(keyRune('N')))
+	assert.Equal(t, "main.go", current(), "matches are cycled")
+
+	assert.Nil(t, press(keyRune('g')), "a new search is started")
+	assert.Nil(t, press(key(tcell.KeyTab)))
+	assert.Equal(t, []string{"main.go", "model.go"}, visibleNames(nav), "filter mode shows ranked matches")
+	assert.Equal(t, "[DarkGray]Filter:[-]g▏ [DarkGray]1/2[-]", f.GetTitle())
+	assert.Nil(t, press(keyRune('x')))
+	assert.Empty(t, visibleNames(nav))
+	assert.Equal(t, "[DarkGray]Filter:[-]gx▏ [red]0[-]", f.GetTitle())
+	assert.Nil(t, press(key(tcell.KeyBackspace2)))
+	assert.Nil(t, press(key(tcell.KeyEnter)))
+
+	f.SetRows(NewFileRows(f.rows.Dir), true)
+	assert.Equal(t, []string{"main.go", "model.go"}, visibleNames(nav), "the search is kept on refresh")
+
+	assert.Nil(t, press(key(tcell.KeyEscape)))
+	assert.Len(t, f.rows.VisibleEntries, 4)
+	assert.Equal(t, "", f.GetTitle())
+	assert.NotNil(t, press(key(tcell.KeyEscape)), "Esc is passed on without a search")
+
+	assert.Nil(t, press(keyRune('r')))
+	assert.Nil(t, press(key(tcell.KeyBackspace2)))
+	assert.False(t, f.searching, "the search ends with the last rune")
+	assert.Nil(t, press(keyRune(' ')), "space selects an entry")
+	assert.Len(t, f.rows.SelectedEntries(), 1)
+	alt := tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt)
+	assert.Equal(t, alt, f.searchInputCapture(alt))
+}
+
+func TestHighlightRunes(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "[black:lightgreen]a[-:-]b[black:lightgreen]c[-:-]", highlightRunes("abc", []int{0, 2}))
+	assert.Equal(t, "[a[][black:lightgreen]b[-:-]", highlightRunes("[a]b", []int{3}), "tags in names are escaped")
+}
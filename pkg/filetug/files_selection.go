@@ -2,23 +2,24 @@ package filetug
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/fsutils"
+	"github.com/gdamore/tcell/v2"
 )
 
 // GetCurrentEntry returns the currently selected entry in the files panel.
 // It handles the parent directory entry and regular file/directory entries.
 func (f *filesPanel) GetCurrentEntry() files.EntryWithDirPath {
 	row, _ := f.table.GetSelection()
-	i := row - 1
+	i := f.rows.entryIndex(row)
 	if i < 0 || i >= len(f.rows.VisibleEntries) {
 		return nil
 	}
 	entry := f.rows.VisibleEntries[i]
 	if entry.DirPath() == "" {
 		if f.rows.Dir == nil {
-			_, _ = fmt.Fprintf(os.Stderr, "files panel missing dir path for entry %q\n", entry.Name())
+			f.nav.notify(notificationWarning, "Files", fmt.Sprintf("missing dir path for entry %q", entry.Name()))
 			return nil
 		}
 		entry = files.NewEntryWithDirPath(entry, f.rows.Dir.Path())
@@ -40,9 +41,13 @@ func (f *filesPanel) selectCurrentFile() {
 	}
 	for i, entry := range f.rows.AllEntries {
 		if entry.Name() == f.currentFileName {
+			expectedRow := i + 1
+			if f.rows.HideParent() {
+				expectedRow = i
+			}
 			row, _ := f.table.GetSelection()
-			if row != i+1 {
-				f.table.Select(i+1, 0)
+			if row != expectedRow {
+				f.table.Select(expectedRow, 0)
 			}
 			return
 		}
@@ -76,7 +81,7 @@ func (f *filesPanel) entryFromRow(row int) files.EntryWithDirPath {
 	if f.table == nil || f.rows == nil {
 		return nil
 	}
-	if row == 0 {
+	if row == 0 && !f.rows.HideParent() {
 		cell := f.table.GetCell(row, 0)
 		if cell != nil {
 			ref := cell.GetReference()
@@ -90,7 +95,7 @@ func (f *filesPanel) entryFromRow(row int) files.EntryWithDirPath {
 		// Fallback to generating reference if not set
 		return f.rows.getTopRowEntry()
 	}
-	i := row - 1
+	i := f.rows.entryIndex(row)
 	if i < 0 || i >= len(f.rows.VisibleEntries) {
 		return nil
 	}
@@ -102,3 +107,65 @@ func (f *filesPanel) entryFromRow(row int) files.EntryWithDirPath {
 	}
 	return entry
 }
+
+// selectionInputCapture changes the selection of entries of a dir:
+// Space or Insert toggles the current entry, Shift+Up/Down selects a range,
+// + selects all, - clears & * inverts the selection.
+func (f *filesPanel) selectionInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if f.rows == nil || f.rows.virtual {
+		return event
+	}
+	row, _ := f.table.GetSelection()
+	switch key := event.Key(); {
+	case key == tcell.KeyInsert, key == tcell.KeyRune && event.Rune() == ' ':
+		f.rows.endRange()
+		f.rows.ToggleSelected(f.rows.entryIndex(row))
+		if row+1 < f.rows.GetRowCount() {
+			f.table.Select(row+1, 0)
+		}
+	case (key == tcell.KeyUp || key == tcell.KeyDown) && event.Modifiers()&tcell.ModShift != 0:
+		next := row + 1
+		if key == tcell.KeyUp {
+			next = row - 1
+		}
+		if next < 0 || next >= f.rows.GetRowCount() {
+			return nil
+		}
+		f.rows.SelectRange(f.rows.entryIndex(row))
+		f.table.Select(next, 0)
+		f.rows.SelectRange(f.rows.entryIndex(next))
+	case key == tcell.KeyRune && event.Rune() == '+':
+		f.rows.SelectAll()
+	case key == tcell.KeyRune && event.Rune() == '-':
+		f.rows.ClearSelection()
+	case key == tcell.KeyRune && event.Rune() == '*':
+		f.rows.InvertSelection()
+	default:
+		f.rows.endRange()
+		return event
+	}
+	f.updateFooter()
+	return nil
+}
+
+// updateFooter shows the number & total size of selected entries.
+func (f *filesPanel) updateFooter() {
+	var text string
+	if f.rows != nil {
+		if summary := f.rows.SelectionSummary(); summary.Count > 0 {
+			text = fmt.Sprintf("%d selected · %s", summary.Count, fsutils.GetSizeShortText(summary.Size))
+			if summary.Hidden > 0 {
+				text += fmt.Sprintf(" · %d hidden", summary.Hidden)
+			}
+		}
+	}
+	f.footer.SetText(text)
+}
+
+// filesSelection returns entries selected in the files panel if it's active, nil if there is no selection.
+func (nav *Navigator) filesSelection() []files.EntryWithDirPath {
+	if nav.activeCol != 1 || nav.files == nil || nav.files.rows == nil || nav.files.rows.virtual {
+		return nil
+	}
+	return nav.files.rows.SelectedEntries()
+}
@@ -0,0 +1,58 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNavigator_SelectionOperations(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	withBasketFile(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.txt", "c.txt")
+	nav.files.rows.ToggleSelected(0)
+	nav.files.rows.ToggleSelected(2)
+
+	entries := nav.selectedEntries()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, filepath.Join(dir, "c.txt"), entries[1].FullName())
+	}
+	sources, err := nav.archiveSources()
+	assert.NoError(t, err)
+	assert.Len(t, sources, 2, "archive acts on the selection")
+
+	nav.activeCol = 0
+	assert.Nil(t, nav.filesSelection(), "the selection is used while the files panel is active only")
+	nav.activeCol = 1
+
+	t.Run("basket", func(t *testing.T) {
+		nav.files.inputCapture(tcell.NewEventKey(tcell.KeyCtrlB, 0, tcell.ModCtrl))
+		drainQueuedUpdates(updates)
+		assert.Equal(t, []BasketItem{
+			{Store: "file:", Path: filepath.Join(dir, "a.txt")},
+			{Store: "file:", Path: filepath.Join(dir, "c.txt")},
+		}, nav.basket.Items())
+		assert.Nil(t, nav.files.rows.SelectedEntries(), "the selection is cleared")
+		assert.Empty(t, nav.files.footer.GetText(true))
+		items := nav.notifications.all()
+		assert.Equal(t, "added 2 item(s)", items[len(items)-1].message)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		nav.files.rows.ToggleSelected(0)
+		nav.files.rows.ToggleSelected(1)
+		nav.delete()
+		o := lastOperation(nav)
+		assert.Equal(t, "Delete 2 item(s)", o.Title)
+		waitOperation(t, o)
+		for _, name := range []string{"a.txt", "b.txt"} {
+			_, err := os.Stat(filepath.Join(dir, name))
+			assert.True(t, os.IsNotExist(err))
+		}
+		assertFileContent(t, filepath.Join(dir, "c.txt"), "c.txt")
+	})
+}
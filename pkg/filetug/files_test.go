@@ -188,23 +188,6 @@ func TestFilesPanel_InputCapture(t *testing.T) {
 	nav, _ := setupNavigatorForFilesTest(t)
 	fp := newFiles(nav)
 
-	t.Run("Space_Toggle", func(t *testing.T) {
-		event := tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)
-
-		cell := tview.NewTableCell(" file1.txt")
-		fp.table.SetCell(1, 0, cell)
-		fp.table.Select(1, 0)
-
-		res := fp.inputCapture(event)
-		assert.Nil(t, res)
-		cell = fp.table.GetCell(1, 0)
-		assert.True(t, strings.HasPrefix(cell.Text, "✓"), "Expected cell text %q to start with ✓", cell.Text)
-
-		fp.inputCapture(event)
-		cell = fp.table.GetCell(1, 0)
-		assert.True(t, strings.HasPrefix(cell.Text, " "), "Expected cell text %q to start with space", cell.Text)
-	})
-
 	// For other tests that might need rows
 	entries := []files.EntryWithDirPath{
 		files.NewEntryWithDirPath(mockDirEntry{name: "file1.txt", isDir: false}, ""),
@@ -218,6 +201,25 @@ func TestFilesPanel_InputCapture(t *testing.T) {
 	fp.table.SetContent(rows)
 	fp.table.Select(1, 0) // Select file1.txt
 
+	t.Run("Space_Toggle", func(t *testing.T) {
+		event := tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)
+
+		res := fp.inputCapture(event)
+		assert.Nil(t, res)
+		assert.True(t, rows.IsSelected(entries[0]))
+		cell := rows.GetCell(1, 0)
+		assert.True(t, strings.HasPrefix(cell.Text, selectedEmoji), "Expected cell text %q to start with ✅", cell.Text)
+		row, _ := fp.table.GetSelection()
+		assert.Equal(t, 2, row, "moves to the next entry")
+
+		fp.table.Select(1, 0)
+		fp.inputCapture(event)
+		assert.False(t, rows.IsSelected(entries[0]))
+		cell = rows.GetCell(1, 0)
+		assert.True(t, strings.HasPrefix(cell.Text, "📄"), "Expected cell text %q to start with 📄", cell.Text)
+		fp.table.Select(1, 0)
+	})
+
 	t.Run("KeyRight", func(t *testing.T) {
 		event := tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone)
 		res := fp.inputCapture(event)
@@ -52,8 +52,20 @@ func (f *filesPanel) doLoadingAnimation(loading *tview.TableCell) {
 func (f *filesPanel) SetRows(rows *FileRows, showDirs bool) {
 	f.table.Select(0, 0)
 	f.filter.ShowDirs = showDirs
+	if !rows.keepSearch(f.rows) {
+		f.searching = false
+	}
 	rows.SetFilter(f.filter)
+	rows.keepSelection(f.rows)
+	if f.nav != nil {
+		rows.tagsOf = f.nav.entryTags
+	}
 	f.rows = rows
+	f.updateFooter()
+	f.updateTitle()
+	if f.nav != nil {
+		f.nav.countMaskMatchesIfVisible()
+	}
 	f.table.SetContent(rows)
 	if f.currentFileName != "" {
 		f.selectCurrentFile()
@@ -69,25 +81,33 @@ func (f *filesPanel) SetRows(rows *FileRows, showDirs bool) {
 
 }
 
-// SetFilter updates the filter applied to the file rows.
+// SetFilter updates the filter applied to the file rows, it is kept for rows of other dirs.
 func (f *filesPanel) SetFilter(filter ftui.Filter) {
+	f.filter = filter
 	f.rows.SetFilter(filter)
+	f.updateFooter()
 }
 
 // inputCapture handles keyboard input for the files panel.
 func (f *filesPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
 	table := f.table
-	if string(event.Rune()) == " " {
-		row, _ := table.GetSelection()
-		cell := table.GetCell(row, 0)
-
-		if strings.HasPrefix(cell.Text, " ") {
-			trimmed := strings.TrimPrefix(cell.Text, " ")
-			cell.SetText("✓" + trimmed)
-		} else {
-			trimmed := strings.TrimPrefix(cell.Text, "✓")
-			cell.SetText(" " + trimmed)
+	if f.rows != nil && f.rows.onKey != nil {
+		if event = f.rows.onKey(event); event == nil {
+			return nil
+		}
+	}
+	if !f.searching && (f.rows == nil || !f.rows.virtual) {
+		if event = f.nav.marksInputCapture(event); event == nil {
+			return nil
 		}
+	}
+	if event = f.searchInputCapture(event); event == nil {
+		return nil
+	}
+	if event = f.selectionInputCapture(event); event == nil {
+		return nil
+	}
+	if event = f.nav.entryActionsInputCapture(event); event == nil {
 		return nil
 	}
 	switch event.Key() {
@@ -107,6 +127,10 @@ func (f *filesPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
 		}
 		return event
 	case tcell.KeyRune:
+		if event.Rune() == '/' && event.Modifiers()&tcell.ModAlt == 0 {
+			f.showQueryBar()
+			return nil
+		}
 		return f.nav.globalNavInputCapture(event)
 	case tcell.KeyEnter:
 		row, _ := table.GetSelection()
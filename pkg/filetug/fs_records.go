@@ -45,6 +45,8 @@ func NewFileRows(dir *files.DirContext) *FileRows {
 type FileRows struct {
 	tview.TableContentReadOnly
 	hideParent     bool
+	virtual        bool // entries come from different directories, e.g. the basket
+	onKey          func(event *tcell.EventKey) *tcell.EventKey
 	store          files.Store
 	Dir            *files.DirContext
 	AllEntries     []files.EntryWithDirPath
@@ -55,10 +57,23 @@ type FileRows struct {
 	filter         ftui.Filter
 	gitStatusMu    sync.RWMutex
 	gitStatusText  map[string]string
+	selection      map[string]bool // full paths of selected entries, kept while they are hidden by the filter
+	rangeAnchor    int             // index in VisibleEntries where a range selection was started
+	rangeBase      map[string]bool // selection before a range was started, nil if no range is being selected
+	search         rowsSearch
+	tagsOf         func(entry files.EntryWithDirPath) []string // tags shown as chips after names
 }
 
 func (r *FileRows) HideParent() bool {
-	return r.hideParent || r.Dir.Path() == "/"
+	return r.hideParent || r.virtual || r.Dir != nil && r.Dir.Path() == "/"
+}
+
+// entryIndex maps a table row to an index in VisibleEntries. The parent row maps to -1.
+func (r *FileRows) entryIndex(row int) int {
+	if r.HideParent() {
+		return row
+	}
+	return row - 1
 }
 
 //func (r *FileRows) SetSelected(row int) {
@@ -111,6 +126,7 @@ func (r *FileRows) applyFilter() {
 			r.VisualInfos = append(r.VisualInfos, r.Infos[i])
 		}
 	}
+	r.applySearch()
 }
 
 func (r *FileRows) GetRowCount() int {
@@ -173,14 +189,27 @@ func (r *FileRows) GetCell(row, col int) *tview.TableCell {
 			fullPath := dirEntry.FullName()
 			statusText := r.getGitStatusText(fullPath)
 			displayName := name
-			if isDir {
+			if r.virtual {
+				displayName = dirEntry.String()
+			} else if positions := r.searchPositions(dirEntry); positions != nil {
+				displayName = highlightRunes(name, positions)
+			}
+			switch {
+			case r.IsSelected(dirEntry):
+				displayName = selectedEmoji + " " + displayName
+			case isDir:
 				displayName = dirEmoji + " " + displayName
-			} else {
+			default:
 				displayName = "📄 " + displayName
 			}
 			if statusText != "" {
 				displayName = displayName + " " + statusText
 			}
+			if r.tagsOf != nil {
+				if tags := r.tagsOf(dirEntry); len(tags) > 0 {
+					displayName = displayName + " " + tagChips(tags)
+				}
+			}
 			cell = tview.NewTableCell(displayName)
 		} else {
 			fi := r.VisualInfos[i]
@@ -226,6 +255,9 @@ func (r *FileRows) GetCell(row, col int) *tview.TableCell {
 			}
 		}
 		color := GetColorByFileExt(name)
+		if r.IsSelected(dirEntry) {
+			color = selectedColor
+		}
 		cell.SetTextColor(color)
 		cell.SetReference(dirEntry)
 	}
@@ -0,0 +1,128 @@
+package filetug
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftfuzzy"
+	"github.com/rivo/tview"
+)
+
+// rowsSearch is an incremental search of entries by fuzzy matching of their names.
+type rowsSearch struct {
+	pattern   string
+	filter    bool             // visible entries are narrowed to matches ranked by score, otherwise matches are only highlighted
+	matches   []searchMatch    // visible entries matching the pattern, the best first
+	positions map[string][]int // matched runes of names by full paths of entries
+}
+
+type searchMatch struct {
+	entry files.EntryWithDirPath
+	info  os.FileInfo
+	score int
+}
+
+// SetSearch highlights entries with names fuzzy matching a pattern, in filter mode other entries are hidden
+// & matches are ordered by score. An empty pattern ends the search.
+func (r *FileRows) SetSearch(pattern string, filter bool) {
+	r.search = rowsSearch{pattern: pattern, filter: filter}
+	r.applyFilter()
+}
+
+// SearchMatches returns visible entries matching the search pattern, the best first.
+func (r *FileRows) SearchMatches() []files.EntryWithDirPath {
+	result := make([]files.EntryWithDirPath, len(r.search.matches))
+	for i, m := range r.search.matches {
+		result[i] = m.entry
+	}
+	return result
+}
+
+// applySearch finds matches among entries that passed the filter & narrows them in filter mode.
+func (r *FileRows) applySearch() {
+	r.search.matches, r.search.positions = nil, nil
+	if r.search.pattern == "" {
+		return
+	}
+	r.search.positions = make(map[string][]int)
+	for i, entry := range r.VisibleEntries {
+		if score, positions, ok := ftfuzzy.Match(r.search.pattern, entry.Name()); ok {
+			r.search.matches = append(r.search.matches, searchMatch{entry: entry, info: r.VisualInfos[i], score: score})
+			r.search.positions[r.entryPath(entry)] = positions
+		}
+	}
+	slices.SortStableFunc(r.search.matches, func(a, b searchMatch) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return len(a.entry.Name()) - len(b.entry.Name())
+	})
+	if !r.search.filter {
+		return
+	}
+	r.VisibleEntries = make([]files.EntryWithDirPath, len(r.search.matches))
+	r.VisualInfos = make([]os.FileInfo, len(r.search.matches))
+	for i, m := range r.search.matches {
+		r.VisibleEntries[i] = m.entry
+		r.VisualInfos[i] = m.info
+	}
+}
+
+// searchPositions returns indexes of runes of a name matching the search pattern, nil if it doesn't match.
+func (r *FileRows) searchPositions(entry files.EntryWithDirPath) []int {
+	if r.search.positions == nil {
+		return nil
+	}
+	return r.search.positions[r.entryPath(entry)]
+}
+
+// highlightRunes marks runes of a text at positions the same way the tree marks found dirs.
+func highlightRunes(text string, positions []int) string {
+	var sb strings.Builder
+	var plain strings.Builder
+	flush := func() {
+		sb.WriteString(tview.Escape(plain.String()))
+		plain.Reset()
+	}
+	i := 0
+	for j, r := range []rune(text) {
+		if i < len(positions) && positions[i] == j {
+			i++
+			flush()
+			sb.WriteString("[black:lightgreen]")
+			sb.WriteString(tview.Escape(string(r)))
+			sb.WriteString("[-:-]")
+			continue
+		}
+		plain.WriteRune(r)
+	}
+	flush()
+	return sb.String()
+}
+
+// keepSearch carries over the search of rows of the same dir, e.g. on refresh.
+// It reports whether the search is kept.
+func (r *FileRows) keepSearch(prev *FileRows) bool {
+	if prev == nil || prev.search.pattern == "" || prev.virtual != r.virtual ||
+		prev.Dir == nil || r.Dir == nil || prev.Dir.Path() != r.Dir.Path() {
+		return false
+	}
+	r.search = rowsSearch{pattern: prev.search.pattern, filter: prev.search.filter}
+	return true
+}
+
+// rowOf returns a table row of a visible entry, -1 if it's hidden.
+func (r *FileRows) rowOf(entry files.EntryWithDirPath) int {
+	fullPath := r.entryPath(entry)
+	for i, e := range r.VisibleEntries {
+		if r.entryPath(e) == fullPath {
+			if r.HideParent() {
+				return i
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
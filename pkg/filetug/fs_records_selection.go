@@ -0,0 +1,173 @@
+package filetug
+
+import (
+	"os"
+	"path"
+	"reflect"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/gdamore/tcell/v2"
+)
+
+// selectedEmoji replaces the icon of a selected entry.
+const selectedEmoji = "✅"
+
+var selectedColor = tcell.ColorYellow
+
+// selectionSummary describes selected entries for the files panel footer.
+type selectionSummary struct {
+	Count  int   // selected entries including hidden by the filter
+	Hidden int   // selected entries hidden by the filter
+	Size   int64 // total size of visible selected files
+}
+
+// entryPath returns a full path of an entry that is used as a key of the selection.
+func (r *FileRows) entryPath(entry files.EntryWithDirPath) string {
+	if entry.DirPath() == "" && r.Dir != nil {
+		return path.Join(r.Dir.Path(), entry.Name())
+	}
+	return entry.FullName()
+}
+
+// IsSelected reports whether an entry is in the selection.
+func (r *FileRows) IsSelected(entry files.EntryWithDirPath) bool {
+	return r.selection[r.entryPath(entry)]
+}
+
+func (r *FileRows) setSelected(entry files.EntryWithDirPath, selected bool) {
+	if r.selection == nil {
+		r.selection = make(map[string]bool)
+	}
+	if selected {
+		r.selection[r.entryPath(entry)] = true
+	} else {
+		delete(r.selection, r.entryPath(entry))
+	}
+}
+
+// ToggleSelected selects or deselects a visible entry by its index in VisibleEntries.
+func (r *FileRows) ToggleSelected(i int) {
+	if i < 0 || i >= len(r.VisibleEntries) {
+		return
+	}
+	entry := r.VisibleEntries[i]
+	r.setSelected(entry, !r.IsSelected(entry))
+}
+
+// SelectAll selects all visible entries.
+func (r *FileRows) SelectAll() {
+	for _, entry := range r.VisibleEntries {
+		r.setSelected(entry, true)
+	}
+}
+
+// InvertSelection toggles selection of all visible entries.
+func (r *FileRows) InvertSelection() {
+	for i := range r.VisibleEntries {
+		r.ToggleSelected(i)
+	}
+}
+
+// ClearSelection deselects all entries including hidden by the filter.
+func (r *FileRows) ClearSelection() {
+	r.selection = nil
+	r.endRange()
+}
+
+// SelectRange selects visible entries between the index where a range was started & i, inclusive.
+// The range is started at i if there is none, moving back shrinks it.
+func (r *FileRows) SelectRange(i int) {
+	if i < 0 || i >= len(r.VisibleEntries) {
+		return
+	}
+	if r.rangeBase == nil {
+		r.rangeAnchor = i
+		r.rangeBase = make(map[string]bool, len(r.selection))
+		for p := range r.selection {
+			r.rangeBase[p] = true
+		}
+	}
+	r.selection = make(map[string]bool, len(r.rangeBase))
+	for p := range r.rangeBase {
+		r.selection[p] = true
+	}
+	from, to := min(r.rangeAnchor, i), max(r.rangeAnchor, i)
+	for _, entry := range r.VisibleEntries[from : to+1] {
+		r.setSelected(entry, true)
+	}
+}
+
+// endRange makes the next SelectRange start a new range.
+func (r *FileRows) endRange() {
+	r.rangeBase = nil
+}
+
+// SelectedEntries returns visible selected entries in the order they are listed.
+func (r *FileRows) SelectedEntries() []files.EntryWithDirPath {
+	if len(r.selection) == 0 {
+		return nil
+	}
+	var selected []files.EntryWithDirPath
+	for _, entry := range r.VisibleEntries {
+		if r.IsSelected(entry) {
+			if entry.DirPath() == "" && r.Dir != nil {
+				entry = files.NewEntryWithDirPath(entry, r.Dir.Path())
+			}
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
+
+// SelectionSummary counts selected entries & sums sizes of the visible ones.
+func (r *FileRows) SelectionSummary() selectionSummary {
+	summary := selectionSummary{Count: len(r.selection)}
+	if summary.Count == 0 {
+		return summary
+	}
+	var visible int
+	for i, entry := range r.VisibleEntries {
+		if !r.IsSelected(entry) {
+			continue
+		}
+		visible++
+		if entry.IsDir() {
+			continue
+		}
+		var info os.FileInfo
+		if i < len(r.VisualInfos) {
+			info = r.VisualInfos[i]
+		}
+		if isNilFileInfo(info) {
+			info, _ = entry.Info()
+		}
+		if !isNilFileInfo(info) {
+			summary.Size += info.Size()
+		}
+	}
+	summary.Hidden = summary.Count - visible
+	return summary
+}
+
+// keepSelection carries over the selection of rows of the same dir, e.g. on refresh.
+// Entries that no longer exist are dropped.
+func (r *FileRows) keepSelection(prev *FileRows) {
+	if prev == nil || len(prev.selection) == 0 || prev.virtual || r.virtual ||
+		prev.Dir == nil || r.Dir == nil || prev.Dir.Path() != r.Dir.Path() {
+		return
+	}
+	for _, entry := range r.AllEntries {
+		if prev.selection[r.entryPath(entry)] {
+			r.setSelected(entry, true)
+		}
+	}
+}
+
+// isNilFileInfo checks for nil including a typed nil pointer in the interface.
+func isNilFileInfo(info os.FileInfo) bool {
+	if info == nil {
+		return true
+	}
+	v := reflect.ValueOf(info)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,133 @@
+package filetug
+
+import (
+	"os"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftui"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSelectionTestRows(names ...string) *FileRows {
+	children := make([]os.DirEntry, len(names))
+	for i, name := range names {
+		children[i] = mockDirEntryInfo{name: name, info: mockFileInfo{size: int64(i + 1)}}
+	}
+	rows := NewFileRows(files.NewDirContext(nil, "/dir", children))
+	rows.SetFilter(ftui.Filter{ShowHidden: true})
+	return rows
+}
+
+func selectedNames(rows *FileRows) []string {
+	var names []string
+	for _, entry := range rows.SelectedEntries() {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestFileRows_Selection(t *testing.T) {
+	rows := newSelectionTestRows("a", "b", "c", ".d")
+	assert.Nil(t, rows.SelectedEntries())
+	assert.Equal(t, selectionSummary{}, rows.SelectionSummary())
+
+	rows.ToggleSelected(0)
+	rows.ToggleSelected(2)
+	rows.ToggleSelected(-1)
+	rows.ToggleSelected(10)
+	assert.Equal(t, []string{"a", "c"}, selectedNames(rows))
+	assert.Equal(t, "/dir/a", rows.SelectedEntries()[0].FullName())
+	assert.Equal(t, selectionSummary{Count: 2, Size: 4}, rows.SelectionSummary())
+
+	cell := rows.GetCell(1, nameColIndex)
+	assert.Equal(t, selectedEmoji+" a", cell.Text)
+	fg, _, _ := cell.Style.Decompose()
+	assert.Equal(t, selectedColor, fg)
+
+	rows.InvertSelection()
+	assert.Equal(t, []string{"b", ".d"}, selectedNames(rows))
+
+	rows.SelectAll()
+	assert.Len(t, rows.SelectedEntries(), 4)
+
+	t.Run("survives_filter", func(t *testing.T) {
+		rows.SetFilter(ftui.Filter{})
+		assert.Equal(t, []string{"a", "b", "c"}, selectedNames(rows), "hidden by the filter are not acted on")
+		assert.Equal(t, selectionSummary{Count: 4, Hidden: 1, Size: 6}, rows.SelectionSummary())
+		rows.SetFilter(ftui.Filter{ShowHidden: true})
+		assert.Len(t, rows.SelectedEntries(), 4)
+	})
+
+	rows.ClearSelection()
+	assert.Nil(t, rows.SelectedEntries())
+}
+
+func TestFileRows_SelectRange(t *testing.T) {
+	rows := newSelectionTestRows("a", "b", "c", "d", "e")
+	rows.ToggleSelected(4)
+	rows.SelectRange(10)
+	assert.Equal(t, []string{"e"}, selectedNames(rows), "out of range is ignored")
+
+	rows.SelectRange(1)
+	rows.SelectRange(3)
+	assert.Equal(t, []string{"b", "c", "d", "e"}, selectedNames(rows))
+	rows.SelectRange(2)
+	assert.Equal(t, []string{"b", "c", "e"}, selectedNames(rows), "moving back shrinks the range")
+	rows.SelectRange(0)
+	assert.Equal(t, []string{"a", "b", "e"}, selectedNames(rows), "the range crosses its start")
+
+	rows.endRange()
+	rows.SelectRange(3)
+	assert.Equal(t, []string{"a", "b", "d", "e"}, selectedNames(rows), "a new range keeps the selection")
+}
+
+func TestFileRows_KeepSelection(t *testing.T) {
+	prev := newSelectionTestRows("a", "b", "c")
+	prev.SelectAll()
+
+	rows := newSelectionTestRows("a", "c", "d")
+	rows.keepSelection(prev)
+	assert.Equal(t, []string{"a", "c"}, selectedNames(rows), "deleted entries are dropped")
+
+	other := NewFileRows(files.NewDirContext(nil, "/other", []os.DirEntry{mockDirEntry{name: "a"}}))
+	other.keepSelection(prev)
+	assert.Nil(t, other.SelectedEntries(), "not kept for another dir")
+	other.keepSelection(nil)
+	assert.Nil(t, other.SelectedEntries())
+}
+
+func TestFilesPanel_SelectionKeys(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t, "a.txt", "b.txt", "c.txt")
+	f := nav.files
+	key := func(k tcell.Key, mod tcell.ModMask) *tcell.EventKey {
+		return tcell.NewEventKey(k, 0, mod)
+	}
+
+	assert.Nil(t, f.inputCapture(key(tcell.KeyInsert, tcell.ModNone)))
+	assert.Equal(t, "1 selected · 5B", f.footer.GetText(true))
+	assert.Nil(t, f.inputCapture(key(tcell.KeyDown, tcell.ModShift)))
+	assert.Nil(t, f.inputCapture(key(tcell.KeyDown, tcell.ModShift)))
+	assert.Equal(t, []string{"a.txt", "b.txt", "c.txt"}, selectedNames(f.rows))
+	assert.Nil(t, f.inputCapture(key(tcell.KeyDown, tcell.ModShift)), "the last row")
+	assert.Nil(t, f.inputCapture(key(tcell.KeyUp, tcell.ModShift)))
+	assert.Equal(t, []string{"a.txt", "b.txt"}, selectedNames(f.rows))
+	assert.Equal(t, "2 selected · 10B", f.footer.GetText(true))
+
+	assert.Nil(t, f.inputCapture(keyRune('*')))
+	assert.Equal(t, []string{"c.txt"}, selectedNames(f.rows))
+	assert.Nil(t, f.inputCapture(keyRune('+')))
+	assert.Len(t, f.rows.SelectedEntries(), 3)
+
+	f.SetFilter(ftui.Filter{Extensions: []string{".md"}})
+	assert.Equal(t, "3 selected · 0B · 3 hidden", f.footer.GetText(true))
+	f.SetFilter(ftui.Filter{})
+
+	assert.Nil(t, f.inputCapture(keyRune('-')))
+	assert.Empty(t, f.footer.GetText(true))
+
+	f.table.Select(0, 0)
+	assert.Nil(t, f.inputCapture(key(tcell.KeyUp, tcell.ModShift)), "the parent row")
+	assert.Nil(t, f.rows.SelectedEntries())
+}
@@ -0,0 +1,352 @@
+// Package ftarchive creates archives streaming content of entries from any files.Store.
+package ftarchive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+)
+
+type Format string
+
+const (
+	FormatZip   Format = "zip"
+	FormatTarGz Format = "tar.gz"
+)
+
+// Formats lists supported formats in order they are offered to a user.
+var Formats = []Format{FormatZip, FormatTarGz}
+
+// Ext returns the file extension including the leading dot.
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// DefaultLevel is the default compression level of the format.
+const DefaultLevel = flate.DefaultCompression
+
+var ErrFileReadNotSupported = fmt.Errorf("reading files: %w", files.ErrNotSupported)
+
+// Source is an entry to be archived. Directories are archived recursively.
+type Source struct {
+	Store files.Store
+	Path  string
+	IsDir bool
+	Info  os.FileInfo // optional, used for mode & mtime of the top entry
+}
+
+type Options struct {
+	Format  Format
+	Level   int         // compression level from 0 (store) to 9 (best), DefaultLevel for the default one
+	Include *masks.Mask // if set, only files with names matching the mask are archived
+	Exclude *masks.Mask // files with names matching the mask are skipped
+}
+
+// Entry is a file or a directory to be written into an archive.
+type Entry struct {
+	Store     files.Store
+	Path      string // full path in the store
+	Name      string // path inside the archive
+	IsDir     bool
+	Size      int64
+	SizeKnown bool // false if the store provides no file info, e.g. HTTP
+	Mode      os.FileMode
+	ModTime   time.Time
+}
+
+var timeNow = time.Now
+
+// Collect walks sources and returns entries to be archived with names relative to parents of sources.
+// Directories are included only if no Include mask is set, so a filtered archive has no empty dirs.
+func Collect(ctx context.Context, sources []Source, opts Options) ([]Entry, error) {
+	var entries []Entry
+	names := make(map[string]bool)
+	add := func(e Entry) error {
+		if names[e.Name] {
+			return fmt.Errorf("duplicate entry name in archive: %s", e.Name)
+		}
+		names[e.Name] = true
+		entries = append(entries, e)
+		return nil
+	}
+	for _, src := range sources {
+		e := newEntry(src.Store, src.Path, path.Base(src.Path), src.IsDir, src.Info)
+		if err := collect(ctx, e, src.Info, opts, add); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func newEntry(store files.Store, fullPath, name string, isDir bool, info os.FileInfo) Entry {
+	e := Entry{Store: store, Path: fullPath, Name: name, IsDir: isDir, Mode: 0o644, ModTime: timeNow()}
+	if isDir {
+		e.Mode = 0o755
+	}
+	if info != nil {
+		if !isDir {
+			e.Size = info.Size()
+			e.SizeKnown = true
+		}
+		if perm := info.Mode().Perm(); perm != 0 {
+			e.Mode = perm
+		}
+		if modTime := info.ModTime(); !modTime.IsZero() {
+			e.ModTime = modTime
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			e.Mode |= os.ModeSymlink
+		}
+	}
+	return e
+}
+
+func collect(ctx context.Context, e Entry, info os.FileInfo, opts Options, add func(Entry) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if e.Mode&os.ModeSymlink != 0 {
+		return nil // links are not followed to avoid cycles & escaping the selection
+	}
+	if !e.IsDir {
+		matched, err := matchFile(e, info, opts)
+		if err != nil || !matched {
+			return err
+		}
+		return add(e)
+	}
+	if opts.Include == nil {
+		if err := add(e); err != nil {
+			return err
+		}
+	}
+	children, err := e.Store.ReadDir(ctx, e.Path)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		info, _ := child.Info()
+		name := child.Name()
+		childEntry := newEntry(e.Store, path.Join(e.Path, name), path.Join(e.Name, name), child.IsDir(), info)
+		if err = collect(ctx, childEntry, info, opts, add); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchFile applies masks to a file, path patterns match its name inside the archive.
+func matchFile(e Entry, info os.FileInfo, opts Options) (bool, error) {
+	entry := masks.Entry{DirEntry: files.NewDirEntry(path.Base(e.Path), e.IsDir), RelPath: e.Name}
+	if opts.Exclude != nil {
+		excluded, err := opts.Exclude.Match(entry, info)
+		if err != nil || excluded {
+			return false, err
+		}
+	}
+	if opts.Include != nil {
+		return opts.Include.Match(entry, info)
+	}
+	return true, nil
+}
+
+// Write streams entries into w in the given format.
+// report is called after each chunk of content with the total number of content bytes written so far.
+func Write(ctx context.Context, w io.Writer, entries []Entry, opts Options, report func(e Entry, bytesDone int64)) error {
+	aw, err := newArchiveWriter(w, opts)
+	if err != nil {
+		return err
+	}
+	var bytesDone int64
+	for _, e := range entries {
+		if err = ctx.Err(); err != nil {
+			return errors.Join(err, aw.Close())
+		}
+		onWritten := func(n int64) {
+			bytesDone += n
+			if report != nil {
+				report(e, bytesDone)
+			}
+		}
+		if e.IsDir {
+			if _, err = aw.Add(e); err != nil {
+				return errors.Join(err, aw.Close())
+			}
+			onWritten(0)
+			continue
+		}
+		if err = writeFile(ctx, aw, e, onWritten); err != nil {
+			return errors.Join(err, aw.Close())
+		}
+	}
+	return aw.Close()
+}
+
+func writeFile(ctx context.Context, aw archiveWriter, e Entry, onWritten func(n int64)) error {
+	reader, ok := e.Store.(files.FileReader)
+	if !ok {
+		return ErrFileReadNotSupported
+	}
+	r, err := reader.OpenReader(ctx, e.Path)
+	if err != nil {
+		return err
+	}
+	if e.SizeKnown || !aw.NeedsSize() {
+		var w io.Writer
+		if w, err = aw.Add(e); err != nil {
+			return errors.Join(err, r.Close())
+		}
+		return copyContent(ctx, w, r, onWritten)
+	}
+	// The size is unknown, so the content is spooled to a temp file to get it.
+	spool, err := os.CreateTemp("", "ftarchive-*")
+	if err != nil {
+		return errors.Join(err, r.Close())
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	if err = copyContent(ctx, spool, r, onWritten); err != nil {
+		return err
+	}
+	if e.Size, err = spool.Seek(0, io.SeekCurrent); err != nil {
+		return err
+	}
+	if _, err = spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w, err := aw.Add(e)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, spool)
+	return err
+}
+
+// copyContent copies r to w reporting written bytes and closes r.
+func copyContent(ctx context.Context, w io.Writer, r io.ReadCloser, onWritten func(n int64)) error {
+	var err error
+	buf := make([]byte, 32*1024)
+	for {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if _, err = w.Write(buf[:n]); err != nil {
+				break
+			}
+			onWritten(int64(n))
+		}
+		if readErr != nil {
+			if readErr != io.EOF {
+				err = readErr
+			}
+			break
+		}
+	}
+	return errors.Join(err, r.Close())
+}
+
+type archiveWriter interface {
+	// Add writes a header of the entry and returns a writer for its content.
+	Add(e Entry) (io.Writer, error)
+	// NeedsSize reports whether content size should be known before it is written.
+	NeedsSize() bool
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, opts Options) (archiveWriter, error) {
+	if opts.Level < flate.HuffmanOnly || opts.Level > flate.BestCompression {
+		return nil, fmt.Errorf("invalid compression level: %d", opts.Level)
+	}
+	switch opts.Format {
+	case FormatZip:
+		zw := zip.NewWriter(w)
+		zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, opts.Level)
+		})
+		return &zipWriter{zw: zw, store: opts.Level == flate.NoCompression}, nil
+	case FormatTarGz:
+		gw, err := gzip.NewWriterLevel(w, opts.Level)
+		if err != nil {
+			return nil, err
+		}
+		return &tarGzWriter{gw: gw, tw: tar.NewWriter(gw)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %q", opts.Format)
+	}
+}
+
+type zipWriter struct {
+	zw    *zip.Writer
+	store bool
+}
+
+func (z *zipWriter) Add(e Entry) (io.Writer, error) {
+	h := &zip.FileHeader{Name: e.Name, Method: zip.Deflate, Modified: e.ModTime}
+	if z.store {
+		h.Method = zip.Store
+	}
+	if e.IsDir {
+		h.Name += "/"
+		h.Method = zip.Store
+		h.SetMode(os.ModeDir | e.Mode.Perm())
+	} else {
+		h.SetMode(e.Mode.Perm())
+	}
+	return z.zw.CreateHeader(h)
+}
+
+func (z *zipWriter) NeedsSize() bool {
+	return false
+}
+
+func (z *zipWriter) Close() error {
+	return z.zw.Close()
+}
+
+type tarGzWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func (t *tarGzWriter) Add(e Entry) (io.Writer, error) {
+	h := &tar.Header{
+		Name:    e.Name,
+		Mode:    int64(e.Mode.Perm()),
+		ModTime: e.ModTime,
+		Size:    e.Size,
+	}
+	if e.IsDir {
+		h.Typeflag = tar.TypeDir
+		h.Name += "/"
+		h.Size = 0
+	} else {
+		h.Typeflag = tar.TypeReg
+	}
+	if err := t.tw.WriteHeader(h); err != nil {
+		return nil, err
+	}
+	return t.tw, nil
+}
+
+func (t *tarGzWriter) NeedsSize() bool {
+	return true
+}
+
+func (t *tarGzWriter) Close() error {
+	return errors.Join(t.tw.Close(), t.gw.Close())
+}
@@ -0,0 +1,277 @@
+package ftarchive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTree creates:
+//
+//	root/a.txt
+//	root/b.go
+//	root/sub/c.txt
+//	root/empty/
+func newTestTree(t *testing.T) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "root")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "empty"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "b.go"), []byte("package b"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "sub", "c.txt"), []byte("c"), 0o644))
+	assert.NoError(t, os.Symlink("a.txt", filepath.Join(root, "link")))
+	return root
+}
+
+func entryNames(entries []Entry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	root := newTestTree(t)
+	store := osfile.NewStore("/")
+	sources := []Source{{Store: store, Path: root, IsDir: true}}
+
+	t.Run("all", func(t *testing.T) {
+		entries, err := Collect(ctx, sources, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"root", "root/a.txt", "root/b.go", "root/empty", "root/sub", "root/sub/c.txt"}, entryNames(entries))
+		for _, e := range entries {
+			switch e.Name {
+			case "root/a.txt":
+				assert.Equal(t, os.FileMode(0o600), e.Mode)
+				assert.Equal(t, int64(1), e.Size)
+				assert.True(t, e.SizeKnown)
+			case "root/empty":
+				assert.Equal(t, os.FileMode(0o700), e.Mode)
+			}
+		}
+	})
+
+	t.Run("include_exclude", func(t *testing.T) {
+		include := &masks.Mask{Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `\.(txt|go)$`}}}
+		exclude := &masks.Mask{Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `^b\.`}}}
+		entries, err := Collect(ctx, sources, Options{Include: include, Exclude: exclude})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"root/a.txt", "root/sub/c.txt"}, entryNames(entries))
+	})
+
+	t.Run("invalid_mask", func(t *testing.T) {
+		exclude := &masks.Mask{Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `(`}}}
+		_, err := Collect(ctx, sources, Options{Exclude: exclude})
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate_names", func(t *testing.T) {
+		_, err := Collect(ctx, []Source{
+			{Store: store, Path: filepath.Join(root, "a.txt")},
+			{Store: store, Path: filepath.Join(root, "a.txt")},
+		}, Options{})
+		assert.EqualError(t, err, "duplicate entry name in archive: a.txt")
+	})
+
+	t.Run("read_dir_error", func(t *testing.T) {
+		_, err := Collect(ctx, []Source{{Store: store, Path: filepath.Join(root, "missing"), IsDir: true}}, Options{})
+		assert.Error(t, err)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := Collect(cancelled, sources, Options{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestWrite_Zip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	root := newTestTree(t)
+	store := osfile.NewStore("/")
+	entries, err := Collect(ctx, []Source{{Store: store, Path: root, IsDir: true}}, Options{})
+	assert.NoError(t, err)
+
+	for _, level := range []int{DefaultLevel, 0, 9} {
+		var buf bytes.Buffer
+		var lastBytes int64
+		err = Write(ctx, &buf, entries, Options{Format: FormatZip, Level: level}, func(_ Entry, bytesDone int64) {
+			lastBytes = bytesDone
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len("a")+len("package b")+len("c")), lastBytes)
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.NoError(t, err)
+		contents := make(map[string]string)
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				contents[f.Name] = "<dir>"
+				continue
+			}
+			r, err := f.Open()
+			assert.NoError(t, err)
+			data, _ := io.ReadAll(r)
+			_ = r.Close()
+			contents[f.Name] = string(data)
+			if f.Name == "root/a.txt" {
+				assert.Equal(t, os.FileMode(0o600), f.Mode().Perm())
+			}
+		}
+		assert.Equal(t, map[string]string{
+			"root/":          "<dir>",
+			"root/a.txt":     "a",
+			"root/b.go":      "package b",
+			"root/empty/":    "<dir>",
+			"root/sub/":      "<dir>",
+			"root/sub/c.txt": "c",
+		}, contents)
+	}
+}
+
+func readTarGz(t *testing.T, data []byte) map[string]*tar.Header {
+	t.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+	headers := make(map[string]*tar.Header)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(tr)
+		h.PAXRecords = map[string]string{"content": string(content)}
+		headers[h.Name] = h
+	}
+	return headers
+}
+
+func TestWrite_TarGz(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	root := newTestTree(t)
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(filepath.Join(root, "a.txt"), modTime, modTime))
+	store := osfile.NewStore("/")
+	entries, err := Collect(ctx, []Source{{Store: store, Path: root, IsDir: true}}, Options{})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(ctx, &buf, entries, Options{Format: FormatTarGz, Level: 1}, nil))
+	headers := readTarGz(t, buf.Bytes())
+	assert.Len(t, headers, 6)
+	a := headers["root/a.txt"]
+	assert.Equal(t, "a", a.PAXRecords["content"])
+	assert.Equal(t, int64(0o600), a.Mode)
+	assert.True(t, modTime.Equal(a.ModTime))
+	assert.Equal(t, byte(tar.TypeDir), headers["root/sub/"].Typeflag)
+}
+
+// readerStore streams files of a map and provides no file info like an HTTP store.
+type readerStore struct {
+	files.Store
+	files map[string]string
+}
+
+func (s readerStore) RootURL() url.URL {
+	return url.URL{Scheme: "https", Host: "example.com"}
+}
+
+func (s readerStore) ReadDir(_ context.Context, dir string) ([]os.DirEntry, error) {
+	var entries []os.DirEntry
+	for p := range s.files {
+		if filepath.Dir(p) == dir {
+			entries = append(entries, files.NewDirEntry(filepath.Base(p), false))
+		}
+	}
+	return entries, nil
+}
+
+func (s readerStore) OpenReader(_ context.Context, p string) (io.ReadCloser, error) {
+	content, ok := s.files[p]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func TestWrite_UnknownSize(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := readerStore{files: map[string]string{"/pub/x.txt": "remote content"}}
+	entries, err := Collect(ctx, []Source{{Store: store, Path: "/pub", IsDir: true}}, Options{})
+	assert.NoError(t, err)
+	assert.False(t, entries[1].SizeKnown)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(ctx, &buf, entries, Options{Format: FormatTarGz, Level: DefaultLevel}, nil))
+	headers := readTarGz(t, buf.Bytes())
+	assert.Equal(t, "remote content", headers["pub/x.txt"].PAXRecords["content"])
+	assert.Equal(t, int64(len("remote content")), headers["pub/x.txt"].Size)
+}
+
+func TestWrite_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var buf bytes.Buffer
+
+	err := Write(ctx, &buf, nil, Options{Format: FormatZip, Level: 10}, nil)
+	assert.EqualError(t, err, "invalid compression level: 10")
+
+	err = Write(ctx, &buf, nil, Options{Format: "rar"}, nil)
+	assert.EqualError(t, err, `unsupported archive format: "rar"`)
+
+	store := readerStore{files: map[string]string{}}
+	entries := []Entry{{Store: store, Path: "/missing", Name: "missing"}}
+	err = Write(ctx, &buf, entries, Options{Format: FormatZip}, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	entries = []Entry{{Store: store.Store, Path: "/x", Name: "x"}}
+	err = Write(ctx, &buf, entries, Options{Format: FormatTarGz}, nil)
+	assert.ErrorIs(t, err, ErrFileReadNotSupported)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = Write(cancelled, &buf, []Entry{{Name: "x", IsDir: true}}, Options{Format: FormatZip}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = Write(ctx, failingWriter{}, []Entry{{Name: "x", IsDir: true}}, Options{Format: FormatTarGz}, nil)
+	assert.Error(t, err)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestFormat_Ext(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, ".zip", FormatZip.Ext())
+	assert.Equal(t, ".tar.gz", FormatTarGz.Ext())
+}
@@ -0,0 +1,490 @@
+package ftarchive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+)
+
+const (
+	FormatTar Format = "tar"
+	FormatGz  Format = "gz" // a single compressed file
+)
+
+var ErrFileWriteNotSupported = fmt.Errorf("writing files: %w", files.ErrNotSupported)
+
+// ErrUnsafePath is reported for archive entries that would be extracted outside the target directory.
+var ErrUnsafePath = errors.New("unsafe path in archive")
+
+// DetectFormat returns the format of an archive by its file name.
+func DetectFormat(name string) (Format, bool) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip, true
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz, true
+	case strings.HasSuffix(lower, ".tar"):
+		return FormatTar, true
+	case strings.HasSuffix(lower, ".gz"):
+		return FormatGz, true
+	default:
+		return "", false
+	}
+}
+
+// TrimExt returns the name of an archive without the extension of its format.
+func TrimExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip", ".gz"} {
+		if strings.HasSuffix(lower, ext) && len(name) > len(ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+type ExtractOptions struct {
+	Format    Format // detected by the archive name if empty
+	Overwrite bool   // existing files are overwritten, otherwise they are skipped
+	Size      int64  // size of the archive if known, used to report progress of streamed formats
+}
+
+// ExtractProgress is reported after each extracted entry & each chunk of content.
+type ExtractProgress struct {
+	Total      int // number of entries, 0 if unknown until the end of a stream
+	Done       int
+	Skipped    int
+	BytesTotal int64 // 0 if unknown
+	BytesDone  int64
+	Current    string // name of the entry being extracted
+}
+
+// ExtractResult lists what has been changed in the target store, it is filled even if extraction fails.
+type ExtractResult struct {
+	Created     []string // full paths of created dirs & files, parents first
+	Overwritten []string
+	Skipped     []string // names of entries skipped as existing or not supported, e.g. links
+	Rejected    []string // names of entries pointing outside the target directory, also via existing links
+}
+
+// Extract streams an archive from one store into a directory of another one.
+// Directories are created as needed, permissions & modification times are preserved if the target store supports it.
+// Entries with unsafe paths (absolute or escaping via "..") and links are never extracted,
+// nothing is written through links existing in the target directory.
+func Extract(
+	ctx context.Context,
+	src files.Store, archivePath string,
+	dst files.Store, dstDir string,
+	opts ExtractOptions,
+	report func(p ExtractProgress),
+) (result ExtractResult, err error) {
+	if opts.Format == "" {
+		var ok bool
+		if opts.Format, ok = DetectFormat(archivePath); !ok {
+			return result, fmt.Errorf("unsupported archive format: %s", path.Base(archivePath))
+		}
+	}
+	reader, ok := src.(files.FileReader)
+	if !ok {
+		return result, ErrFileReadNotSupported
+	}
+	writer, ok := dst.(files.FileWriter)
+	if !ok {
+		return result, ErrFileWriteNotSupported
+	}
+	x := &extractor{
+		ctx:      ctx,
+		store:    dst,
+		writer:   writer,
+		dir:      dstDir,
+		opts:     opts,
+		report:   report,
+		existing: make(map[string]map[string]fs.FileMode),
+	}
+	x.attrSetter, _ = dst.(files.AttrSetter)
+	r, err := reader.OpenReader(ctx, archivePath)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		err = errors.Join(err, r.Close())
+	}()
+	if err = x.ensureDir(dstDir); err != nil {
+		return x.result, err
+	}
+	switch opts.Format {
+	case FormatZip:
+		err = x.extractZip(r)
+	case FormatTar, FormatTarGz:
+		err = x.extractTar(r, opts.Format == FormatTarGz)
+	case FormatGz:
+		err = x.extractGz(r, path.Base(archivePath))
+	default:
+		err = fmt.Errorf("unsupported archive format: %q", opts.Format)
+	}
+	if err == nil {
+		err = x.applyDirAttrs()
+	}
+	if err == nil && len(x.result.Rejected) > 0 {
+		err = fmt.Errorf("%w: %d entries skipped, e.g. %s", ErrUnsafePath, len(x.result.Rejected), x.result.Rejected[0])
+	}
+	return x.result, err
+}
+
+type dirAttrs struct {
+	path    string
+	mode    os.FileMode
+	modTime time.Time
+}
+
+type extractor struct {
+	ctx        context.Context
+	store      files.Store
+	writer     files.FileWriter
+	attrSetter files.AttrSetter // nil if the store can't set attributes
+	dir        string
+	opts       ExtractOptions
+	report     func(p ExtractProgress)
+	progress   ExtractProgress
+	source     *countingReader // bytes read from a streamed archive, nil for zip
+	result     ExtractResult
+	existing   map[string]map[string]fs.FileMode // dir => types of its entries by names
+	dirAttrs   []dirAttrs                        // applied at the end as extracting content changes mtime of dirs
+}
+
+func (x *extractor) reportProgress() {
+	if x.report != nil {
+		x.report(x.progress)
+	}
+}
+
+// targetPath resolves a name from an archive inside the target dir.
+func (x *extractor) targetPath(name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if path.IsAbs(name) || (len(name) > 1 && name[1] == ':') {
+		return "", ErrUnsafePath
+	}
+	clean := path.Clean(name)
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", ErrUnsafePath
+	}
+	return path.Join(x.dir, clean), nil
+}
+
+func (x *extractor) names(dir string) map[string]fs.FileMode {
+	names, ok := x.existing[dir]
+	if !ok {
+		names = make(map[string]fs.FileMode)
+		x.existing[dir] = names
+		children, err := x.store.ReadDir(x.ctx, dir)
+		if err == nil {
+			for _, child := range children {
+				names[child.Name()] = child.Type()
+			}
+		}
+	}
+	return names
+}
+
+func (x *extractor) exists(p string) bool {
+	dir := path.Dir(p)
+	if dir == p {
+		return true // root
+	}
+	_, ok := x.names(dir)[path.Base(p)]
+	return ok
+}
+
+// throughLink reports whether the path or any of its parents below the target dir is an existing link.
+// Writing there would follow the link, possibly outside the target dir.
+func (x *extractor) throughLink(p string) bool {
+	root := path.Clean(x.dir)
+	for ; p != root && strings.HasPrefix(p, root+"/"); p = path.Dir(p) {
+		if x.names(path.Dir(p))[path.Base(p)]&fs.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureDir creates a dir & its missing parents.
+func (x *extractor) ensureDir(dir string) error {
+	if x.exists(dir) {
+		return nil
+	}
+	if err := x.ensureDir(path.Dir(dir)); err != nil {
+		return err
+	}
+	if err := x.store.CreateDir(x.ctx, dir); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	x.names(path.Dir(dir))[path.Base(dir)] = fs.ModeDir
+	x.existing[dir] = make(map[string]fs.FileMode)
+	x.result.Created = append(x.result.Created, dir)
+	return nil
+}
+
+func (x *extractor) skip(name string, rejected bool) {
+	if rejected {
+		x.result.Rejected = append(x.result.Rejected, name)
+	} else {
+		x.result.Skipped = append(x.result.Skipped, name)
+	}
+	x.progress.Skipped++
+	x.reportProgress()
+}
+
+// extractDir creates a dir of an archive. Its attributes are applied after the content is extracted.
+func (x *extractor) extractDir(name string, mode os.FileMode, modTime time.Time) error {
+	p, err := x.targetPath(name)
+	if err != nil || x.throughLink(p) {
+		x.skip(name, true)
+		return nil
+	}
+	x.progress.Current = name
+	existed := x.exists(p)
+	if err = x.ensureDir(p); err != nil {
+		return err
+	}
+	if !existed { // attributes of existing dirs are kept
+		x.dirAttrs = append(x.dirAttrs, dirAttrs{path: p, mode: mode, modTime: modTime})
+	}
+	x.progress.Done++
+	x.reportProgress()
+	return nil
+}
+
+// extractFile writes content of a file of an archive honoring the overwrite policy.
+func (x *extractor) extractFile(name string, mode os.FileMode, modTime time.Time, content io.Reader) error {
+	p, err := x.targetPath(name)
+	if err != nil || x.throughLink(p) {
+		x.skip(name, true)
+		return nil
+	}
+	if p == x.dir {
+		x.skip(name, false)
+		return nil
+	}
+	x.progress.Current = name
+	if err = x.ensureDir(path.Dir(p)); err != nil {
+		return err
+	}
+	existed := x.exists(p)
+	if existed && !x.opts.Overwrite {
+		x.skip(name, false)
+		return nil
+	}
+	w, err := x.writer.OpenWriter(x.ctx, p)
+	if err != nil {
+		return err
+	}
+	if existed {
+		x.result.Overwritten = append(x.result.Overwritten, p)
+	} else {
+		x.names(path.Dir(p))[path.Base(p)] = 0
+		x.result.Created = append(x.result.Created, p)
+	}
+	err = copyContent(x.ctx, w, io.NopCloser(content), func(n int64) {
+		x.addBytesDone(n)
+	})
+	if err = errors.Join(err, w.Close()); err != nil {
+		return err
+	}
+	if err = x.setAttrs(p, mode, modTime); err != nil {
+		return err
+	}
+	x.progress.Done++
+	x.reportProgress()
+	return nil
+}
+
+// addBytesDone counts extracted content. Streamed archives count bytes read from the archive instead
+// as their BytesTotal is the size of the archive, uncompressed sizes of entries are not known in advance.
+func (x *extractor) addBytesDone(n int64) {
+	if x.source != nil {
+		x.progress.BytesDone = x.source.n
+	} else {
+		x.progress.BytesDone += n
+	}
+	x.reportProgress()
+}
+
+func (x *extractor) setAttrs(p string, mode os.FileMode, modTime time.Time) error {
+	if x.attrSetter == nil {
+		return nil
+	}
+	if perm := mode.Perm(); perm != 0 {
+		if err := x.attrSetter.Chmod(x.ctx, p, perm); err != nil {
+			return err
+		}
+	}
+	if !modTime.IsZero() {
+		return x.attrSetter.Chtimes(x.ctx, p, modTime)
+	}
+	return nil
+}
+
+func (x *extractor) applyDirAttrs() error {
+	for i := len(x.dirAttrs) - 1; i >= 0; i-- {
+		a := x.dirAttrs[i]
+		if err := x.setAttrs(a.path, a.mode, a.modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) extractZip(r io.Reader) error {
+	ra, size, cleanup, err := readerAt(x.ctx, r)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	x.progress.Total = len(zr.File)
+	for _, f := range zr.File {
+		x.progress.BytesTotal += int64(f.UncompressedSize64)
+	}
+	x.reportProgress()
+	for _, f := range zr.File {
+		if err = x.ctx.Err(); err != nil {
+			return err
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.extractDir(f.Name, mode, f.Modified)
+		case mode.IsRegular():
+			err = x.extractZipFile(f)
+		default:
+			x.skip(f.Name, false) // links & devices
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) extractZipFile(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	err = x.extractFile(f.Name, f.Mode(), f.Modified, rc)
+	return errors.Join(err, rc.Close())
+}
+
+func (x *extractor) extractTar(r io.Reader, gzipped bool) error {
+	counter := &countingReader{r: r}
+	x.source = counter
+	x.progress.BytesTotal = x.opts.Size
+	var err error
+	if gzipped {
+		if r, err = gzip.NewReader(counter); err != nil {
+			return err
+		}
+	} else {
+		r = counter
+	}
+	x.reportProgress()
+	tr := tar.NewReader(r)
+	for {
+		if err = x.ctx.Err(); err != nil {
+			return err
+		}
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = x.extractDir(h.Name, os.FileMode(h.Mode), h.ModTime)
+		case tar.TypeReg:
+			err = x.extractFile(h.Name, os.FileMode(h.Mode), h.ModTime, tr)
+		default:
+			x.skip(h.Name, false) // links, devices, etc.
+		}
+		if err != nil {
+			return err
+		}
+		x.progress.BytesDone = counter.n
+	}
+}
+
+// extractGz extracts a single compressed file named as in the gzip header or as the archive without ".gz".
+func (x *extractor) extractGz(r io.Reader, archiveName string) error {
+	counter := &countingReader{r: r}
+	x.source = counter
+	gr, err := gzip.NewReader(counter)
+	if err != nil {
+		return err
+	}
+	name := path.Base(gr.Name)
+	if gr.Name == "" || name == "." || name == ".." || name == "/" {
+		name = TrimExt(archiveName)
+	}
+	x.progress.Total = 1
+	x.progress.BytesTotal = x.opts.Size
+	x.reportProgress()
+	if err = x.extractFile(name, 0, gr.ModTime, gr); err != nil {
+		return err
+	}
+	x.progress.BytesDone = counter.n
+	x.reportProgress()
+	return nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readerAt returns random access to content of a zip archive as it keeps its directory at the end.
+// Local files are accessed directly, other content is spooled to a temp file.
+func readerAt(ctx context.Context, r io.Reader) (ra io.ReaderAt, size int64, cleanup func(), err error) {
+	if f, ok := r.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		return f, info.Size(), func() {}, nil
+	}
+	spool, err := os.CreateTemp("", "ftarchive-*.zip")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup = func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}
+	if err = copyContent(ctx, spool, io.NopCloser(r), func(n int64) { size += n }); err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+	return spool, size, cleanup, nil
+}
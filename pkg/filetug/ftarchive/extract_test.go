@@ -0,0 +1,322 @@
+package ftarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		expected Format
+		ok       bool
+	}{
+		{"a.zip", FormatZip, true},
+		{"a.TAR.GZ", FormatTarGz, true},
+		{"a.tgz", FormatTarGz, true},
+		{"a.tar", FormatTar, true},
+		{"a.txt.gz", FormatGz, true},
+		{"a.txt", "", false},
+	}
+	for _, tt := range tests {
+		format, ok := DetectFormat(tt.name)
+		assert.Equal(t, tt.expected, format, tt.name)
+		assert.Equal(t, tt.ok, ok, tt.name)
+	}
+	assert.Equal(t, "a", TrimExt("a.tar.gz"))
+	assert.Equal(t, "a.txt", TrimExt("a.txt.gz"))
+	assert.Equal(t, ".zip", TrimExt(".zip"))
+}
+
+// writeArchiveFile archives the test tree into a file in a temp dir.
+func writeArchiveFile(t *testing.T, root string, format Format) string {
+	t.Helper()
+	ctx := context.Background()
+	store := osfile.NewStore("/")
+	entries, err := Collect(ctx, []Source{{Store: store, Path: root, IsDir: true}}, Options{})
+	assert.NoError(t, err)
+	archivePath := filepath.Join(t.TempDir(), "test"+format.Ext())
+	f, err := os.Create(archivePath)
+	assert.NoError(t, err)
+	assert.NoError(t, Write(ctx, f, entries, Options{Format: format, Level: DefaultLevel}, nil))
+	assert.NoError(t, f.Close())
+	return archivePath
+}
+
+func TestExtract_RoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	root := newTestTree(t)
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(filepath.Join(root, "a.txt"), modTime, modTime))
+	assert.NoError(t, os.Chtimes(filepath.Join(root, "sub"), modTime, modTime))
+	store := osfile.NewStore("/")
+
+	for _, format := range []Format{FormatZip, FormatTarGz} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+			archivePath := writeArchiveFile(t, root, format)
+			dstDir := filepath.Join(t.TempDir(), "out", "nested")
+			var last ExtractProgress
+			result, err := Extract(ctx, store, archivePath, store, dstDir, ExtractOptions{}, func(p ExtractProgress) {
+				last = p
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 6, last.Done)
+			assert.Equal(t, filepath.Dir(dstDir), result.Created[0])
+			assert.Contains(t, result.Created, filepath.Join(dstDir, "root", "sub", "c.txt"))
+
+			extracted := filepath.Join(dstDir, "root")
+			data, err := os.ReadFile(filepath.Join(extracted, "b.go"))
+			assert.NoError(t, err)
+			assert.Equal(t, "package b", string(data))
+			info, err := os.Stat(filepath.Join(extracted, "a.txt"))
+			assert.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			assert.True(t, modTime.Equal(info.ModTime()))
+			info, err = os.Stat(filepath.Join(extracted, "sub"))
+			assert.NoError(t, err)
+			assert.True(t, modTime.Equal(info.ModTime()))
+			info, err = os.Stat(filepath.Join(extracted, "empty"))
+			assert.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+			t.Run("skip_existing", func(t *testing.T) {
+				assert.NoError(t, os.WriteFile(filepath.Join(extracted, "a.txt"), []byte("changed"), 0o644))
+				result, err := Extract(ctx, store, archivePath, store, dstDir, ExtractOptions{}, nil)
+				assert.NoError(t, err)
+				assert.Empty(t, result.Created)
+				assert.Len(t, result.Skipped, 3)
+				data, _ := os.ReadFile(filepath.Join(extracted, "a.txt"))
+				assert.Equal(t, "changed", string(data))
+			})
+
+			t.Run("overwrite", func(t *testing.T) {
+				result, err := Extract(ctx, store, archivePath, store, dstDir, ExtractOptions{Overwrite: true}, nil)
+				assert.NoError(t, err)
+				assert.Empty(t, result.Created)
+				assert.Len(t, result.Overwritten, 3)
+				data, _ := os.ReadFile(filepath.Join(extracted, "a.txt"))
+				assert.Equal(t, "a", string(data))
+			})
+		})
+	}
+}
+
+func TestExtract_UnsafeEntries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	add := func(h *tar.Header, content string) {
+		h.Size = int64(len(content))
+		assert.NoError(t, tw.WriteHeader(h))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	add(&tar.Header{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0o644}, "evil")
+	add(&tar.Header{Name: "/etc/evil.txt", Typeflag: tar.TypeReg, Mode: 0o644}, "evil")
+	add(&tar.Header{Name: "ok/../../evil", Typeflag: tar.TypeDir, Mode: 0o755}, "")
+	add(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}, "")
+	add(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0o700}, "")
+	add(&tar.Header{Name: "ok.txt", Typeflag: tar.TypeReg, Mode: 0o644}, "ok")
+	assert.NoError(t, tw.Close())
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "unsafe.tar")
+	assert.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0o644))
+	dstDir := filepath.Join(dir, "a", "b")
+	assert.NoError(t, os.MkdirAll(dstDir, 0o755))
+	store := osfile.NewStore("/")
+
+	result, err := Extract(ctx, store, archivePath, store, dstDir, ExtractOptions{Size: int64(buf.Len())}, nil)
+	assert.ErrorIs(t, err, ErrUnsafePath)
+	assert.Equal(t, []string{"../evil.txt", "/etc/evil.txt", "ok/../../evil"}, result.Rejected)
+	assert.Equal(t, []string{"link"}, result.Skipped)
+	assert.Equal(t, []string{filepath.Join(dstDir, "ok.txt")}, result.Created)
+	_, err = os.Lstat(filepath.Join(dir, "a", "evil.txt"))
+	assert.True(t, os.IsNotExist(err))
+	info, err := os.Stat(dstDir)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm(), "attributes of the target dir are kept")
+}
+
+func TestExtract_ExistingLinks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	add := func(h *tar.Header, content string) {
+		h.Size = int64(len(content))
+		assert.NoError(t, tw.WriteHeader(h))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	add(&tar.Header{Name: "file.txt", Typeflag: tar.TypeReg, Mode: 0o644}, "evil")
+	add(&tar.Header{Name: "sub", Typeflag: tar.TypeDir, Mode: 0o755}, "")
+	add(&tar.Header{Name: "sub/inner.txt", Typeflag: tar.TypeReg, Mode: 0o644}, "evil")
+	add(&tar.Header{Name: "ok.txt", Typeflag: tar.TypeReg, Mode: 0o644}, "ok")
+	assert.NoError(t, tw.Close())
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "links.tar")
+	assert.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0o644))
+	outside := filepath.Join(dir, "outside")
+	assert.NoError(t, os.MkdirAll(outside, 0o755))
+	outsideFile := filepath.Join(outside, "file.txt")
+	assert.NoError(t, os.WriteFile(outsideFile, []byte("kept"), 0o644))
+	dstDir := filepath.Join(dir, "dst")
+	assert.NoError(t, os.MkdirAll(dstDir, 0o755))
+	assert.NoError(t, os.Symlink(outsideFile, filepath.Join(dstDir, "file.txt")))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(dstDir, "sub")))
+	store := osfile.NewStore("/")
+
+	result, err := Extract(ctx, store, archivePath, store, dstDir, ExtractOptions{Overwrite: true}, nil)
+	assert.ErrorIs(t, err, ErrUnsafePath)
+	assert.Equal(t, []string{"file.txt", "sub", "sub/inner.txt"}, result.Rejected, "nothing is written through links")
+	assert.Equal(t, []string{filepath.Join(dstDir, "ok.txt")}, result.Created)
+	data, err := os.ReadFile(outsideFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "kept", string(data))
+	_, err = os.Lstat(filepath.Join(outside, "inner.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExtract_Gz(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := osfile.NewStore("/")
+	modTime := time.Date(2019, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	newGz := func(name string) string {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		gw.Name = name
+		gw.ModTime = modTime
+		_, err := gw.Write([]byte("gz content"))
+		assert.NoError(t, err)
+		assert.NoError(t, gw.Close())
+		archivePath := filepath.Join(t.TempDir(), "data.txt.gz")
+		assert.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0o644))
+		return archivePath
+	}
+
+	dstDir := t.TempDir()
+	_, err := Extract(ctx, store, newGz("original.txt"), store, dstDir, ExtractOptions{}, nil)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dstDir, "original.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "gz content", string(data))
+	info, err := os.Stat(filepath.Join(dstDir, "original.txt"))
+	assert.NoError(t, err)
+	assert.True(t, modTime.Equal(info.ModTime()))
+
+	_, err = Extract(ctx, store, newGz(""), store, dstDir, ExtractOptions{}, nil)
+	assert.NoError(t, err)
+	data, err = os.ReadFile(filepath.Join(dstDir, "data.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "gz content", string(data))
+}
+
+func TestExtract_StreamedProgress(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := osfile.NewStore("/")
+	dir := t.TempDir()
+	content := make([]byte, 1024*1024) // compresses to a small fraction of its size
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err := gw.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, gw.Close())
+	gzPath := filepath.Join(dir, "zeros.gz")
+	assert.NoError(t, os.WriteFile(gzPath, gz.Bytes(), 0o644))
+
+	var tgz bytes.Buffer
+	gw = gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gw)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "zeros", Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}))
+	_, err = tw.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+	tgzPath := filepath.Join(dir, "zeros.tar.gz")
+	assert.NoError(t, os.WriteFile(tgzPath, tgz.Bytes(), 0o644))
+
+	for archivePath, size := range map[string]int{gzPath: gz.Len(), tgzPath: tgz.Len()} {
+		t.Run(filepath.Base(archivePath), func(t *testing.T) {
+			var last ExtractProgress
+			_, err := Extract(ctx, store, archivePath, store, t.TempDir(), ExtractOptions{Size: int64(size)}, func(p ExtractProgress) {
+				assert.LessOrEqual(t, p.BytesDone, p.BytesTotal, "compressed bytes are counted")
+				last = p
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(size), last.BytesTotal)
+			assert.Positive(t, last.BytesDone)
+		})
+	}
+}
+
+func TestExtract_ZipFromRemoteStore(t *testing.T) {
+	t.Parallel()
+	root := newTestTree(t)
+	archivePath := writeArchiveFile(t, root, FormatZip)
+	content, err := os.ReadFile(archivePath)
+	assert.NoError(t, err)
+	remote := readerStore{files: map[string]string{"/pub/test.zip": string(content)}}
+
+	dstDir := t.TempDir()
+	_, err = Extract(context.Background(), remote, "/pub/test.zip", osfile.NewStore("/"), dstDir, ExtractOptions{}, nil)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dstDir, "root", "sub", "c.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "c", string(data))
+}
+
+func TestExtract_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := osfile.NewStore("/")
+	dir := t.TempDir()
+
+	_, err := Extract(ctx, store, filepath.Join(dir, "a.rar"), store, dir, ExtractOptions{}, nil)
+	assert.EqualError(t, err, "unsupported archive format: a.rar")
+
+	_, err = Extract(ctx, readerStore{}.Store, "/a.zip", store, dir, ExtractOptions{}, nil)
+	assert.ErrorIs(t, err, ErrFileReadNotSupported)
+
+	_, err = Extract(ctx, store, "/a.zip", readerStore{}, dir, ExtractOptions{}, nil)
+	assert.ErrorIs(t, err, ErrFileWriteNotSupported)
+
+	_, err = Extract(ctx, store, filepath.Join(dir, "missing.zip"), store, dir, ExtractOptions{}, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	notArchive := filepath.Join(dir, "bad.tar.gz")
+	assert.NoError(t, os.WriteFile(notArchive, []byte("not gzip"), 0o644))
+	_, err = Extract(ctx, store, notArchive, store, dir, ExtractOptions{}, nil)
+	assert.Error(t, err)
+	_, err = Extract(ctx, store, notArchive, store, dir, ExtractOptions{Format: FormatZip}, nil)
+	assert.Error(t, err)
+	_, err = Extract(ctx, store, notArchive, store, dir, ExtractOptions{Format: FormatGz}, nil)
+	assert.Error(t, err)
+	_, err = Extract(ctx, store, notArchive, store, dir, ExtractOptions{Format: "rar"}, nil)
+	assert.EqualError(t, err, `unsupported archive format: "rar"`)
+
+	root := newTestTree(t)
+	archivePath := writeArchiveFile(t, root, FormatTarGz)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Extract(cancelled, store, archivePath, store, dir, ExtractOptions{}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,108 @@
+// Package ftchecksum computes checksums of files streamed from a files.Store
+// & reads, writes and verifies checksum manifests like SHA256SUMS.
+package ftchecksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/filetug/filetug/pkg/files"
+)
+
+type Algorithm string
+
+const (
+	MD5    Algorithm = "MD5"
+	SHA1   Algorithm = "SHA-1"
+	SHA256 Algorithm = "SHA-256"
+	SHA512 Algorithm = "SHA-512"
+	CRC32  Algorithm = "CRC32"
+)
+
+// Algorithms lists supported algorithms in the order they are shown.
+var Algorithms = []Algorithm{MD5, SHA1, SHA256, SHA512, CRC32}
+
+var ErrUnknownAlgorithm = errors.New("unknown checksum algorithm")
+
+// ErrFileReadNotSupported is returned for stores that can't stream content of files.
+var ErrFileReadNotSupported = errors.New("store does not support reading files")
+
+// NewHash returns a hash for an algorithm.
+func NewHash(algorithm Algorithm) (hash.Hash, error) {
+	switch algorithm {
+	case MD5:
+		return md5.New(), nil
+	case SHA1:
+		return sha1.New(), nil
+	case SHA256:
+		return sha256.New(), nil
+	case SHA512:
+		return sha512.New(), nil
+	case CRC32:
+		return crc32.NewIEEE(), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, algorithm)
+}
+
+// Sums are hex encoded checksums of a file by algorithm.
+type Sums map[Algorithm]string
+
+// progressWriter reports number of bytes written through it.
+type progressWriter struct {
+	ctx    context.Context
+	report func(n int64)
+}
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if w.report != nil {
+		w.report(int64(len(p)))
+	}
+	return len(p), nil
+}
+
+// Compute reads a file once & computes checksums for all algorithms.
+// report is called with a number of bytes read so far & can be nil.
+func Compute(ctx context.Context, store files.Store, filePath string, algorithms []Algorithm, report func(n int64)) (Sums, error) {
+	reader, ok := store.(files.FileReader)
+	if !ok {
+		return nil, ErrFileReadNotSupported
+	}
+	hashes := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, 0, len(algorithms)+1)
+	for i, algorithm := range algorithms {
+		h, err := NewHash(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = h
+		writers = append(writers, h)
+	}
+	writers = append(writers, progressWriter{ctx: ctx, report: report})
+	r, err := reader.OpenReader(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	if _, err = io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+	sums := make(Sums, len(algorithms))
+	for i, algorithm := range algorithms {
+		sums[algorithm] = hex.EncodeToString(hashes[i].Sum(nil))
+	}
+	return sums, nil
+}
@@ -0,0 +1,161 @@
+package ftchecksum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/stretchr/testify/assert"
+)
+
+// Checksums of "hello\n"
+const (
+	helloMD5    = "b1946ac92492d2347c6235b4d2611184"
+	helloSHA1   = "f572d396fae9206628714fb2ce00f72e94f2258f"
+	helloSHA256 = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	helloCRC32  = "363a3020"
+)
+
+func newTestDir(t *testing.T, content map[string]string) (files.Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range content {
+		filePath := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+		assert.NoError(t, os.WriteFile(filePath, []byte(data), 0o644))
+	}
+	return osfile.NewStore("/"), dir
+}
+
+func TestNewHash(t *testing.T) {
+	for _, algorithm := range Algorithms {
+		h, err := NewHash(algorithm)
+		assert.NoError(t, err)
+		assert.NotNil(t, h)
+	}
+	_, err := NewHash("SHA-3")
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+}
+
+func TestCompute(t *testing.T) {
+	store, dir := newTestDir(t, map[string]string{"hello.txt": "hello\n"})
+	ctx := context.Background()
+	filePath := filepath.Join(dir, "hello.txt")
+
+	var read int64
+	sums, err := Compute(ctx, store, filePath, []Algorithm{MD5, SHA1, SHA256, CRC32}, func(n int64) {
+		read += n
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Sums{MD5: helloMD5, SHA1: helloSHA1, SHA256: helloSHA256, CRC32: helloCRC32}, sums)
+	assert.Equal(t, int64(6), read)
+
+	sums, err = Compute(ctx, store, filePath, []Algorithm{SHA512}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sums[SHA512], 128)
+
+	_, err = Compute(ctx, store, filePath, []Algorithm{"x"}, nil)
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	_, err = Compute(ctx, store, filepath.Join(dir, "missing"), []Algorithm{MD5}, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = Compute(ctx, struct{ files.Store }{}, filePath, []Algorithm{MD5}, nil)
+	assert.ErrorIs(t, err, ErrFileReadNotSupported)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Compute(canceled, store, filePath, []Algorithm{MD5}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestManifestAlgorithm(t *testing.T) {
+	for name, expected := range map[string]Algorithm{
+		"SHA256SUMS":         SHA256,
+		"sha256sums.txt":     SHA256,
+		"MD5SUMS":            MD5,
+		"app.tar.gz.sha256":  SHA256,
+		"app.tar.gz.SHA512":  SHA512,
+		"app.sha1":           SHA1,
+		"app.md5":            MD5,
+		"release-notes.txt":  "",
+		"SHA256SUMS.asc.sig": "",
+	} {
+		algorithm, ok := ManifestAlgorithm(name)
+		assert.Equal(t, expected, algorithm, name)
+		assert.Equal(t, expected != "", ok, name)
+	}
+	name, ok := ManifestName(SHA256)
+	assert.True(t, ok)
+	assert.Equal(t, "SHA256SUMS", name)
+	_, ok = ManifestName(CRC32)
+	assert.False(t, ok)
+}
+
+func TestParseManifest(t *testing.T) {
+	t.Run("gnu", func(t *testing.T) {
+		data := "# comment\n" + helloSHA256 + "  hello.txt\n\n" + helloSHA256 + " *bin/app\n"
+		m, err := ParseManifest("SHA256SUMS", []byte(data))
+		assert.NoError(t, err)
+		assert.Equal(t, SHA256, m.Algorithm)
+		assert.Equal(t, []Entry{{Name: "hello.txt", Sum: helloSHA256}, {Name: "bin/app", Sum: helloSHA256}}, m.Entries)
+		assert.Equal(t, helloSHA256+"  hello.txt\n"+helloSHA256+"  bin/app\n", string(m.Format()))
+	})
+
+	t.Run("bsd", func(t *testing.T) {
+		m, err := ParseManifest("checksums", []byte("MD5 (hello.txt) = "+helloMD5+"\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, MD5, m.Algorithm, "detected by length")
+		assert.Equal(t, []Entry{{Name: "hello.txt", Sum: helloMD5}}, m.Entries)
+	})
+
+	t.Run("single_sum", func(t *testing.T) {
+		m, err := ParseManifest("hello.txt.sha1", []byte("F572D396FAE9206628714FB2CE00F72E94F2258F\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, []Entry{{Name: "hello.txt", Sum: helloSHA1}}, m.Entries)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseManifest("SHA256SUMS", []byte("not a checksum\n"))
+		assert.ErrorIs(t, err, errInvalidManifest)
+		_, err = ParseManifest("checksums", []byte("abc  x\n"))
+		assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	store, dir := newTestDir(t, map[string]string{"hello.txt": "hello\n", "changed.txt": "changed", "sub/dir/x": "x"})
+	ctx := context.Background()
+	m := Manifest{Algorithm: MD5, Entries: []Entry{
+		{Name: "hello.txt", Sum: helloMD5},
+		{Name: "changed.txt", Sum: helloMD5},
+		{Name: "missing.txt", Sum: helloMD5},
+		{Name: "sub/dir", Sum: helloMD5},
+	}}
+	var reported []string
+	results, err := Verify(ctx, store, dir, m, func(_ int, name string) {
+		reported = append(reported, name)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello.txt", "changed.txt", "missing.txt", "sub/dir"}, reported)
+	if assert.Len(t, results, 4) {
+		assert.Equal(t, StatusPass, results[0].Status)
+		assert.Equal(t, helloMD5, results[0].Actual)
+		assert.Equal(t, StatusFail, results[1].Status)
+		assert.NotEqual(t, helloMD5, results[1].Actual)
+		assert.Equal(t, StatusMissing, results[2].Status)
+		assert.Equal(t, StatusError, results[3].Status)
+		assert.Error(t, results[3].Err)
+	}
+
+	_, err = Verify(ctx, struct{ files.Store }{}, dir, m, nil)
+	assert.ErrorIs(t, err, ErrFileReadNotSupported)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Verify(canceled, store, dir, m, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
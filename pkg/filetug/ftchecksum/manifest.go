@@ -0,0 +1,183 @@
+package ftchecksum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+)
+
+// Entry is a file listed in a manifest with its expected checksum.
+type Entry struct {
+	Name string // path relative to the dir of the manifest
+	Sum  string // lower case hex
+}
+
+type Manifest struct {
+	Algorithm Algorithm
+	Entries   []Entry
+}
+
+// manifestNames maps GNU coreutils manifest names to algorithms, CRC32 has no standard manifest.
+var manifestNames = map[string]Algorithm{
+	"MD5SUMS":    MD5,
+	"SHA1SUMS":   SHA1,
+	"SHA256SUMS": SHA256,
+	"SHA512SUMS": SHA512,
+}
+
+var manifestExtensions = map[string]Algorithm{
+	".md5":    MD5,
+	".sha1":   SHA1,
+	".sha256": SHA256,
+	".sha512": SHA512,
+}
+
+// ManifestAlgorithm returns an algorithm of a manifest by its file name,
+// e.g. SHA256SUMS or release.tar.gz.sha256.
+func ManifestAlgorithm(name string) (Algorithm, bool) {
+	if algorithm, ok := manifestNames[strings.TrimSuffix(strings.ToUpper(name), ".TXT")]; ok {
+		return algorithm, true
+	}
+	algorithm, ok := manifestExtensions[strings.ToLower(path.Ext(name))]
+	return algorithm, ok
+}
+
+// ManifestName returns a name of a manifest to be written for an algorithm.
+func ManifestName(algorithm Algorithm) (string, bool) {
+	for name, a := range manifestNames {
+		if a == algorithm {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+var (
+	// GNU format: "<hex>  <name>" or "<hex> *<name>" for binary mode
+	gnuLine = regexp.MustCompile(`^\\?([0-9a-fA-F]+) [ *]?(.+)$`)
+	// BSD format: "SHA256 (<name>) = <hex>"
+	bsdLine = regexp.MustCompile(`^([A-Z0-9-]+) ?\((.+)\) ?= ?([0-9a-fA-F]+)$`)
+)
+
+var errInvalidManifest = errors.New("invalid manifest line")
+
+// ParseManifest reads a manifest in GNU or BSD format. A line with a checksum only,
+// as in release.tar.gz.sha256, refers to a file named as the manifest without its extension.
+func ParseManifest(name string, data []byte) (Manifest, error) {
+	algorithm, _ := ManifestAlgorithm(name)
+	manifest := Manifest{Algorithm: algorithm}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var entry Entry
+		if m := bsdLine.FindStringSubmatch(line); m != nil {
+			entry = Entry{Name: m[2], Sum: m[3]}
+		} else if m = gnuLine.FindStringSubmatch(line); m != nil {
+			entry = Entry{Name: m[2], Sum: m[1]}
+		} else if isHex(line) {
+			entry = Entry{Name: strings.TrimSuffix(name, path.Ext(name)), Sum: line}
+		} else {
+			return manifest, fmt.Errorf("%s:%d: %w", name, lineNum, errInvalidManifest)
+		}
+		entry.Sum = strings.ToLower(entry.Sum)
+		if manifest.Algorithm == "" {
+			if manifest.Algorithm = algorithmByLength(len(entry.Sum)); manifest.Algorithm == "" {
+				return manifest, fmt.Errorf("%s:%d: %w", name, lineNum, ErrUnknownAlgorithm)
+			}
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+	return manifest, scanner.Err()
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func algorithmByLength(hexLen int) Algorithm {
+	switch hexLen {
+	case 32:
+		return MD5
+	case 40:
+		return SHA1
+	case 64:
+		return SHA256
+	case 128:
+		return SHA512
+	}
+	return ""
+}
+
+// Format writes entries in GNU format that sha256sum -c & similar tools can verify.
+func (m Manifest) Format() []byte {
+	var buf bytes.Buffer
+	for _, entry := range m.Entries {
+		_, _ = fmt.Fprintf(&buf, "%s  %s\n", entry.Sum, entry.Name)
+	}
+	return buf.Bytes()
+}
+
+type Status string
+
+const (
+	StatusPass    Status = "pass"
+	StatusFail    Status = "fail"
+	StatusMissing Status = "missing"
+	StatusError   Status = "error"
+)
+
+// Result is a verification result of a manifest entry.
+type Result struct {
+	Entry
+	Actual string
+	Status Status
+	Err    error // set for StatusError
+}
+
+// Verify computes checksums of files listed in a manifest located in dir.
+// report is called before each file is checked & can be nil.
+func Verify(ctx context.Context, store files.Store, dir string, manifest Manifest, report func(i int, name string)) ([]Result, error) {
+	results := make([]Result, 0, len(manifest.Entries))
+	for i, entry := range manifest.Entries {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if report != nil {
+			report(i, entry.Name)
+		}
+		result := Result{Entry: entry}
+		sums, err := Compute(ctx, store, path.Join(dir, entry.Name), []Algorithm{manifest.Algorithm}, nil)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			result.Status = StatusMissing
+		case errors.Is(err, ErrFileReadNotSupported), errors.Is(err, context.Canceled):
+			return results, err
+		case err != nil:
+			result.Status, result.Err = StatusError, err
+		default:
+			result.Actual = sums[manifest.Algorithm]
+			result.Status = StatusFail
+			if result.Actual == entry.Sum {
+				result.Status = StatusPass
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,311 @@
+// Package ftcompare compares two directory trees that can be in different files.Store implementations
+// & merges them into a single tree with a status of each entry.
+package ftcompare
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+)
+
+type Status string
+
+const (
+	StatusSame      Status = "same"
+	StatusDifferent Status = "different"
+	StatusOnlyLeft  Status = "only left"
+	StatusOnlyRight Status = "only right"
+)
+
+// Reason tells why files are different.
+type Reason string
+
+const (
+	ReasonType    Reason = "type" // a file on one side & a dir on another
+	ReasonSize    Reason = "size"
+	ReasonModTime Reason = "mtime"
+	ReasonContent Reason = "content"
+	ReasonNested  Reason = "nested" // a dir with different entries inside
+)
+
+// DefaultModTimeWindow is the default tolerance for comparing modification times.
+const DefaultModTimeWindow = 2 * time.Second
+
+type Options struct {
+	// Content compares content of files with equal sizes instead of modification times.
+	// It needs both stores to be able to read files.
+	Content       bool
+	ModTimeWindow time.Duration // DefaultModTimeWindow if not positive
+}
+
+// Endpoint is a directory in a store.
+type Endpoint struct {
+	Store files.Store
+	Dir   string
+}
+
+func (e Endpoint) path(rel string) string {
+	return path.Join(e.Dir, rel)
+}
+
+// Side is an entry on one side of a comparison.
+type Side struct {
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+type Node struct {
+	Name     string
+	Path     string // relative to compared dirs, "." for the root
+	Left     *Side  // nil if missing on the left
+	Right    *Side  // nil if missing on the right
+	Status   Status
+	Reason   Reason  // set for StatusDifferent only
+	Children []*Node // sorted by name, dirs first
+}
+
+// IsDir returns true if the entry is a dir on any side.
+func (n *Node) IsDir() bool {
+	return (n.Left != nil && n.Left.IsDir) || (n.Right != nil && n.Right.IsDir)
+}
+
+// Walk calls f for the node & all its descendants in pre-order. Children are skipped if f returns false.
+func (n *Node) Walk(f func(n *Node) bool) {
+	if !f(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(f)
+	}
+}
+
+// Find returns a descendant by its relative path or nil.
+func (n *Node) Find(rel string) *Node {
+	if n.Path == rel {
+		return n
+	}
+	for _, child := range n.Children {
+		if child.Path == rel || strings.HasPrefix(rel, child.Path+"/") {
+			return child.Find(rel)
+		}
+	}
+	return nil
+}
+
+// Compare merges left & right trees. Links are ignored.
+// report is called with a path of each dir before it's read.
+func Compare(ctx context.Context, left, right Endpoint, opts Options, report func(dir string)) (*Node, error) {
+	if opts.ModTimeWindow <= 0 {
+		opts.ModTimeWindow = DefaultModTimeWindow
+	}
+	c := comparer{ctx: ctx, left: left, right: right, opts: opts, report: report}
+	if opts.Content {
+		leftReader, leftOK := left.Store.(files.FileReader)
+		rightReader, rightOK := right.Store.(files.FileReader)
+		if leftOK && rightOK {
+			c.leftReader, c.rightReader = leftReader, rightReader
+		}
+	}
+	root := &Node{Name: path.Base(left.Dir), Path: ".", Left: &Side{IsDir: true}, Right: &Side{IsDir: true}}
+	if err := c.compareDir(root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+type comparer struct {
+	ctx         context.Context
+	left, right Endpoint
+	opts        Options
+	report      func(dir string)
+	leftReader  files.FileReader // nil unless comparing content
+	rightReader files.FileReader
+}
+
+func (c *comparer) readDir(e Endpoint, rel string) (map[string]os.DirEntry, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.report != nil {
+		c.report(e.path(rel))
+	}
+	entries, err := e.Store.ReadDir(c.ctx, e.path(rel))
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]os.DirEntry, len(entries))
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			result[entry.Name()] = entry
+		}
+	}
+	return result, nil
+}
+
+func sideOf(entry os.DirEntry) (*Side, error) {
+	side := &Side{IsDir: entry.IsDir()}
+	info, err := entry.Info()
+	if err != nil {
+		return nil, err
+	}
+	side.ModTime = info.ModTime()
+	if !side.IsDir {
+		side.Size = info.Size()
+	}
+	return side, nil
+}
+
+// compareDir fills children of a node that is a dir on at least one side & sets its status.
+func (c *comparer) compareDir(n *Node) error {
+	var leftEntries, rightEntries map[string]os.DirEntry
+	var err error
+	if n.Left != nil && n.Left.IsDir {
+		if leftEntries, err = c.readDir(c.left, n.Path); err != nil {
+			return err
+		}
+	}
+	if n.Right != nil && n.Right.IsDir {
+		if rightEntries, err = c.readDir(c.right, n.Path); err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(leftEntries)+len(rightEntries))
+	for name := range leftEntries {
+		names = append(names, name)
+	}
+	for name := range rightEntries {
+		if _, ok := leftEntries[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		child := &Node{Name: name, Path: path.Join(n.Path, name)}
+		if e, ok := leftEntries[name]; ok {
+			if child.Left, err = sideOf(e); err != nil {
+				return err
+			}
+		}
+		if e, ok := rightEntries[name]; ok {
+			if child.Right, err = sideOf(e); err != nil {
+				return err
+			}
+		}
+		if err = c.compareNode(child); err != nil {
+			return err
+		}
+		n.Children = append(n.Children, child)
+	}
+	slices.SortFunc(n.Children, func(a, b *Node) int {
+		if a.IsDir() != b.IsDir() {
+			if a.IsDir() {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if n.Status == "" {
+		n.Status = StatusSame
+		for _, child := range n.Children {
+			if child.Status != StatusSame {
+				n.Status, n.Reason = StatusDifferent, ReasonNested
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (c *comparer) compareNode(n *Node) error {
+	switch {
+	case n.Right == nil:
+		n.Status = StatusOnlyLeft
+	case n.Left == nil:
+		n.Status = StatusOnlyRight
+	case n.Left.IsDir != n.Right.IsDir:
+		n.Status, n.Reason = StatusDifferent, ReasonType
+	case n.Left.IsDir:
+		return c.compareDir(n)
+	default:
+		reason, err := c.compareFiles(n)
+		if err != nil {
+			return err
+		}
+		n.Status = StatusSame
+		if reason != "" {
+			n.Status, n.Reason = StatusDifferent, reason
+		}
+		return nil
+	}
+	if n.IsDir() {
+		return c.compareDir(n)
+	}
+	return nil
+}
+
+func (c *comparer) compareFiles(n *Node) (Reason, error) {
+	if n.Left.Size != n.Right.Size {
+		return ReasonSize, nil
+	}
+	if c.leftReader == nil {
+		if d := n.Left.ModTime.Sub(n.Right.ModTime); d > c.opts.ModTimeWindow || d < -c.opts.ModTimeWindow {
+			return ReasonModTime, nil
+		}
+		return "", nil
+	}
+	same, err := c.sameContent(n.Path)
+	if err != nil || same {
+		return "", err
+	}
+	return ReasonContent, nil
+}
+
+func (c *comparer) sameContent(rel string) (bool, error) {
+	l, err := c.leftReader.OpenReader(c.ctx, c.left.path(rel))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = l.Close()
+	}()
+	r, err := c.rightReader.OpenReader(c.ctx, c.right.path(rel))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	const chunkSize = 64 * 1024
+	lBuf, rBuf := make([]byte, chunkSize), make([]byte, chunkSize)
+	for {
+		if err = c.ctx.Err(); err != nil {
+			return false, err
+		}
+		ln, lErr := io.ReadFull(l, lBuf)
+		rn, rErr := io.ReadFull(r, rBuf)
+		if !bytes.Equal(lBuf[:ln], rBuf[:rn]) {
+			return false, nil
+		}
+		lEOF := errors.Is(lErr, io.EOF) || errors.Is(lErr, io.ErrUnexpectedEOF)
+		rEOF := errors.Is(rErr, io.EOF) || errors.Is(rErr, io.ErrUnexpectedEOF)
+		if lErr != nil && !lEOF {
+			return false, lErr
+		}
+		if rErr != nil && !rEOF {
+			return false, rErr
+		}
+		if lEOF || rEOF {
+			return lEOF == rEOF, nil
+		}
+	}
+}
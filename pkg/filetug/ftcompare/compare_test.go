@@ -0,0 +1,157 @@
+package ftcompare
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/stretchr/testify/assert"
+)
+
+var baseTime = time.Date(2022, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func writeFile(t *testing.T, root, name, content string, modTime time.Time) {
+	t.Helper()
+	filePath := filepath.Join(root, name)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+	assert.NoError(t, os.Chtimes(filePath, modTime, modTime))
+}
+
+func newTestTrees(t *testing.T) (left, right string) {
+	t.Helper()
+	root := t.TempDir()
+	left, right = filepath.Join(root, "left"), filepath.Join(root, "right")
+	writeFile(t, left, "same.txt", "same", baseTime)
+	writeFile(t, right, "same.txt", "same", baseTime.Add(time.Second))
+	writeFile(t, left, "size.txt", "longer", baseTime)
+	writeFile(t, right, "size.txt", "short", baseTime)
+	writeFile(t, left, "touched.txt", "aaa", baseTime.Add(time.Hour))
+	writeFile(t, right, "touched.txt", "aaa", baseTime)
+	writeFile(t, left, "content.txt", "abc", baseTime)
+	writeFile(t, right, "content.txt", "xyz", baseTime)
+	writeFile(t, left, "left-only/a.txt", "a", baseTime)
+	writeFile(t, right, "right-only.txt", "r", baseTime)
+	writeFile(t, left, "kind", "file", baseTime)
+	writeFile(t, right, "kind/inner.txt", "inner", baseTime)
+	writeFile(t, left, "sub/same.txt", "same", baseTime)
+	writeFile(t, right, "sub/same.txt", "same", baseTime)
+	assert.NoError(t, os.Symlink("same.txt", filepath.Join(left, "link.txt")))
+	return left, right
+}
+
+type nodeState struct {
+	Status Status
+	Reason Reason
+}
+
+func states(root *Node) map[string]nodeState {
+	result := make(map[string]nodeState)
+	root.Walk(func(n *Node) bool {
+		result[n.Path] = nodeState{n.Status, n.Reason}
+		return true
+	})
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	leftDir, rightDir := newTestTrees(t)
+	store := osfile.NewStore("/")
+	left, right := Endpoint{Store: store, Dir: leftDir}, Endpoint{Store: store, Dir: rightDir}
+
+	t.Run("size_mtime", func(t *testing.T) {
+		var dirs []string
+		root, err := Compare(ctx, left, right, Options{}, func(dir string) { dirs = append(dirs, dir) })
+		assert.NoError(t, err)
+		assert.Contains(t, dirs, filepath.Join(leftDir, "left-only"))
+		assert.Equal(t, map[string]nodeState{
+			".":               {StatusDifferent, ReasonNested},
+			"same.txt":        {StatusSame, ""},
+			"size.txt":        {StatusDifferent, ReasonSize},
+			"touched.txt":     {StatusDifferent, ReasonModTime},
+			"content.txt":     {StatusSame, ""},
+			"left-only":       {StatusOnlyLeft, ""},
+			"left-only/a.txt": {StatusOnlyLeft, ""},
+			"right-only.txt":  {StatusOnlyRight, ""},
+			"kind":            {StatusDifferent, ReasonType},
+			"kind/inner.txt":  {StatusOnlyRight, ""},
+			"sub":             {StatusSame, ""},
+			"sub/same.txt":    {StatusSame, ""},
+		}, states(root))
+		names := make([]string, len(root.Children))
+		for i, child := range root.Children {
+			names[i] = child.Name
+		}
+		assert.Equal(t, []string{"kind", "left-only", "sub", "content.txt", "right-only.txt", "same.txt", "size.txt", "touched.txt"}, names)
+		assert.Equal(t, "left", root.Name)
+		assert.Equal(t, int64(6), root.Find("size.txt").Left.Size)
+		assert.Equal(t, "a.txt", root.Find("left-only/a.txt").Name)
+		assert.Nil(t, root.Find("left-only/missing"))
+	})
+
+	t.Run("content", func(t *testing.T) {
+		root, err := Compare(ctx, left, right, Options{Content: true}, nil)
+		assert.NoError(t, err)
+		s := states(root)
+		assert.Equal(t, nodeState{StatusDifferent, ReasonContent}, s["content.txt"])
+		assert.Equal(t, nodeState{StatusSame, ""}, s["touched.txt"], "same content with another mtime")
+	})
+
+	t.Run("big_content", func(t *testing.T) {
+		dir := t.TempDir()
+		big := strings.Repeat("x", 100_000)
+		writeFile(t, dir, "l/a", big+"1", baseTime)
+		writeFile(t, dir, "r/a", big+"2", baseTime)
+		writeFile(t, dir, "l/b", big, baseTime)
+		writeFile(t, dir, "r/b", big, baseTime)
+		root, err := Compare(ctx, Endpoint{store, filepath.Join(dir, "l")}, Endpoint{store, filepath.Join(dir, "r")}, Options{Content: true}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusDifferent, root.Find("a").Status)
+		assert.Equal(t, StatusSame, root.Find("b").Status)
+	})
+}
+
+type failingReaderStore struct {
+	*osfile.Store
+	failOn string
+}
+
+func (s failingReaderStore) OpenReader(ctx context.Context, p string) (io.ReadCloser, error) {
+	if strings.HasSuffix(p, s.failOn) {
+		return nil, errors.New("open failed")
+	}
+	return s.Store.OpenReader(ctx, p)
+}
+
+func TestCompare_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	leftDir, rightDir := newTestTrees(t)
+	store := osfile.NewStore("/")
+	left, right := Endpoint{Store: store, Dir: leftDir}, Endpoint{Store: store, Dir: rightDir}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := Compare(cancelled, left, right, Options{}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = Compare(ctx, left, Endpoint{Store: store, Dir: filepath.Join(rightDir, "missing")}, Options{}, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = Compare(ctx, Endpoint{Store: store, Dir: filepath.Join(leftDir, "missing")}, right, Options{}, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	failing := failingReaderStore{Store: store, failOn: "content.txt"}
+	_, err = Compare(ctx, Endpoint{Store: failing, Dir: leftDir}, right, Options{Content: true}, nil)
+	assert.EqualError(t, err, "open failed")
+	_, err = Compare(ctx, left, Endpoint{Store: failing, Dir: rightDir}, Options{Content: true}, nil)
+	assert.EqualError(t, err, "open failed")
+}
@@ -0,0 +1,248 @@
+// Package ftdiff computes line diffs of 2 texts grouped into hunks with intra-line changes
+// so they can be rendered as unified or side-by-side views.
+package ftdiff
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DefaultContext is the default number of unchanged lines around changes.
+const DefaultContext = 3
+
+type Options struct {
+	IgnoreWhitespace bool // lines that differ by whitespace only are equal
+	Context          int  // lines of context around changes, DefaultContext if not positive
+}
+
+// Kind of a row in a diff.
+type Kind int
+
+const (
+	KindEqual  Kind = iota // both lines are the same
+	KindChange             // old line is replaced by new line
+	KindDelete             // old line only
+	KindInsert             // new line only
+)
+
+// Span is a byte range [Start, End) of a changed part of a line.
+type Span struct {
+	Start, End int
+}
+
+// Line is a line of one side. Num is 1-based & 0 if the line is absent.
+type Line struct {
+	Num     int
+	Text    string
+	Changes []Span // set for KindChange rows only
+}
+
+// Row pairs an old line with a new line as in a side-by-side view.
+type Row struct {
+	Kind Kind
+	Old  Line
+	New  Line
+}
+
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Rows               []Row
+}
+
+// Header returns a unified diff header of the hunk, e.g. "@@ -1,3 +1,4 @@".
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	if lines == 0 {
+		start-- // an empty range starts before the line where changes happen as in GNU diff
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+type Diff struct {
+	Hunks      []Hunk
+	Insertions int
+	Deletions  int
+}
+
+// IsEmpty returns true if there are no differences.
+func (d *Diff) IsEmpty() bool {
+	return len(d.Hunks) == 0
+}
+
+// SplitLines splits text into lines without trailing new line characters.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.HasSuffix(text, "\n") {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func normalizeWhitespace(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return result
+}
+
+// Compute returns differences between old & new texts.
+func Compute(oldText, newText string, opts Options) *Diff {
+	if opts.Context <= 0 {
+		opts.Context = DefaultContext
+	}
+	oldLines, newLines := SplitLines(oldText), SplitLines(newText)
+	a, b := oldLines, newLines
+	if opts.IgnoreWhitespace {
+		a, b = normalizeWhitespace(oldLines), normalizeWhitespace(newLines)
+	}
+	diff := &Diff{}
+	matcher := difflib.NewMatcher(a, b)
+	for _, group := range matcher.GetGroupedOpCodes(opts.Context) {
+		if len(group) == 1 && group[0].Tag == 'e' {
+			continue // identical texts
+		}
+		first, last := group[0], group[len(group)-1]
+		hunk := Hunk{
+			OldStart: first.I1 + 1, OldLines: last.I2 - first.I1,
+			NewStart: first.J1 + 1, NewLines: last.J2 - first.J1,
+		}
+		for _, op := range group {
+			hunk.Rows = append(hunk.Rows, opRows(op, oldLines, newLines)...)
+			switch op.Tag {
+			case 'r':
+				diff.Deletions += op.I2 - op.I1
+				diff.Insertions += op.J2 - op.J1
+			case 'd':
+				diff.Deletions += op.I2 - op.I1
+			case 'i':
+				diff.Insertions += op.J2 - op.J1
+			}
+		}
+		diff.Hunks = append(diff.Hunks, hunk)
+	}
+	return diff
+}
+
+func opRows(op difflib.OpCode, oldLines, newLines []string) []Row {
+	var rows []Row
+	oldLine := func(i int) Line {
+		return Line{Num: i + 1, Text: oldLines[i]}
+	}
+	newLine := func(j int) Line {
+		return Line{Num: j + 1, Text: newLines[j]}
+	}
+	switch op.Tag {
+	case 'e':
+		for k := 0; k < op.I2-op.I1; k++ {
+			rows = append(rows, Row{Kind: KindEqual, Old: oldLine(op.I1 + k), New: newLine(op.J1 + k)})
+		}
+	case 'd':
+		for i := op.I1; i < op.I2; i++ {
+			rows = append(rows, Row{Kind: KindDelete, Old: oldLine(i)})
+		}
+	case 'i':
+		for j := op.J1; j < op.J2; j++ {
+			rows = append(rows, Row{Kind: KindInsert, New: newLine(j)})
+		}
+	case 'r':
+		for k := 0; op.I1+k < op.I2 || op.J1+k < op.J2; k++ {
+			i, j := op.I1+k, op.J1+k
+			switch {
+			case j >= op.J2:
+				rows = append(rows, Row{Kind: KindDelete, Old: oldLine(i)})
+			case i >= op.I2:
+				rows = append(rows, Row{Kind: KindInsert, New: newLine(j)})
+			default:
+				row := Row{Kind: KindChange, Old: oldLine(i), New: newLine(j)}
+				row.Old.Changes, row.New.Changes = LineChanges(row.Old.Text, row.New.Text)
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+type tokenClass int
+
+const (
+	tokenWord tokenClass = iota
+	tokenSpace
+	tokenPunct
+)
+
+func classOf(r rune) tokenClass {
+	switch {
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return tokenWord
+	case unicode.IsSpace(r):
+		return tokenSpace
+	default:
+		return tokenPunct
+	}
+}
+
+// tokenize splits a line into runs of word characters & spaces, every other character is a separate token.
+func tokenize(line string) []string {
+	var tokens []string
+	start, prev := 0, tokenClass(-1)
+	for i, r := range line {
+		class := classOf(r)
+		if i > start && (class != prev || class == tokenPunct) {
+			tokens = append(tokens, line[start:i])
+			start = i
+		}
+		prev = class
+	}
+	if start < len(line) {
+		tokens = append(tokens, line[start:])
+	}
+	return tokens
+}
+
+// LineChanges returns changed parts of an old & a new versions of a line.
+func LineChanges(oldLine, newLine string) (oldChanges, newChanges []Span) {
+	a, b := tokenize(oldLine), tokenize(newLine)
+	offsets := func(tokens []string) []int {
+		result := make([]int, len(tokens)+1)
+		for i, token := range tokens {
+			result[i+1] = result[i] + len(token)
+		}
+		return result
+	}
+	aOffsets, bOffsets := offsets(a), offsets(b)
+	matcher := difflib.NewMatcher(a, b)
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+		if op.I2 > op.I1 {
+			oldChanges = appendSpan(oldChanges, Span{aOffsets[op.I1], aOffsets[op.I2]})
+		}
+		if op.J2 > op.J1 {
+			newChanges = appendSpan(newChanges, Span{bOffsets[op.J1], bOffsets[op.J2]})
+		}
+	}
+	return oldChanges, newChanges
+}
+
+func appendSpan(spans []Span, span Span) []Span {
+	if n := len(spans); n > 0 && spans[n-1].End == span.Start {
+		spans[n-1].End = span.End
+		return spans
+	}
+	return append(spans, span)
+}
@@ -0,0 +1,109 @@
+package ftdiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func numberedLines(from, to int) string {
+	var sb strings.Builder
+	for i := from; i <= to; i++ {
+		_, _ = fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	return sb.String()
+}
+
+func TestSplitLines(t *testing.T) {
+	assert.Nil(t, SplitLines(""))
+	assert.Equal(t, []string{"a", "b"}, SplitLines("a\r\nb\n"))
+	assert.Equal(t, []string{"a", ""}, SplitLines("a\n\n"))
+	assert.Equal(t, []string{"a"}, SplitLines("a"))
+}
+
+func TestCompute(t *testing.T) {
+	t.Run("identical", func(t *testing.T) {
+		assert.True(t, Compute("a\nb\n", "a\nb\n", Options{}).IsEmpty())
+		assert.True(t, Compute("", "", Options{}).IsEmpty())
+	})
+
+	t.Run("hunks", func(t *testing.T) {
+		oldText := numberedLines(1, 20)
+		newText := strings.Replace(oldText, "line 2\n", "line two\n", 1)
+		newText = strings.Replace(newText, "line 15\n", "", 1)
+		newText += "line 21\nline 22\n"
+		d := Compute(oldText, newText, Options{})
+		assert.Equal(t, 3, d.Insertions)
+		assert.Equal(t, 2, d.Deletions)
+		if !assert.Len(t, d.Hunks, 2) {
+			return
+		}
+		h := d.Hunks[0]
+		assert.Equal(t, "@@ -1,5 +1,5 @@", h.Header())
+		assert.Equal(t, KindChange, h.Rows[1].Kind)
+		assert.Equal(t, Line{Num: 2, Text: "line 2", Changes: []Span{{5, 6}}}, h.Rows[1].Old)
+		assert.Equal(t, Line{Num: 2, Text: "line two", Changes: []Span{{5, 8}}}, h.Rows[1].New)
+
+		h = d.Hunks[1]
+		assert.Equal(t, "@@ -12,9 +12,10 @@", h.Header())
+		assert.Equal(t, Row{Kind: KindDelete, Old: Line{Num: 15, Text: "line 15"}}, h.Rows[3])
+		last := h.Rows[len(h.Rows)-1]
+		assert.Equal(t, Row{Kind: KindInsert, New: Line{Num: 21, Text: "line 22"}}, last)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		d := Compute(numberedLines(1, 9), strings.Replace(numberedLines(1, 9), "line 5", "line five", 1), Options{Context: 1})
+		if assert.Len(t, d.Hunks, 1) {
+			assert.Equal(t, "@@ -4,3 +4,3 @@", d.Hunks[0].Header())
+		}
+	})
+
+	t.Run("replace_unequal_blocks", func(t *testing.T) {
+		d := Compute("a\nb\nc\n", "x\ny\n", Options{})
+		if assert.Len(t, d.Hunks, 1) {
+			rows := d.Hunks[0].Rows
+			assert.Equal(t, []Kind{KindChange, KindChange, KindDelete}, []Kind{rows[0].Kind, rows[1].Kind, rows[2].Kind})
+		}
+		d = Compute("a\n", "x\ny\n", Options{})
+		assert.Equal(t, KindInsert, d.Hunks[0].Rows[1].Kind)
+	})
+
+	t.Run("added_to_empty", func(t *testing.T) {
+		d := Compute("", "a\n", Options{})
+		if assert.Len(t, d.Hunks, 1) {
+			assert.Equal(t, "@@ -0,0 +1 @@", d.Hunks[0].Header())
+		}
+	})
+
+	t.Run("ignore_whitespace", func(t *testing.T) {
+		oldText, newText := "if a {\n\treturn b\n}\n", "if  a {\n    return b \n}\nx\n"
+		assert.Len(t, Compute(oldText, newText, Options{}).Hunks, 1)
+		d := Compute(oldText, newText, Options{IgnoreWhitespace: true})
+		if assert.Len(t, d.Hunks, 1) {
+			assert.Equal(t, 1, d.Insertions)
+			assert.Equal(t, 0, d.Deletions)
+			assert.Equal(t, "    return b ", d.Hunks[0].Rows[1].New.Text, "original text is kept")
+		}
+	})
+}
+
+func TestLineChanges(t *testing.T) {
+	oldChanges, newChanges := LineChanges("foo(a, b)", "foo(a, c, b)")
+	assert.Nil(t, oldChanges)
+	assert.Equal(t, []Span{{7, 10}}, newChanges) // "c, "
+
+	oldChanges, newChanges = LineChanges("héllo world", "héllo there")
+	assert.Equal(t, []Span{{7, 12}}, oldChanges)
+	assert.Equal(t, []Span{{7, 12}}, newChanges)
+
+	oldChanges, newChanges = LineChanges("", "x")
+	assert.Nil(t, oldChanges)
+	assert.Equal(t, []Span{{0, 1}}, newChanges)
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"a_1", "  ", "(", "(", "b", ")"}, tokenize("a_1  ((b)"))
+	assert.Nil(t, tokenize(""))
+}
@@ -0,0 +1,295 @@
+// Package ftdupes finds duplicate files in a tree of any files.Store.
+// Candidates are narrowed by size, then by a hash of the first bytes, and confirmed by a full SHA-256.
+package ftdupes
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+)
+
+// partialSize is how many leading bytes are hashed to cheaply rule out files of the same size.
+const partialSize = 4 * 1024
+
+var ErrFileReadNotSupported = fmt.Errorf("reading files: %w", files.ErrNotSupported)
+
+type Phase string
+
+const (
+	PhaseScan    Phase = "scan"
+	PhasePartial Phase = "partial hash"
+	PhaseFull    Phase = "full hash"
+)
+
+type Options struct {
+	Include *masks.Mask // if set, only files with names matching the mask are considered
+	Exclude *masks.Mask // files with names matching the mask are ignored
+	MinSize int64       // smaller files are ignored, empty files are always ignored
+}
+
+type Progress struct {
+	Phase      Phase
+	Total      int // files to process in the current phase, 0 while scanning
+	Done       int
+	BytesTotal int64
+	BytesDone  int64
+	Current    string
+}
+
+type File struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Group is a set of files with identical content.
+type Group struct {
+	Size  int64
+	Hash  string // hex SHA-256 of the content
+	Files []File // sorted by path
+}
+
+// Wasted returns the number of bytes taken by redundant copies.
+func (g Group) Wasted() int64 {
+	return g.Size * int64(len(g.Files)-1)
+}
+
+// Keep is a strategy to pick the file that is kept from a group of duplicates.
+type Keep string
+
+const (
+	KeepNewest       Keep = "newest"
+	KeepOldest       Keep = "oldest"
+	KeepShortestPath Keep = "shortest path"
+)
+
+// Kept returns the file a strategy keeps. Ties are resolved by path for stable results.
+func (g Group) Kept(keep Keep) File {
+	return slices.MinFunc(g.Files, func(a, b File) int {
+		var c int
+		switch keep {
+		case KeepNewest:
+			c = b.ModTime.Compare(a.ModTime)
+		case KeepOldest:
+			c = a.ModTime.Compare(b.ModTime)
+		case KeepShortestPath:
+			c = cmp.Compare(len(a.Path), len(b.Path))
+		}
+		if c == 0 {
+			c = cmp.Compare(a.Path, b.Path)
+		}
+		return c
+	})
+}
+
+// Find walks dir recursively & returns groups of duplicates sorted by wasted space, the biggest first.
+// Links are not followed.
+func Find(ctx context.Context, store files.Store, dir string, opts Options, report func(p Progress)) ([]Group, error) {
+	reader, ok := store.(files.FileReader)
+	if !ok {
+		return nil, ErrFileReadNotSupported
+	}
+	f := finder{ctx: ctx, root: dir, store: store, reader: reader, opts: opts, report: report}
+	f.progress.Phase = PhaseScan
+	if err := f.scan(dir); err != nil {
+		return nil, err
+	}
+	candidates := groupBy(f.files, func(file File) int64 { return file.Size })
+	// The partial hash of small files is the full one, so they skip the partial phase.
+	var small, big [][]File
+	for _, c := range candidates {
+		if c[0].Size <= partialSize {
+			small = append(small, c)
+		} else {
+			big = append(big, c)
+		}
+	}
+	big, err := f.hashPhase(PhasePartial, big, partialSize)
+	if err != nil {
+		return nil, err
+	}
+	confirmed, err := f.hashPhase(PhaseFull, append(small, big...), -1)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]Group, 0, len(confirmed))
+	for _, c := range confirmed {
+		g := Group{Size: c[0].Size, Hash: f.hashes[c[0].Path], Files: c}
+		slices.SortFunc(g.Files, func(a, b File) int { return cmp.Compare(a.Path, b.Path) })
+		groups = append(groups, g)
+	}
+	slices.SortFunc(groups, func(a, b Group) int {
+		if c := cmp.Compare(b.Wasted(), a.Wasted()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Files[0].Path, b.Files[0].Path)
+	})
+	return groups, nil
+}
+
+type finder struct {
+	ctx      context.Context
+	root     string // dir being searched, paths matched by masks are relative to it
+	store    files.Store
+	reader   files.FileReader
+	opts     Options
+	report   func(p Progress)
+	progress Progress
+	files    []File
+	hashes   map[string]string // path => hash of the last phase
+}
+
+func (f *finder) reportProgress() {
+	if f.report != nil {
+		f.report(f.progress)
+	}
+}
+
+func (f *finder) scan(dir string) error {
+	if err := f.ctx.Err(); err != nil {
+		return err
+	}
+	f.progress.Current = dir
+	f.reportProgress()
+	children, err := f.store.ReadDir(f.ctx, dir)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		childPath := path.Join(dir, child.Name())
+		if child.IsDir() {
+			if err = f.scan(childPath); err != nil {
+				return err
+			}
+			continue
+		}
+		info, err := child.Info()
+		if err != nil {
+			continue // removed since listed
+		}
+		if !info.Mode().IsRegular() || info.Size() == 0 || info.Size() < f.opts.MinSize {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(childPath, f.root), "/")
+		if matched, err := f.match(masks.Entry{DirEntry: child, RelPath: rel}, info); err != nil {
+			return err
+		} else if !matched {
+			continue
+		}
+		f.files = append(f.files, File{Path: childPath, Size: info.Size(), ModTime: info.ModTime()})
+		f.progress.Done++
+	}
+	return nil
+}
+
+func (f *finder) match(entry masks.Entry, info os.FileInfo) (bool, error) {
+	if f.opts.Exclude != nil {
+		excluded, err := f.opts.Exclude.Match(entry, info)
+		if err != nil || excluded {
+			return false, err
+		}
+	}
+	if f.opts.Include != nil {
+		return f.opts.Include.Match(entry, info)
+	}
+	return true, nil
+}
+
+// hashPhase hashes files of each candidate group & splits them by hash. Groups of a single file are dropped.
+// A limit < 0 hashes whole files.
+func (f *finder) hashPhase(phase Phase, candidates [][]File, limit int64) ([][]File, error) {
+	f.progress = Progress{Phase: phase}
+	for _, c := range candidates {
+		f.progress.Total += len(c)
+		if limit < 0 {
+			f.progress.BytesTotal += c[0].Size * int64(len(c))
+		} else {
+			f.progress.BytesTotal += min(limit, c[0].Size) * int64(len(c))
+		}
+	}
+	f.reportProgress()
+	f.hashes = make(map[string]string, f.progress.Total)
+	var result [][]File
+	for _, c := range candidates {
+		for _, file := range c {
+			hash, err := f.hash(file.Path, limit)
+			if errors.Is(err, os.ErrNotExist) {
+				continue // removed since scanned
+			}
+			if err != nil {
+				return nil, err
+			}
+			f.hashes[file.Path] = hash
+			f.progress.Done++
+			f.reportProgress()
+		}
+		result = append(result, groupBy(c, func(file File) string { return f.hashes[file.Path] })...)
+	}
+	return result, nil
+}
+
+func (f *finder) hash(filePath string, limit int64) (string, error) {
+	f.progress.Current = filePath
+	r, err := f.reader.OpenReader(f.ctx, filePath)
+	if err != nil {
+		return "", err
+	}
+	var src io.Reader = r
+	if limit >= 0 {
+		src = io.LimitReader(r, limit)
+	}
+	h := sha256.New()
+	buf := make([]byte, 64*1024)
+	for {
+		if err = f.ctx.Err(); err != nil {
+			break
+		}
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			f.progress.BytesDone += int64(n)
+		}
+		if readErr != nil {
+			if readErr != io.EOF {
+				err = readErr
+			}
+			break
+		}
+	}
+	if err = errors.Join(err, r.Close()); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// groupBy splits files by a key keeping only groups of 2 or more files in order of first appearance.
+func groupBy[K comparable](items []File, key func(File) K) [][]File {
+	var keys []K
+	byKey := make(map[K][]File)
+	for _, item := range items {
+		k := key(item)
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], item)
+	}
+	var groups [][]File
+	for _, k := range keys {
+		if g := byKey[k]; len(g) > 1 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,270 @@
+package ftdupes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTree creates a tree with 2 sets of duplicates:
+//
+//	root/a.txt      = small
+//	root/sub/a.txt  = small
+//	root/b.bin      = big (same first bytes as c.bin & d.bin)
+//	root/sub/d.bin  = big
+//	root/c.bin      = big with a different tail
+//	root/e.txt      = other small of the same size as a.txt
+//	root/empty.txt  = empty
+func newTestTree(t *testing.T) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "root")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0o755))
+	big := bytes.Repeat([]byte("0123456789"), partialSize)
+	otherBig := append(bytes.Clone(big[:len(big)-1]), 'x')
+	write := func(name string, data []byte) {
+		assert.NoError(t, os.WriteFile(filepath.Join(root, name), data, 0o644))
+	}
+	write("a.txt", []byte("small"))
+	write("sub/a.txt", []byte("small"))
+	write("e.txt", []byte("other"))
+	write("b.bin", big)
+	write("sub/d.bin", big)
+	write("c.bin", otherBig)
+	write("empty.txt", nil)
+	write("sub/empty.txt", nil)
+	assert.NoError(t, os.Symlink("a.txt", filepath.Join(root, "link.txt")))
+	return root
+}
+
+func groupPaths(root string, groups []Group) [][]string {
+	result := make([][]string, len(groups))
+	for i, g := range groups {
+		for _, f := range g.Files {
+			rel, _ := filepath.Rel(root, f.Path)
+			result[i] = append(result[i], rel)
+		}
+	}
+	return result
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	root := newTestTree(t)
+	store := osfile.NewStore("/")
+
+	t.Run("all", func(t *testing.T) {
+		var phases []Phase
+		var last Progress
+		groups, err := Find(ctx, store, root, Options{}, func(p Progress) {
+			if len(phases) == 0 || phases[len(phases)-1] != p.Phase {
+				phases = append(phases, p.Phase)
+			}
+			last = p
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"b.bin", "sub/d.bin"}, {"a.txt", "sub/a.txt"}}, groupPaths(root, groups))
+		assert.Equal(t, []Phase{PhaseScan, PhasePartial, PhaseFull}, phases)
+		assert.Equal(t, last.Total, last.Done)
+		assert.Equal(t, last.BytesTotal, last.BytesDone)
+		assert.Equal(t, int64(10*partialSize), groups[0].Size)
+		assert.Equal(t, int64(10*partialSize), groups[0].Wasted())
+		assert.Len(t, groups[0].Hash, 64)
+	})
+
+	t.Run("masks", func(t *testing.T) {
+		include := &masks.Mask{Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `\.txt$`}}}
+		groups, err := Find(ctx, store, root, Options{Include: include}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"a.txt", "sub/a.txt"}}, groupPaths(root, groups))
+
+		exclude := &masks.Mask{Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `\.txt$`}}}
+		groups, err = Find(ctx, store, root, Options{Exclude: exclude}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"b.bin", "sub/d.bin"}}, groupPaths(root, groups))
+
+		invalid := &masks.Mask{Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `(`}}}
+		_, err = Find(ctx, store, root, Options{Include: invalid}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("min_size", func(t *testing.T) {
+		groups, err := Find(ctx, store, root, Options{MinSize: 100}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"b.bin", "sub/d.bin"}}, groupPaths(root, groups))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := Find(cancelled, store, root, Options{}, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("cancelled_while_hashing", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		_, err := Find(cancelCtx, store, root, Options{}, func(p Progress) {
+			if p.Phase == PhasePartial {
+				cancel()
+			}
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("missing_dir", func(t *testing.T) {
+		_, err := Find(ctx, store, filepath.Join(root, "missing"), Options{}, nil)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+// noReaderStore is a store without files.FileReader.
+type noReaderStore struct {
+	files.Store
+}
+
+func (noReaderStore) RootURL() url.URL {
+	return url.URL{Scheme: "mem"}
+}
+
+// failingStore fails to open or read some files.
+type failingStore struct {
+	*osfile.Store
+	openErr map[string]error
+	readErr error
+}
+
+type failingReader struct {
+	io.ReadCloser
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func (s failingStore) OpenReader(ctx context.Context, p string) (io.ReadCloser, error) {
+	if err := s.openErr[filepath.Base(p)]; err != nil {
+		return nil, err
+	}
+	r, err := s.Store.OpenReader(ctx, p)
+	if err == nil && s.readErr != nil {
+		return failingReader{ReadCloser: r, err: s.readErr}, nil
+	}
+	return r, err
+}
+
+func TestFind_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	root := newTestTree(t)
+
+	_, err := Find(ctx, noReaderStore{}, "/", Options{}, nil)
+	assert.ErrorIs(t, err, ErrFileReadNotSupported)
+
+	store := failingStore{Store: osfile.NewStore("/"), openErr: map[string]error{"d.bin": os.ErrNotExist}}
+	groups, err := Find(ctx, store, root, Options{}, nil)
+	assert.NoError(t, err, "files removed while hashing are ignored")
+	assert.Equal(t, [][]string{{"a.txt", "sub/a.txt"}}, groupPaths(root, groups))
+
+	store = failingStore{Store: osfile.NewStore("/"), openErr: map[string]error{"a.txt": os.ErrPermission}}
+	_, err = Find(ctx, store, root, Options{}, nil)
+	assert.ErrorIs(t, err, os.ErrPermission)
+
+	store = failingStore{Store: osfile.NewStore("/"), readErr: errors.New("read failed")}
+	_, err = Find(ctx, store, root, Options{}, nil)
+	assert.EqualError(t, err, "read failed")
+}
+
+func TestGroup_Kept(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	g := Group{Size: 1, Files: []File{
+		{Path: "/a/long/path.txt", ModTime: now},
+		{Path: "/b.txt", ModTime: now.Add(-time.Hour)},
+		{Path: "/c/x.txt", ModTime: now.Add(time.Hour)},
+		{Path: "/a.txt", ModTime: now.Add(time.Hour)},
+	}}
+	assert.Equal(t, "/a.txt", g.Kept(KeepNewest).Path, "ties are resolved by path")
+	assert.Equal(t, "/b.txt", g.Kept(KeepOldest).Path)
+	assert.Equal(t, "/a.txt", g.Kept(KeepShortestPath).Path)
+	assert.Equal(t, int64(3), g.Wasted())
+}
+
+func TestReplaceWithLink(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := osfile.NewStore("/")
+	kept := filepath.Join(dir, "kept.txt")
+	assert.NoError(t, os.WriteFile(kept, []byte("x"), 0o644))
+	newDuplicate := func(name string) string {
+		p := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(p, []byte("x"), 0o644))
+		return p
+	}
+
+	hard := newDuplicate("hard.txt")
+	assert.NoError(t, ReplaceWithLink(ctx, store, HardLink, kept, hard))
+	keptInfo, _ := os.Stat(kept)
+	hardInfo, _ := os.Stat(hard)
+	assert.True(t, os.SameFile(keptInfo, hardInfo))
+
+	soft := newDuplicate("soft.txt")
+	assert.NoError(t, ReplaceWithLink(ctx, store, SymLink, kept, soft))
+	target, err := os.Readlink(soft)
+	assert.NoError(t, err)
+	assert.Equal(t, kept, target)
+
+	assert.EqualError(t, ReplaceWithLink(ctx, store, "x", kept, soft), `unknown link kind: "x"`)
+	assert.ErrorIs(t, ReplaceWithLink(ctx, noReaderStore{}, HardLink, kept, soft), ErrLinkNotSupported)
+
+	// A failed rename leaves the duplicate untouched & removes the temp link.
+	intoDir := filepath.Join(dir, "dir")
+	assert.NoError(t, os.MkdirAll(filepath.Join(intoDir, "child"), 0o755))
+	assert.Error(t, ReplaceWithLink(ctx, store, SymLink, kept, intoDir))
+	_, err = os.Lstat(intoDir + tempSuffix)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.ErrorIs(t, ReplaceWithLink(ctx, store, HardLink, filepath.Join(dir, "missing"), hard), os.ErrNotExist)
+}
+
+func TestReplaceWithCopy(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := osfile.NewStore("/")
+	kept := filepath.Join(dir, "kept.txt")
+	assert.NoError(t, os.WriteFile(kept, []byte("x"), 0o644))
+	link := filepath.Join(dir, "link.txt")
+	assert.NoError(t, os.Link(kept, link))
+
+	assert.NoError(t, ReplaceWithCopy(ctx, store, kept, link))
+	keptInfo, _ := os.Stat(kept)
+	linkInfo, _ := os.Stat(link)
+	assert.False(t, os.SameFile(keptInfo, linkInfo))
+	data, err := os.ReadFile(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", string(data))
+
+	assert.ErrorIs(t, ReplaceWithCopy(ctx, noReaderStore{}, kept, link), ErrCopyNotSupported)
+	assert.ErrorIs(t, ReplaceWithCopy(ctx, store, filepath.Join(dir, "missing"), link), os.ErrNotExist)
+
+	// A failed rename leaves the link untouched & removes the temp copy.
+	intoDir := filepath.Join(dir, "dir")
+	assert.NoError(t, os.MkdirAll(filepath.Join(intoDir, "child"), 0o755))
+	assert.Error(t, ReplaceWithCopy(ctx, store, kept, intoDir))
+	_, err = os.Lstat(intoDir + tempSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,75 @@
+package ftdupes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/filetug/filetug/pkg/files"
+)
+
+var (
+	ErrLinkNotSupported = fmt.Errorf("linking files: %w", files.ErrNotSupported)
+	ErrCopyNotSupported = fmt.Errorf("copying files: %w", files.ErrNotSupported)
+)
+
+type LinkKind string
+
+const (
+	HardLink LinkKind = "hard link"
+	SymLink  LinkKind = "symlink"
+)
+
+// tempSuffix names a link before it replaces a duplicate, so the duplicate is kept if linking fails.
+const tempSuffix = ".ftdupes-tmp"
+
+// ReplaceWithLink replaces the duplicate file with a link to the kept one.
+// The link is created next to the duplicate & renamed over it.
+func ReplaceWithLink(ctx context.Context, store files.Store, kind LinkKind, kept, duplicate string) error {
+	linker, ok := store.(files.Linker)
+	if !ok {
+		return ErrLinkNotSupported
+	}
+	renamer, ok := store.(files.Renamer)
+	if !ok {
+		return ErrLinkNotSupported
+	}
+	tempPath := duplicate + tempSuffix
+	var err error
+	switch kind {
+	case HardLink:
+		err = linker.Link(ctx, kept, tempPath)
+	case SymLink:
+		err = linker.Symlink(ctx, kept, tempPath)
+	default:
+		return fmt.Errorf("unknown link kind: %q", kind)
+	}
+	if err != nil {
+		return err
+	}
+	if err = renamer.Rename(ctx, tempPath, duplicate); err != nil {
+		return errors.Join(err, store.Delete(context.Background(), tempPath))
+	}
+	return nil
+}
+
+// ReplaceWithCopy reverts ReplaceWithLink, the link is replaced with a copy of the kept file
+// that is made next to it & renamed over it.
+func ReplaceWithCopy(ctx context.Context, store files.Store, kept, link string) error {
+	copier, ok := store.(files.Copier)
+	if !ok {
+		return ErrCopyNotSupported
+	}
+	renamer, ok := store.(files.Renamer)
+	if !ok {
+		return ErrCopyNotSupported
+	}
+	tempPath := link + tempSuffix
+	if err := copier.Copy(ctx, kept, tempPath); err != nil {
+		return err
+	}
+	if err := renamer.Rename(ctx, tempPath, link); err != nil {
+		return errors.Join(err, store.Delete(context.Background(), tempPath))
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+// Package ftenc encrypts & decrypts streams with a passphrase into the .ftenc format.
+//
+// A key is derived from the passphrase with scrypt & content is encrypted with AES-256-GCM
+// in chunks, so files of any size are processed in constant memory. Every chunk is authenticated
+// with the header & its position, the last chunk is marked, so reordered, truncated or extended
+// files are detected on decryption.
+//
+// Format:
+//
+//	magic "FTENC" | version 1 | scrypt logN, r, p (1 byte each) | salt (16) | key check (16) |
+//	nonce prefix (7) | chunk size (uint32 big endian) | chunks of ciphertext with a 16 bytes tag
+package ftenc
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Extension of encrypted files.
+const Extension = ".ftenc"
+
+const (
+	magic           = "FTENC"
+	version         = 1
+	saltSize        = 16
+	keyCheckSize    = 16
+	noncePrefixSize = 7
+	headerSize      = len(magic) + 1 + 3 + saltSize + keyCheckSize + noncePrefixSize + 4
+	keySize         = 32
+	tagSize         = 16
+	maxChunkSize    = 16 * 1024 * 1024
+	// Limits of scrypt parameters read from a header, an untrusted file must not make key derivation
+	// use gigabytes of memory or run for hours.
+	maxLogN   = 20
+	maxR      = 32
+	maxP      = 16
+	maxRP     = 64
+	maxMemory = 1 << 30 // scrypt needs 128*R*2^LogN bytes
+	// DefaultChunkSize is the size of plaintext encrypted in one chunk.
+	DefaultChunkSize = 64 * 1024
+)
+
+var (
+	ErrNotEncrypted       = errors.New("not an encrypted file")
+	ErrUnsupportedVersion = errors.New("unsupported encryption format version")
+	ErrWrongPassphrase    = errors.New("wrong passphrase")
+	// ErrCorrupted is returned when content fails authentication, e.g. it's modified or truncated.
+	ErrCorrupted     = errors.New("encrypted content is corrupted or modified")
+	ErrNoPassphrase  = errors.New("passphrase is empty")
+	errInvalidHeader = errors.New("invalid header")
+)
+
+// Params are scrypt cost parameters stored in the header of an encrypted stream.
+type Params struct {
+	LogN uint8 // CPU/memory cost is 2^LogN
+	R    uint8
+	P    uint8
+}
+
+// withinLimits reports whether params are safe to be used to decrypt an untrusted file.
+func (p Params) withinLimits() bool {
+	if p.LogN == 0 || p.LogN > maxLogN || p.R == 0 || p.R > maxR || p.P == 0 || p.P > maxP {
+		return false
+	}
+	return int(p.R)*int(p.P) <= maxRP && 128*int64(p.R)<<p.LogN <= maxMemory
+}
+
+// DefaultParams are the recommended interactive scrypt parameters: about 32 MB of memory.
+var DefaultParams = Params{LogN: 15, R: 8, P: 1}
+
+type header struct {
+	params      Params
+	salt        [saltSize]byte
+	keyCheck    [keyCheckSize]byte
+	noncePrefix [noncePrefixSize]byte
+	chunkSize   uint32
+}
+
+func (h *header) marshal() []byte {
+	b := make([]byte, 0, headerSize)
+	b = append(b, magic...)
+	b = append(b, version, h.params.LogN, h.params.R, h.params.P)
+	b = append(b, h.salt[:]...)
+	b = append(b, h.keyCheck[:]...)
+	b = append(b, h.noncePrefix[:]...)
+	return binary.BigEndian.AppendUint32(b, h.chunkSize)
+}
+
+func readHeader(r io.Reader) (h header, raw []byte, err error) {
+	raw = make([]byte, headerSize)
+	if _, err = io.ReadFull(r, raw); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = ErrNotEncrypted
+		}
+		return h, nil, err
+	}
+	if string(raw[:len(magic)]) != magic {
+		return h, nil, ErrNotEncrypted
+	}
+	b := raw[len(magic):]
+	if b[0] != version {
+		return h, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, b[0])
+	}
+	h.params = Params{LogN: b[1], R: b[2], P: b[3]}
+	b = b[4:]
+	b = b[copy(h.salt[:], b):]
+	b = b[copy(h.keyCheck[:], b):]
+	b = b[copy(h.noncePrefix[:], b):]
+	h.chunkSize = binary.BigEndian.Uint32(b)
+	if h.chunkSize == 0 || h.chunkSize > maxChunkSize || !h.params.withinLimits() {
+		return h, nil, fmt.Errorf("%w: %w", ErrNotEncrypted, errInvalidHeader)
+	}
+	return h, raw, nil
+}
+
+// deriveKey returns an encryption key & a value to check the passphrase before decryption.
+func deriveKey(passphrase []byte, h *header) (key, keyCheck []byte, err error) {
+	if len(passphrase) == 0 {
+		return nil, nil, ErrNoPassphrase
+	}
+	derived, err := scrypt.Key(passphrase, h.salt[:], 1<<h.params.LogN, int(h.params.R), int(h.params.P), keySize+keyCheckSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return derived[:keySize], derived[keySize:], nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce is the prefix, a chunk counter & a flag of the last chunk.
+func nonce(prefix [noncePrefixSize]byte, counter uint32, last bool) []byte {
+	n := make([]byte, 0, noncePrefixSize+5)
+	n = append(n, prefix[:]...)
+	n = binary.BigEndian.AppendUint32(n, counter)
+	if last {
+		return append(n, 1)
+	}
+	return append(n, 0)
+}
+
+// Encrypt reads plaintext from src & writes the encrypted stream to dst.
+func Encrypt(ctx context.Context, dst io.Writer, src io.Reader, passphrase []byte, params Params) error {
+	if !params.withinLimits() {
+		// Such a stream would be refused by Decrypt.
+		return fmt.Errorf("scrypt parameters exceed limits: %+v", params)
+	}
+	h := header{params: params, chunkSize: DefaultChunkSize}
+	if _, err := rand.Read(h.salt[:]); err != nil {
+		return err
+	}
+	if _, err := rand.Read(h.noncePrefix[:]); err != nil {
+		return err
+	}
+	key, keyCheck, err := deriveKey(passphrase, &h)
+	if err != nil {
+		return err
+	}
+	copy(h.keyCheck[:], keyCheck)
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	raw := h.marshal()
+	if _, err = dst.Write(raw); err != nil {
+		return err
+	}
+	// A chunk is written once the next one is started, so the last one is known when src ends.
+	br := bufio.NewReaderSize(src, int(h.chunkSize)+1)
+	plaintext := make([]byte, h.chunkSize)
+	ciphertext := make([]byte, 0, int(h.chunkSize)+tagSize)
+	for counter := uint32(0); ; counter++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		n, readErr := io.ReadFull(br, plaintext)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return readErr
+		}
+		last := readErr != nil
+		if !last {
+			if _, peekErr := br.Peek(1); peekErr != nil {
+				if !errors.Is(peekErr, io.EOF) {
+					return peekErr
+				}
+				last = true
+			}
+		}
+		ciphertext = aead.Seal(ciphertext[:0], nonce(h.noncePrefix, counter, last), plaintext[:n], raw)
+		if _, err = dst.Write(ciphertext); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		if counter == ^uint32(0) {
+			return errors.New("content is too large")
+		}
+	}
+}
+
+// Decrypt reads an encrypted stream from src & writes plaintext to dst.
+// Content is written chunk by chunk after it's authenticated, so if ErrCorrupted is returned,
+// dst has a part of plaintext that must be discarded. Use io.Discard as dst to verify integrity only.
+func Decrypt(ctx context.Context, dst io.Writer, src io.Reader, passphrase []byte) error {
+	h, raw, err := readHeader(src)
+	if err != nil {
+		return err
+	}
+	key, keyCheck, err := deriveKey(passphrase, &h)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(keyCheck, h.keyCheck[:]) != 1 {
+		return ErrWrongPassphrase
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReaderSize(src, int(h.chunkSize)+tagSize+1)
+	ciphertext := make([]byte, int(h.chunkSize)+tagSize)
+	plaintext := make([]byte, 0, h.chunkSize)
+	for counter := uint32(0); ; counter++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		n, readErr := io.ReadFull(br, ciphertext)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return readErr
+		}
+		last := readErr != nil
+		if !last {
+			if _, peekErr := br.Peek(1); peekErr != nil {
+				if !errors.Is(peekErr, io.EOF) {
+					return peekErr
+				}
+				last = true
+			}
+		}
+		plaintext, err = aead.Open(plaintext[:0], nonce(h.noncePrefix, counter, last), ciphertext[:n], raw)
+		if err != nil {
+			return ErrCorrupted
+		}
+		if _, err = dst.Write(plaintext); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
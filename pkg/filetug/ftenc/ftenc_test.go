@@ -0,0 +1,170 @@
+package ftenc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testParams are cheap to keep tests fast.
+var testParams = Params{LogN: 10, R: 8, P: 1}
+
+var passphrase = []byte("correct horse battery staple")
+
+func encrypt(t *testing.T, plaintext []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	assert.NoError(t, Encrypt(context.Background(), &buf, bytes.NewReader(plaintext), passphrase, testParams))
+	return buf.Bytes()
+}
+
+func decrypt(encrypted []byte, passphrase []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	err := Decrypt(context.Background(), &buf, bytes.NewReader(encrypted), passphrase)
+	return buf.Bytes(), err
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	for _, size := range []int{0, 1, DefaultChunkSize - 1, DefaultChunkSize, DefaultChunkSize + 1, 3 * DefaultChunkSize} {
+		plaintext := make([]byte, size)
+		_, _ = rand.Read(plaintext)
+		encrypted := encrypt(t, plaintext)
+		chunks := size/DefaultChunkSize + 1
+		if size > 0 && size%DefaultChunkSize == 0 {
+			chunks--
+		}
+		assert.Equal(t, headerSize+size+chunks*tagSize, len(encrypted), "size %d", size)
+		decrypted, err := decrypt(encrypted, passphrase)
+		assert.NoError(t, err, "size %d", size)
+		assert.True(t, bytes.Equal(plaintext, decrypted), "size %d", size)
+	}
+	a, b := encrypt(t, []byte("x")), encrypt(t, []byte("x"))
+	assert.NotEqual(t, a, b, "random salt & nonce")
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("0123456789abcdef"), DefaultChunkSize/8) // 2 chunks
+	encrypted := encrypt(t, plaintext)
+	modified := func(f func(b []byte) []byte) []byte {
+		return f(bytes.Clone(encrypted))
+	}
+
+	for name, tt := range map[string]struct {
+		data       []byte
+		passphrase []byte
+		err        error
+	}{
+		"wrong_passphrase": {data: encrypted, passphrase: []byte("wrong"), err: ErrWrongPassphrase},
+		"empty_passphrase": {data: encrypted, err: ErrNoPassphrase},
+		"not_encrypted":    {data: plaintext, passphrase: passphrase, err: ErrNotEncrypted},
+		"short":            {data: encrypted[:10], passphrase: passphrase, err: ErrNotEncrypted},
+		"version": {data: modified(func(b []byte) []byte {
+			b[len(magic)] = 2
+			return b
+		}), passphrase: passphrase, err: ErrUnsupportedVersion},
+		"chunk_size": {data: modified(func(b []byte) []byte {
+			copy(b[headerSize-4:], []byte{0, 0, 0, 0})
+			return b
+		}), passphrase: passphrase, err: ErrNotEncrypted},
+		"scrypt_params": {data: modified(func(b []byte) []byte {
+			b[len(magic)+3] = 0 // p
+			return b
+		}), passphrase: passphrase, err: ErrNotEncrypted},
+		"scrypt_log_n": {data: modified(func(b []byte) []byte {
+			b[len(magic)+1] = 30
+			return b
+		}), passphrase: passphrase, err: errInvalidHeader},
+		"scrypt_r_p": {data: modified(func(b []byte) []byte {
+			b[len(magic)+2], b[len(magic)+3] = 255, 255
+			return b
+		}), passphrase: passphrase, err: errInvalidHeader},
+		"header_modified": {data: modified(func(b []byte) []byte {
+			b[headerSize-5]++ // nonce prefix
+			return b
+		}), passphrase: passphrase, err: ErrCorrupted},
+		"content_modified": {data: modified(func(b []byte) []byte {
+			b[len(b)-1]++
+			return b
+		}), passphrase: passphrase, err: ErrCorrupted},
+		"truncated_at_chunk": {data: encrypted[:headerSize+DefaultChunkSize+tagSize], passphrase: passphrase, err: ErrCorrupted},
+		"extended": {data: append(bytes.Clone(encrypted), encrypted[headerSize:headerSize+DefaultChunkSize+tagSize]...),
+			passphrase: passphrase, err: ErrCorrupted},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := decrypt(tt.data, tt.passphrase)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParams_WithinLimits(t *testing.T) {
+	assert.True(t, DefaultParams.withinLimits())
+	assert.True(t, Params{LogN: maxLogN, R: 8, P: 1}.withinLimits())
+	assert.False(t, Params{LogN: maxLogN + 1, R: 8, P: 1}.withinLimits(), "log n")
+	assert.False(t, Params{LogN: 15, R: maxR + 1, P: 1}.withinLimits(), "r")
+	assert.False(t, Params{LogN: 15, R: 8, P: maxP + 1}.withinLimits(), "p")
+	assert.False(t, Params{LogN: 15, R: maxR, P: maxP}.withinLimits(), "r*p")
+	assert.False(t, Params{LogN: maxLogN, R: maxR, P: 1}.withinLimits(), "memory")
+	assert.False(t, Params{LogN: 15, R: 8, P: 0}.withinLimits(), "zero p")
+}
+
+type failingWriter struct {
+	failAfter int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.failAfter <= 0 {
+		return 0, errWrite
+	}
+	w.failAfter--
+	return len(p), nil
+}
+
+type failingReader struct{}
+
+var errRead = errors.New("read failed")
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errRead
+}
+
+func TestEncrypt_Errors(t *testing.T) {
+	ctx := context.Background()
+	src := func() io.Reader {
+		return bytes.NewReader(make([]byte, 10))
+	}
+	assert.ErrorIs(t, Encrypt(ctx, io.Discard, src(), nil, testParams), ErrNoPassphrase)
+	assert.Error(t, Encrypt(ctx, io.Discard, src(), passphrase, Params{LogN: 10, R: 0, P: 1}), "invalid scrypt params")
+	assert.Error(t, Encrypt(ctx, io.Discard, src(), passphrase, Params{LogN: maxLogN + 1, R: 8, P: 1}), "params beyond limits")
+	assert.ErrorIs(t, Encrypt(ctx, &failingWriter{}, src(), passphrase, testParams), errWrite, "header")
+	assert.ErrorIs(t, Encrypt(ctx, &failingWriter{failAfter: 1}, src(), passphrase, testParams), errWrite, "chunk")
+	assert.ErrorIs(t, Encrypt(ctx, io.Discard, failingReader{}, passphrase, testParams), errRead)
+	reader := io.MultiReader(bytes.NewReader(make([]byte, DefaultChunkSize)), failingReader{})
+	assert.ErrorIs(t, Encrypt(ctx, io.Discard, reader, passphrase, testParams), errRead, "peek")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, Encrypt(canceled, io.Discard, src(), passphrase, testParams), context.Canceled)
+}
+
+func TestDecrypt_StreamErrors(t *testing.T) {
+	ctx := context.Background()
+	encrypted := encrypt(t, make([]byte, 2*DefaultChunkSize))
+	assert.ErrorIs(t, Decrypt(ctx, &failingWriter{}, bytes.NewReader(encrypted), passphrase), errWrite)
+	assert.ErrorIs(t, Decrypt(ctx, io.Discard, failingReader{}, passphrase), errRead)
+	reader := io.MultiReader(bytes.NewReader(encrypted[:headerSize+10]), failingReader{})
+	assert.ErrorIs(t, Decrypt(ctx, io.Discard, reader, passphrase), errRead)
+	reader = io.MultiReader(bytes.NewReader(encrypted[:headerSize+DefaultChunkSize+tagSize]), failingReader{})
+	assert.ErrorIs(t, Decrypt(ctx, io.Discard, reader, passphrase), errRead, "peek")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, Decrypt(canceled, io.Discard, bytes.NewReader(encrypted), passphrase), context.Canceled)
+}
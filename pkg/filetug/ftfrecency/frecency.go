@@ -0,0 +1,231 @@
+// Package ftfrecency ranks visited directories by frecency, a mix of how frequently & how recently they were visited,
+// the way zoxide does. Directories of any store are kept.
+package ftfrecency
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/filetug/filetug/pkg/filetug/ftfuzzy"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxEntries limits the size of a database, entries with the lowest scores are dropped first.
+const MaxEntries = 500
+
+// Entry is a visited directory.
+type Entry struct {
+	Store     string    `yaml:"store"`
+	Path      string    `yaml:"path"`
+	Count     int       `yaml:"count"`
+	LastVisit time.Time `yaml:"last_visit"`
+}
+
+// Score weights the number of visits by how long ago the last one was.
+func (e Entry) Score(now time.Time) float64 {
+	count := float64(e.Count)
+	switch age := now.Sub(e.LastVisit); {
+	case age < time.Hour:
+		return count * 4
+	case age < 24*time.Hour:
+		return count * 2
+	case age < 7*24*time.Hour:
+		return count / 2
+	default:
+		return count / 4
+	}
+}
+
+// Match is an entry matching a query, positions are indexes of matched runes in the path.
+type Match struct {
+	Entry
+	Positions []int
+	score     int
+}
+
+// DB keeps visited directories, it is safe for concurrent use.
+type DB struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewDB() *DB {
+	return &DB{}
+}
+
+func indexOf(entries []Entry, store, path string) int {
+	return slices.IndexFunc(entries, func(e Entry) bool { return e.Store == store && e.Path == path })
+}
+
+// Visit counts a visit of a directory.
+func (db *DB) Visit(store, path string, at time.Time) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if i := indexOf(db.entries, store, path); i >= 0 {
+		db.entries[i].Count++
+		db.entries[i].LastVisit = at
+		return
+	}
+	db.entries = append(db.entries, Entry{Store: store, Path: path, Count: 1, LastVisit: at})
+	if len(db.entries) > MaxEntries {
+		ranked := rank(db.entries, at)
+		db.entries = slices.DeleteFunc(db.entries, func(e Entry) bool {
+			return e == ranked[len(ranked)-1]
+		})
+	}
+}
+
+// Entries returns a copy of all entries.
+func (db *DB) Entries() []Entry {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return slices.Clone(db.entries)
+}
+
+// Top returns up to limit entries with the highest scores.
+func (db *DB) Top(now time.Time, limit int) []Entry {
+	ranked := rank(db.Entries(), now)
+	return ranked[:min(limit, len(ranked))]
+}
+
+// rank sorts entries by score, the most recent first if scores are equal.
+func rank(entries []Entry, now time.Time) []Entry {
+	ranked := slices.Clone(entries)
+	slices.SortStableFunc(ranked, func(a, b Entry) int {
+		if sa, sb := a.Score(now), b.Score(now); sa != sb {
+			if sa > sb {
+				return -1
+			}
+			return 1
+		}
+		return b.LastVisit.Compare(a.LastVisit)
+	})
+	return ranked
+}
+
+// Query returns up to limit entries matching space separated keywords ranked by frecency.
+// Like in zoxide all keywords must match the path in order & the last one must match its last component.
+// Keywords are matched fuzzily, an empty query returns the top entries.
+func (db *DB) Query(query string, now time.Time, limit int) []Match {
+	keywords := strings.Fields(query)
+	var matches []Match
+	for _, e := range rank(db.Entries(), now) {
+		if m, ok := matchKeywords(e, keywords); ok {
+			matches = append(matches, m)
+		}
+	}
+	// Frecency is the primary order, the match score only breaks ties.
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		if sa, sb := a.Score(now), b.Score(now); sa != sb {
+			if sa > sb {
+				return -1
+			}
+			return 1
+		}
+		return b.score - a.score
+	})
+	return matches[:min(limit, len(matches))]
+}
+
+func matchKeywords(e Entry, keywords []string) (Match, bool) {
+	m := Match{Entry: e}
+	path := []rune(e.Path)
+	start := 0
+	for i, keyword := range keywords {
+		score, positions, ok := ftfuzzy.Match(keyword, string(path[start:]))
+		if !ok {
+			return Match{}, false
+		}
+		for _, p := range positions {
+			m.Positions = append(m.Positions, start+p)
+		}
+		m.score += score
+		start += positions[len(positions)-1] + 1
+		if i == len(keywords)-1 {
+			lastComponent := strings.LastIndex(strings.TrimSuffix(e.Path, "/"), "/") + 1
+			if m.Positions[len(m.Positions)-1] < len([]rune(e.Path[:lastComponent])) {
+				return Match{}, false
+			}
+		}
+	}
+	return m, true
+}
+
+// Remove forgets a directory, returns false if it is not there.
+func (db *DB) Remove(store, path string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	i := indexOf(db.entries, store, path)
+	if i < 0 {
+		return false
+	}
+	db.entries = slices.Delete(db.entries, i, i+1)
+	return true
+}
+
+// Prune removes entries of directories that no longer exist & returns how many were removed.
+func (db *DB) Prune(exists func(e Entry) bool) int {
+	entries := db.Entries()
+	var removed []Entry
+	for _, e := range entries {
+		if !exists(e) {
+			removed = append(removed, e)
+		}
+	}
+	for _, e := range removed {
+		db.Remove(e.Store, e.Path)
+	}
+	return len(removed)
+}
+
+// Load reads a database persisted by Save. A missing file means an empty database.
+func Load(filePath string) (*DB, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return NewDB(), nil
+		}
+		return nil, err
+	}
+	db := NewDB()
+	if err = yaml.Unmarshal(data, &db.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return db, nil
+}
+
+// Save writes all entries to a file.
+func (db *DB) Save(filePath string) error {
+	data, err := yaml.Marshal(db.Entries())
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(filePath)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// Visits are saved on every change of a dir, the file is replaced at once so it's never read half-written.
+	tmp, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filePath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
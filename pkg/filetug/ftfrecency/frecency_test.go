@@ -0,0 +1,119 @@
+package ftfrecency
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func TestEntry_Score(t *testing.T) {
+	t.Parallel()
+	e := Entry{Count: 4}
+	for _, tt := range []struct {
+		age   time.Duration
+		score float64
+	}{
+		{time.Minute, 16},
+		{2 * time.Hour, 8},
+		{48 * time.Hour, 2},
+		{30 * 24 * time.Hour, 1},
+	} {
+		e.LastVisit = now.Add(-tt.age)
+		assert.Equal(t, tt.score, e.Score(now), tt.age.String())
+	}
+}
+
+func TestDB_VisitTopRemove(t *testing.T) {
+	t.Parallel()
+	db := NewDB()
+	db.Visit("file:", "/old", now.Add(-30*24*time.Hour))
+	db.Visit("file:", "/old", now.Add(-30*24*time.Hour))
+	db.Visit("file:", "/recent", now.Add(-time.Minute))
+	db.Visit("ftp://host", "/old", now)
+	db.Visit("ftp://host", "/old", now)
+
+	top := db.Top(now, 2)
+	require.Len(t, top, 2)
+	assert.Equal(t, Entry{Store: "ftp://host", Path: "/old", Count: 2, LastVisit: now}, top[0])
+	assert.Equal(t, "/recent", top[1].Path, "a recent visit outranks older frequent ones")
+	assert.Len(t, db.Top(now, 10), 3)
+
+	assert.True(t, db.Remove("file:", "/old"))
+	assert.False(t, db.Remove("file:", "/old"))
+	assert.Len(t, db.Entries(), 2)
+}
+
+func TestDB_Visit_MaxEntries(t *testing.T) {
+	t.Parallel()
+	db := NewDB()
+	db.Visit("file:", "/stale", now.Add(-30*24*time.Hour))
+	for i := 1; i < MaxEntries; i++ {
+		db.Visit("file:", "/d"+strconv.Itoa(i), now)
+	}
+	db.Visit("file:", "/new", now)
+	entries := db.Entries()
+	assert.Len(t, entries, MaxEntries)
+	assert.Equal(t, "/d1", entries[0].Path, "the entry with the lowest score is dropped")
+}
+
+func TestDB_Query(t *testing.T) {
+	t.Parallel()
+	db := NewDB()
+	db.Visit("file:", "/home/user/projects/filetug", now)
+	db.Visit("file:", "/home/user/projects/filetug/pkg", now)
+	for range 3 {
+		db.Visit("file:", "/home/user/photos", now)
+	}
+	db.Visit("file:", "/", now.Add(-time.Hour*48))
+
+	names := func(matches []Match) (paths []string) {
+		for _, m := range matches {
+			paths = append(paths, m.Path)
+		}
+		return
+	}
+	assert.Equal(t, []string{"/home/user/photos", "/home/user/projects/filetug", "/home/user/projects/filetug/pkg", "/"},
+		names(db.Query("", now, 10)))
+	assert.Equal(t, []string{"/home/user/projects/filetug"}, names(db.Query("ftug", now, 10)),
+		"the last keyword matches the last component")
+	assert.Equal(t, []string{"/home/user/projects/filetug/pkg"}, names(db.Query("tug pkg", now, 10)))
+	assert.Empty(t, db.Query("pkg tug", now, 10), "keywords match in order")
+	assert.Equal(t, []string{"/home/user/photos"}, names(db.Query("ph", now, 1)))
+
+	m := db.Query("proj tug", now, 1)[0]
+	assert.Equal(t, []int{11, 12, 13, 14, 24, 25, 26}, m.Positions)
+}
+
+func TestDB_Prune(t *testing.T) {
+	t.Parallel()
+	db := NewDB()
+	db.Visit("file:", "/a", now)
+	db.Visit("file:", "/b", now)
+	assert.Equal(t, 1, db.Prune(func(e Entry) bool { return e.Path == "/a" }))
+	assert.Equal(t, []Entry{{Store: "file:", Path: "/a", Count: 1, LastVisit: now}}, db.Entries())
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", "frecency.yaml")
+	db, err := Load(filePath)
+	require.NoError(t, err)
+	assert.Empty(t, db.Entries())
+
+	db.Visit("file:", "/a", now)
+	require.NoError(t, db.Save(filePath))
+	loaded, err := Load(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, db.Entries(), loaded.Entries())
+
+	require.NoError(t, os.WriteFile(filePath, []byte("a: [b"), 0o644))
+	_, err = Load(filePath)
+	assert.Error(t, err)
+}
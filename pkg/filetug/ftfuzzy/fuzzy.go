@@ -0,0 +1,117 @@
+// Package ftfuzzy matches a typed pattern against names & paths the way fuzzy finders do:
+// runes of the pattern must appear in the text in order but not necessarily next to each other.
+package ftfuzzy
+
+import (
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 16
+	bonusBoundary    = 24 // the start of a word: after a separator or a lower-case letter followed by an upper-case one
+	bonusFirst       = 8  // the first rune of the text
+	bonusCase        = 1  // the same case as in the pattern
+	penaltyGap       = 3  // per rune skipped between matched runes
+	penaltyLeading   = 1  // per rune skipped before the first matched rune, limited by maxLeading runes
+	maxLeading       = 8
+)
+
+// Match reports whether all runes of a pattern appear in a text in order, ignoring case,
+// & scores how well they match: consecutive runes, starts of words & the start of the text score higher.
+// Positions are indexes of matched runes in the text, the best scoring alignment is chosen.
+// An empty pattern matches any text with a zero score.
+func Match(pattern, text string) (score int, positions []int, ok bool) {
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	t := []rune(text)
+	if len(p) > len(t) || !isSubsequence(p, t) {
+		return 0, nil, false
+	}
+	// best[i][j] is the best score of p[:i+1] with p[i] matched at t[j], from[i][j] is where p[i-1] is matched.
+	const none = -1 << 30
+	best := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for i := range p {
+		best[i] = make([]int, len(t))
+		from[i] = make([]int, len(t))
+		for j := range t {
+			best[i][j] = none
+			if !equalFold(p[i], t[j]) {
+				continue
+			}
+			s := runeScore(p[i], t, j)
+			if i == 0 {
+				best[i][j] = s - min(j, maxLeading)*penaltyLeading
+				continue
+			}
+			for k := i - 1; k < j; k++ {
+				if best[i-1][k] == none {
+					continue
+				}
+				prev := best[i-1][k]
+				if k == j-1 {
+					prev += bonusConsecutive
+				} else {
+					prev -= (j - k - 1) * penaltyGap
+				}
+				if prev+s > best[i][j] {
+					best[i][j] = prev + s
+					from[i][j] = k
+				}
+			}
+		}
+	}
+	last := len(p) - 1
+	end := -1
+	for j := range t {
+		if best[last][j] != none && (end < 0 || best[last][j] > best[last][end]) {
+			end = j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	positions = make([]int, len(p))
+	for i, j := last, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+	return best[last][end], positions, true
+}
+
+func isSubsequence(p, t []rune) bool {
+	i := 0
+	for _, r := range t {
+		if i < len(p) && equalFold(p[i], r) {
+			i++
+		}
+	}
+	return i == len(p)
+}
+
+func equalFold(a, b rune) bool {
+	return a == b || unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+func runeScore(p rune, t []rune, j int) int {
+	s := scoreMatch
+	if p == t[j] {
+		s += bonusCase
+	}
+	switch {
+	case j == 0:
+		s += bonusBoundary + bonusFirst
+	case !isWordRune(t[j-1]) && isWordRune(t[j]):
+		s += bonusBoundary
+	case unicode.IsLower(t[j-1]) && unicode.IsUpper(t[j]):
+		s += bonusBoundary
+	}
+	return s
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,58 @@
+package ftfuzzy
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int
+	}{
+		{pattern: "", text: "abc", ok: true},
+		{pattern: "abc", text: "abc", ok: true, positions: []int{0, 1, 2}},
+		{pattern: "ABC", text: "abc", ok: true, positions: []int{0, 1, 2}},
+		{pattern: "fb", text: "foo_bar", ok: true, positions: []int{0, 4}},
+		{pattern: "mg", text: "main.go", ok: true, positions: []int{0, 5}},
+		{pattern: "rdm", text: "README.md", ok: true, positions: []int{0, 3, 4}},
+		{pattern: "gc", text: "getConfig.go", ok: true, positions: []int{0, 3}},
+		{pattern: "ый", text: "Новый.txt", ok: true, positions: []int{3, 4}},
+		{pattern: "ba", text: "abc"},
+		{pattern: "abcd", text: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.text, func(t *testing.T) {
+			t.Parallel()
+			_, positions, ok := Match(tt.pattern, tt.text)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.positions, positions)
+		})
+	}
+}
+
+func TestMatch_Ranking(t *testing.T) {
+	t.Parallel()
+	names := []string{"x_file_test.go", "files.go", "fs_records.go", "profiles.go", "file.go"}
+	type scored struct {
+		name  string
+		score int
+	}
+	var ranked []scored
+	for _, name := range names {
+		if score, _, ok := Match("file", name); ok {
+			ranked = append(ranked, scored{name, score})
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int { return b.score - a.score })
+	var got []string
+	for _, s := range ranked {
+		got = append(got, s.name)
+	}
+	assert.Equal(t, []string{"files.go", "file.go", "x_file_test.go", "profiles.go"}, got,
+		"prefixes first, then starts of words, then matches inside words")
+}
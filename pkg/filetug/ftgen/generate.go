@@ -0,0 +1,294 @@
+// Package ftgen generates trees of nested directories & files, e.g. to reproduce performance issues with deep trees.
+package ftgen
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/filetug/filetug/pkg/files"
+)
+
+type Pattern string
+
+const (
+	PatternZeros  Pattern = "zeros"
+	PatternRandom Pattern = "random"
+	PatternLorem  Pattern = "lorem"
+)
+
+// Patterns lists content patterns in order they are offered to a user.
+var Patterns = []Pattern{PatternZeros, PatternRandom, PatternLorem}
+
+const (
+	DefaultSubDirNameFormat = "Directory%d"
+	DefaultFileNameFormat   = "File%d.txt"
+)
+
+// MaxEntries limits the number of dirs & files a single run can create.
+const MaxEntries = 10_000_000
+
+var ErrFileWriteNotSupported = fmt.Errorf("writing files: %w", files.ErrNotSupported)
+
+type Options struct {
+	Depth            int // levels of sub-dirs below the root dir
+	SubDirs          int // sub-dirs in each dir above the deepest level
+	FilesPerDir      int
+	FileSize         int64
+	Pattern          Pattern
+	SubDirNameFormat string // fmt format with a single %d verb, DefaultSubDirNameFormat if empty
+	FileNameFormat   string // fmt format with a single %d verb, DefaultFileNameFormat if empty
+	Workers          int    // number of concurrent workers, runtime.NumCPU() if not positive
+}
+
+type Progress struct {
+	DirsTotal  int
+	DirsDone   int
+	FilesTotal int
+	FilesDone  int
+	BytesTotal int64
+	BytesDone  int64
+}
+
+// Count returns the number of dirs including the root one & the number of files to be generated.
+// It returns an error if the total exceeds MaxEntries.
+func (o Options) Count() (dirs, fileCount int, err error) {
+	if o.Depth < 0 || o.SubDirs < 0 || o.FilesPerDir < 0 || o.FileSize < 0 {
+		return 0, 0, errors.New("negative values are not allowed")
+	}
+	errTooMany := fmt.Errorf("too many entries to generate, max is %d", MaxEntries)
+	levelDirs := 1
+	for level := 0; level <= o.Depth; level++ {
+		if dirs += levelDirs; dirs > MaxEntries {
+			return 0, 0, errTooMany
+		}
+		if level == o.Depth || o.SubDirs == 0 {
+			break
+		}
+		if levelDirs > MaxEntries/o.SubDirs {
+			return 0, 0, errTooMany
+		}
+		levelDirs *= o.SubDirs
+	}
+	if o.FilesPerDir > MaxEntries || dirs*(o.FilesPerDir+1) > MaxEntries {
+		return 0, 0, errTooMany
+	}
+	fileCount = dirs * o.FilesPerDir
+	if fileCount > 0 && o.FileSize > math.MaxInt64/int64(fileCount) {
+		return 0, 0, errors.New("total size of files is too big")
+	}
+	return dirs, fileCount, nil
+}
+
+// Generate creates dirPath with nested sub-dirs & files using a bounded number of concurrent workers.
+// Dirs are created level by level, so a parent always exists before its children.
+// It stops at the first error or once ctx is cancelled.
+func Generate(ctx context.Context, store files.Store, dirPath string, opts Options, report func(p Progress)) error {
+	dirs, fileCount, err := opts.Count()
+	if err != nil {
+		return err
+	}
+	if opts.SubDirNameFormat == "" {
+		opts.SubDirNameFormat = DefaultSubDirNameFormat
+	}
+	if opts.FileNameFormat == "" {
+		opts.FileNameFormat = DefaultFileNameFormat
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	g := &generator{
+		store:  store,
+		opts:   opts,
+		report: report,
+		progress: Progress{
+			DirsTotal:  dirs,
+			FilesTotal: fileCount,
+			BytesTotal: int64(fileCount) * opts.FileSize,
+		},
+	}
+	if fileCount > 0 && opts.FileSize > 0 {
+		writer, ok := store.(files.FileWriter)
+		if !ok {
+			return ErrFileWriteNotSupported
+		}
+		g.writer = writer
+		if g.chunk, err = newChunk(opts.Pattern, opts.FileSize); err != nil {
+			return err
+		}
+	}
+	g.reportProgress(0, 0, 0)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	levelDirs := 1
+	for level := 0; level <= opts.Depth && levelDirs > 0; level++ {
+		if err = g.generateLevel(ctx, cancel, dirPath, level, levelDirs); err != nil {
+			return err
+		}
+		levelDirs *= opts.SubDirs
+	}
+	return nil
+}
+
+type generator struct {
+	store  files.Store
+	writer files.FileWriter // nil if files are created empty
+	chunk  []byte           // content pattern repeated to fill files
+	opts   Options
+	report func(p Progress)
+
+	mu       sync.Mutex
+	progress Progress
+	reportMu sync.Mutex
+}
+
+// reportProgress adds to the counters & passes a copy of them to the callback without holding mu,
+// so workers keep counting while it runs. Calls of the callback are serialized by reportMu & each of them
+// gets the latest counters, so reported progress never goes back.
+func (g *generator) reportProgress(dirs, fileCount int, bytes int64) {
+	g.mu.Lock()
+	g.progress.DirsDone += dirs
+	g.progress.FilesDone += fileCount
+	g.progress.BytesDone += bytes
+	g.mu.Unlock()
+	if g.report == nil {
+		return
+	}
+	g.reportMu.Lock()
+	defer g.reportMu.Unlock()
+	g.mu.Lock()
+	progress := g.progress
+	g.mu.Unlock()
+	g.report(progress)
+}
+
+// generateLevel creates all dirs of a level with their files. Paths of dirs are derived from their indexes,
+// so no level is kept in memory.
+func (g *generator) generateLevel(ctx context.Context, cancel context.CancelFunc, root string, level, count int) error {
+	indexes := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	workers := min(g.opts.Workers, count)
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := g.generateDir(ctx, g.dirPath(root, level, i)); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+feeding:
+	for i := 0; i < count; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feeding
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// dirPath returns the path of the i-th dir of a level, its digits in base SubDirs are indexes of sub-dirs.
+func (g *generator) dirPath(root string, level, i int) string {
+	names := make([]string, level)
+	for l := level - 1; l >= 0; l-- {
+		names[l] = fmt.Sprintf(g.opts.SubDirNameFormat, i%g.opts.SubDirs)
+		i /= g.opts.SubDirs
+	}
+	return path.Join(root, path.Join(names...))
+}
+
+func (g *generator) generateDir(ctx context.Context, dirPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := g.store.CreateDir(ctx, dirPath); err != nil {
+		return err
+	}
+	g.reportProgress(1, 0, 0)
+	for i := 0; i < g.opts.FilesPerDir; i++ {
+		filePath := path.Join(dirPath, fmt.Sprintf(g.opts.FileNameFormat, i))
+		if err := g.generateFile(ctx, filePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) generateFile(ctx context.Context, filePath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if g.writer == nil {
+		if err := g.store.CreateFile(ctx, filePath); err != nil {
+			return err
+		}
+		g.reportProgress(0, 1, 0)
+		return nil
+	}
+	w, err := g.writer.OpenWriter(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	for remaining := g.opts.FileSize; remaining > 0; {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		n := min(remaining, int64(len(g.chunk)))
+		if _, err = w.Write(g.chunk[:n]); err != nil {
+			break
+		}
+		remaining -= n
+		g.reportProgress(0, 0, n)
+	}
+	if err = errors.Join(err, w.Close()); err != nil {
+		return err
+	}
+	g.reportProgress(0, 1, 0)
+	return nil
+}
+
+const chunkSize = 32 * 1024
+
+const loremText = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt " +
+	"ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris " +
+	"nisi ut aliquip ex ea commodo consequat.\n"
+
+// newChunk returns content a file is filled with. Random content is shared by all files to keep generation fast.
+func newChunk(pattern Pattern, fileSize int64) ([]byte, error) {
+	size := int(min(fileSize, chunkSize))
+	switch pattern {
+	case PatternZeros, "":
+		return make([]byte, size), nil
+	case PatternRandom:
+		chunk := make([]byte, size)
+		_, _ = rand.Read(chunk) // never returns an error
+		return chunk, nil
+	case PatternLorem:
+		// Whole repetitions of the text so files bigger than a chunk continue it seamlessly.
+		repeat := max(1, size/len(loremText)+1)
+		return []byte(strings.Repeat(loremText, repeat)), nil
+	default:
+		return nil, fmt.Errorf("unknown content pattern: %q", pattern)
+	}
+}
@@ -0,0 +1,199 @@
+package ftgen
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_Count(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		opts      Options
+		dirs      int
+		fileCount int
+		err       string
+	}{
+		{"root_only", Options{}, 1, 0, ""},
+		{"no_sub_dirs", Options{Depth: 5, FilesPerDir: 2}, 1, 2, ""},
+		{"tree", Options{Depth: 2, SubDirs: 3, FilesPerDir: 2}, 13, 26, ""},
+		{"negative", Options{Depth: -1}, 0, 0, "negative values are not allowed"},
+		{"too_many_dirs", Options{Depth: 20, SubDirs: 10}, 0, 0, "too many entries to generate, max is 10000000"},
+		{"too_many_files", Options{Depth: 3, SubDirs: 10, FilesPerDir: 10000}, 0, 0, "too many entries to generate, max is 10000000"},
+		{"too_big", Options{FilesPerDir: 2, FileSize: 1 << 62}, 0, 0, "total size of files is too big"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirs, fileCount, err := tt.opts.Count()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.dirs, dirs)
+			assert.Equal(t, tt.fileCount, fileCount)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	for _, pattern := range Patterns {
+		t.Run(string(pattern), func(t *testing.T) {
+			t.Parallel()
+			root := filepath.Join(t.TempDir(), "gen")
+			var last Progress
+			opts := Options{Depth: 2, SubDirs: 2, FilesPerDir: 2, FileSize: 40_000, Pattern: pattern, Workers: 3}
+			err := Generate(context.Background(), osfile.NewStore("/"), root, opts, func(p Progress) {
+				last = p
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, Progress{
+				DirsTotal: 7, DirsDone: 7, FilesTotal: 14, FilesDone: 14, BytesTotal: 14 * 40_000, BytesDone: 14 * 40_000,
+			}, last)
+			data, err := os.ReadFile(filepath.Join(root, "Directory1", "Directory1", "File1.txt"))
+			assert.NoError(t, err)
+			assert.Len(t, data, 40_000)
+			switch pattern {
+			case PatternZeros:
+				assert.Equal(t, make([]byte, 40_000), data)
+			case PatternLorem:
+				assert.Equal(t, strings.Repeat(loremText, 40_000/len(loremText)+1)[:40_000], string(data))
+			}
+		})
+	}
+}
+
+// countingStore records the max number of concurrent CreateDir calls.
+type countingStore struct {
+	files.Store
+	mu       sync.Mutex
+	created  []string
+	inFlight atomic.Int32
+	max      atomic.Int32
+	failOn   string
+}
+
+func (s *countingStore) RootURL() url.URL {
+	return url.URL{Scheme: "mem"}
+}
+
+func (s *countingStore) CreateDir(ctx context.Context, p string) error {
+	n := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		m := s.max.Load()
+		if n <= m || s.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	if p == s.failOn {
+		return errors.New("fail")
+	}
+	s.mu.Lock()
+	s.created = append(s.created, p)
+	s.mu.Unlock()
+	return ctx.Err()
+}
+
+func (s *countingStore) CreateFile(_ context.Context, _ string) error {
+	return nil
+}
+
+func TestGenerate_BoundedWorkers(t *testing.T) {
+	t.Parallel()
+	store := &countingStore{}
+	err := Generate(context.Background(), store, "/r", Options{Depth: 2, SubDirs: 6, Workers: 2}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, store.created, 43)
+	assert.LessOrEqual(t, store.max.Load(), int32(2))
+	assert.Equal(t, "/r", store.created[0])
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("first_error_stops", func(t *testing.T) {
+		store := &countingStore{failOn: "/r/Directory1"}
+		err := Generate(ctx, store, "/r", Options{Depth: 3, SubDirs: 4, Workers: 2}, nil)
+		assert.EqualError(t, err, "fail")
+		for _, p := range store.created {
+			assert.Less(t, len(strings.Split(p, "/")), 5, "deeper levels are not generated after an error: %s", p)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		err := Generate(cancelled, &countingStore{}, "/r", Options{Depth: 1, SubDirs: 2}, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("cancelled_while_writing", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		root := filepath.Join(t.TempDir(), "gen")
+		err := Generate(cancelCtx, osfile.NewStore("/"), root, Options{FilesPerDir: 1, FileSize: 10 * chunkSize},
+			func(p Progress) {
+				if p.BytesDone > 0 {
+					cancel()
+				}
+			})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("write_not_supported", func(t *testing.T) {
+		err := Generate(ctx, &countingStore{}, "/r", Options{FilesPerDir: 1, FileSize: 1}, nil)
+		assert.ErrorIs(t, err, ErrFileWriteNotSupported)
+	})
+
+	t.Run("empty_files_without_writer", func(t *testing.T) {
+		var last Progress
+		err := Generate(ctx, &countingStore{}, "/r", Options{FilesPerDir: 3}, func(p Progress) { last = p })
+		assert.NoError(t, err)
+		assert.Equal(t, 3, last.FilesDone)
+	})
+
+	t.Run("unknown_pattern", func(t *testing.T) {
+		err := Generate(ctx, osfile.NewStore("/"), t.TempDir(), Options{FilesPerDir: 1, FileSize: 1, Pattern: "x"}, nil)
+		assert.EqualError(t, err, `unknown content pattern: "x"`)
+	})
+
+	t.Run("invalid_options", func(t *testing.T) {
+		err := Generate(ctx, &countingStore{}, "/r", Options{SubDirs: -1}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("open_writer_error", func(t *testing.T) {
+		root := filepath.Join(t.TempDir(), "gen")
+		err := Generate(ctx, osfile.NewStore("/"), root, Options{FilesPerDir: 1, FileSize: 1, FileNameFormat: "missing/%d"}, nil)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestGenerator_ReportProgress(t *testing.T) {
+	t.Parallel()
+	g := &generator{}
+	var reported []Progress
+	g.report = func(p Progress) {
+		g.mu.Lock() // deadlocks if counters are still locked while the callback runs
+		g.mu.Unlock()
+		reported = append(reported, p)
+	}
+	g.reportProgress(1, 0, 0)
+	g.reportProgress(0, 2, 10)
+	assert.Equal(t, []Progress{{DirsDone: 1}, {DirsDone: 1, FilesDone: 2, BytesDone: 10}}, reported)
+}
@@ -0,0 +1,97 @@
+package ftjournal
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Entry is a user action with its current undo state.
+type Entry struct {
+	Record
+	Undone bool
+}
+
+// History is the state of the journal after replaying undo & redo records.
+type History struct {
+	Entries   []Entry // user actions in chronological order
+	Malformed []int   // numbers of journal lines skipped as malformed
+	done      []int   // indexes of entries that can be undone, the last one is undone first
+	undone    []int   // indexes of entries that can be redone, the last one is redone first
+}
+
+// NewHistory replays records. A new user action clears the redo stack.
+func NewHistory(records []Record) History {
+	var h History
+	byID := make(map[string]int, len(records))
+	for _, r := range records {
+		switch r.Action {
+		case ActionUndo:
+			if i, ok := byID[r.Target]; ok && !h.Entries[i].Undone {
+				h.Entries[i].Undone = true
+				h.done = removeIndex(h.done, i)
+				h.undone = append(h.undone, i)
+			}
+		case ActionRedo:
+			if i, ok := byID[r.Target]; ok && h.Entries[i].Undone {
+				h.Entries[i].Undone = false
+				h.undone = removeIndex(h.undone, i)
+				h.done = append(h.done, i)
+			}
+		default:
+			byID[r.ID] = len(h.Entries)
+			h.done = append(h.done, len(h.Entries))
+			h.Entries = append(h.Entries, Entry{Record: r})
+			h.undone = nil
+		}
+	}
+	return h
+}
+
+func removeIndex(indexes []int, i int) []int {
+	for j, v := range indexes {
+		if v == i {
+			return append(indexes[:j:j], indexes[j+1:]...)
+		}
+	}
+	return indexes
+}
+
+// UndoCandidate returns the most recent action that has not been undone.
+// Actions are undone strictly in reverse order, so an irreversible action blocks undo of older ones.
+func (h History) UndoCandidate() (Record, error) {
+	if len(h.done) == 0 {
+		return Record{}, ErrNothingToUndo
+	}
+	r := h.Entries[h.done[len(h.done)-1]].Record
+	if !r.CanUndo() {
+		return r, fmt.Errorf("%s: can not be undone", r.Title())
+	}
+	return r, nil
+}
+
+// RedoCandidate returns the most recently undone action.
+func (h History) RedoCandidate() (Record, error) {
+	if len(h.undone) == 0 {
+		return Record{}, ErrNothingToRedo
+	}
+	r := h.Entries[h.undone[len(h.undone)-1]].Record
+	if !r.CanRedo() {
+		return r, fmt.Errorf("%s: can not be redone", r.Title())
+	}
+	return r, nil
+}
+
+// FindByBatchID returns the action related to the given rename batch.
+func (h History) FindByBatchID(batchID string) (Entry, bool) {
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		if h.Entries[i].BatchID == batchID {
+			return h.Entries[i], true
+		}
+	}
+	return Entry{}, false
+}
@@ -0,0 +1,85 @@
+package ftjournal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHistory(t *testing.T) {
+	t.Parallel()
+	create := Record{ID: "1", Action: ActionCreateDir, Paths: []string{"/a"}}
+	rename := Record{ID: "2", Action: ActionRename, Moves: []Move{{From: "/a/x", To: "/a/y"}}, BatchID: "b1"}
+	del := Record{ID: "3", Action: ActionDelete, Paths: []string{"/z"}}
+
+	t.Run("empty", func(t *testing.T) {
+		h := NewHistory(nil)
+		_, err := h.UndoCandidate()
+		assert.ErrorIs(t, err, ErrNothingToUndo)
+		_, err = h.RedoCandidate()
+		assert.ErrorIs(t, err, ErrNothingToRedo)
+	})
+
+	t.Run("undo_redo", func(t *testing.T) {
+		h := NewHistory([]Record{create, rename})
+		r, err := h.UndoCandidate()
+		assert.NoError(t, err)
+		assert.Equal(t, "2", r.ID)
+
+		h = NewHistory([]Record{create, rename, {Action: ActionUndo, Target: "2"}})
+		assert.True(t, h.Entries[1].Undone)
+		r, err = h.UndoCandidate()
+		assert.NoError(t, err)
+		assert.Equal(t, "1", r.ID)
+		r, err = h.RedoCandidate()
+		assert.NoError(t, err)
+		assert.Equal(t, "2", r.ID)
+
+		h = NewHistory([]Record{create, rename, {Action: ActionUndo, Target: "2"}, {Action: ActionRedo, Target: "2"}})
+		assert.False(t, h.Entries[1].Undone)
+		_, err = h.RedoCandidate()
+		assert.ErrorIs(t, err, ErrNothingToRedo)
+	})
+
+	t.Run("new_action_clears_redo", func(t *testing.T) {
+		h := NewHistory([]Record{create, {Action: ActionUndo, Target: "1"}, rename})
+		_, err := h.RedoCandidate()
+		assert.ErrorIs(t, err, ErrNothingToRedo)
+		assert.Len(t, h.Entries, 2)
+	})
+
+	t.Run("irreversible", func(t *testing.T) {
+		h := NewHistory([]Record{create, del})
+		_, err := h.UndoCandidate()
+		assert.EqualError(t, err, "Delete /z: can not be undone")
+	})
+
+	t.Run("not_redoable", func(t *testing.T) {
+		cp := Record{ID: "4", Action: ActionCopy, Moves: []Move{{From: "/a", To: "/b"}}}
+		h := NewHistory([]Record{cp, {Action: ActionUndo, Target: "4"}})
+		_, err := h.RedoCandidate()
+		assert.EqualError(t, err, "Copy /a → /b: can not be redone")
+	})
+
+	t.Run("ignores_unknown_and_repeated_targets", func(t *testing.T) {
+		h := NewHistory([]Record{
+			create,
+			{Action: ActionUndo, Target: "x"},
+			{Action: ActionRedo, Target: "1"},
+			{Action: ActionUndo, Target: "1"},
+			{Action: ActionUndo, Target: "1"},
+		})
+		assert.True(t, h.Entries[0].Undone)
+		_, err := h.UndoCandidate()
+		assert.ErrorIs(t, err, ErrNothingToUndo)
+	})
+
+	t.Run("find_by_batch_id", func(t *testing.T) {
+		h := NewHistory([]Record{create, rename})
+		e, ok := h.FindByBatchID("b1")
+		assert.True(t, ok)
+		assert.Equal(t, "2", e.ID)
+		_, ok = h.FindByBatchID("b2")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,118 @@
+package ftjournal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+)
+
+// Journal is an append-only JSONL file with records of mutating actions.
+type Journal struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+const journalFileName = "history.jsonl"
+
+var getDatatugUserDir = ftsettings.GetDatatugUserDir
+var timeNow = time.Now
+var idCounter atomic.Int64
+
+func NewJournal(filePath string) *Journal {
+	return &Journal{filePath: filePath}
+}
+
+// DefaultJournal returns the journal stored in the user's filetug directory.
+func DefaultJournal() (*Journal, error) {
+	dir, err := getDatatugUserDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewJournal(filepath.Join(dir, journalFileName)), nil
+}
+
+// Append writes the record to the end of the journal.
+// ID & Time are assigned if not set yet. The stored record is returned.
+func (j *Journal) Append(r Record) (Record, error) {
+	if r.Time.IsZero() {
+		r.Time = timeNow()
+	}
+	if r.ID == "" {
+		r.ID = strconv.FormatInt(r.Time.UnixNano(), 36) + "-" + strconv.FormatInt(idCounter.Add(1), 36)
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return r, err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err = os.MkdirAll(filepath.Dir(j.filePath), 0o755); err != nil {
+		return r, err
+	}
+	f, err := os.OpenFile(j.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return r, err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return r, err
+	}
+	return r, f.Close()
+}
+
+// Load reads all records. A missing journal is not an error.
+// Malformed lines are skipped, see History.Malformed.
+func (j *Journal) Load() ([]Record, error) {
+	records, _, err := j.load()
+	return records, err
+}
+
+// load reads all records & returns numbers of malformed lines, e.g. a half-written last one after a crash.
+// They are skipped, so a damaged line doesn't make the whole history unusable.
+func (j *Journal) load() (records []Record, malformed []int, err error) {
+	j.mu.Lock()
+	data, err := os.ReadFile(j.filePath)
+	j.mu.Unlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r Record
+		if json.Unmarshal(line, &r) != nil {
+			malformed = append(malformed, lineNum)
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, malformed, scanner.Err()
+}
+
+// History loads the journal and replays undo & redo records.
+func (j *Journal) History() (History, error) {
+	records, malformed, err := j.load()
+	if err != nil {
+		return History{}, err
+	}
+	h := NewHistory(records)
+	h.Malformed = malformed
+	return h, nil
+}
@@ -0,0 +1,83 @@
+package ftjournal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	t.Parallel()
+	j := NewJournal(filepath.Join(t.TempDir(), "sub", journalFileName))
+
+	records, err := j.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	first, err := j.Append(Record{Action: ActionCreateFile, Store: "file:", Paths: []string{"/a.txt"}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.ID)
+	assert.False(t, first.Time.IsZero())
+	second, err := j.Append(Record{Action: ActionUndo, Target: first.ID})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	records, err = j.Load()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, first.ID, records[0].ID)
+	assert.Equal(t, []string{"/a.txt"}, records[0].Paths)
+	assert.Equal(t, first.Time.UnixNano(), records[0].Time.UnixNano())
+
+	h, err := j.History()
+	assert.NoError(t, err)
+	assert.True(t, h.Entries[0].Undone)
+}
+
+func TestJournal_Errors(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, journalFileName)
+	content := `{"id":"1","action":"create_dir","paths":["/a"]}` + "\nbroken\n" +
+		`{"id":"2","action":"create_dir","paths":["/b"]}` + "\n" + `{"id":"3","act`
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+	j := NewJournal(filePath)
+	records, err := j.Load()
+	assert.NoError(t, err, "malformed lines are skipped")
+	assert.Len(t, records, 2)
+	h, err := j.History()
+	assert.NoError(t, err)
+	assert.Len(t, h.Entries, 2)
+	assert.Equal(t, []int{2, 4}, h.Malformed, "a half-written last line is skipped as well")
+	undo, err := h.UndoCandidate()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", undo.ID)
+
+	dirPath := filepath.Join(dir, "dir")
+	assert.NoError(t, os.Mkdir(dirPath, 0o755))
+	_, err = NewJournal(dirPath).Load()
+	assert.Error(t, err)
+
+	blocker := filepath.Join(dir, "file")
+	assert.NoError(t, os.WriteFile(blocker, nil, 0o644))
+	j = NewJournal(filepath.Join(blocker, journalFileName))
+	_, err = j.Append(Record{Action: ActionCreateDir})
+	assert.Error(t, err)
+}
+
+func TestDefaultJournal(t *testing.T) {
+	orig := getDatatugUserDir
+	t.Cleanup(func() { getDatatugUserDir = orig })
+
+	getDatatugUserDir = func() (string, error) { return "/home/u/.filetug", nil }
+	j, err := DefaultJournal()
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/u/.filetug/"+journalFileName, j.filePath)
+
+	getDatatugUserDir = func() (string, error) { return "", errors.New("no home") }
+	_, err = DefaultJournal()
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+// Package ftjournal keeps an append-only history of mutating actions
+// with enough information to undo & redo them.
+package ftjournal
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionCreateDir  Action = "create_dir"
+	ActionCreateFile Action = "create_file"
+	ActionWriteFile  Action = "write_file" // Paths are files written with generated content, e.g. manifests
+	ActionArchive    Action = "archive"
+	ActionExtract    Action = "extract" // Paths are created dirs & files, parents first
+	ActionDelete     Action = "delete"
+	ActionRename     Action = "rename"
+	ActionCopy       Action = "copy"
+	ActionMove       Action = "move"
+	ActionHardLink   Action = "hard_link" // Moves are from kept files to duplicates replaced with links to them
+	ActionSymlink    Action = "symlink"
+	ActionGitStage   Action = "git_stage"
+	ActionGitUnstage Action = "git_unstage"
+
+	// ActionUndo & ActionRedo refer to another record by Target.
+	ActionUndo Action = "undo"
+	ActionRedo Action = "redo"
+)
+
+// Move is a source & destination of a renamed, moved or copied entry.
+type Move struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Record is a single line of the journal.
+type Record struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Store   string    `json:"store,omitempty"` // root URL of the store
+	Action  Action    `json:"action"`
+	Paths   []string  `json:"paths,omitempty"`  // created, deleted, staged or unstaged paths
+	Moves   []Move    `json:"moves,omitempty"`  // renamed, moved or copied paths
+	BatchID string    `json:"batch,omitempty"`  // ID of a related batch in the rename journal
+	Target  string    `json:"target,omitempty"` // ID of the undone or redone record
+}
+
+// CanUndo reports whether the record holds enough information to be reverted.
+func (r Record) CanUndo() bool {
+	switch r.Action {
+	case ActionCreateDir, ActionCreateFile, ActionWriteFile, ActionArchive, ActionExtract, ActionRename, ActionMove, ActionCopy,
+		ActionHardLink, ActionSymlink, ActionGitStage, ActionGitUnstage:
+		return true
+	default: // deleted content is not preserved
+		return false
+	}
+}
+
+// CanRedo reports whether an undone record can be applied again.
+func (r Record) CanRedo() bool {
+	switch r.Action {
+	case ActionCreateDir, ActionCreateFile, ActionRename, ActionMove, ActionHardLink, ActionSymlink, ActionGitStage, ActionGitUnstage:
+		return true
+	default: // copies, written files, archives & extracted content can't be recreated from the journal
+		return false
+	}
+}
+
+// Title is a human-readable description of the record.
+func (r Record) Title() string {
+	switch r.Action {
+	case ActionCreateDir:
+		return "Create dir " + r.pathsText()
+	case ActionCreateFile:
+		return "Create file " + r.pathsText()
+	case ActionWriteFile:
+		return "Write file " + r.pathsText()
+	case ActionArchive:
+		return "Archive " + r.pathsText()
+	case ActionExtract:
+		return "Extract " + r.pathsText()
+	case ActionDelete:
+		return "Delete " + r.pathsText()
+	case ActionRename:
+		return "Rename " + r.movesText()
+	case ActionCopy:
+		return "Copy " + r.movesText()
+	case ActionMove:
+		return "Move " + r.movesText()
+	case ActionHardLink:
+		return "Hard link " + r.movesText()
+	case ActionSymlink:
+		return "Symlink " + r.movesText()
+	case ActionGitStage:
+		return "Git stage " + r.pathsText()
+	case ActionGitUnstage:
+		return "Git unstage " + r.pathsText()
+	default:
+		return fmt.Sprintf("%s %s", r.Action, r.Target)
+	}
+}
+
+func (r Record) pathsText() string {
+	if len(r.Paths) == 1 {
+		return r.Paths[0]
+	}
+	return fmt.Sprintf("%d items", len(r.Paths))
+}
+
+func (r Record) movesText() string {
+	if len(r.Moves) == 1 {
+		return r.Moves[0].From + " → " + r.Moves[0].To
+	}
+	return fmt.Sprintf("%d items", len(r.Moves))
+}
+
+// Dirs returns distinct parent directories of all paths in the record.
+func (r Record) Dirs() []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		dir := path.Dir(p)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, p := range r.Paths {
+		add(p)
+	}
+	for _, m := range r.Moves {
+		add(m.From)
+		add(m.To)
+	}
+	return dirs
+}
+
+// InverseMoves returns moves that revert the record's moves.
+func (r Record) InverseMoves() []Move {
+	inverse := make([]Move, len(r.Moves))
+	for i, m := range r.Moves {
+		inverse[len(r.Moves)-1-i] = Move{From: m.To, To: m.From}
+	}
+	return inverse
+}
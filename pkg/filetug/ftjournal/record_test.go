@@ -0,0 +1,64 @@
+package ftjournal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord_Title(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		record   Record
+		expected string
+	}{
+		{Record{Action: ActionCreateDir, Paths: []string{"/a/b"}}, "Create dir /a/b"},
+		{Record{Action: ActionCreateFile, Paths: []string{"/a/b.txt"}}, "Create file /a/b.txt"},
+		{Record{Action: ActionWriteFile, Paths: []string{"/a/SHA256SUMS"}}, "Write file /a/SHA256SUMS"},
+		{Record{Action: ActionArchive, Paths: []string{"/a/b.zip"}}, "Archive /a/b.zip"},
+		{Record{Action: ActionExtract, Paths: []string{"/a", "/a/b"}}, "Extract 2 items"},
+		{Record{Action: ActionDelete, Paths: []string{"/a", "/b"}}, "Delete 2 items"},
+		{Record{Action: ActionRename, Moves: []Move{{From: "/a/x", To: "/a/y"}}}, "Rename /a/x → /a/y"},
+		{Record{Action: ActionCopy, Moves: []Move{{}, {}, {}}}, "Copy 3 items"},
+		{Record{Action: ActionMove, Moves: []Move{{From: "/a", To: "/b/a"}}}, "Move /a → /b/a"},
+		{Record{Action: ActionHardLink, Moves: []Move{{From: "/a", To: "/b/a"}}}, "Hard link /a → /b/a"},
+		{Record{Action: ActionSymlink, Moves: []Move{{}, {}}}, "Symlink 2 items"},
+		{Record{Action: ActionGitStage, Paths: []string{"/r/f"}}, "Git stage /r/f"},
+		{Record{Action: ActionGitUnstage, Paths: []string{"/r/f"}}, "Git unstage /r/f"},
+		{Record{Action: ActionUndo, Target: "x1"}, "undo x1"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.record.Title())
+	}
+}
+
+func TestRecord_CanUndoRedo(t *testing.T) {
+	t.Parallel()
+	for _, action := range []Action{
+		ActionCreateDir, ActionCreateFile, ActionRename, ActionMove, ActionHardLink, ActionSymlink, ActionGitStage, ActionGitUnstage,
+	} {
+		r := Record{Action: action}
+		assert.True(t, r.CanUndo(), action)
+		assert.True(t, r.CanRedo(), action)
+	}
+	assert.False(t, Record{Action: ActionDelete}.CanUndo())
+	assert.False(t, Record{Action: ActionDelete}.CanRedo())
+	assert.True(t, Record{Action: ActionCopy}.CanUndo())
+	assert.False(t, Record{Action: ActionCopy}.CanRedo())
+	assert.True(t, Record{Action: ActionWriteFile}.CanUndo())
+	assert.False(t, Record{Action: ActionWriteFile}.CanRedo(), "generated content can't be recreated")
+	assert.True(t, Record{Action: ActionArchive}.CanUndo())
+	assert.False(t, Record{Action: ActionArchive}.CanRedo())
+	assert.True(t, Record{Action: ActionExtract}.CanUndo())
+	assert.False(t, Record{Action: ActionExtract}.CanRedo())
+}
+
+func TestRecord_DirsAndInverseMoves(t *testing.T) {
+	t.Parallel()
+	r := Record{
+		Paths: []string{"/a/1", "/a/2"},
+		Moves: []Move{{From: "/a/3", To: "/b/3"}, {From: "/c/4", To: "/c/5"}},
+	}
+	assert.Equal(t, []string{"/a", "/b", "/c"}, r.Dirs())
+	assert.Equal(t, []Move{{From: "/c/5", To: "/c/4"}, {From: "/b/3", To: "/a/3"}}, r.InverseMoves())
+}
@@ -0,0 +1,167 @@
+// Package ftlists keeps curated lists: named collections of files & directories from any store
+// that a user puts together to come back to them or to process them as a whole.
+package ftlists
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrEmptyName     = errors.New("list name is empty")
+	ErrDuplicateName = errors.New("list with the same name already exists")
+)
+
+// Item is an entry of a list. Items can come from any directory of any store.
+type Item struct {
+	Store string `yaml:"store"`
+	Path  string `yaml:"path"`
+	IsDir bool   `yaml:"dir,omitempty"`
+}
+
+func (i Item) key() string {
+	return i.Store + i.Path
+}
+
+// List is a named collection of items. Filter is a query in the ftquery syntax applied when the list is shown.
+type List struct {
+	Name   string `yaml:"name"`
+	Filter string `yaml:"filter,omitempty"`
+	Items  []Item `yaml:"items,omitempty"`
+}
+
+// Contains reports whether an item is in the list.
+func (l *List) Contains(item Item) bool {
+	return l.indexOf(item) >= 0
+}
+
+// Add appends items that are not in the list yet & returns how many have been added.
+func (l *List) Add(items ...Item) (added int) {
+	for _, item := range items {
+		if l.indexOf(item) < 0 {
+			l.Items = append(l.Items, item)
+			added++
+		}
+	}
+	return added
+}
+
+// Remove removes an item from the list.
+func (l *List) Remove(item Item) bool {
+	i := l.indexOf(item)
+	if i < 0 {
+		return false
+	}
+	l.Items = slices.Delete(l.Items, i, i+1)
+	return true
+}
+
+func (l *List) indexOf(item Item) int {
+	key := item.key()
+	return slices.IndexFunc(l.Items, func(existing Item) bool { return existing.key() == key })
+}
+
+// IndexOf returns the index of a list with the given name, -1 if there is none.
+func IndexOf(lists []List, name string) int {
+	return slices.IndexFunc(lists, func(l List) bool { return l.Name == name })
+}
+
+// Put adds a list or replaces the one named oldName, items of the replaced list are kept.
+// Names are trimmed & must be unique.
+func Put(lists []List, oldName string, l List) ([]List, error) {
+	l.Name = strings.TrimSpace(l.Name)
+	l.Filter = strings.TrimSpace(l.Filter)
+	if l.Name == "" {
+		return lists, ErrEmptyName
+	}
+	if i := IndexOf(lists, l.Name); i >= 0 && (oldName == "" || lists[i].Name != oldName) {
+		return lists, fmt.Errorf("%s: %w", l.Name, ErrDuplicateName)
+	}
+	result := slices.Clone(lists)
+	if i := IndexOf(result, oldName); oldName != "" && i >= 0 {
+		l.Items = result[i].Items
+		result[i] = l
+		return result, nil
+	}
+	return append(result, l), nil
+}
+
+// Delete removes a list by name.
+func Delete(lists []List, name string) []List {
+	return slices.DeleteFunc(slices.Clone(lists), func(l List) bool { return l.Name == name })
+}
+
+// ApplyRecord keeps items pointing to their entries after they are deleted, renamed or moved.
+// Returns true if any list has been changed.
+func ApplyRecord(lists []List, r ftjournal.Record) bool {
+	var changed bool
+	for li := range lists {
+		l := &lists[li]
+		switch r.Action {
+		case ftjournal.ActionDelete:
+			n := len(l.Items)
+			l.Items = slices.DeleteFunc(l.Items, func(item Item) bool {
+				return item.Store == r.Store && isPathOrDescendant(item.Path, r.Paths...)
+			})
+			changed = changed || len(l.Items) != n
+		case ftjournal.ActionRename, ftjournal.ActionMove:
+			// Moves of a batch are applied simultaneously, e.g. a swap of names.
+			for i, item := range l.Items {
+				if item.Store != r.Store {
+					continue
+				}
+				for _, m := range r.Moves {
+					if isPathOrDescendant(item.Path, m.From) {
+						l.Items[i].Path = m.To + strings.TrimPrefix(item.Path, m.From)
+						changed = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return changed
+}
+
+func isPathOrDescendant(p string, parents ...string) bool {
+	for _, parent := range parents {
+		if p == parent || strings.HasPrefix(p, strings.TrimSuffix(parent, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads lists persisted by Save. A missing file means no lists.
+func Load(filePath string) ([]List, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lists []List
+	if err = yaml.Unmarshal(data, &lists); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return lists, nil
+}
+
+func Save(filePath string, lists []List) error {
+	data, err := yaml.Marshal(lists)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
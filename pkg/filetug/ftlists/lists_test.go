@@ -0,0 +1,97 @@
+package ftlists
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList_AddRemove(t *testing.T) {
+	t.Parallel()
+	var l List
+	a := Item{Store: "file:", Path: "/a"}
+	b := Item{Store: "file:", Path: "/b", IsDir: true}
+	assert.Equal(t, 2, l.Add(a, b, a))
+	assert.Equal(t, 0, l.Add(Item{Store: "file:", Path: "/a"}), "items are identified by store & path")
+	assert.Equal(t, 1, l.Add(Item{Store: "ftp://example.com", Path: "/a"}))
+	assert.True(t, l.Contains(b))
+	assert.True(t, l.Remove(a))
+	assert.False(t, l.Remove(a))
+	assert.False(t, l.Contains(a))
+	assert.Len(t, l.Items, 2)
+}
+
+func TestPut(t *testing.T) {
+	t.Parallel()
+	lists, err := Put(nil, "", List{Name: " Docs ", Filter: " ext:md "})
+	require.NoError(t, err)
+	assert.Equal(t, []List{{Name: "Docs", Filter: "ext:md"}}, lists)
+
+	_, err = Put(lists, "", List{Name: " "})
+	assert.ErrorIs(t, err, ErrEmptyName)
+	_, err = Put(lists, "", List{Name: "Docs"})
+	assert.ErrorIs(t, err, ErrDuplicateName)
+
+	lists[0].Add(Item{Store: "file:", Path: "/README.md"})
+	lists, err = Put(lists, "", List{Name: "Work"})
+	require.NoError(t, err)
+	_, err = Put(lists, "Work", List{Name: "Docs"})
+	assert.ErrorIs(t, err, ErrDuplicateName)
+
+	renamed, err := Put(lists, "Docs", List{Name: "Notes"})
+	require.NoError(t, err)
+	assert.Equal(t, "Notes", renamed[0].Name)
+	assert.Empty(t, renamed[0].Filter)
+	assert.Len(t, renamed[0].Items, 1, "items are kept")
+	assert.Equal(t, "Docs", lists[0].Name, "the original slice is not changed")
+
+	assert.Equal(t, 1, IndexOf(renamed, "Work"))
+	assert.Equal(t, -1, IndexOf(renamed, "Docs"))
+	renamed = Delete(renamed, "Notes")
+	assert.Equal(t, []List{{Name: "Work"}}, renamed)
+}
+
+func TestApplyRecord(t *testing.T) {
+	t.Parallel()
+	lists := []List{
+		{Name: "a", Items: []Item{{Store: "file:", Path: "/a/1"}, {Store: "file:", Path: "/b"}}},
+		{Name: "b", Items: []Item{{Store: "ftp://h", Path: "/a/1"}, {Store: "file:", Path: "/ab"}}},
+	}
+	assert.False(t, ApplyRecord(lists, ftjournal.Record{Store: "file:", Action: ftjournal.ActionCreateDir, Paths: []string{"/a"}}))
+	assert.True(t, ApplyRecord(lists, ftjournal.Record{Store: "file:", Action: ftjournal.ActionMove, Moves: []ftjournal.Move{
+		{From: "/a", To: "/c"},
+		{From: "/b", To: "/a"},
+	}}))
+	assert.Equal(t, []Item{{Store: "file:", Path: "/c/1"}, {Store: "file:", Path: "/a"}}, lists[0].Items,
+		"moves are applied simultaneously")
+	assert.Equal(t, []Item{{Store: "ftp://h", Path: "/a/1"}, {Store: "file:", Path: "/ab"}}, lists[1].Items)
+
+	assert.True(t, ApplyRecord(lists, ftjournal.Record{Store: "file:", Action: ftjournal.ActionDelete, Paths: []string{"/c", "/ab"}}))
+	assert.Equal(t, []Item{{Store: "file:", Path: "/a"}}, lists[0].Items)
+	assert.Equal(t, []Item{{Store: "ftp://h", Path: "/a/1"}}, lists[1].Items)
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", "lists.yaml")
+	lists, err := Load(filePath)
+	require.NoError(t, err)
+	assert.Nil(t, lists)
+
+	expected := []List{
+		{Name: "Docs", Filter: "ext:md", Items: []Item{{Store: "file:", Path: "/docs", IsDir: true}}},
+		{Name: "Empty"},
+	}
+	require.NoError(t, Save(filePath, expected))
+	lists, err = Load(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, expected, lists)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("a: [b"), 0o644))
+	_, err = Load(filePath)
+	assert.Error(t, err)
+}
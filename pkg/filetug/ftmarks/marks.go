@@ -0,0 +1,173 @@
+// Package ftmarks keeps vim-style marks: a letter bound to a directory of a store & optionally a file in it.
+// Lower-case marks live for a session, upper-case (global) marks are persisted.
+package ftmarks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"gopkg.in/yaml.v3"
+)
+
+// Mark is a position to jump back to.
+type Mark struct {
+	Letter rune   `yaml:"letter"`
+	Name   string `yaml:"name,omitempty"` // a label given by a user, empty by default
+	Store  string `yaml:"store"`
+	Dir    string `yaml:"dir"`
+	File   string `yaml:"file,omitempty"` // the name of a file selected in the dir
+}
+
+// IsLetter reports whether a rune can be used as a mark.
+func IsLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || IsGlobal(r)
+}
+
+// IsGlobal reports whether a mark is global & is persisted across sessions.
+func IsGlobal(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+// Path returns the marked file or dir.
+func (m Mark) Path() string {
+	if m.File == "" {
+		return m.Dir
+	}
+	return strings.TrimSuffix(m.Dir, "/") + "/" + m.File
+}
+
+// Find returns a mark by letter.
+func Find(marks []Mark, letter rune) (Mark, bool) {
+	if i := slices.IndexFunc(marks, func(m Mark) bool { return m.Letter == letter }); i >= 0 {
+		return marks[i], true
+	}
+	return Mark{}, false
+}
+
+// Set adds a mark or replaces the one with the same letter, marks are kept ordered by letter.
+func Set(marks []Mark, m Mark) []Mark {
+	result := Delete(marks, m.Letter)
+	result = append(result, m)
+	slices.SortFunc(result, func(a, b Mark) int { return int(a.Letter) - int(b.Letter) })
+	return result
+}
+
+// Delete removes a mark by letter.
+func Delete(marks []Mark, letter rune) []Mark {
+	return slices.DeleteFunc(slices.Clone(marks), func(m Mark) bool { return m.Letter == letter })
+}
+
+// Rename sets the name of a mark, an empty name resets it.
+func Rename(marks []Mark, letter rune, name string) []Mark {
+	result := slices.Clone(marks)
+	for i := range result {
+		if result[i].Letter == letter {
+			result[i].Name = strings.TrimSpace(name)
+		}
+	}
+	return result
+}
+
+// ApplyRecord keeps marks pointing to their dirs & files after they are renamed or moved.
+// Marks of deleted entries are kept like in vim. Returns true if any mark has been changed.
+func ApplyRecord(marks []Mark, r ftjournal.Record) bool {
+	if r.Action != ftjournal.ActionRename && r.Action != ftjournal.ActionMove {
+		return false
+	}
+	var changed bool
+	for i, m := range marks {
+		if m.Store != r.Store {
+			continue
+		}
+		// Moves of a batch are applied simultaneously, e.g. a swap of names.
+		for _, move := range r.Moves {
+			if p := m.Path(); m.File != "" && p == move.From {
+				marks[i].Dir, marks[i].File = splitPath(move.To)
+				changed = true
+				break
+			}
+			if isPathOrDescendant(m.Dir, move.From) {
+				marks[i].Dir = move.To + strings.TrimPrefix(m.Dir, move.From)
+				changed = true
+				break
+			}
+		}
+	}
+	return changed
+}
+
+func splitPath(p string) (dir, name string) {
+	i := strings.LastIndex(p, "/")
+	dir, name = p[:i], p[i+1:]
+	if dir == "" {
+		dir = "/"
+	}
+	return dir, name
+}
+
+func isPathOrDescendant(p string, parents ...string) bool {
+	for _, parent := range parents {
+		if p == parent || strings.HasPrefix(p, strings.TrimSuffix(parent, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Global returns marks that are persisted.
+func Global(marks []Mark) []Mark {
+	return slices.DeleteFunc(slices.Clone(marks), func(m Mark) bool { return !IsGlobal(m.Letter) })
+}
+
+type markFile struct {
+	Letter string `yaml:"letter"`
+	Name   string `yaml:"name,omitempty"`
+	Store  string `yaml:"store"`
+	Dir    string `yaml:"dir"`
+	File   string `yaml:"file,omitempty"`
+}
+
+// Load reads global marks persisted by Save. A missing file means no marks.
+func Load(filePath string) ([]Mark, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var list []markFile
+	if err = yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	var marks []Mark
+	for _, f := range list {
+		letter := []rune(f.Letter)
+		if len(letter) != 1 || !IsGlobal(letter[0]) {
+			return nil, fmt.Errorf("failed to parse %s: invalid mark %q", filePath, f.Letter)
+		}
+		marks = Set(marks, Mark{Letter: letter[0], Name: f.Name, Store: f.Store, Dir: f.Dir, File: f.File})
+	}
+	return marks, nil
+}
+
+// Save writes global marks, session marks are skipped.
+func Save(filePath string, marks []Mark) error {
+	list := make([]markFile, 0, len(marks))
+	for _, m := range Global(marks) {
+		list = append(list, markFile{Letter: string(m.Letter), Name: m.Name, Store: m.Store, Dir: m.Dir, File: m.File})
+	}
+	data, err := yaml.Marshal(list)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
@@ -0,0 +1,88 @@
+package ftmarks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsLetter(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsLetter('a'))
+	assert.True(t, IsLetter('Z'))
+	assert.False(t, IsLetter('1'))
+	assert.False(t, IsLetter('й'))
+	assert.True(t, IsGlobal('A'))
+	assert.False(t, IsGlobal('a'))
+}
+
+func TestSetFindDeleteRename(t *testing.T) {
+	t.Parallel()
+	marks := Set(nil, Mark{Letter: 'b', Store: "file:", Dir: "/b"})
+	marks = Set(marks, Mark{Letter: 'A', Store: "file:", Dir: "/a", File: "x.txt"})
+	marks = Set(marks, Mark{Letter: 'b', Store: "file:", Dir: "/c"})
+	assert.Equal(t, []Mark{
+		{Letter: 'A', Store: "file:", Dir: "/a", File: "x.txt"},
+		{Letter: 'b', Store: "file:", Dir: "/c"},
+	}, marks)
+	m, ok := Find(marks, 'A')
+	assert.True(t, ok)
+	assert.Equal(t, "/a/x.txt", m.Path())
+	_, ok = Find(marks, 'z')
+	assert.False(t, ok)
+
+	renamed := Rename(marks, 'b', " work ")
+	assert.Equal(t, "work", renamed[1].Name)
+	assert.Empty(t, marks[1].Name, "the original slice is not changed")
+	assert.Equal(t, []Mark{{Letter: 'b', Store: "file:", Dir: "/c", Name: "work"}}, Delete(renamed, 'A'))
+	assert.Equal(t, []Mark{marks[0]}, Global(marks))
+}
+
+func TestApplyRecord(t *testing.T) {
+	t.Parallel()
+	marks := []Mark{
+		{Letter: 'a', Store: "file:", Dir: "/a/b", File: "f.txt"},
+		{Letter: 'b', Store: "file:", Dir: "/x", File: "f.txt"},
+		{Letter: 'c', Store: "ftp://h", Dir: "/a"},
+		{Letter: 'd', Store: "file:", Dir: "/ab"},
+	}
+	assert.False(t, ApplyRecord(marks, ftjournal.Record{Store: "file:", Action: ftjournal.ActionDelete, Paths: []string{"/a"}}))
+	assert.True(t, ApplyRecord(marks, ftjournal.Record{Store: "file:", Action: ftjournal.ActionMove, Moves: []ftjournal.Move{
+		{From: "/a", To: "/c"},
+		{From: "/x/f.txt", To: "/g.txt"},
+	}}))
+	assert.Equal(t, []Mark{
+		{Letter: 'a', Store: "file:", Dir: "/c/b", File: "f.txt"},
+		{Letter: 'b', Store: "file:", Dir: "/", File: "g.txt"},
+		{Letter: 'c', Store: "ftp://h", Dir: "/a"},
+		{Letter: 'd', Store: "file:", Dir: "/ab"},
+	}, marks)
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", "marks.yaml")
+	marks, err := Load(filePath)
+	require.NoError(t, err)
+	assert.Nil(t, marks)
+
+	require.NoError(t, Save(filePath, []Mark{
+		{Letter: 'A', Name: "docs", Store: "file:", Dir: "/docs", File: "README.md"},
+		{Letter: 'a', Store: "file:", Dir: "/tmp"},
+	}))
+	marks, err = Load(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, []Mark{{Letter: 'A', Name: "docs", Store: "file:", Dir: "/docs", File: "README.md"}}, marks,
+		"session marks are not persisted")
+
+	require.NoError(t, os.WriteFile(filePath, []byte("- letter: a\n  dir: /\n"), 0o644))
+	_, err = Load(filePath)
+	assert.ErrorContains(t, err, "invalid mark")
+	require.NoError(t, os.WriteFile(filePath, []byte("a: [b"), 0o644))
+	_, err = Load(filePath)
+	assert.Error(t, err)
+}
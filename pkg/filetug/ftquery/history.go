@@ -0,0 +1,63 @@
+package ftquery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxHistory is how many recent queries are kept.
+const MaxHistory = 50
+
+// LoadHistory reads recent queries persisted by SaveHistory, the most recent first. A missing file means no history.
+func LoadHistory(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var history []string
+	if err = yaml.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return history, nil
+}
+
+// SaveHistory writes recent queries.
+func SaveHistory(filePath string, history []string) error {
+	data, err := yaml.Marshal(history)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
+
+// AddToHistory puts a query first, removes its older duplicate & keeps at most MaxHistory queries.
+// Empty queries are not added.
+func AddToHistory(history []string, query string) []string {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return history
+	}
+	result := make([]string, 0, min(len(history)+1, MaxHistory))
+	result = append(result, query)
+	for _, q := range history {
+		if len(result) == MaxHistory {
+			break
+		}
+		if q != query {
+			result = append(result, q)
+		}
+	}
+	return slices.Clip(result)
+}
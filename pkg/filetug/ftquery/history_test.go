@@ -0,0 +1,48 @@
+package ftquery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_SaveLoad(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", "queries.yaml")
+	history, err := LoadHistory(filePath)
+	require.NoError(t, err)
+	assert.Nil(t, history)
+
+	require.NoError(t, SaveHistory(filePath, []string{"ext:go", `name:"a b"`}))
+	history, err = LoadHistory(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ext:go", `name:"a b"`}, history)
+}
+
+func TestLoadHistory_Invalid(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "queries.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("a: [b"), 0o644))
+	_, err := LoadHistory(filePath)
+	assert.Error(t, err)
+}
+
+func TestAddToHistory(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"a"}, AddToHistory(nil, " a "))
+	assert.Equal(t, []string{"b", "a"}, AddToHistory([]string{"b", "a"}, "  "))
+	assert.Equal(t, []string{"a", "b", "c"}, AddToHistory([]string{"b", "a", "c"}, "a"))
+
+	var long []string
+	for i := range MaxHistory {
+		long = append(long, fmt.Sprint(i))
+	}
+	history := AddToHistory(long, "new")
+	assert.Len(t, history, MaxHistory)
+	assert.Equal(t, "new", history[0])
+	assert.Equal(t, fmt.Sprint(MaxHistory-2), history[MaxHistory-1])
+}
@@ -0,0 +1,352 @@
+package ftquery
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/filetug/masks"
+)
+
+// Fields of terms.
+const (
+	FieldExt      = "ext"
+	FieldName     = "name"
+	FieldPath     = "path"
+	FieldSize     = "size"
+	FieldModified = "modified"
+	FieldAge      = "age" // an alias of modified
+	FieldType     = "type"
+	FieldGit      = "git"
+)
+
+const keywordHidden = "hidden"
+
+// operators are ordered so longer ones are cut first.
+var operators = []string{"<=", ">=", ":", "~", "<", ">", "="}
+
+var comparisons = []string{"<=", ">=", "<", ">", "="}
+
+// SyntaxError tells where a query is invalid, Pos is a byte offset in the query.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Msg)
+}
+
+func syntaxError(pos int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokNot
+	tokOr
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind    tokenKind
+	pos     int
+	text    string // unquoted text of a word
+	quoteAt int    // offset in text where a quoted part starts, -1 if there is none
+}
+
+// tokenize splits a query into words, operators & parentheses.
+// A word can have quoted parts, e.g. name:"my file*", with \" for a quote.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i})
+			i++
+		case c == '|':
+			tokens = append(tokens, token{kind: tokOr, pos: i})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{kind: tokNot, pos: i})
+			i++
+		default:
+			word := token{kind: tokWord, pos: i, quoteAt: -1}
+			var sb strings.Builder
+			for i < len(query) && !strings.ContainsRune(" \t()|", rune(query[i])) {
+				if query[i] != '"' {
+					sb.WriteByte(query[i])
+					i++
+					continue
+				}
+				if word.quoteAt < 0 {
+					word.quoteAt = sb.Len()
+				}
+				start := i
+				for i++; ; i++ {
+					if i >= len(query) {
+						return nil, syntaxError(start, "unterminated quote")
+					}
+					if query[i] == '\\' && i+1 < len(query) && query[i+1] == '"' {
+						sb.WriteByte('"')
+						i++
+						continue
+					}
+					if query[i] == '"' {
+						i++
+						break
+					}
+					sb.WriteByte(query[i])
+				}
+			}
+			word.text = sb.String()
+			if word.text == "OR" && word.quoteAt < 0 {
+				word.kind = tokOr
+			}
+			tokens = append(tokens, word)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(query)}), nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// Parse parses a query, an empty query matches everything. Errors are of *SyntaxError type.
+func Parse(query string) (*Query, error) {
+	q := &Query{Text: query}
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return q, nil
+	}
+	if q.Root, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, syntaxError(t.pos, "unexpected )")
+	}
+	return q, nil
+}
+
+// MustParse is like Parse but panics if the query is invalid.
+func MustParse(query string) *Query {
+	q, err := Parse(query)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func (p *parser) parseOr() (Node, error) {
+	var nodes Or
+	for {
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+		if p.peek().kind != tokOr {
+			break
+		}
+		p.next()
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var nodes And
+	for {
+		switch t := p.peek(); t.kind {
+		case tokEOF, tokOr, tokRParen:
+			switch {
+			case len(nodes) == 1:
+				return nodes[0], nil
+			case len(nodes) > 1:
+				return nodes, nil
+			case t.kind == tokOr:
+				return nil, syntaxError(t.pos, "unexpected OR")
+			case t.kind == tokRParen:
+				return nil, syntaxError(t.pos, "unexpected )")
+			default:
+				return nil, syntaxError(t.pos, "expected a term")
+			}
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	switch t := p.next(); t.kind {
+	case tokNot:
+		if next := p.peek(); next.kind != tokWord && next.kind != tokNot && next.kind != tokLParen {
+			return nil, syntaxError(next.pos, "expected a term after !")
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: n}, nil
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, syntaxError(t.pos, "missing )")
+		}
+		p.next()
+		return n, nil
+	default:
+		return parseTerm(t)
+	}
+}
+
+// cutField splits a word like "size>1M" into a field, an operator & a value.
+// Only an unquoted part of the word can have a field.
+func cutField(t token) (field, op, value string, ok bool) {
+	key := t.text
+	if t.quoteAt >= 0 {
+		key = t.text[:t.quoteAt]
+	}
+	i := strings.IndexFunc(key, func(r rune) bool { return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') })
+	if i <= 0 {
+		return "", "", "", false
+	}
+	for _, op = range operators {
+		if strings.HasPrefix(key[i:], op) {
+			return strings.ToLower(key[:i]), op, t.text[i+len(op):], true
+		}
+	}
+	return "", "", "", false
+}
+
+func parseTerm(t token) (*Term, error) {
+	field, op, value, ok := cutField(t)
+	if !ok {
+		if t.quoteAt < 0 && strings.EqualFold(t.text, keywordHidden) {
+			return compileTerm(t, &Term{Field: keywordHidden}, regexPattern(`^\.`))
+		}
+		if t.text == "" {
+			return nil, syntaxError(t.pos, "empty text")
+		}
+		return compileTerm(t, &Term{Value: t.text}, regexPattern(`(?i)`+regexp.QuoteMeta(t.text)))
+	}
+	term := &Term{Field: field, Op: op, Value: value}
+	if value == "" {
+		return nil, syntaxError(t.pos, "empty value of %s", field)
+	}
+	invalidOp := func() (*Term, error) {
+		return nil, syntaxError(t.pos, "%s doesn't support %q", field, op)
+	}
+	var pattern masks.Pattern
+	switch field {
+	case FieldExt:
+		if op != ":" {
+			return invalidOp()
+		}
+		var exts []string
+		for _, ext := range strings.Split(value, ",") {
+			if ext = strings.TrimPrefix(strings.TrimSpace(ext), "."); ext != "" {
+				exts = append(exts, regexp.QuoteMeta(ext))
+			}
+		}
+		if len(exts) == 0 {
+			return nil, syntaxError(t.pos, "empty value of %s", field)
+		}
+		pattern = regexPattern(`(?i)\.(` + strings.Join(exts, "|") + `)$`)
+	case FieldName, FieldPath:
+		switch op {
+		case ":":
+			pattern = masks.Pattern{Kind: masks.KindGlob, Value: value}
+		case "~":
+			if _, err := regexp.Compile(value); err != nil {
+				return nil, syntaxError(t.pos, "invalid regex %q", value)
+			}
+			if field == FieldPath {
+				pattern = masks.Pattern{Kind: masks.KindPath, Regex: value}
+			} else {
+				pattern = regexPattern(value)
+			}
+		default:
+			return invalidOp()
+		}
+	case FieldSize, FieldModified, FieldAge:
+		kind := masks.KindSize
+		if field != FieldSize {
+			kind = masks.KindAge
+		}
+		switch {
+		case op == ":":
+			pattern = masks.Pattern{Kind: kind, Value: value}
+		case isComparison(op):
+			pattern = masks.Pattern{Kind: kind, Value: op + value}
+		default:
+			return invalidOp()
+		}
+	case FieldType, FieldGit:
+		if op != ":" {
+			return invalidOp()
+		}
+		kind := masks.KindType
+		if field == FieldGit {
+			kind = masks.KindGit
+		}
+		pattern = masks.Pattern{Kind: kind, Value: value}
+	default:
+		return nil, syntaxError(t.pos, "unknown field %q", field)
+	}
+	return compileTerm(t, term, pattern)
+}
+
+// compileTerm compiles a pattern of a term so the term can be matched concurrently.
+func compileTerm(t token, term *Term, pattern masks.Pattern) (*Term, error) {
+	pattern.Type = masks.Required
+	if err := pattern.Compile(); err != nil {
+		return nil, syntaxError(t.pos, "%s: %v", term.Field, err)
+	}
+	term.pattern = pattern
+	return term, nil
+}
+
+func isComparison(op string) bool {
+	return slices.Contains(comparisons, op)
+}
+
+func regexPattern(regex string) masks.Pattern {
+	return masks.Pattern{Type: masks.Required, Kind: masks.KindRegex, Regex: regex}
+}
@@ -0,0 +1,216 @@
+// Package ftquery implements a small query language to filter entries of a dir, e.g.
+//
+//	ext:go,md size>1M modified<3d name~test !hidden git:modified
+//
+// Terms separated by spaces must all match, OR (or |) matches any of terms,
+// ! negates a term & parentheses group terms. A query is parsed into a tree of nodes
+// that can be combined with other nodes. Terms are evaluated by masks patterns,
+// so values are written the same way as in masks, e.g. sizes "1.5MB" & ages "2w".
+//
+// Terms:
+//
+//	ext:go,md            extensions, case-insensitive
+//	name:*.go name~re    glob or regex on the name
+//	path:a/** path~re    glob or regex on the path relative to the dir
+//	size>1M size:1K-2K   size of a file, operators: < <= > >= =
+//	modified<3d age>1w   time since modification
+//	type:file,dir        types of entries: file, dir, symlink
+//	git:modified         git states: modified, untracked, ignored
+//	hidden               names starting with a dot
+//	word "two words"     names containing a text, case-insensitive
+package ftquery
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/filetug/masks"
+)
+
+// Node is a node of a parsed query.
+type Node interface {
+	// Match reports whether an entry matches, info can be nil to be read from the entry when needed.
+	// Pass masks.Entry for path & git terms.
+	Match(entry os.DirEntry, info os.FileInfo) bool
+	// String returns the node as a query that parses to the same node.
+	String() string
+}
+
+// And matches entries matching all of its nodes.
+type And []Node
+
+// Or matches entries matching any of its nodes.
+type Or []Node
+
+// Not matches entries not matching its node.
+type Not struct {
+	Node Node
+}
+
+// Term is a single condition, e.g. "size>1M" or a bare word.
+type Term struct {
+	Field string // empty for a bare word
+	Op    string // ":", "~" or a comparison operator
+	Value string
+	// pattern is compiled by the parser
+	pattern masks.Pattern
+}
+
+var (
+	_ Node = And(nil)
+	_ Node = Or(nil)
+	_ Node = Not{}
+	_ Node = (*Term)(nil)
+)
+
+func (a And) Match(entry os.DirEntry, info os.FileInfo) bool {
+	for _, n := range a {
+		if !n.Match(entry, info) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a And) String() string {
+	texts := make([]string, len(a))
+	for i, n := range a {
+		texts[i] = groupText(n)
+	}
+	return strings.Join(texts, " ")
+}
+
+func (o Or) Match(entry os.DirEntry, info os.FileInfo) bool {
+	for _, n := range o {
+		if n.Match(entry, info) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o Or) String() string {
+	texts := make([]string, len(o))
+	for i, n := range o {
+		text := n.String()
+		if _, isOr := n.(Or); isOr {
+			text = "(" + text + ")"
+		}
+		texts[i] = text
+	}
+	return strings.Join(texts, " OR ")
+}
+
+func (n Not) Match(entry os.DirEntry, info os.FileInfo) bool {
+	return !n.Node.Match(entry, info)
+}
+
+func (n Not) String() string {
+	return "!" + groupText(n.Node)
+}
+
+// groupText wraps nodes of several terms in parentheses.
+func groupText(n Node) string {
+	switch n.(type) {
+	case And, Or:
+		return "(" + n.String() + ")"
+	default:
+		return n.String()
+	}
+}
+
+func (t *Term) Match(entry os.DirEntry, info os.FileInfo) bool {
+	matched, _ := t.pattern.Match(entry, info)
+	return matched
+}
+
+func (t *Term) String() string {
+	if t.Op == "" && t.Field != "" {
+		return t.Field // a keyword
+	}
+	value := t.Value
+	if needsQuotes(value, t.Field == "") {
+		value = `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	return t.Field + t.Op + value
+}
+
+// needsQuotes reports whether a value would be parsed differently without quotes.
+func needsQuotes(value string, isWord bool) bool {
+	if value == "" || strings.ContainsAny(value, " \t()|\"") {
+		return true
+	}
+	return isWord && (strings.EqualFold(value, keywordHidden) || value == "OR" ||
+		strings.HasPrefix(value, "!") || strings.ContainsAny(value, ":~<>="))
+}
+
+// Query is a parsed query with its text.
+type Query struct {
+	Text string
+	Root Node // nil for an empty query
+}
+
+// Match reports whether an entry matches the query, an empty query matches everything.
+func (q *Query) Match(entry os.DirEntry, info os.FileInfo) bool {
+	return q.Root == nil || q.Root.Match(entry, info)
+}
+
+// IsEmpty reports whether the query has no terms.
+func (q *Query) IsEmpty() bool {
+	return q == nil || q.Root == nil
+}
+
+// String returns the normalized query, e.g. "ext:go size>1M".
+func (q *Query) String() string {
+	if q.Root == nil {
+		return ""
+	}
+	return q.Root.String()
+}
+
+// NeedsGitState reports whether entries should be passed as masks.Entry with a git state.
+func (q *Query) NeedsGitState() bool {
+	return q.hasTerm(func(t *Term) bool { return t.Field == FieldGit })
+}
+
+// MatchesDirs reports whether the query selects dirs by their type,
+// otherwise callers can keep dirs visible so the tree can still be navigated.
+func (q *Query) MatchesDirs() bool {
+	return q.hasTerm(func(t *Term) bool {
+		if t.Field != FieldType {
+			return false
+		}
+		return slices.ContainsFunc(strings.Split(t.Value, ","), func(s string) bool { return strings.TrimSpace(s) == "dir" })
+	})
+}
+
+func (q *Query) hasTerm(f func(t *Term) bool) bool {
+	var found bool
+	Walk(q.Root, func(n Node) {
+		if t, ok := n.(*Term); ok && f(t) {
+			found = true
+		}
+	})
+	return found
+}
+
+// Walk calls f for a node & all its descendants.
+func Walk(n Node, f func(Node)) {
+	if n == nil {
+		return
+	}
+	f(n)
+	switch n := n.(type) {
+	case And:
+		for _, child := range n {
+			Walk(child, f)
+		}
+	case Or:
+		for _, child := range n {
+			Walk(child, f)
+		}
+	case Not:
+		Walk(n.Node, f)
+	}
+}
@@ -0,0 +1,150 @@
+package ftquery
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/filetug/filetug/pkg/gitutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Match(t *testing.T) {
+	t.Parallel()
+	day := 24 * time.Hour
+	file := func(name string, size int64, age time.Duration) os.DirEntry {
+		return files.NewDirEntry(name, false, files.Size(size), files.ModTime(time.Now().Add(-age)))
+	}
+	mainGo := file("main.go", 100, time.Hour)
+	readme := file("README.md", 2<<20, 10*day)
+	hidden := file(".env", 10, day)
+	testGo := masks.Entry{DirEntry: file("query_test.go", 3<<20, 2*day), RelPath: "pkg/query_test.go", GitState: gitutils.FileModified}
+	dir := files.NewDirEntry("docs", true)
+	all := []os.DirEntry{mainGo, readme, hidden, testGo, dir}
+
+	tests := []struct {
+		query string
+		want  []os.DirEntry
+	}{
+		{query: "", want: all},
+		{query: "ext:go,md", want: []os.DirEntry{mainGo, readme, testGo}},
+		{query: "ext:.MD", want: []os.DirEntry{readme}},
+		{query: "size>1M", want: []os.DirEntry{readme, testGo}},
+		{query: "size:1M-2M", want: []os.DirEntry{readme}},
+		{query: "modified<3d", want: []os.DirEntry{mainGo, hidden, testGo}},
+		{query: "age:>3d", want: []os.DirEntry{readme}},
+		{query: "name~test", want: []os.DirEntry{testGo}},
+		{query: "name:*.go", want: []os.DirEntry{mainGo, testGo}},
+		{query: "path:pkg/*", want: []os.DirEntry{testGo}},
+		{query: "path~^pkg/", want: []os.DirEntry{testGo}},
+		{query: "hidden", want: []os.DirEntry{hidden}},
+		{query: "!hidden", want: []os.DirEntry{mainGo, readme, testGo, dir}},
+		{query: "git:modified", want: []os.DirEntry{testGo}},
+		{query: "type:dir", want: []os.DirEntry{dir}},
+		{query: "readme", want: []os.DirEntry{readme}},
+		{query: `"main.go"`, want: []os.DirEntry{mainGo}},
+		{query: "ext:go,md size>1M modified<3d name~test !hidden git:modified", want: []os.DirEntry{testGo}},
+		{query: "ext:md OR hidden", want: []os.DirEntry{readme, hidden}},
+		{query: "ext:go (size>1M | name:main*)", want: []os.DirEntry{mainGo, testGo}},
+		{query: "!(ext:go OR type:dir)", want: []os.DirEntry{readme, hidden}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			q, err := Parse(tt.query)
+			require.NoError(t, err)
+			var got []os.DirEntry
+			for _, entry := range all {
+				if q.Match(entry, nil) {
+					got = append(got, entry)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: `name:"abc`, want: "col 6: unterminated quote"},
+		{query: "sise>1M", want: `col 1: unknown field "sise"`},
+		{query: "ext~go", want: `col 1: ext doesn't support "~"`},
+		{query: "name>1", want: `col 1: name doesn't support ">"`},
+		{query: "size>", want: "col 1: empty value of size"},
+		{query: "ext:,", want: "col 1: empty value of ext"},
+		{query: "size>1X", want: `col 1: size: unknown size unit: "X"`},
+		{query: `name~"("`, want: `col 1: invalid regex "("`},
+		{query: "a (b", want: "col 3: missing )"},
+		{query: "a)", want: "col 2: unexpected )"},
+		{query: "OR a", want: "col 1: unexpected OR"},
+		{query: "a OR", want: "col 5: expected a term"},
+		{query: "a !", want: "col 4: expected a term after !"},
+		{query: "()", want: "col 2: unexpected )"},
+		{query: `""`, want: "col 1: empty text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(tt.query)
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "expected a syntax error, got %v", err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestQuery_String(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: ""},
+		{query: "  ext:go   Size>1M ", want: "ext:go size>1M"},
+		{query: "a | b c", want: "a OR b c"},
+		{query: "(a | b) c", want: "(a OR b) c"},
+		{query: "!(a b) !hidden", want: "!(a b) !hidden"},
+		{query: `name:"my file*" "hidden" "a:b" "OR"`, want: `name:"my file*" "hidden" "a:b" "OR"`},
+		{query: `"say \"hi\""`, want: `"say \"hi\""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			q := MustParse(tt.query)
+			assert.Equal(t, tt.want, q.String())
+			assert.Equal(t, tt.want, MustParse(q.String()).String(), "a normalized query should be stable")
+		})
+	}
+}
+
+func TestQuery_Flags(t *testing.T) {
+	t.Parallel()
+	assert.True(t, (*Query)(nil).IsEmpty())
+	assert.True(t, MustParse(" ").IsEmpty())
+	assert.False(t, MustParse("a").IsEmpty())
+	assert.True(t, MustParse("a OR !git:untracked").NeedsGitState())
+	assert.False(t, MustParse("ext:go").NeedsGitState())
+	assert.False(t, MustParse("type:file, dir").MatchesDirs(), "a space splits terms")
+	assert.True(t, MustParse("!type:file,dir").MatchesDirs())
+	assert.False(t, MustParse("type:file").MatchesDirs())
+	assert.Panics(t, func() { MustParse("(") })
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+	var fields []string
+	Walk(MustParse("!(ext:go OR size>1) hidden").Root, func(n Node) {
+		if term, ok := n.(*Term); ok {
+			fields = append(fields, term.Field)
+		}
+	})
+	assert.Equal(t, []string{FieldExt, FieldSize, "hidden"}, fields)
+}
@@ -0,0 +1,103 @@
+package ftrename
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+)
+
+// Change is a rename of a single entry within a directory.
+type Change struct {
+	Dir  string `json:"dir"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Inverse returns changes that revert the given ones.
+func Inverse(changes []Change) []Change {
+	inverse := make([]Change, len(changes))
+	for i, c := range changes {
+		inverse[len(changes)-1-i] = Change{Dir: c.Dir, From: c.To, To: c.From}
+	}
+	return inverse
+}
+
+// Undo reverts changes of the batch.
+func Undo(ctx context.Context, renamer files.Renamer, b Batch, report func(done, total int)) error {
+	return Apply(ctx, renamer, Inverse(b.Changes), report)
+}
+
+var timeNow = time.Now
+
+type step struct {
+	from, to string
+}
+
+// Apply renames entries in 2 phases - first to unique temporary names, then to the target names.
+// This allows swaps & rotations like a->b, b->a. If any step fails (or ctx is cancelled)
+// the already completed steps are rolled back, so either all changes are applied or none.
+// If the renamer implements files.Lstater, a target created after planning fails the batch instead of being replaced.
+// The report callback is called after each step with the number of completed & total steps.
+func Apply(ctx context.Context, renamer files.Renamer, changes []Change, report func(done, total int)) error {
+	lstater, _ := renamer.(files.Lstater)
+	prefix := ".ftrename-" + strconv.FormatInt(timeNow().UnixNano(), 36) + "-"
+	steps := make([]step, 0, len(changes)*2)
+	for i, c := range changes {
+		tmp := path.Join(c.Dir, prefix+strconv.Itoa(i))
+		steps = append(steps, step{from: path.Join(c.Dir, c.From), to: tmp})
+	}
+	for i, c := range changes {
+		tmp := path.Join(c.Dir, prefix+strconv.Itoa(i))
+		steps = append(steps, step{from: tmp, to: path.Join(c.Dir, c.To)})
+	}
+	for i, s := range steps {
+		err := ctx.Err()
+		if err == nil && i >= len(changes) && lstater != nil {
+			err = checkFree(ctx, lstater, s.to)
+		}
+		if err == nil {
+			err = renamer.Rename(ctx, s.from, s.to)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to rename %s to %s: %w", s.from, s.to, err)
+			if rollbackErr := rollback(context.WithoutCancel(ctx), renamer, steps[:i]); rollbackErr != nil {
+				return errors.Join(err, rollbackErr)
+			}
+			return err
+		}
+		if report != nil {
+			report(i+1, len(steps))
+		}
+	}
+	return nil
+}
+
+// checkFree fails if an entry exists at the path, renaming over it would silently replace it.
+func checkFree(ctx context.Context, lstater files.Lstater, p string) error {
+	_, err := lstater.Lstat(ctx, p)
+	switch {
+	case err == nil:
+		return fs.ErrExist
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	default:
+		return err
+	}
+}
+
+func rollback(ctx context.Context, renamer files.Renamer, completed []step) error {
+	var errs []error
+	for i := len(completed) - 1; i >= 0; i-- {
+		s := completed[i]
+		if err := renamer.Rename(ctx, s.to, s.from); err != nil {
+			errs = append(errs, fmt.Errorf("rollback of %s failed: %w", s.from, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package ftrename
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644))
+	}
+}
+
+// readFiles returns map of file name to its content.
+func readFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	result := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		assert.NoError(t, err)
+		result[entry.Name()] = string(data)
+	}
+	return result
+}
+
+// failingRenamer fails calls with the given sequence numbers.
+type failingRenamer struct {
+	store  *osfile.Store
+	calls  int
+	failAt map[int]error
+}
+
+func (r *failingRenamer) Rename(ctx context.Context, oldPath, newPath string) error {
+	r.calls++
+	if err := r.failAt[r.calls]; err != nil {
+		return err
+	}
+	return r.store.Rename(ctx, oldPath, newPath)
+}
+
+// racingRenamer creates a file right after each rename, like another process would.
+type racingRenamer struct {
+	*osfile.Store
+	create string
+}
+
+func (r racingRenamer) Rename(ctx context.Context, oldPath, newPath string) error {
+	if err := r.Store.Rename(ctx, oldPath, newPath); err != nil {
+		return err
+	}
+	return os.WriteFile(r.create, []byte("new"), 0o644)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("swap", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, "a", "b", "c")
+		changes := []Change{
+			{Dir: dir, From: "a", To: "b"},
+			{Dir: dir, From: "b", To: "a"},
+			{Dir: dir, From: "c", To: "d"},
+		}
+		var reports []int
+		err := Apply(ctx, osfile.NewStore("/"), changes, func(done, total int) {
+			assert.Equal(t, 6, total)
+			reports = append(reports, done)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, reports)
+		assert.Equal(t, map[string]string{"a": "b", "b": "a", "d": "c"}, readFiles(t, dir))
+
+		err = Undo(ctx, osfile.NewStore("/"), Batch{Changes: changes}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "a", "b": "b", "c": "c"}, readFiles(t, dir))
+	})
+
+	t.Run("rollback_on_failure", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, "a", "b")
+		changes := []Change{
+			{Dir: dir, From: "a", To: "b"},
+			{Dir: dir, From: "b", To: "a"},
+		}
+		renamer := &failingRenamer{store: osfile.NewStore("/"), failAt: map[int]error{
+			4: errors.New("disk full"),
+		}}
+		err := Apply(ctx, renamer, changes, nil)
+		assert.ErrorContains(t, err, "disk full")
+		assert.Equal(t, map[string]string{"a": "a", "b": "b"}, readFiles(t, dir))
+	})
+
+	t.Run("rollback_failure", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, "a", "b")
+		changes := []Change{
+			{Dir: dir, From: "a", To: "x"},
+			{Dir: dir, From: "b", To: "y"},
+		}
+		renamer := &failingRenamer{store: osfile.NewStore("/"), failAt: map[int]error{
+			2: errors.New("disk full"),
+			3: errors.New("read-only"), // rollback of the 1st step
+		}}
+		err := Apply(ctx, renamer, changes, nil)
+		assert.ErrorContains(t, err, "disk full")
+		assert.ErrorContains(t, err, "rollback of")
+	})
+
+	t.Run("target_created_meanwhile", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, "a")
+		renamer := racingRenamer{Store: osfile.NewStore("/"), create: filepath.Join(dir, "b")}
+		err := Apply(ctx, renamer, []Change{{Dir: dir, From: "a", To: "b"}}, nil)
+		assert.ErrorIs(t, err, os.ErrExist)
+		assert.Equal(t, map[string]string{"a": "a", "b": "new"}, readFiles(t, dir), "rolled back, the new file is kept")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, "a")
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := Apply(cancelledCtx, osfile.NewStore("/"), []Change{{Dir: dir, From: "a", To: "b"}}, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, map[string]string{"a": "a"}, readFiles(t, dir))
+	})
+}
+
+func TestInverse(t *testing.T) {
+	t.Parallel()
+	changes := []Change{{Dir: "/d", From: "a", To: "b"}, {Dir: "/e", From: "c", To: "d"}}
+	assert.Equal(t, []Change{{Dir: "/e", From: "d", To: "c"}, {Dir: "/d", From: "b", To: "a"}}, Inverse(changes))
+}
@@ -0,0 +1,160 @@
+package ftrename
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+)
+
+// Batch is a set of changes applied together.
+type Batch struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Store   string    `json:"store,omitempty"` // root URL of the store the batch was applied to
+	Changes []Change  `json:"changes"`
+	Undone  bool      `json:"undone,omitempty"`
+}
+
+// NewBatch creates a batch with a unique ID.
+func NewBatch(changes []Change) Batch {
+	now := timeNow()
+	return Batch{
+		ID:      strconv.FormatInt(now.UnixNano(), 36),
+		Time:    now,
+		Changes: changes,
+	}
+}
+
+// Dirs returns distinct directories affected by the batch.
+func (b Batch) Dirs() []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, c := range b.Changes {
+		if !seen[c.Dir] {
+			seen[c.Dir] = true
+			dirs = append(dirs, c.Dir)
+		}
+	}
+	return dirs
+}
+
+// Journal persists applied batches as JSON lines so they can be rolled back later.
+type Journal struct {
+	filePath string
+}
+
+const journalFileName = "rename-journal.jsonl"
+
+var getDatatugUserDir = ftsettings.GetDatatugUserDir
+
+// ErrNothingToUndo is returned when the journal has no batches that can be undone.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+func NewJournal(filePath string) *Journal {
+	return &Journal{filePath: filePath}
+}
+
+// DefaultJournal returns the journal stored in the user's filetug directory.
+func DefaultJournal() (*Journal, error) {
+	dir, err := getDatatugUserDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewJournal(filepath.Join(dir, journalFileName)), nil
+}
+
+// Append adds a batch to the end of the journal.
+func (j *Journal) Append(b Batch) error {
+	line, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(j.filePath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads all batches from the journal. A missing journal is not an error.
+func (j *Journal) Load() ([]Batch, error) {
+	data, err := os.ReadFile(j.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var batches []Batch
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var b Batch
+		if err = json.Unmarshal(line, &b); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", j.filePath, lineNum, err)
+		}
+		batches = append(batches, b)
+	}
+	return batches, scanner.Err()
+}
+
+// LastUndoable returns the most recent batch that has not been undone yet.
+func (j *Journal) LastUndoable() (Batch, error) {
+	batches, err := j.Load()
+	if err != nil {
+		return Batch{}, err
+	}
+	for i := len(batches) - 1; i >= 0; i-- {
+		if !batches[i].Undone {
+			return batches[i], nil
+		}
+	}
+	return Batch{}, ErrNothingToUndo
+}
+
+// SetUndone flags a batch as undone so it is not offered for rollback again,
+// or clears the flag once the batch is redone.
+func (j *Journal) SetUndone(id string, undone bool) error {
+	batches, err := j.Load()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, b := range batches {
+		if b.ID == id {
+			b.Undone = undone
+		}
+		line, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	tmpPath := j.filePath + ".tmp"
+	if err = os.WriteFile(tmpPath, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, j.filePath)
+}
@@ -0,0 +1,78 @@
+package ftrename
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	t.Parallel()
+	j := NewJournal(filepath.Join(t.TempDir(), "sub", journalFileName))
+
+	batches, err := j.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, batches)
+	_, err = j.LastUndoable()
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	first := Batch{ID: "1", Changes: []Change{{Dir: "/a", From: "x", To: "y"}, {Dir: "/b", From: "x", To: "y"}, {Dir: "/a", From: "z", To: "w"}}}
+	second := Batch{ID: "2", Changes: []Change{{Dir: "/c", From: "x", To: "y"}}}
+	assert.NoError(t, j.Append(first))
+	assert.NoError(t, j.Append(second))
+	assert.Equal(t, []string{"/a", "/b"}, first.Dirs())
+
+	last, err := j.LastUndoable()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", last.ID)
+
+	assert.NoError(t, j.SetUndone("2", true))
+	last, err = j.LastUndoable()
+	assert.NoError(t, err)
+	assert.Equal(t, "1", last.ID)
+	assert.Equal(t, first.Changes, last.Changes)
+
+	batches, err = j.Load()
+	assert.NoError(t, err)
+	assert.Len(t, batches, 2)
+	assert.True(t, batches[1].Undone)
+
+	assert.NoError(t, j.SetUndone("2", false))
+	last, err = j.LastUndoable()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", last.ID)
+}
+
+func TestJournal_InvalidLine(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), journalFileName)
+	assert.NoError(t, os.WriteFile(filePath, []byte("{}\n\nnot json\n"), 0o644))
+	j := NewJournal(filePath)
+	_, err := j.Load()
+	assert.ErrorContains(t, err, ":3:")
+	assert.Error(t, j.SetUndone("x", true))
+}
+
+func TestNewBatch(t *testing.T) {
+	t.Parallel()
+	b := NewBatch([]Change{{Dir: "/a", From: "x", To: "y"}})
+	assert.NotEmpty(t, b.ID)
+	assert.False(t, b.Time.IsZero())
+}
+
+func TestDefaultJournal(t *testing.T) {
+	orig := getDatatugUserDir
+	t.Cleanup(func() { getDatatugUserDir = orig })
+
+	getDatatugUserDir = func() (string, error) { return "/home/u/.filetug", nil }
+	j, err := DefaultJournal()
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/u/.filetug/"+journalFileName, j.filePath)
+
+	getDatatugUserDir = func() (string, error) { return "", errors.New("no home") }
+	_, err = DefaultJournal()
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Package ftrename implements bulk renaming of files: planning new names
+// using regular expressions & templates, applying renames safely and keeping
+// a journal that allows to roll a batch back.
+package ftrename
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Item is an entry to be renamed.
+type Item struct {
+	Dir     string
+	Name    string
+	ModTime time.Time
+}
+
+// Options defines how new names are computed.
+// The regular expression is applied to the whole name first, then the template is rendered.
+type Options struct {
+	Find     string // regular expression, empty to skip find & replace
+	Replace  string // replacement that can reference groups as $1 or ${name}
+	Template string // e.g. "{name}_{n:03}{ext}", empty means "{name}{ext}"
+	Start    int    // first value of the {n} counter
+}
+
+type Status int
+
+const (
+	StatusUnchanged Status = iota
+	StatusOK
+	StatusInvalid
+	StatusCollision
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusUnchanged:
+		return "unchanged"
+	case StatusOK:
+		return "ok"
+	case StatusInvalid:
+		return "invalid"
+	case StatusCollision:
+		return "collision"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// Rename is a planned rename of a single item.
+type Rename struct {
+	Item
+	NewName string
+	Status  Status
+	Problem string
+}
+
+// Plan is a result of a dry run.
+type Plan struct {
+	Renames []Rename
+}
+
+// HasProblems reports whether any item has an invalid or colliding new name.
+func (p Plan) HasProblems() bool {
+	for _, r := range p.Renames {
+		if r.Status == StatusInvalid || r.Status == StatusCollision {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes returns renames that will be applied.
+func (p Plan) Changes() []Change {
+	var changes []Change
+	for _, r := range p.Renames {
+		if r.Status == StatusOK {
+			changes = append(changes, Change{Dir: r.Dir, From: r.Name, To: r.NewName})
+		}
+	}
+	return changes
+}
+
+// ExistsFunc reports whether an entry with the given name exists in the directory.
+type ExistsFunc = func(dir, name string) bool
+
+const maxNameLength = 255
+
+// NewPlan computes new names and validates them. It returns an error if options are invalid.
+func NewPlan(items []Item, options Options, exists ExistsFunc) (Plan, error) {
+	var find *regexp.Regexp
+	if options.Find != "" {
+		var err error
+		if find, err = regexp.Compile(options.Find); err != nil {
+			return Plan{}, fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	tmpl, err := parseTemplate(options.Template)
+	if err != nil {
+		return Plan{}, fmt.Errorf("invalid template: %w", err)
+	}
+	if len(tmpl) == 0 {
+		tmpl = template{{token: "name"}, {token: "ext"}}
+	}
+
+	plan := Plan{Renames: make([]Rename, len(items))}
+	sources := make(map[string]bool, len(items))
+	for _, item := range items {
+		sources[path.Join(item.Dir, item.Name)] = true
+	}
+	for i, item := range items {
+		newName := item.Name
+		if find != nil {
+			newName = find.ReplaceAllString(newName, options.Replace)
+		}
+		ext := path.Ext(newName)
+		name := strings.TrimSuffix(newName, ext)
+		newName = tmpl.render(name, ext, options.Start+i, item.ModTime)
+		r := Rename{Item: item, NewName: newName}
+		if problem := validateName(newName); problem != "" {
+			r.Status, r.Problem = StatusInvalid, problem
+		} else if newName != item.Name {
+			r.Status = StatusOK
+		}
+		plan.Renames[i] = r
+	}
+	markCollisions(plan.Renames, sources, exists)
+	return plan, nil
+}
+
+func validateName(name string) string {
+	switch {
+	case name == "":
+		return "empty name"
+	case name == "." || name == "..":
+		return "reserved name"
+	case strings.ContainsAny(name, "/\x00"):
+		return "name contains '/' or NUL"
+	case len(name) > maxNameLength:
+		return fmt.Sprintf("name is longer than %d bytes", maxNameLength)
+	default:
+		return ""
+	}
+}
+
+// markCollisions flags renames that target the same name
+// or a name of an existing entry that is not renamed away by the batch.
+func markCollisions(renames []Rename, sources map[string]bool, exists ExistsFunc) {
+	targets := make(map[string][]int, len(renames))
+	for i, r := range renames {
+		if r.Status == StatusInvalid {
+			continue
+		}
+		target := path.Join(r.Dir, r.NewName)
+		targets[target] = append(targets[target], i)
+	}
+	for target, indexes := range targets {
+		if len(indexes) > 1 {
+			for _, i := range indexes {
+				renames[i].Status = StatusCollision
+				renames[i].Problem = fmt.Sprintf("%d items would be named %q", len(indexes), renames[i].NewName)
+			}
+			continue
+		}
+		i := indexes[0]
+		r := renames[i]
+		if r.Status != StatusOK || sources[target] || exists == nil {
+			continue
+		}
+		if exists(r.Dir, r.NewName) {
+			renames[i].Status = StatusCollision
+			renames[i].Problem = "already exists"
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ftrename
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func items(names ...string) []Item {
+	result := make([]Item, len(names))
+	for i, name := range names {
+		result[i] = Item{Dir: "/d", Name: name}
+	}
+	return result
+}
+
+func newNames(plan Plan) (names []string, statuses []Status) {
+	for _, r := range plan.Renames {
+		names = append(names, r.NewName)
+		statuses = append(statuses, r.Status)
+	}
+	return
+}
+
+func TestNewPlan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("regex_and_template", func(t *testing.T) {
+		plan, err := NewPlan(items("IMG_1.JPG", "IMG_2.JPG", "notes.txt"), Options{
+			Find:     `^IMG_(\d+)\.JPG$`,
+			Replace:  "img-$1.jpg",
+			Template: "{name}_{n:02}{ext}",
+			Start:    1,
+		}, nil)
+		assert.NoError(t, err)
+		names, statuses := newNames(plan)
+		assert.Equal(t, []string{"img-1_01.jpg", "img-2_02.jpg", "notes_03.txt"}, names)
+		assert.Equal(t, []Status{StatusOK, StatusOK, StatusOK}, statuses)
+		assert.False(t, plan.HasProblems())
+		assert.Len(t, plan.Changes(), 3)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		plan, err := NewPlan(items("a.txt"), Options{Find: "z", Replace: "y"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusUnchanged, plan.Renames[0].Status)
+		assert.Empty(t, plan.Changes())
+	})
+
+	t.Run("invalid_names", func(t *testing.T) {
+		plan, err := NewPlan(items("a", "b", "c", "d"), Options{Find: "^a$|^b$|^c$|^d$", Replace: ""}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusInvalid, plan.Renames[0].Status)
+		assert.Equal(t, "empty name", plan.Renames[0].Problem)
+
+		plan, err = NewPlan(items("a"), Options{Replace: "x/y", Find: "a"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusInvalid, plan.Renames[0].Status)
+
+		plan, err = NewPlan(items("a"), Options{Replace: "..", Find: "a"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "reserved name", plan.Renames[0].Problem)
+
+		plan, err = NewPlan(items("a"), Options{Replace: strings.Repeat("x", 256), Find: "a"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusInvalid, plan.Renames[0].Status)
+		assert.True(t, plan.HasProblems())
+	})
+
+	t.Run("collision_within_batch", func(t *testing.T) {
+		plan, err := NewPlan(items("a1", "a2", "b"), Options{Find: `\d`, Replace: ""}, nil)
+		assert.NoError(t, err)
+		_, statuses := newNames(plan)
+		assert.Equal(t, []Status{StatusCollision, StatusCollision, StatusUnchanged}, statuses)
+		assert.Contains(t, plan.Renames[0].Problem, `2 items would be named "a"`)
+	})
+
+	t.Run("collision_with_unchanged_item", func(t *testing.T) {
+		plan, err := NewPlan(items("a", "b"), Options{Find: "^b$", Replace: "a"}, nil)
+		assert.NoError(t, err)
+		_, statuses := newNames(plan)
+		assert.Equal(t, []Status{StatusCollision, StatusCollision}, statuses)
+	})
+
+	t.Run("collision_with_existing", func(t *testing.T) {
+		exists := func(dir, name string) bool {
+			return dir == "/d" && (name == "c" || name == "b")
+		}
+		plan, err := NewPlan(items("a", "b"), Options{Find: "^a$|^b$", Replace: "${0}x"}, exists)
+		assert.NoError(t, err)
+		_, statuses := newNames(plan)
+		assert.Equal(t, []Status{StatusOK, StatusOK}, statuses)
+
+		plan, err = NewPlan(items("a"), Options{Find: "a", Replace: "c"}, exists)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCollision, plan.Renames[0].Status)
+		assert.Equal(t, "already exists", plan.Renames[0].Problem)
+	})
+
+	t.Run("swap_is_allowed", func(t *testing.T) {
+		exists := func(dir, name string) bool { return true }
+		plan, err := NewPlan(items("a", "b"), Options{Find: "^(a|b)$", Template: "{n}", Start: 0}, exists)
+		assert.NoError(t, err)
+		assert.True(t, plan.HasProblems(), "'0' & '1' do not exist as sources")
+
+		plan, err = NewPlan(items("a", "b"), Options{Find: "^a$|^b$", Replace: "${0}"}, exists)
+		assert.NoError(t, err)
+		assert.False(t, plan.HasProblems())
+	})
+
+	t.Run("invalid_options", func(t *testing.T) {
+		_, err := NewPlan(items("a"), Options{Find: "("}, nil)
+		assert.ErrorContains(t, err, "invalid regular expression")
+		_, err = NewPlan(items("a"), Options{Template: "{x}"}, nil)
+		assert.ErrorContains(t, err, "invalid template")
+	})
+}
+
+func TestStatus_String(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "unchanged", StatusUnchanged.String())
+	assert.Equal(t, "ok", StatusOK.String())
+	assert.Equal(t, "invalid", StatusInvalid.String())
+	assert.Equal(t, "collision", StatusCollision.String())
+	assert.Equal(t, "Status(9)", Status(9).String())
+}
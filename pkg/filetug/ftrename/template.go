@@ -0,0 +1,85 @@
+package ftrename
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported template tokens:
+//
+//	{name}         - file name without extension (after find/replace is applied)
+//	{ext}          - extension including the leading dot, e.g. ".txt"
+//	{n}, {n:03}    - counter, optionally zero-padded to the given width
+//	{mtime}        - modification time formatted as 2006-01-02
+//	{mtime:layout} - modification time formatted with a Go time layout
+type template []templatePart
+
+type templatePart struct {
+	literal string
+	token   string // empty for literals
+	arg     string
+}
+
+const defaultMTimeLayout = "2006-01-02"
+
+func parseTemplate(s string) (template, error) {
+	var t template
+	for len(s) > 0 {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			t = append(t, templatePart{literal: s})
+			break
+		}
+		if start > 0 {
+			t = append(t, templatePart{literal: s[:start]})
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed token at position %d", start)
+		}
+		token, arg, _ := strings.Cut(s[start+1:start+end], ":")
+		switch token {
+		case "name", "ext":
+			if arg != "" {
+				return nil, fmt.Errorf("token {%s} does not accept arguments", token)
+			}
+		case "n":
+			if arg != "" {
+				if _, err := strconv.ParseUint(arg, 10, 8); err != nil {
+					return nil, fmt.Errorf("invalid counter width %q", arg)
+				}
+			}
+		case "mtime":
+			if arg == "" {
+				arg = defaultMTimeLayout
+			}
+		default:
+			return nil, fmt.Errorf("unknown token {%s}", token)
+		}
+		t = append(t, templatePart{token: token, arg: arg})
+		s = s[start+end+1:]
+	}
+	return t, nil
+}
+
+func (t template) render(name, ext string, n int, modTime time.Time) string {
+	var sb strings.Builder
+	for _, part := range t {
+		switch part.token {
+		case "":
+			sb.WriteString(part.literal)
+		case "name":
+			sb.WriteString(name)
+		case "ext":
+			sb.WriteString(ext)
+		case "n":
+			width, _ := strconv.Atoi(part.arg)
+			_, _ = fmt.Fprintf(&sb, "%0*d", width, n)
+		case "mtime":
+			sb.WriteString(modTime.Format(part.arg))
+		}
+	}
+	return sb.String()
+}
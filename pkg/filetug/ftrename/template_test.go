@@ -0,0 +1,40 @@
+package ftrename
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTemplate(t *testing.T) {
+	t.Parallel()
+	modTime := time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		template string
+		expected string
+		err      string
+	}{
+		{template: "{name}{ext}", expected: "photo.jpg"},
+		{template: "{name}_{n:03}{ext}", expected: "photo_007.jpg"},
+		{template: "{n}-{name}", expected: "7-photo"},
+		{template: "{mtime}_{name}{ext}", expected: "2024-03-09_photo.jpg"},
+		{template: "{mtime:20060102}{ext}", expected: "20240309.jpg"},
+		{template: "plain", expected: "plain"},
+		{template: "{name", err: "unclosed token at position 0"},
+		{template: "{size}", err: "unknown token {size}"},
+		{template: "{n:x}", err: `invalid counter width "x"`},
+		{template: "{ext:1}", err: "token {ext} does not accept arguments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := parseTemplate(tt.template)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tmpl.render("photo", ".jpg", 7, modTime))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package ftsync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a saved sync that can be rerun. Source & target are URLs of stores or local paths.
+type Profile struct {
+	Name             string  `yaml:"name"`
+	Source           string  `yaml:"source"`
+	Target           string  `yaml:"target"`
+	Compare          Compare `yaml:"compare,omitempty"`
+	DeleteExtraneous bool    `yaml:"deleteExtraneous,omitempty"`
+	Exclude          string  `yaml:"exclude,omitempty"` // name of a mask
+	DryRun           bool    `yaml:"dryRun,omitempty"`
+}
+
+// LoadProfiles reads profiles persisted by SaveProfiles. A missing file means no profiles.
+func LoadProfiles(filePath string) ([]Profile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var profiles []Profile
+	if err = yaml.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return profiles, nil
+}
+
+// SaveProfiles writes profiles readable by the user only as URLs can contain credentials.
+func SaveProfiles(filePath string, profiles []Profile) error {
+	data, err := yaml.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o600)
+}
+
+// UpsertProfile replaces a profile with the same name or appends it.
+func UpsertProfile(profiles []Profile, profile Profile) []Profile {
+	i := slices.IndexFunc(profiles, func(p Profile) bool { return p.Name == profile.Name })
+	if i < 0 {
+		return append(profiles, profile)
+	}
+	profiles[i] = profile
+	return profiles
+}
@@ -0,0 +1,495 @@
+// Package ftsync mirrors a directory tree of one files.Store into another one.
+// A Plan is built first by comparing both trees & is applied separately, so it can be reviewed or used as a dry run.
+package ftsync
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+)
+
+var (
+	ErrFileReadNotSupported  = fmt.Errorf("reading files: %w", files.ErrNotSupported)
+	ErrFileWriteNotSupported = fmt.Errorf("writing files: %w", files.ErrNotSupported)
+)
+
+// Compare is a way to decide if a file in the target differs from the source one.
+type Compare string
+
+const (
+	// CompareSizeModTime treats a file as changed if sizes or modification times differ, the latter beyond Options.ModTimeWindow.
+	CompareSizeModTime Compare = "size+mtime"
+	// CompareChecksum treats a file as changed if sizes or SHA-256 of the content differ.
+	// It needs both stores to be able to read files, otherwise CompareSizeModTime is used.
+	CompareChecksum Compare = "checksum"
+)
+
+// Compares lists comparison modes in order they are offered to a user.
+var Compares = []Compare{CompareSizeModTime, CompareChecksum}
+
+// DefaultModTimeWindow is the default tolerance for comparing modification times,
+// e.g. FAT stores them with 2 seconds precision.
+const DefaultModTimeWindow = 2 * time.Second
+
+type Status string
+
+const (
+	StatusNew       Status = "new"
+	StatusChanged   Status = "changed"
+	StatusDeleted   Status = "deleted"
+	StatusUnchanged Status = "unchanged"
+)
+
+type Options struct {
+	Compare          Compare
+	DeleteExtraneous bool          // delete target entries missing in the source
+	Exclude          *masks.Mask   // entries with names matching the mask are neither copied nor deleted
+	ModTimeWindow    time.Duration // DefaultModTimeWindow if not positive
+}
+
+// Endpoint is a directory in a store.
+type Endpoint struct {
+	Store files.Store
+	Dir   string
+}
+
+func (e Endpoint) path(rel string) string {
+	return path.Join(e.Dir, rel)
+}
+
+// Item is an entry of a plan. Unchanged dirs are not listed.
+type Item struct {
+	Path    string // relative to the synced dirs, slash separated
+	IsDir   bool
+	Status  Status
+	Size    int64 // of the source file, or of the target one if deleted
+	ModTime time.Time
+}
+
+type Plan struct {
+	Source  Endpoint
+	Target  Endpoint
+	Compare Compare // the comparison actually used
+	Items   []Item  // in pre-order, a dir goes before its content
+	// TargetMissing is true if the target dir doesn't exist & is created when the plan is applied.
+	TargetMissing bool
+}
+
+type Summary struct {
+	New         int
+	Changed     int
+	Deleted     int
+	Unchanged   int
+	BytesToCopy int64
+}
+
+func (p *Plan) Summary() (s Summary) {
+	for _, item := range p.Items {
+		switch item.Status {
+		case StatusNew:
+			s.New++
+		case StatusChanged:
+			s.Changed++
+		case StatusDeleted:
+			s.Deleted++
+		case StatusUnchanged:
+			s.Unchanged++
+		}
+		if !item.IsDir && (item.Status == StatusNew || item.Status == StatusChanged) {
+			s.BytesToCopy += item.Size
+		}
+	}
+	return s
+}
+
+// IsEmpty returns true if applying the plan changes nothing.
+func (p *Plan) IsEmpty() bool {
+	s := p.Summary()
+	return !p.TargetMissing && s.New+s.Changed+s.Deleted == 0
+}
+
+type Progress struct {
+	Total      int
+	Done       int
+	BytesTotal int64
+	BytesDone  int64
+	Current    string
+}
+
+// BuildPlan compares source & target trees. Links are ignored.
+// If a source entry is a file & the target one is a dir or vice versa, the target one is deleted
+// even without Options.DeleteExtraneous as it can't be updated otherwise.
+func BuildPlan(ctx context.Context, src, dst Endpoint, opts Options, report func(p Progress)) (*Plan, error) {
+	if opts.ModTimeWindow <= 0 {
+		opts.ModTimeWindow = DefaultModTimeWindow
+	}
+	p := planner{
+		ctx:    ctx,
+		opts:   opts,
+		report: report,
+		plan:   &Plan{Source: src, Target: dst, Compare: CompareSizeModTime},
+	}
+	if opts.Compare == CompareChecksum {
+		srcReader, srcOK := src.Store.(files.FileReader)
+		dstReader, dstOK := dst.Store.(files.FileReader)
+		if srcOK && dstOK {
+			p.srcReader, p.dstReader = srcReader, dstReader
+			p.plan.Compare = CompareChecksum
+		}
+	}
+	dstEntries, err := dst.Store.ReadDir(ctx, dst.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		p.plan.TargetMissing = true
+	} else if err != nil {
+		return nil, err
+	}
+	if err = p.walk(".", dstEntries, !p.plan.TargetMissing); err != nil {
+		return nil, err
+	}
+	return p.plan, nil
+}
+
+type planner struct {
+	ctx       context.Context
+	opts      Options
+	report    func(p Progress)
+	plan      *Plan
+	srcReader files.FileReader // nil unless comparing by checksum
+	dstReader files.FileReader
+	progress  Progress
+}
+
+func (p *planner) reportProgress(current string) {
+	p.progress.Current = current
+	if p.report != nil {
+		p.report(p.progress)
+	}
+}
+
+// excluded applies the Exclude mask to an entry, path patterns match its path relative to the synced dirs.
+func (p *planner) excluded(rel string, entry os.DirEntry) (bool, error) {
+	if p.opts.Exclude == nil {
+		return false, nil
+	}
+	return p.opts.Exclude.Match(masks.Entry{DirEntry: entry, RelPath: rel}, nil)
+}
+
+func isLink(entry os.DirEntry) bool {
+	return entry.Type()&os.ModeSymlink != 0
+}
+
+func sortedEntries(entries []os.DirEntry) []os.DirEntry {
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	return entries
+}
+
+// walk compares the source dir rel with its target counterpart which entries are given if it exists.
+func (p *planner) walk(rel string, dstEntries []os.DirEntry, dstExists bool) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	p.reportProgress(p.plan.Source.path(rel))
+	srcEntries, err := p.plan.Source.Store.ReadDir(p.ctx, p.plan.Source.path(rel))
+	if err != nil {
+		return err
+	}
+	targets := make(map[string]os.DirEntry, len(dstEntries))
+	for _, d := range dstEntries {
+		targets[d.Name()] = d
+	}
+	for _, s := range sortedEntries(srcEntries) {
+		name := s.Name()
+		d, ok := targets[name]
+		delete(targets, name)
+		if excluded, err := p.excluded(path.Join(rel, name), s); err != nil {
+			return err
+		} else if excluded || isLink(s) {
+			continue
+		}
+		childRel := path.Join(rel, name)
+		if ok && (d.IsDir() != s.IsDir() || isLink(d)) {
+			if err = p.addDeleted(childRel, d); err != nil {
+				return err
+			}
+			ok = false
+		}
+		if s.IsDir() {
+			var children []os.DirEntry
+			if ok {
+				if children, err = p.plan.Target.Store.ReadDir(p.ctx, p.plan.Target.path(childRel)); err != nil {
+					return err
+				}
+			} else {
+				p.plan.Items = append(p.plan.Items, Item{Path: childRel, IsDir: true, Status: StatusNew})
+			}
+			if err = p.walk(childRel, children, ok); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = p.addFile(childRel, s, d, ok); err != nil {
+			return err
+		}
+	}
+	if !dstExists || !p.opts.DeleteExtraneous {
+		return nil
+	}
+	extraneous := make([]os.DirEntry, 0, len(targets))
+	for _, d := range targets {
+		extraneous = append(extraneous, d)
+	}
+	for _, d := range sortedEntries(extraneous) {
+		if excluded, err := p.excluded(path.Join(rel, d.Name()), d); err != nil {
+			return err
+		} else if excluded {
+			continue
+		}
+		if err = p.addDeleted(path.Join(rel, d.Name()), d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *planner) addFile(rel string, s, d os.DirEntry, dstExists bool) error {
+	srcInfo, err := s.Info()
+	if err != nil {
+		return err
+	}
+	item := Item{Path: rel, Status: StatusNew, Size: srcInfo.Size(), ModTime: srcInfo.ModTime()}
+	if dstExists {
+		dstInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+		changed, err := p.changed(rel, srcInfo, dstInfo)
+		if err != nil {
+			return err
+		}
+		item.Status = StatusUnchanged
+		if changed {
+			item.Status = StatusChanged
+		}
+	}
+	p.plan.Items = append(p.plan.Items, item)
+	return nil
+}
+
+func (p *planner) changed(rel string, srcInfo, dstInfo os.FileInfo) (bool, error) {
+	if srcInfo.Size() != dstInfo.Size() {
+		return true, nil
+	}
+	if p.srcReader == nil {
+		// A target newer than the source is changed too, e.g. edited in place, so the difference goes either way.
+		return (srcInfo.ModTime().Sub(dstInfo.ModTime())).Abs() > p.opts.ModTimeWindow, nil
+	}
+	srcHash, err := p.hash(p.srcReader, p.plan.Source.path(rel))
+	if err != nil {
+		return false, err
+	}
+	dstHash, err := p.hash(p.dstReader, p.plan.Target.path(rel))
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(srcHash, dstHash), nil
+}
+
+func (p *planner) hash(reader files.FileReader, filePath string) ([]byte, error) {
+	p.reportProgress(filePath)
+	r, err := reader.OpenReader(p.ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, contextReader{ctx: p.ctx, r: r})
+	if err = errors.Join(err, r.Close()); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// addDeleted adds a target entry to be deleted followed by its content if it's a dir.
+func (p *planner) addDeleted(rel string, d os.DirEntry) error {
+	item := Item{Path: rel, IsDir: d.IsDir() && !isLink(d), Status: StatusDeleted}
+	if info, err := d.Info(); err == nil {
+		item.ModTime = info.ModTime()
+		if !item.IsDir {
+			item.Size = info.Size()
+		}
+	}
+	p.plan.Items = append(p.plan.Items, item)
+	if !item.IsDir {
+		return nil
+	}
+	children, err := p.plan.Target.Store.ReadDir(p.ctx, p.plan.Target.path(rel))
+	if err != nil {
+		return err
+	}
+	for _, child := range sortedEntries(children) {
+		if err = p.addDeleted(path.Join(rel, child.Name()), child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply makes the target match the source. Deletions go first, content of deleted dirs before the dirs.
+// It stops at the first error or once ctx is cancelled. Items applied before that are returned in order of applying,
+// a created missing target dir goes first as a new dir with "." path.
+func (p *Plan) Apply(ctx context.Context, report func(p Progress)) (applied []Item, err error) {
+	a := applier{ctx: ctx, plan: p, report: report}
+	var deleted, copied []Item
+	for _, item := range p.Items {
+		switch item.Status {
+		case StatusDeleted:
+			deleted = append(deleted, item)
+		case StatusNew, StatusChanged:
+			copied = append(copied, item)
+			if !item.IsDir {
+				a.progress.BytesTotal += item.Size
+			}
+		}
+	}
+	a.progress.Total = len(deleted) + len(copied)
+	if slices.ContainsFunc(copied, func(item Item) bool { return !item.IsDir }) {
+		var ok bool
+		if a.reader, ok = p.Source.Store.(files.FileReader); !ok {
+			return nil, ErrFileReadNotSupported
+		}
+		if a.writer, ok = p.Target.Store.(files.FileWriter); !ok {
+			return nil, ErrFileWriteNotSupported
+		}
+	}
+	a.reportProgress("")
+	if p.TargetMissing {
+		if err = p.Target.Store.CreateDir(ctx, p.Target.Dir); err != nil {
+			return nil, err
+		}
+		applied = append(applied, Item{Path: ".", IsDir: true, Status: StatusNew})
+	}
+	for _, item := range slices.Backward(deleted) {
+		if err = a.apply(item); err != nil {
+			return applied, err
+		}
+		applied = append(applied, item)
+	}
+	for _, item := range copied {
+		if err = a.apply(item); err != nil {
+			return applied, err
+		}
+		applied = append(applied, item)
+	}
+	a.reportProgress("")
+	return applied, nil
+}
+
+type applier struct {
+	ctx      context.Context
+	plan     *Plan
+	report   func(p Progress)
+	reader   files.FileReader
+	writer   files.FileWriter
+	progress Progress
+}
+
+func (a *applier) reportProgress(current string) {
+	a.progress.Current = current
+	if a.report != nil {
+		a.report(a.progress)
+	}
+}
+
+func (a *applier) apply(item Item) error {
+	if err := a.ctx.Err(); err != nil {
+		return err
+	}
+	dstPath := a.plan.Target.path(item.Path)
+	a.reportProgress(dstPath)
+	var err error
+	switch {
+	case item.Status == StatusDeleted:
+		err = a.plan.Target.Store.Delete(a.ctx, dstPath)
+	case item.IsDir:
+		err = a.plan.Target.Store.CreateDir(a.ctx, dstPath)
+	default:
+		err = a.copyFile(item, dstPath)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", item.Path, err)
+	}
+	a.progress.Done++
+	return nil
+}
+
+func (a *applier) copyFile(item Item, dstPath string) error {
+	r, err := a.reader.OpenReader(a.ctx, a.plan.Source.path(item.Path))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	w, err := a.writer.OpenWriter(a.ctx, dstPath)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 64*1024)
+	for {
+		if err = a.ctx.Err(); err != nil {
+			break
+		}
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if _, err = w.Write(buf[:n]); err != nil {
+				break
+			}
+			a.progress.BytesDone += int64(n)
+			a.reportProgress(dstPath)
+		}
+		if readErr != nil {
+			if readErr != io.EOF {
+				err = readErr
+			}
+			break
+		}
+	}
+	if err = errors.Join(err, w.Close()); err != nil {
+		// A partially written file is not left behind, it would look like a synced one of a different size.
+		if deleteErr := a.plan.Target.Store.Delete(context.WithoutCancel(a.ctx), dstPath); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to delete incomplete file: %w", deleteErr))
+		}
+		return err
+	}
+	// Keeps the next size+mtime comparison from seeing the file as changed.
+	if setter, ok := a.plan.Target.Store.(files.AttrSetter); ok && !item.ModTime.IsZero() {
+		return setter.Chtimes(a.ctx, dstPath, item.ModTime)
+	}
+	return nil
+}
+
+// contextReader stops reading once ctx is cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,325 @@
+package ftsync
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/stretchr/testify/assert"
+)
+
+var oldTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func writeFile(t *testing.T, root, name, content string, modTime time.Time) {
+	t.Helper()
+	filePath := filepath.Join(root, name)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+	assert.NoError(t, os.Chtimes(filePath, modTime, modTime))
+}
+
+// newTestTrees creates a source & a target dir:
+//
+//	src/same.txt        = dst/same.txt
+//	src/changed.txt     newer & bigger than dst/changed.txt
+//	src/touched.txt     same size, newer, other content than dst/touched.txt
+//	src/new/file.txt    missing in dst
+//	src/kind            a file while dst/kind is a dir
+//	src/skip.log        excluded by the test mask
+//	                    dst/extra/x.txt & dst/extra.log are extraneous
+func newTestTrees(t *testing.T) (src, dst string) {
+	t.Helper()
+	root := t.TempDir()
+	src, dst = filepath.Join(root, "src"), filepath.Join(root, "dst")
+	newTime := oldTime.Add(time.Hour)
+	writeFile(t, src, "same.txt", "same", oldTime)
+	writeFile(t, dst, "same.txt", "same", oldTime.Add(time.Second))
+	writeFile(t, src, "changed.txt", "changed!", newTime)
+	writeFile(t, dst, "changed.txt", "old", oldTime)
+	writeFile(t, src, "touched.txt", "aaa", newTime)
+	writeFile(t, dst, "touched.txt", "bbb", oldTime)
+	writeFile(t, src, "new/file.txt", "new", newTime)
+	writeFile(t, src, "kind", "file", newTime)
+	writeFile(t, dst, "kind/inner.txt", "inner", oldTime)
+	writeFile(t, src, "skip.log", "log", newTime)
+	writeFile(t, dst, "extra/x.txt", "x", oldTime)
+	writeFile(t, dst, "extra.log", "log", oldTime)
+	return src, dst
+}
+
+func statuses(plan *Plan) map[string]Status {
+	result := make(map[string]Status, len(plan.Items))
+	for _, item := range plan.Items {
+		result[item.Path] = item.Status
+	}
+	return result
+}
+
+var logMask = &masks.Mask{Name: "logs", Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `\.log$`}}}
+
+func TestBuildPlan_Apply(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srcDir, dstDir := newTestTrees(t)
+	store := osfile.NewStore("/")
+	src, dst := Endpoint{Store: store, Dir: srcDir}, Endpoint{Store: store, Dir: dstDir}
+	opts := Options{DeleteExtraneous: true, Exclude: logMask}
+
+	plan, err := BuildPlan(ctx, src, dst, opts, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CompareSizeModTime, plan.Compare)
+	assert.Equal(t, map[string]Status{
+		"same.txt":       StatusUnchanged,
+		"changed.txt":    StatusChanged,
+		"touched.txt":    StatusChanged,
+		"new":            StatusNew,
+		"new/file.txt":   StatusNew,
+		"kind":           StatusNew, // the dir is deleted first
+		"kind/inner.txt": StatusDeleted,
+		"extra":          StatusDeleted,
+		"extra/x.txt":    StatusDeleted,
+	}, statuses(plan))
+	assert.Equal(t, Summary{New: 3, Changed: 2, Deleted: 4, Unchanged: 1, BytesToCopy: 18}, plan.Summary())
+	assert.False(t, plan.IsEmpty())
+
+	var last Progress
+	applied, err := plan.Apply(ctx, func(p Progress) { last = p })
+	assert.NoError(t, err)
+	assert.Len(t, applied, 9)
+	assert.Equal(t, StatusDeleted, applied[0].Status, "deletions go first")
+	assert.Equal(t, Progress{Total: 9, Done: 9, BytesTotal: 18, BytesDone: 18}, last)
+	for name, content := range map[string]string{
+		"changed.txt": "changed!", "touched.txt": "aaa", "new/file.txt": "new", "kind": "file", "extra.log": "log",
+	} {
+		data, err := os.ReadFile(filepath.Join(dstDir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(data), name)
+	}
+	for _, name := range []string{"extra", "skip.log"} {
+		_, err = os.Stat(filepath.Join(dstDir, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	plan, err = BuildPlan(ctx, src, dst, opts, nil)
+	assert.NoError(t, err)
+	assert.True(t, plan.IsEmpty(), "mod times are copied, so nothing changes on the 2nd run: %+v", plan.Items)
+}
+
+func TestBuildPlan_Options(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srcDir, dstDir := newTestTrees(t)
+	store := osfile.NewStore("/")
+	src, dst := Endpoint{Store: store, Dir: srcDir}, Endpoint{Store: store, Dir: dstDir}
+
+	t.Run("keep_extraneous", func(t *testing.T) {
+		plan, err := BuildPlan(ctx, src, dst, Options{}, nil)
+		assert.NoError(t, err)
+		s := statuses(plan)
+		assert.NotContains(t, s, "extra")
+		assert.Equal(t, StatusNew, s["skip.log"])
+		assert.Equal(t, StatusDeleted, s["kind/inner.txt"], "a dir is replaced with a file anyway")
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		writeFile(t, dstDir, "touched.txt", "aaa", oldTime)
+		plan, err := BuildPlan(ctx, src, dst, Options{Compare: CompareChecksum}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, CompareChecksum, plan.Compare)
+		s := statuses(plan)
+		assert.Equal(t, StatusUnchanged, s["touched.txt"], "same content while the source is newer")
+		assert.Equal(t, StatusChanged, s["changed.txt"])
+	})
+
+	t.Run("checksum_not_available", func(t *testing.T) {
+		plan, err := BuildPlan(ctx, src, Endpoint{Store: listOnlyStore{Store: store}, Dir: dstDir}, Options{Compare: CompareChecksum}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, CompareSizeModTime, plan.Compare)
+	})
+
+	t.Run("target_newer", func(t *testing.T) {
+		newTime := oldTime.Add(time.Hour) // of the source touched.txt
+		writeFile(t, dstDir, "touched.txt", "bbb", newTime.Add(time.Hour))
+		plan, err := BuildPlan(ctx, src, dst, Options{}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusChanged, statuses(plan)["touched.txt"], "the target was modified after the source")
+
+		writeFile(t, dstDir, "touched.txt", "bbb", newTime.Add(time.Second))
+		plan, err = BuildPlan(ctx, src, dst, Options{}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusUnchanged, statuses(plan)["touched.txt"], "within the window")
+	})
+
+	t.Run("target_missing", func(t *testing.T) {
+		target := Endpoint{Store: store, Dir: filepath.Join(dstDir, "missing")}
+		plan, err := BuildPlan(ctx, src, target, Options{}, nil)
+		assert.NoError(t, err)
+		assert.True(t, plan.TargetMissing)
+		assert.Equal(t, StatusNew, statuses(plan)["same.txt"])
+		applied, err := plan.Apply(ctx, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, Item{Path: ".", IsDir: true, Status: StatusNew}, applied[0], "the created target dir")
+		data, err := os.ReadFile(filepath.Join(target.Dir, "new", "file.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("links_are_ignored", func(t *testing.T) {
+		assert.NoError(t, os.Symlink("same.txt", filepath.Join(srcDir, "link.txt")))
+		plan, err := BuildPlan(ctx, src, dst, Options{}, nil)
+		assert.NoError(t, err)
+		assert.NotContains(t, statuses(plan), "link.txt")
+	})
+}
+
+// listOnlyStore can't read or write files.
+type listOnlyStore struct {
+	files.Store
+}
+
+func (listOnlyStore) RootURL() url.URL {
+	return url.URL{Scheme: "mem"}
+}
+
+// failingReaderStore fails to read file content.
+type failingReaderStore struct {
+	*osfile.Store
+}
+
+func (failingReaderStore) OpenReader(context.Context, string) (io.ReadCloser, error) {
+	return nil, errors.New("read failed")
+}
+
+// partialReaderStore fails to read file content after its first bytes.
+type partialReaderStore struct {
+	*osfile.Store
+}
+
+func (partialReaderStore) OpenReader(context.Context, string) (io.ReadCloser, error) {
+	return io.NopCloser(io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("read failed")))), nil
+}
+
+func TestBuildPlan_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srcDir, dstDir := newTestTrees(t)
+	store := osfile.NewStore("/")
+	src, dst := Endpoint{Store: store, Dir: srcDir}, Endpoint{Store: store, Dir: dstDir}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := BuildPlan(cancelled, src, Endpoint{Store: store, Dir: filepath.Join(dstDir, "missing")}, Options{}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = BuildPlan(ctx, Endpoint{Store: store, Dir: filepath.Join(srcDir, "missing")}, dst, Options{}, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = BuildPlan(ctx, src, Endpoint{Store: store, Dir: filepath.Join(dstDir, "same.txt")}, Options{}, nil)
+	assert.Error(t, err, "target is a file")
+
+	invalid := &masks.Mask{Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `(`}}}
+	_, err = BuildPlan(ctx, src, dst, Options{Exclude: invalid}, nil)
+	assert.Error(t, err)
+
+	_, err = BuildPlan(ctx, Endpoint{Store: failingReaderStore{store}, Dir: srcDir}, dst, Options{Compare: CompareChecksum}, nil)
+	assert.EqualError(t, err, "read failed")
+
+	var reported bool
+	_, err = BuildPlan(ctx, src, dst, Options{}, func(p Progress) { reported = true })
+	assert.NoError(t, err)
+	assert.True(t, reported)
+}
+
+func TestPlan_Apply_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srcDir, dstDir := newTestTrees(t)
+	store := osfile.NewStore("/")
+	src, dst := Endpoint{Store: store, Dir: srcDir}, Endpoint{Store: store, Dir: dstDir}
+	plan, err := BuildPlan(ctx, src, dst, Options{}, nil)
+	assert.NoError(t, err)
+
+	noReader := *plan
+	noReader.Source.Store = listOnlyStore{Store: store}
+	assert.ErrorIs(t, applyErr(noReader.Apply(ctx, nil)), ErrFileReadNotSupported)
+
+	noWriter := *plan
+	noWriter.Target.Store = listOnlyStore{Store: store}
+	assert.ErrorIs(t, applyErr(noWriter.Apply(ctx, nil)), ErrFileWriteNotSupported)
+
+	failingReader := *plan
+	failingReader.Source.Store = failingReaderStore{store}
+	assert.ErrorContains(t, applyErr(failingReader.Apply(ctx, nil)), "read failed")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, applyErr(plan.Apply(cancelled, nil)), context.Canceled)
+
+	missingTarget := *plan
+	missingTarget.TargetMissing = true
+	missingTarget.Target.Dir = filepath.Join(dstDir, "a", "b")
+	assert.ErrorIs(t, applyErr(missingTarget.Apply(ctx, nil)), os.ErrNotExist)
+}
+
+func TestPlan_Apply_DeletesPartialFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srcDir, dstDir := newTestTrees(t)
+	store := osfile.NewStore("/")
+	plan, err := BuildPlan(ctx, Endpoint{Store: store, Dir: srcDir}, Endpoint{Store: store, Dir: dstDir}, Options{}, nil)
+	assert.NoError(t, err)
+	plan.Source.Store = partialReaderStore{store}
+	assert.ErrorContains(t, applyErr(plan.Apply(ctx, nil)), "read failed")
+	assert.NoError(t, filepath.WalkDir(dstDir, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			content, _ := os.ReadFile(p)
+			assert.NotEqual(t, "partial", string(content), "a partially copied file is deleted: %s", p)
+		}
+		return err
+	}))
+}
+
+func TestProfiles(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", "sync.yaml")
+	profiles, err := LoadProfiles(filePath)
+	assert.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	profiles = UpsertProfile(profiles, Profile{Name: "build", Source: "/build", Target: "ftp://u:p@host/www"})
+	profiles = UpsertProfile(profiles, Profile{Name: "docs", Source: "/docs", Target: "/backup"})
+	profiles = UpsertProfile(profiles, Profile{Name: "build", Source: "/build", Target: "ftp://host/www", DryRun: true})
+	assert.NoError(t, SaveProfiles(filePath, profiles))
+
+	info, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := LoadProfiles(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, profiles, loaded)
+	assert.Len(t, loaded, 2)
+	assert.True(t, loaded[0].DryRun)
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("{"), 0o600))
+	_, err = LoadProfiles(filePath)
+	assert.Error(t, err)
+
+	_, err = LoadProfiles(t.TempDir())
+	assert.Error(t, err, "a dir can't be read")
+	assert.Error(t, SaveProfiles(filepath.Join(filePath, "x.yaml"), nil), "parent is a file")
+}
+
+func applyErr(_ []Item, err error) error {
+	return err
+}
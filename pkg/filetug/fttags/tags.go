@@ -0,0 +1,303 @@
+// Package fttags keeps tags & free-text notes of files and directories in a local index
+// keyed by a store URL & a path. Tags of local files can be mirrored into the user.xdg.tags
+// extended attribute understood by other file managers.
+package fttags
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"gopkg.in/yaml.v3"
+)
+
+// Entry holds tags & a note of a file or a directory.
+type Entry struct {
+	Store string   `yaml:"store"`
+	Path  string   `yaml:"path"`
+	IsDir bool     `yaml:"dir,omitempty"`
+	Tags  []string `yaml:"tags,omitempty,flow"`
+	Note  string   `yaml:"note,omitempty"`
+}
+
+func (e Entry) key() string {
+	return e.Store + e.Path
+}
+
+func (e Entry) isEmpty() bool {
+	return len(e.Tags) == 0 && e.Note == ""
+}
+
+// isLocal reports whether the entry is on the local file system & its tags can be mirrored into xattrs.
+func (e Entry) isLocal() bool {
+	return strings.HasPrefix(e.Store, "file:")
+}
+
+// TagCount is a tag with the number of entries it is assigned to.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Index is a set of tagged or annotated entries.
+// It is safe for concurrent use as operations update it from background goroutines.
+type Index struct {
+	mu           sync.RWMutex
+	mirrorXattrs bool
+	entries      map[string]Entry
+}
+
+type indexFile struct {
+	MirrorXattrs bool    `yaml:"mirror_xattrs,omitempty"`
+	Entries      []Entry `yaml:"entries,omitempty"`
+}
+
+func NewIndex() *Index {
+	return &Index{entries: make(map[string]Entry)}
+}
+
+// Get returns tags & a note of an entry, false if it has neither.
+func (x *Index) Get(store, p string) (Entry, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.entries[store+p]
+	if !ok {
+		return Entry{Store: store, Path: p}, false
+	}
+	e.Tags = slices.Clone(e.Tags)
+	return e, true
+}
+
+// Put sets tags & a note of an entry, an entry without both is removed from the index.
+// Tags are normalized by NormalizeTags. If mirroring is on, tags of local entries are written to xattrs
+// & an error of that is returned while the index is updated anyway.
+func (x *Index) Put(e Entry) error {
+	e.Tags = NormalizeTags(e.Tags)
+	e.Note = strings.TrimSpace(e.Note)
+	x.mu.Lock()
+	prev, existed := x.entries[e.key()]
+	if e.isEmpty() {
+		delete(x.entries, e.key())
+	} else {
+		x.entries[e.key()] = e
+	}
+	mirror := x.mirrorXattrs && e.isLocal() && (existed || len(e.Tags) > 0) && !slices.Equal(prev.Tags, e.Tags)
+	x.mu.Unlock()
+	if mirror {
+		return WriteXattr(e.Path, e.Tags)
+	}
+	return nil
+}
+
+// MirrorXattrs reports whether tags of local entries are mirrored into xattrs.
+func (x *Index) MirrorXattrs() bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.mirrorXattrs
+}
+
+// SetMirrorXattrs turns mirroring on or off. Once turned on, tags of all local entries are written to xattrs,
+// the first error is returned.
+func (x *Index) SetMirrorXattrs(mirror bool) error {
+	x.mu.Lock()
+	x.mirrorXattrs = mirror
+	var local []Entry
+	if mirror {
+		for _, e := range x.entries {
+			if e.isLocal() && len(e.Tags) > 0 {
+				local = append(local, e)
+			}
+		}
+	}
+	x.mu.Unlock()
+	var errs []error
+	for _, e := range local {
+		if err := WriteXattr(e.Path, e.Tags); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to mirror tags of %d entries: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// Tags returns all tags with the number of entries they are assigned to, ordered by tag.
+func (x *Index) Tags() []TagCount {
+	x.mu.RLock()
+	counts := make(map[string]int)
+	for _, e := range x.entries {
+		for _, tag := range e.Tags {
+			counts[tag]++
+		}
+	}
+	x.mu.RUnlock()
+	result := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(result, func(a, b TagCount) int { return strings.Compare(a.Tag, b.Tag) })
+	return result
+}
+
+// Find returns entries having all the given tags ordered by store & path.
+func (x *Index) Find(tags ...string) []Entry {
+	tags = NormalizeTags(tags)
+	if len(tags) == 0 {
+		return nil
+	}
+	x.mu.RLock()
+	var result []Entry
+	for _, e := range x.entries {
+		if hasAll(e.Tags, tags) {
+			e.Tags = slices.Clone(e.Tags)
+			result = append(result, e)
+		}
+	}
+	x.mu.RUnlock()
+	sortEntries(result)
+	return result
+}
+
+func hasAll(tags, required []string) bool {
+	for _, tag := range required {
+		if _, found := slices.BinarySearch(tags, tag); !found {
+			return false
+		}
+	}
+	return true
+}
+
+func sortEntries(entries []Entry) {
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.key(), b.key()) })
+}
+
+// ApplyRecord keeps tags & notes with their entries after they are deleted, renamed or moved.
+// Returns true if the index has been changed.
+func (x *Index) ApplyRecord(r ftjournal.Record) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var changed bool
+	switch r.Action {
+	case ftjournal.ActionDelete:
+		for key, e := range x.entries {
+			if e.Store == r.Store && isPathOrDescendant(e.Path, r.Paths...) {
+				delete(x.entries, key)
+				changed = true
+			}
+		}
+	case ftjournal.ActionRename, ftjournal.ActionMove:
+		// Moves of a batch are applied simultaneously, e.g. a swap of names.
+		moved := make(map[string]Entry)
+		for key, e := range x.entries {
+			if e.Store != r.Store {
+				continue
+			}
+			for _, m := range r.Moves {
+				if isPathOrDescendant(e.Path, m.From) {
+					delete(x.entries, key)
+					e.Path = m.To + strings.TrimPrefix(e.Path, m.From)
+					moved[e.key()] = e
+					break
+				}
+			}
+		}
+		for key, e := range moved {
+			x.entries[key] = e
+			changed = true
+		}
+	}
+	return changed
+}
+
+func isPathOrDescendant(p string, parents ...string) bool {
+	for _, parent := range parents {
+		if p == parent || strings.HasPrefix(p, strings.TrimSuffix(parent, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags trims & lower-cases tags dropping a leading '#', empty tags & duplicates. The result is sorted.
+func NormalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag != "" {
+			result = append(result, tag)
+		}
+	}
+	slices.Sort(result)
+	result = slices.Compact(result)
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// ParseTags splits a text typed by a user into normalized tags, tags are separated by commas or spaces.
+func ParseTags(text string) []string {
+	return NormalizeTags(strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}))
+}
+
+// chipColors are backgrounds of tag chips readable with black text.
+var chipColors = []string{"lightgreen", "lightskyblue", "khaki", "lightpink", "plum", "aquamarine", "lightsalmon", "palegreen"}
+
+// Color returns a color name of a tag chip, the same tag always gets the same color.
+func Color(tag string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(tag))
+	return chipColors[h.Sum32()%uint32(len(chipColors))]
+}
+
+// Load reads the index persisted by Save. A missing file is an empty index.
+func Load(filePath string) (*Index, error) {
+	x := NewIndex()
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return x, nil
+		}
+		return x, err
+	}
+	var f indexFile
+	if err = yaml.Unmarshal(data, &f); err != nil {
+		return x, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	x.mirrorXattrs = f.MirrorXattrs
+	for _, e := range f.Entries {
+		e.Tags = NormalizeTags(e.Tags)
+		if !e.isEmpty() {
+			x.entries[e.key()] = e
+		}
+	}
+	return x, nil
+}
+
+func (x *Index) Save(filePath string) error {
+	x.mu.RLock()
+	f := indexFile{MirrorXattrs: x.mirrorXattrs, Entries: make([]Entry, 0, len(x.entries))}
+	for _, e := range x.entries {
+		f.Entries = append(f.Entries, e)
+	}
+	x.mu.RUnlock()
+	sortEntries(f.Entries)
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
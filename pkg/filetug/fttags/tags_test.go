@@ -0,0 +1,103 @@
+package fttags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"draft", "todo", "work"}, ParseTags(" #Work, todo draft,,TODO "))
+	assert.Nil(t, ParseTags(" , "))
+}
+
+func TestIndex_PutGet(t *testing.T) {
+	t.Parallel()
+	x := NewIndex()
+	e, ok := x.Get("file:", "/a")
+	assert.False(t, ok)
+	assert.Equal(t, Entry{Store: "file:", Path: "/a"}, e)
+
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/a", Tags: []string{"Work", "draft"}, Note: " to review "}))
+	e, ok = x.Get("file:", "/a")
+	assert.True(t, ok)
+	assert.Equal(t, Entry{Store: "file:", Path: "/a", Tags: []string{"draft", "work"}, Note: "to review"}, e)
+	e.Tags[0] = "changed"
+	e, _ = x.Get("file:", "/a")
+	assert.Equal(t, "draft", e.Tags[0], "a copy is returned")
+
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/a"}))
+	_, ok = x.Get("file:", "/a")
+	assert.False(t, ok, "entries without tags & a note are removed")
+}
+
+func TestIndex_TagsFind(t *testing.T) {
+	t.Parallel()
+	x := NewIndex()
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/b", Tags: []string{"work", "todo"}}))
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/a", Tags: []string{"work"}, IsDir: true}))
+	require.NoError(t, x.Put(Entry{Store: "ftp://h", Path: "/a", Note: "no tags"}))
+	assert.Equal(t, []TagCount{{Tag: "todo", Count: 1}, {Tag: "work", Count: 2}}, x.Tags())
+	assert.Equal(t, []Entry{
+		{Store: "file:", Path: "/a", IsDir: true, Tags: []string{"work"}},
+		{Store: "file:", Path: "/b", Tags: []string{"todo", "work"}},
+	}, x.Find("work"))
+	assert.Len(t, x.Find("Work", "todo"), 1, "all tags are required")
+	assert.Empty(t, x.Find("work", "other"))
+	assert.Nil(t, x.Find())
+}
+
+func TestIndex_ApplyRecord(t *testing.T) {
+	t.Parallel()
+	x := NewIndex()
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/a/1", Tags: []string{"x"}}))
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/b", Tags: []string{"y"}}))
+	require.NoError(t, x.Put(Entry{Store: "ftp://h", Path: "/a/1", Tags: []string{"z"}}))
+	assert.False(t, x.ApplyRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionCopy, Moves: []ftjournal.Move{{From: "/a", To: "/c"}}}))
+	assert.True(t, x.ApplyRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionMove, Moves: []ftjournal.Move{
+		{From: "/a", To: "/b"},
+		{From: "/b", To: "/a"},
+	}}))
+	e, _ := x.Get("file:", "/b/1")
+	assert.Equal(t, []string{"x"}, e.Tags)
+	e, _ = x.Get("file:", "/a")
+	assert.Equal(t, []string{"y"}, e.Tags, "moves are applied simultaneously")
+	_, ok := x.Get("ftp://h", "/a/1")
+	assert.True(t, ok, "entries of other stores are kept")
+
+	assert.True(t, x.ApplyRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionDelete, Paths: []string{"/b"}}))
+	_, ok = x.Get("file:", "/b/1")
+	assert.False(t, ok)
+}
+
+func TestColor(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, Color("work"), Color("work"))
+	assert.Contains(t, chipColors, Color("todo"))
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", "tags.yaml")
+	x, err := Load(filePath)
+	require.NoError(t, err)
+	assert.Empty(t, x.Tags())
+
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/b", Note: "note"}))
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/a", Tags: []string{"work"}}))
+	x.mirrorXattrs = true
+	require.NoError(t, x.Save(filePath))
+	loaded, err := Load(filePath)
+	require.NoError(t, err)
+	assert.True(t, loaded.MirrorXattrs())
+	assert.Equal(t, x.entries, loaded.entries)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("entries: [b"), 0o644))
+	_, err = Load(filePath)
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package fttags
+
+import (
+	"errors"
+	"strings"
+)
+
+// XattrName is the extended attribute tags of local files are mirrored into, as used by KDE & other file managers.
+const XattrName = "user.xdg.tags"
+
+var ErrXattrNotSupported = errors.New("extended attributes are not supported on this platform")
+
+// WriteXattr mirrors tags of a local file into the user.xdg.tags attribute as a comma-separated list,
+// the attribute is removed if there are no tags.
+func WriteXattr(filePath string, tags []string) error {
+	if len(tags) == 0 {
+		return removeXattr(filePath, XattrName)
+	}
+	return setXattr(filePath, XattrName, []byte(strings.Join(tags, ",")))
+}
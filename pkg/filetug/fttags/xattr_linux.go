@@ -0,0 +1,5 @@
+package fttags
+
+import "golang.org/x/sys/unix"
+
+const errNoXattr = unix.ENODATA
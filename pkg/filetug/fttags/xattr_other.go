@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package fttags
+
+func setXattr(_, _ string, _ []byte) error {
+	return ErrXattrNotSupported
+}
+
+func removeXattr(_, _ string) error {
+	return ErrXattrNotSupported
+}
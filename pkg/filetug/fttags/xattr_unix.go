@@ -0,0 +1,23 @@
+//go:build linux || darwin
+
+package fttags
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+var unixSetxattr = unix.Setxattr
+var unixRemovexattr = unix.Removexattr
+
+func setXattr(filePath, name string, data []byte) error {
+	return unixSetxattr(filePath, name, data, 0)
+}
+
+func removeXattr(filePath, name string) error {
+	if err := unixRemovexattr(filePath, name); err != nil && !errors.Is(err, errNoXattr) {
+		return err
+	}
+	return nil
+}
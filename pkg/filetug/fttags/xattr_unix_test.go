@@ -0,0 +1,49 @@
+//go:build linux || darwin
+
+package fttags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex_MirrorXattrs(t *testing.T) {
+	attrs := make(map[string]string)
+	origSet, origRemove := unixSetxattr, unixRemovexattr
+	unixSetxattr = func(path string, name string, data []byte, _ int) error {
+		attrs[path+":"+name] = string(data)
+		return nil
+	}
+	unixRemovexattr = func(path string, name string) error {
+		if _, ok := attrs[path+":"+name]; !ok {
+			return errNoXattr
+		}
+		delete(attrs, path+":"+name)
+		return nil
+	}
+	t.Cleanup(func() {
+		unixSetxattr, unixRemovexattr = origSet, origRemove
+	})
+
+	x := NewIndex()
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/a", Tags: []string{"b", "a"}}))
+	require.NoError(t, x.Put(Entry{Store: "ftp://h", Path: "/a", Tags: []string{"c"}}))
+	assert.Empty(t, attrs, "tags are not mirrored by default")
+
+	require.NoError(t, x.SetMirrorXattrs(true))
+	assert.Equal(t, map[string]string{"/a:user.xdg.tags": "a,b"}, attrs, "only local entries are mirrored")
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/a", Note: "note"}))
+	assert.Empty(t, attrs)
+	require.NoError(t, x.Put(Entry{Store: "file:", Path: "/n", Note: "note"}), "no attribute to remove")
+
+	unixSetxattr = func(string, string, []byte, int) error {
+		return errors.New("not supported by the file system")
+	}
+	assert.Error(t, x.Put(Entry{Store: "file:", Path: "/a", Tags: []string{"x"}}))
+	_, ok := x.Get("file:", "/a")
+	assert.True(t, ok, "the index is updated anyway")
+	assert.Error(t, x.SetMirrorXattrs(true))
+}
@@ -9,10 +9,11 @@ import (
 type FilterFunc func(os.DirEntry) bool
 
 type Filter struct {
-	ShowHidden bool
-	ShowDirs   bool
-	Extensions []string
-	MaskFilter FilterFunc
+	ShowHidden  bool
+	ShowDirs    bool
+	Extensions  []string
+	MaskFilter  FilterFunc
+	QueryFilter FilterFunc // a parsed filter query, see ftquery
 }
 
 func (f Filter) IsEmpty() bool {
@@ -44,5 +45,10 @@ func (f Filter) IsVisible(entry os.DirEntry) (isVisible bool) {
 			return false
 		}
 	}
+	if f.QueryFilter != nil {
+		if !f.QueryFilter(entry) {
+			return false
+		}
+	}
 	return true
 }
@@ -112,6 +112,23 @@ func TestFilter_IsVisible(t *testing.T) {
 			entry: files.NewDirEntry("main.go", false),
 			want:  false,
 		},
+		{
+			name: "query_filter_mismatch",
+			filter: Filter{
+				MaskFilter:  func(entry os.DirEntry) bool { return true },
+				QueryFilter: func(entry os.DirEntry) bool { return entry.Name() != "main.go" },
+			},
+			entry: files.NewDirEntry("main.go", false),
+			want:  false,
+		},
+		{
+			name: "query_filter_match",
+			filter: Filter{
+				QueryFilter: func(entry os.DirEntry) bool { return entry.Name() == "main.go" },
+			},
+			entry: files.NewDirEntry("main.go", false),
+			want:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -12,13 +12,34 @@ func showHelpModal(nav *Navigator) {
 
 func createHelpModal(nav *Navigator, root tview.Primitive) (modal tview.Primitive, helpView *tview.TextView, button *tview.Button) {
 	const helpText = `F1 - Help
-Alt+F - Favorites
-Alt+G - Go to...
+Alt+F - Favorites & recent dirs
+Alt+V - Volumes & free space
+Alt+T - Tasks: running operations
+Alt+Y - History of operations
+Alt+N - Notifications: errors, warnings & info messages
+Ctrl+Z / Ctrl+Y - Undo / redo last operation
+Space/Insert - Select entry, Shift+↑/↓ select range
++ / - / * - Select all / clear / invert selection
+Type in files - Fuzzy search (M for names starting with m): Tab filter mode, Enter done, n/N next/previous match, Esc clear
+/ - Filter files by a query, e.g. ext:go,md size>1M modified<3d !hidden, ↑/↓ recent queries
+Alt+M - Masks: Enter select, Shift+Enter deselect, f filter, n/e/d new/edit/delete
+Ctrl+B - Add to / remove from basket, selection is added
+Alt+K - Basket: c copy, m move here, x remove, D D delete all items
+Alt+L - Lists: a add selection, n/e/d new/edit/delete; in a list c copy, m move here, x remove, D D delete all items
+m<letter> / '<letter> - Set a mark on the dir & file / jump to it, A-Z marks are kept across sessions
+Alt+B - Bookmarks: marks, Enter jump, r rename, d delete
+Ctrl+T - Tags & a note of the current entry or the selection
+Alt+A - Tags: Space to combine tags, Enter to browse tagged entries
+Ctrl+A - Archive selection or basket (zip, tar.gz)
+Ctrl+E - Extract archive here or to a dir
+Ctrl+D - Mark a file for diff, then diff it with another file
+Alt+O - Go to a frequent or recent dir: type keywords like in zoxide, Ctrl+X forget a dir
 Al+P - Show/Hide previewerPanel
 Alt+C - Copy filesPanel & directories
 Alt+M - Move filesPanel & directories
 Alt+D - Delete filesPanel & directories
-Alt+V - View file
+F3 - View file
+F6 - Rename (bulk rename with regex & templates)
 Alt+E - Edit file
 Alt+= - Increase panel size
 Alt+- - Decrease panel size
@@ -76,7 +97,7 @@ Alt+X - Exit the app`
 	// Update modal to use helpFlex
 	modal = tview.NewGrid().
 		SetColumns(0, 40, 0).
-		SetRows(0, 13, 0).
+		SetRows(0, 22, 0).
 		AddItem(helpFlex, 1, 1, 1, 1, 0, 0, true)
 
 	return modal, helpView, button
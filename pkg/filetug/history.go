@@ -0,0 +1,341 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftdupes"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftrename"
+	"github.com/filetug/filetug/pkg/gitutils"
+)
+
+const (
+	undoOperation OperationType = "undo"
+	redoOperation OperationType = "redo"
+)
+
+var getHistoryJournal = ftjournal.DefaultJournal
+var gitStageFile = gitutils.StageFile
+var gitUnstageFile = gitutils.UnstageFile
+
+// recordHistory appends a mutating action to the history journal.
+// It is safe to be called from operation goroutines.
+func (nav *Navigator) recordHistory(store files.Store, r ftjournal.Record) {
+	if r.Store == "" && store != nil {
+		rootURL := store.RootURL()
+		r.Store = rootURL.String()
+	}
+	nav.syncBasket(r)
+	nav.syncLists(r)
+	nav.syncTags(r)
+	nav.syncMarks(r)
+	journal, err := getHistoryJournal()
+	if err == nil {
+		_, err = journal.Append(r)
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to record history: %w", err))
+	}
+}
+
+// onGitStageChanged is called by the git status previewer once a file is staged or unstaged.
+func (nav *Navigator) onGitStageChanged(fullPath string, staged bool) {
+	action := ftjournal.ActionGitUnstage
+	if staged {
+		action = ftjournal.ActionGitStage
+	}
+	nav.recordHistory(nav.store, ftjournal.Record{Action: action, Paths: []string{fullPath}})
+}
+
+// undo reverts the most recent reversible action from the history journal.
+func (nav *Navigator) undo() *Operation {
+	return nav.undoRedo(true)
+}
+
+// redo applies again the most recently undone action.
+func (nav *Navigator) redo() *Operation {
+	return nav.undoRedo(false)
+}
+
+func (nav *Navigator) undoRedo(isUndo bool) *Operation {
+	// Records are appended to the journal once the work is done, so a second undo started meanwhile
+	// would pick the same record & apply it twice.
+	if !nav.undoRedoRunning.CompareAndSwap(false, true) {
+		nav.notify(notificationWarning, "History", "wait for the running undo or redo to finish")
+		return nil
+	}
+	started := false
+	defer func() {
+		if !started {
+			nav.undoRedoRunning.Store(false)
+		}
+	}()
+	journal, err := getHistoryJournal()
+	if err != nil {
+		nav.showError(err)
+		return nil
+	}
+	history, err := journal.History()
+	if err != nil {
+		nav.showError(err)
+		return nil
+	}
+	var r ftjournal.Record
+	if isUndo {
+		r, err = history.UndoCandidate()
+	} else {
+		r, err = history.RedoCandidate()
+	}
+	if err != nil {
+		nav.showError(err)
+		return nil
+	}
+	store := nav.store
+	rootURL := store.RootURL()
+	if r.Store != rootURL.String() {
+		nav.showError(fmt.Errorf("%s: was made in another store: %s", r.Title(), r.Store))
+		return nil
+	}
+	operationType, action, title := redoOperation, ftjournal.ActionRedo, "Redo: "+r.Title()
+	if isUndo {
+		operationType, action, title = undoOperation, ftjournal.ActionUndo, "Undo: "+r.Title()
+	}
+	started = true
+	return nav.operations.Start(operationType, title, r.Dirs(),
+		func(ctx context.Context, _ ProgressReporter) error {
+			defer nav.undoRedoRunning.Store(false)
+			var err error
+			if isUndo {
+				err = undoRecord(ctx, store, r)
+			} else {
+				err = redoRecord(ctx, store, r)
+			}
+			if err != nil {
+				return err
+			}
+			if isUndo {
+				nav.syncBasket(inverseRecord(r))
+				nav.syncLists(inverseRecord(r))
+				nav.syncTags(inverseRecord(r))
+				nav.syncMarks(inverseRecord(r))
+			} else {
+				nav.syncBasket(r)
+				nav.syncLists(r)
+				nav.syncTags(r)
+				nav.syncMarks(r)
+			}
+			if _, err = journal.Append(ftjournal.Record{Action: action, Store: r.Store, Target: r.ID}); err != nil {
+				return err
+			}
+			if r.BatchID != "" {
+				return setRenameBatchUndone(r.BatchID, isUndo)
+			}
+			return nil
+		},
+	)
+}
+
+// setRenameBatchUndone keeps the rename journal in sync with the history journal.
+func setRenameBatchUndone(batchID string, undone bool) error {
+	renameJournal, err := getRenameJournal()
+	if err != nil {
+		return err
+	}
+	return renameJournal.SetUndone(batchID, undone)
+}
+
+// inverseRecord describes the effect of undoing a record on entries, e.g. undo of a creation is a deletion.
+func inverseRecord(r ftjournal.Record) ftjournal.Record {
+	inverse := ftjournal.Record{Store: r.Store, Action: r.Action}
+	switch r.Action {
+	case ftjournal.ActionCreateDir, ftjournal.ActionCreateFile, ftjournal.ActionWriteFile, ftjournal.ActionArchive, ftjournal.ActionExtract:
+		inverse.Action = ftjournal.ActionDelete
+		inverse.Paths = r.Paths
+	case ftjournal.ActionCopy:
+		inverse.Action = ftjournal.ActionDelete
+		for _, m := range r.Moves {
+			inverse.Paths = append(inverse.Paths, m.To)
+		}
+	case ftjournal.ActionRename, ftjournal.ActionMove:
+		inverse.Moves = r.InverseMoves()
+	}
+	return inverse
+}
+
+func undoRecord(ctx context.Context, store files.Store, r ftjournal.Record) error {
+	switch r.Action {
+	case ftjournal.ActionCreateFile:
+		for _, p := range r.Paths {
+			if err := deleteCreatedFile(ctx, store, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ftjournal.ActionCreateDir, ftjournal.ActionWriteFile, ftjournal.ActionArchive, ftjournal.ActionExtract:
+		for i := len(r.Paths) - 1; i >= 0; i-- {
+			if err := store.Delete(ctx, r.Paths[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ftjournal.ActionRename, ftjournal.ActionMove:
+		return applyMoves(ctx, store, r.InverseMoves())
+	case ftjournal.ActionCopy:
+		for _, m := range r.Moves {
+			if err := deleteTree(ctx, store, m.To); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ftjournal.ActionHardLink, ftjournal.ActionSymlink:
+		for _, m := range r.Moves {
+			if err := ftdupes.ReplaceWithCopy(ctx, store, m.From, m.To); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ftjournal.ActionGitStage:
+		return forEachPath(r.Paths, gitUnstageFile)
+	case ftjournal.ActionGitUnstage:
+		return forEachPath(r.Paths, gitStageFile)
+	default:
+		return fmt.Errorf("%s: %w", r.Title(), files.ErrNotSupported)
+	}
+}
+
+// errChangedSinceRecorded is returned by undo of an action whose result has been changed by a user since.
+var errChangedSinceRecorded = errors.New("changed since the action, not undone")
+
+// deleteCreatedFile deletes a file created empty unless content has been written to it since.
+func deleteCreatedFile(ctx context.Context, store files.Store, p string) error {
+	entries, err := store.ReadDir(ctx, path.Dir(p))
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(entries, func(e os.DirEntry) bool { return e.Name() == path.Base(p) })
+	if i < 0 {
+		return fmt.Errorf("%s: %w", p, os.ErrNotExist)
+	}
+	info, err := entries[i].Info()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() || info.Size() > 0 {
+		return fmt.Errorf("%s: %w", p, errChangedSinceRecorded)
+	}
+	return store.Delete(ctx, p)
+}
+
+// deleteTree deletes a file or a dir with all its content as stores delete only empty dirs.
+func deleteTree(ctx context.Context, store files.Store, p string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Reading a file as a dir fails, it is deleted as is.
+	if entries, err := store.ReadDir(ctx, p); err == nil {
+		for _, e := range entries {
+			child := path.Join(p, e.Name())
+			if e.IsDir() {
+				err = deleteTree(ctx, store, child)
+			} else {
+				err = store.Delete(ctx, child)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return store.Delete(ctx, p)
+}
+
+func redoRecord(ctx context.Context, store files.Store, r ftjournal.Record) error {
+	switch r.Action {
+	case ftjournal.ActionCreateDir:
+		for _, p := range r.Paths {
+			if err := store.CreateDir(ctx, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ftjournal.ActionCreateFile:
+		for _, p := range r.Paths {
+			if err := store.CreateFile(ctx, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ftjournal.ActionRename, ftjournal.ActionMove:
+		return applyMoves(ctx, store, r.Moves)
+	case ftjournal.ActionHardLink, ftjournal.ActionSymlink:
+		kind := ftdupes.HardLink
+		if r.Action == ftjournal.ActionSymlink {
+			kind = ftdupes.SymLink
+		}
+		for _, m := range r.Moves {
+			if err := ftdupes.ReplaceWithLink(ctx, store, kind, m.From, m.To); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ftjournal.ActionGitStage:
+		return forEachPath(r.Paths, gitStageFile)
+	case ftjournal.ActionGitUnstage:
+		return forEachPath(r.Paths, gitUnstageFile)
+	default:
+		return fmt.Errorf("%s: %w", r.Title(), files.ErrNotSupported)
+	}
+}
+
+func forEachPath(paths []string, f func(string) error) error {
+	for _, p := range paths {
+		if err := f(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMoves renames entries. Renames within a single directory are applied
+// atomically via temporary names so swaps are handled.
+func applyMoves(ctx context.Context, store files.Store, moves []ftjournal.Move) error {
+	renamer, ok := store.(files.Renamer)
+	if !ok {
+		return errRenameNotSupported
+	}
+	if changes, ok := movesToChanges(moves); ok {
+		return ftrename.Apply(ctx, renamer, changes, nil)
+	}
+	for _, m := range moves {
+		if err := renamer.Rename(ctx, m.From, m.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func movesToChanges(moves []ftjournal.Move) ([]ftrename.Change, bool) {
+	changes := make([]ftrename.Change, len(moves))
+	for i, m := range moves {
+		fromDir, fromName := path.Split(m.From)
+		toDir, toName := path.Split(m.To)
+		if fromDir != toDir {
+			return nil, false
+		}
+		changes[i] = ftrename.Change{Dir: path.Clean(fromDir), From: fromName, To: toName}
+	}
+	return changes, true
+}
+
+func renameBatchMoves(batch ftrename.Batch) []ftjournal.Move {
+	moves := make([]ftjournal.Move, len(batch.Changes))
+	for i, c := range batch.Changes {
+		moves[i] = ftjournal.Move{From: path.Join(c.Dir, c.From), To: path.Join(c.Dir, c.To)}
+	}
+	return moves
+}
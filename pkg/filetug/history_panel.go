@@ -0,0 +1,149 @@
+package filetug
+
+import (
+	"fmt"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// historyPanel lists mutating actions recorded in the history journal, the most recent first.
+type historyPanel struct {
+	*sneatv.Boxed
+	nav     *Navigator
+	table   *tview.Table
+	entries []ftjournal.Entry
+}
+
+const historyTimeLayout = "2006-01-02 15:04:05"
+
+func (nav *Navigator) showHistoryPanel() {
+	if nav.historyPanel == nil {
+		nav.historyPanel = newHistoryPanel(nav)
+	}
+	nav.historyPanel.render()
+	nav.right.SetContent(nav.historyPanel)
+	nav.app.SetFocus(nav.historyPanel.table)
+}
+
+func newHistoryPanel(nav *Navigator) *historyPanel {
+	table := tview.NewTable()
+	table.SetSelectable(true, false)
+	footer := tview.NewTextView().
+		SetText("Ctrl+Z/u: undo · Ctrl+Y/r: redo · Enter: go to dir").
+		SetTextColor(tcell.ColorGray)
+	p := &historyPanel{
+		nav:   nav,
+		table: table,
+		Boxed: sneatv.NewBoxed(
+			table,
+			sneatv.WithLeftBorder(0, -1),
+			sneatv.WithFooter(footer),
+		),
+	}
+	p.SetTitle("History")
+	table.SetInputCapture(p.inputCapture)
+	table.SetFocusFunc(func() {
+		nav.activeCol = 2
+	})
+	return p
+}
+
+func (p *historyPanel) render() {
+	p.table.Clear()
+	p.entries = nil
+	journal, err := getHistoryJournal()
+	var history ftjournal.History
+	if err == nil {
+		history, err = journal.History()
+	}
+	if err != nil {
+		errText := err.Error()
+		p.table.SetCell(0, 0, tview.NewTableCell(tview.Escape(errText)).SetTextColor(tcell.ColorOrangeRed))
+		return
+	}
+	title := "History"
+	if len(history.Malformed) > 0 {
+		title += fmt.Sprintf(" [orangered](%d malformed line(s) skipped)[-]", len(history.Malformed))
+	}
+	p.SetTitle(title)
+	if len(history.Entries) == 0 {
+		p.table.SetCell(0, 0, tview.NewTableCell("[::i]No history yet[::-]").SetTextColor(tcell.ColorGray))
+		return
+	}
+	for i := len(history.Entries) - 1; i >= 0; i-- {
+		entry := history.Entries[i]
+		row := len(p.entries)
+		p.entries = append(p.entries, entry)
+		timeText := entry.Time.Local().Format(historyTimeLayout)
+		color := tcell.ColorWhite
+		var stateText string
+		switch {
+		case entry.Undone:
+			color = tcell.ColorGray
+			stateText = "undone"
+		case !entry.CanUndo():
+			stateText = "irreversible"
+		}
+		p.table.SetCell(row, 0, tview.NewTableCell(timeText).SetTextColor(tcell.ColorGray))
+		p.table.SetCell(row, 1, tview.NewTableCell(tview.Escape(entry.Title())).SetTextColor(color).SetExpansion(1))
+		p.table.SetCell(row, 2, tview.NewTableCell(stateText).SetTextColor(tcell.ColorGray))
+	}
+}
+
+func (p *historyPanel) selectedEntry() (ftjournal.Entry, bool) {
+	row, _ := p.table.GetSelection()
+	if row < 0 || row >= len(p.entries) {
+		return ftjournal.Entry{}, false
+	}
+	return p.entries[row], true
+}
+
+func (p *historyPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.nav.right.SetContent(p.nav.previewer)
+		p.nav.app.SetFocus(p.nav.files)
+		return nil
+	case tcell.KeyLeft:
+		p.nav.app.SetFocus(p.nav.files)
+		return nil
+	case tcell.KeyCtrlZ:
+		p.nav.undo()
+		return nil
+	case tcell.KeyCtrlY:
+		p.nav.redo()
+		return nil
+	case tcell.KeyEnter:
+		entry, ok := p.selectedEntry()
+		if !ok {
+			return nil
+		}
+		dirs := entry.Dirs()
+		if len(dirs) > 0 {
+			p.nav.goDir(files.NewDirContext(p.nav.store, dirs[0], nil))
+		}
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'u', 'U':
+			p.nav.undo()
+			return nil
+		case 'r', 'R':
+			p.nav.redo()
+			return nil
+		}
+		return event
+	default:
+		return event
+	}
+}
+
+func (nav *Navigator) renderHistoryPanelIfVisible() {
+	if nav.historyPanel != nil && nav.right.content == nav.historyPanel {
+		nav.historyPanel.render()
+	}
+}
@@ -0,0 +1,112 @@
+package filetug
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryPanel(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t)
+
+	nav.showHistoryPanel()
+	p := nav.historyPanel
+	assert.Equal(t, p, nav.right.content)
+	assert.Contains(t, p.table.GetCell(0, 0).Text, "No history yet")
+
+	nav.recordHistory(nav.store, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: []string{"/gone"}})
+	nav.newPanel.input.SetText("new.txt")
+	nav.newPanel.createFile()
+	nav.showHistoryPanel()
+	assert.Equal(t, 2, p.table.GetRowCount())
+	assert.Equal(t, "Create file "+filepath.Join(dir, "new.txt"), p.table.GetCell(0, 1).Text)
+	assert.Equal(t, "irreversible", p.table.GetCell(1, 2).Text)
+
+	key := func(r rune) *tcell.EventKey {
+		return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+
+	t.Run("undo_redo", func(t *testing.T) {
+		p.table.Select(0, 0)
+		assert.Nil(t, p.inputCapture(key('u')))
+		operations := nav.operations.Operations()
+		waitOperation(t, operations[len(operations)-1])
+		drainQueuedUpdates(updates)
+		p.render() // the update rendering it may be queued after the operation is waited for
+		assert.Equal(t, "undone", p.table.GetCell(0, 2).Text)
+		_, err := os.Stat(filepath.Join(dir, "new.txt"))
+		assert.True(t, os.IsNotExist(err))
+
+		assert.Nil(t, p.inputCapture(key('r')))
+		operations = nav.operations.Operations()
+		waitOperation(t, operations[len(operations)-1])
+		drainQueuedUpdates(updates)
+		p.render()
+		assert.Equal(t, "", p.table.GetCell(0, 2).Text)
+
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyCtrlZ, 0, tcell.ModCtrl)))
+		waitOperation(t, lastOperation(nav))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModCtrl)))
+		waitOperation(t, lastOperation(nav))
+		drainQueuedUpdates(updates)
+		p.render()
+		assert.Equal(t, "", p.table.GetCell(0, 2).Text)
+	})
+
+	t.Run("enter_goes_to_dir", func(t *testing.T) {
+		p.table.Select(0, 0)
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)))
+		assert.Equal(t, dir, nav.currentDirPath())
+	})
+
+	t.Run("other_keys", func(t *testing.T) {
+		event := key('z')
+		assert.Equal(t, event, p.inputCapture(event))
+		event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone)))
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		nav.showHistoryPanel()
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+
+	t.Run("journal_error", func(t *testing.T) {
+		dirPath := t.TempDir() // a dir can't be read as a journal
+		getHistoryJournal = func() (*ftjournal.Journal, error) {
+			return ftjournal.NewJournal(dirPath), nil
+		}
+		p.render()
+		assert.Contains(t, p.table.GetCell(0, 0).Text, "is a directory")
+	})
+}
+
+func TestHistoryPanel_MalformedLines(t *testing.T) {
+	journal := withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	_, err := journal.Append(ftjournal.Record{Action: ftjournal.ActionCreateDir, Store: "file:", Paths: []string{dir}})
+	assert.NoError(t, err)
+	filePath := filepath.Join(t.TempDir(), "history.jsonl")
+	records, err := journal.Load()
+	assert.NoError(t, err)
+	data, err := json.Marshal(records[0])
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filePath, append(data, "\n{\"id\":"...), 0o644))
+	getHistoryJournal = func() (*ftjournal.Journal, error) {
+		return ftjournal.NewJournal(filePath), nil
+	}
+
+	nav.showHistoryPanel()
+	p := nav.historyPanel
+	assert.Contains(t, p.GetTitle(), "1 malformed line(s) skipped")
+	assert.Len(t, p.entries, 1, "the history is usable despite a half-written line")
+}
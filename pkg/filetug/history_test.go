@@ -0,0 +1,308 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftrename"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func withHistoryJournal(t *testing.T) *ftjournal.Journal {
+	t.Helper()
+	journal := ftjournal.NewJournal(filepath.Join(t.TempDir(), "history.jsonl"))
+	orig := getHistoryJournal
+	getHistoryJournal = func() (*ftjournal.Journal, error) {
+		return journal, nil
+	}
+	t.Cleanup(func() {
+		getHistoryJournal = orig
+	})
+	return journal
+}
+
+func waitOperation(t *testing.T, o *Operation) {
+	t.Helper()
+	if !assert.NotNil(t, o) {
+		t.FailNow()
+	}
+	assert.NoError(t, o.Wait())
+}
+
+func TestNavigator_UndoRedo_Keys(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	nav.newPanel.input.SetText("new.txt")
+	nav.newPanel.createFile()
+	filePath := filepath.Join(dir, "new.txt")
+
+	ctrlZ := tcell.NewEventKey(tcell.KeyCtrlZ, 0, tcell.ModCtrl)
+	assert.Equal(t, ctrlZ, nav.inputCapture(ctrlZ), "input fields keep Ctrl+Z")
+	assert.Empty(t, nav.operations.Operations())
+
+	assert.Nil(t, nav.files.inputCapture(ctrlZ))
+	waitOperation(t, lastOperation(nav))
+	_, err := os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, nav.dirsTree.inputCapture(tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModCtrl)))
+	waitOperation(t, lastOperation(nav))
+	_, err = os.Stat(filePath)
+	assert.NoError(t, err)
+}
+
+func TestNavigator_UndoRedo_CreateFile(t *testing.T) {
+	withRenameJournal(t)
+	journal := withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	nav.newPanel.input.SetText("new.txt")
+	nav.newPanel.createFile()
+	filePath := filepath.Join(dir, "new.txt")
+
+	records, err := journal.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []ftjournal.Record{{
+		ID:     records[0].ID,
+		Time:   records[0].Time,
+		Store:  "file:",
+		Action: ftjournal.ActionCreateFile,
+		Paths:  []string{filePath},
+	}}, records)
+
+	waitOperation(t, nav.undo())
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+
+	waitOperation(t, nav.redo())
+	_, err = os.Stat(filePath)
+	assert.NoError(t, err)
+
+	history, err := journal.History()
+	assert.NoError(t, err)
+	assert.False(t, history.Entries[0].Undone)
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("typed"), 0o644))
+	assert.ErrorIs(t, nav.undo().Wait(), errChangedSinceRecorded, "content written since creation is kept")
+	assertFileContent(t, filePath, "typed")
+}
+
+func TestNavigator_Undo_OneAtATime(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	nav.newPanel.input.SetText("new.txt")
+	nav.newPanel.createFile()
+	filePath := filepath.Join(dir, "new.txt")
+
+	nav.undoRedoRunning.Store(true) // an undo is in progress
+	assert.Nil(t, nav.undo())
+	assert.Nil(t, nav.redo())
+	assert.Equal(t, "wait for the running undo or redo to finish", lastNotification(nav).message)
+	_, err := os.Stat(filePath)
+	assert.NoError(t, err)
+
+	nav.undoRedoRunning.Store(false)
+	waitOperation(t, nav.undo())
+	assert.False(t, nav.undoRedoRunning.Load(), "released once the undo is done")
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, nav.undo(), "nothing left to undo")
+	assert.False(t, nav.undoRedoRunning.Load(), "released if nothing is started")
+}
+
+func TestNavigator_UndoRedo_CreateDir(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t)
+	nav.newPanel.input.SetText("sub")
+	nav.newPanel.createDir()
+	dirPath := filepath.Join(dir, "sub")
+
+	waitOperation(t, nav.undo())
+	_, err := os.Stat(dirPath)
+	assert.True(t, os.IsNotExist(err))
+
+	waitOperation(t, nav.redo())
+	info, err := os.Stat(dirPath)
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestNavigator_UndoRedo_Rename(t *testing.T) {
+	renameJournal := withRenameJournal(t)
+	withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t, "a", "b")
+	batch := ftrename.NewBatch([]ftrename.Change{
+		{Dir: dir, From: "a", To: "b"},
+		{Dir: dir, From: "b", To: "a"},
+	})
+	waitOperation(t, nav.applyRenameBatch(batch))
+	assertFileContent(t, filepath.Join(dir, "a"), "b")
+
+	waitOperation(t, nav.undo())
+	assertFileContent(t, filepath.Join(dir, "a"), "a")
+	_, err := renameJournal.LastUndoable()
+	assert.ErrorIs(t, err, ftrename.ErrNothingToUndo)
+
+	waitOperation(t, nav.redo())
+	assertFileContent(t, filepath.Join(dir, "a"), "b")
+	last, err := renameJournal.LastUndoable()
+	assert.NoError(t, err)
+	assert.Equal(t, batch.ID, last.ID)
+
+	// Undo via rename panel is reflected in history
+	waitOperation(t, nav.undoLastRename())
+	assertFileContent(t, filepath.Join(dir, "a"), "a")
+	waitOperation(t, nav.redo())
+	assertFileContent(t, filepath.Join(dir, "a"), "b")
+}
+
+func assertFileContent(t *testing.T, filePath, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
+
+func TestNavigator_UndoRedo_MoveAndCopy(t *testing.T) {
+	withRenameJournal(t)
+	journal := withHistoryJournal(t)
+	nav, _, dir := newNavigatorWithLocalDir(t, "moved", "copy")
+	sub := filepath.Join(dir, "sub")
+	assert.NoError(t, os.Mkdir(sub, 0o755))
+	assert.NoError(t, os.Rename(filepath.Join(dir, "moved"), filepath.Join(sub, "moved")))
+
+	_, err := journal.Append(ftjournal.Record{Store: "file:", Action: ftjournal.ActionMove, Moves: []ftjournal.Move{
+		{From: filepath.Join(dir, "moved"), To: filepath.Join(sub, "moved")},
+	}})
+	assert.NoError(t, err)
+	waitOperation(t, nav.undo())
+	assertFileContent(t, filepath.Join(dir, "moved"), "moved")
+	waitOperation(t, nav.redo())
+	assertFileContent(t, filepath.Join(sub, "moved"), "moved")
+
+	_, err = journal.Append(ftjournal.Record{Store: "file:", Action: ftjournal.ActionCopy, Moves: []ftjournal.Move{
+		{From: filepath.Join(dir, "original"), To: filepath.Join(dir, "copy")},
+	}})
+	assert.NoError(t, err)
+	waitOperation(t, nav.undo())
+	_, err = os.Stat(filepath.Join(dir, "copy"))
+	assert.True(t, os.IsNotExist(err))
+
+	copiedDir := filepath.Join(dir, "copied")
+	assert.NoError(t, os.MkdirAll(filepath.Join(copiedDir, "nested"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(copiedDir, "nested", "f.txt"), []byte("f"), 0o644))
+	_, err = journal.Append(ftjournal.Record{Store: "file:", Action: ftjournal.ActionCopy, Moves: []ftjournal.Move{
+		{From: sub, To: copiedDir},
+	}})
+	assert.NoError(t, err)
+	waitOperation(t, nav.undo())
+	_, err = os.Stat(copiedDir)
+	assert.True(t, os.IsNotExist(err), "a copied dir is deleted with its content")
+
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+	assert.Nil(t, nav.redo())
+	assert.ErrorContains(t, shownErr, "can not be redone")
+}
+
+func TestNavigator_UndoRedo_GitStage(t *testing.T) {
+	withTestGlobalLock(t)
+	withHistoryJournal(t)
+	var calls []string
+	origStage, origUnstage := gitStageFile, gitUnstageFile
+	gitStageFile = func(p string) error {
+		calls = append(calls, "stage "+p)
+		return nil
+	}
+	gitUnstageFile = func(p string) error {
+		calls = append(calls, "unstage "+p)
+		return nil
+	}
+	t.Cleanup(func() {
+		gitStageFile, gitUnstageFile = origStage, origUnstage
+	})
+
+	nav, _ := newNavigatorWithQueuedUpdates(t)
+	nav.onGitStageChanged("/repo/a.txt", true)
+	waitOperation(t, nav.undo())
+	waitOperation(t, nav.redo())
+	nav.onGitStageChanged("/repo/b.txt", false)
+	waitOperation(t, nav.undo())
+	waitOperation(t, nav.redo())
+	assert.Equal(t, []string{
+		"unstage /repo/a.txt",
+		"stage /repo/a.txt",
+		"stage /repo/b.txt",
+		"unstage /repo/b.txt",
+	}, calls)
+}
+
+func TestNavigator_UndoRedo_Errors(t *testing.T) {
+	withTestGlobalLock(t)
+	journal := withHistoryJournal(t)
+	nav, _ := newNavigatorWithQueuedUpdates(t)
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+
+	assert.Nil(t, nav.undo())
+	assert.ErrorIs(t, shownErr, ftjournal.ErrNothingToUndo)
+	assert.Nil(t, nav.redo())
+	assert.ErrorIs(t, shownErr, ftjournal.ErrNothingToRedo)
+
+	nav.recordHistory(nav.store, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: []string{"/x"}})
+	assert.Nil(t, nav.undo())
+	assert.EqualError(t, shownErr, "Delete /x: can not be undone")
+
+	_, err := journal.Append(ftjournal.Record{Store: "ftp://example.com", Action: ftjournal.ActionCreateDir, Paths: []string{"/y"}})
+	assert.NoError(t, err)
+	assert.Nil(t, nav.undo())
+	assert.ErrorContains(t, shownErr, "another store")
+
+	getHistoryJournal = func() (*ftjournal.Journal, error) {
+		return nil, errors.New("no home")
+	}
+	assert.Nil(t, nav.undo())
+	assert.EqualError(t, shownErr, "no home")
+	nav.recordHistory(nav.store, ftjournal.Record{Action: ftjournal.ActionCreateDir})
+	assert.EqualError(t, shownErr, "failed to record history: no home")
+}
+
+func TestUndoRedoRecord_NotSupported(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore(t)
+	r := ftjournal.Record{Action: ftjournal.ActionDelete}
+	assert.ErrorIs(t, undoRecord(ctx, store, r), files.ErrNotSupported)
+	assert.ErrorIs(t, redoRecord(ctx, store, r), files.ErrNotSupported)
+	r = ftjournal.Record{Action: ftjournal.ActionRename, Moves: []ftjournal.Move{{From: "/a", To: "/b"}}}
+	assert.ErrorIs(t, undoRecord(ctx, store, r), files.ErrNotSupported)
+}
+
+func TestInverseRecord(t *testing.T) {
+	t.Parallel()
+	moves := []ftjournal.Move{{From: "/a", To: "/b"}}
+	assert.Equal(t,
+		ftjournal.Record{Store: "file:", Action: ftjournal.ActionDelete, Paths: []string{"/a"}},
+		inverseRecord(ftjournal.Record{Store: "file:", Action: ftjournal.ActionCreateFile, Paths: []string{"/a"}}))
+	assert.Equal(t,
+		ftjournal.Record{Action: ftjournal.ActionDelete, Paths: []string{"/b"}},
+		inverseRecord(ftjournal.Record{Action: ftjournal.ActionCopy, Moves: moves}))
+	assert.Equal(t,
+		ftjournal.Record{Action: ftjournal.ActionMove, Moves: []ftjournal.Move{{From: "/b", To: "/a"}}},
+		inverseRecord(ftjournal.Record{Action: ftjournal.ActionMove, Moves: moves}))
+	assert.Equal(t,
+		ftjournal.Record{Action: ftjournal.ActionGitStage},
+		inverseRecord(ftjournal.Record{Action: ftjournal.ActionGitStage, Paths: []string{"/a"}}))
+}
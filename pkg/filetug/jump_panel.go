@@ -0,0 +1,117 @@
+package filetug
+
+import (
+	"net/url"
+
+	"github.com/filetug/filetug/pkg/filetug/ftfrecency"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const maxJumpMatches = 50
+
+// jumpPanel goes to a visited dir matching typed keywords, dirs are ranked by frecency like in zoxide.
+type jumpPanel struct {
+	*sneatv.Boxed
+	nav     *Navigator
+	flex    *tview.Flex
+	input   *tview.InputField
+	list    *tview.List
+	matches []ftfrecency.Match
+}
+
+func (nav *Navigator) showJumpPanel() {
+	p := newJumpPanel(nav)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.input)
+}
+
+func newJumpPanel(nav *Navigator) *jumpPanel {
+	p := &jumpPanel{
+		nav:   nav,
+		flex:  tview.NewFlex().SetDirection(tview.FlexRow),
+		input: tview.NewInputField().SetLabel("Go to: ").SetPlaceholder("keywords of a visited dir"),
+		list:  tview.NewList(),
+	}
+	p.list.ShowSecondaryText(false)
+	p.flex.AddItem(p.input, 1, 0, true)
+	p.flex.AddItem(p.list, 0, 1, false)
+	footer := tview.NewTextView().SetDynamicColors(true).SetTextColor(tcell.ColorGray)
+	footer.SetText("↑/↓ choose · ⏎ go · ctrl+x forget · esc close")
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1), sneatv.WithFooter(footer))
+	p.SetTitle("Go to a frequent or recent dir")
+	p.input.SetChangedFunc(p.update)
+	p.input.SetInputCapture(p.inputCapture)
+	p.update("")
+	return p
+}
+
+// update shows visited dirs matching the query, matched runes are highlighted.
+func (p *jumpPanel) update(query string) {
+	p.matches = p.nav.frecency.Query(query, timeNow(), maxJumpMatches)
+	p.list.Clear()
+	for _, m := range p.matches {
+		text := highlightRunes(m.Path, m.Positions)
+		if root, err := url.Parse(m.Store); err != nil || root.Scheme != "file" {
+			text = "[gray]" + tview.Escape(m.Store) + "[-] " + text
+		}
+		p.list.AddItem(text, "", 0, nil)
+	}
+}
+
+// refresh shows matches again after a dir has been forgotten keeping the cursor in place.
+func (p *jumpPanel) refresh() {
+	i := p.list.GetCurrentItem()
+	p.update(p.input.GetText())
+	p.list.SetCurrentItem(i)
+}
+
+func (p *jumpPanel) current() (ftfrecency.Match, bool) {
+	i := p.list.GetCurrentItem()
+	if i < 0 || i >= len(p.matches) {
+		return ftfrecency.Match{}, false
+	}
+	return p.matches[i], true
+}
+
+func (p *jumpPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyUp, tcell.KeyDown:
+		if count := p.list.GetItemCount(); count > 0 {
+			i := p.list.GetCurrentItem()
+			if event.Key() == tcell.KeyUp {
+				i = (i - 1 + count) % count
+			} else {
+				i = (i + 1) % count
+			}
+			p.list.SetCurrentItem(i)
+		}
+		return nil
+	case tcell.KeyEnter:
+		if m, ok := p.current(); ok {
+			if p.nav.openVisited(m.Entry) {
+				p.close()
+			} else {
+				p.refresh()
+			}
+		}
+		return nil
+	case tcell.KeyCtrlX:
+		if m, ok := p.current(); ok {
+			p.nav.forgetVisited(m.Entry)
+			p.refresh()
+		}
+		return nil
+	case tcell.KeyEscape:
+		p.close()
+		return nil
+	default:
+		return event
+	}
+}
+
+func (p *jumpPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
@@ -0,0 +1,274 @@
+package filetug
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/filetug/ftlists"
+	"github.com/filetug/filetug/pkg/filetug/ftquery"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// listsPanel shows curated lists in the left container, the current list is shown in the files panel.
+type listsPanel struct {
+	*sneatv.Boxed
+	flex   *tview.Flex
+	nav    *Navigator
+	list   *tview.List
+	footer *tview.TextView
+	lists  []ftlists.List
+
+	form        *tview.Flex
+	nameInput   *tview.InputField
+	filterInput *tview.InputField
+	formStatus  *tview.TextView
+	formVisible bool
+	editing     string // the name of an edited list, empty for a new one
+
+	pending []BasketItem // entries captured when the panel was opened, "a" adds them to the current list
+}
+
+func newListsPanel(nav *Navigator) *listsPanel {
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	list := tview.NewList()
+	list.SetSecondaryTextColor(tcell.ColorGray)
+	footer := tview.NewTextView().SetDynamicColors(true).SetTextColor(tcell.ColorGray)
+	p := &listsPanel{
+		flex:        flex,
+		nav:         nav,
+		list:        list,
+		footer:      footer,
+		form:        tview.NewFlex().SetDirection(tview.FlexRow),
+		nameInput:   tview.NewInputField().SetLabel("Name:   "),
+		filterInput: tview.NewInputField().SetLabel("Filter: ").SetPlaceholder("e.g. ext:go size>1M"),
+		formStatus:  tview.NewTextView().SetDynamicColors(true),
+		Boxed: sneatv.NewBoxed(
+			flex,
+			sneatv.WithLeftBorder(1, -1),
+			sneatv.WithFooter(footer),
+		),
+	}
+	p.SetTitle("Lists")
+	p.form.AddItem(p.nameInput, 1, 0, true)
+	p.form.AddItem(p.filterInput, 1, 0, false)
+	p.form.AddItem(p.formStatus, 1, 0, false)
+	p.nameInput.SetInputCapture(p.formInputCapture)
+	p.filterInput.SetInputCapture(p.formInputCapture)
+	flex.AddItem(list, 0, 1, true)
+	list.SetInputCapture(p.inputCapture)
+	list.SetChangedFunc(p.changed)
+	return p
+}
+
+// showLists opens the lists panel, the selection or the current entry can be added to a list from there.
+func (nav *Navigator) showLists() {
+	if nav.listsPanel == nil {
+		nav.listsPanel = newListsPanel(nav)
+	}
+	p := nav.listsPanel
+	p.pending = nav.addToListItems()
+	p.setLists(nav.userLists())
+	p.hideForm()
+	nav.left.SetContent(p)
+	nav.app.SetFocus(p.list)
+	if name := p.currentName(); name != "" {
+		nav.showList(name)
+	}
+}
+
+func (p *listsPanel) setLists(lists []ftlists.List) {
+	current := p.currentName()
+	p.lists = lists
+	p.list.SetChangedFunc(nil)
+	p.list.Clear()
+	selected := 0
+	for i, l := range lists {
+		if l.Name == current {
+			selected = i
+		}
+		main := fmt.Sprintf("%s [gray](%d)[-]", tview.Escape(l.Name), len(l.Items))
+		p.list.AddItem(main, tview.Escape(l.Filter), 0, nil)
+	}
+	if len(lists) > 0 {
+		p.list.SetCurrentItem(selected)
+	}
+	p.list.SetChangedFunc(p.changed)
+	p.updateFooter()
+}
+
+// currentName returns the name of the list under the cursor, empty if there are no lists.
+func (p *listsPanel) currentName() string {
+	i := p.list.GetCurrentItem()
+	if i < 0 || i >= len(p.lists) {
+		return ""
+	}
+	return p.lists[i].Name
+}
+
+func (p *listsPanel) updateFooter() {
+	text := "n new"
+	if len(p.lists) > 0 {
+		text = "⏎ open · e edit · d delete · " + text
+		if len(p.pending) > 0 {
+			text = fmt.Sprintf("[white]a[-] add %d item(s) · ", len(p.pending)) + text
+		}
+	}
+	p.footer.SetText(text + " · esc back")
+}
+
+// changed previews the list under the cursor in the files panel.
+func (p *listsPanel) changed(index int, _ string, _ string, _ rune) {
+	if index >= 0 && index < len(p.lists) {
+		p.nav.showList(p.lists[index].Name)
+	}
+}
+
+func (p *listsPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEnter:
+		if name := p.currentName(); name != "" {
+			p.nav.showList(name)
+			p.nav.app.SetFocus(p.nav.files.table)
+		}
+		return nil
+	case tcell.KeyRight:
+		p.nav.app.SetFocus(p.nav.files.table)
+		return nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete:
+		p.deleteCurrent()
+		return nil
+	case tcell.KeyEscape:
+		p.close()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'a':
+			p.addPending()
+		case 'n':
+			p.showForm(ftlists.List{})
+		case 'e':
+			if i := p.list.GetCurrentItem(); i >= 0 && i < len(p.lists) {
+				p.showForm(p.lists[i])
+			}
+		case 'd':
+			p.deleteCurrent()
+		default:
+			return event
+		}
+		return nil
+	default:
+		return event
+	}
+}
+
+// close returns the left container to the dirs tree & the files panel to the current dir.
+func (p *listsPanel) close() {
+	if p.nav.isListShown() {
+		p.nav.closeList()
+	}
+	p.nav.left.SetContent(p.nav.dirsTree)
+	p.nav.app.SetFocus(p.nav.dirsTree)
+}
+
+func (p *listsPanel) addPending() {
+	name := p.currentName()
+	if name == "" || len(p.pending) == 0 {
+		return
+	}
+	added, err := p.nav.addToList(name, p.pending)
+	if err != nil {
+		p.nav.notifyError("Lists", err)
+		return
+	}
+	p.pending = nil
+	p.nav.notify(notificationInfo, "Lists", fmt.Sprintf("added %d item(s) to %s", added, name))
+	p.setLists(p.nav.userLists())
+	p.nav.showList(name)
+}
+
+func (p *listsPanel) deleteCurrent() {
+	name := p.currentName()
+	if name == "" {
+		return
+	}
+	err := p.nav.updateLists(func(lists []ftlists.List) ([]ftlists.List, error) {
+		return ftlists.Delete(lists, name), nil
+	})
+	if err != nil {
+		p.nav.notifyError("Lists", err)
+		return
+	}
+	p.setLists(p.nav.userLists())
+	if name = p.currentName(); name != "" {
+		p.nav.showList(name)
+	} else if p.nav.isListShown() {
+		p.nav.closeList()
+	}
+}
+
+// showForm shows inputs for the name & the filter of a new or edited list.
+func (p *listsPanel) showForm(l ftlists.List) {
+	p.editing = l.Name
+	p.nameInput.SetText(l.Name)
+	p.filterInput.SetText(l.Filter)
+	p.formStatus.SetText("[gray]⏎ save · tab next · esc cancel[-]")
+	if !p.formVisible {
+		p.flex.AddItem(p.form, 3, 0, false)
+		p.formVisible = true
+	}
+	p.nav.app.SetFocus(p.nameInput)
+}
+
+func (p *listsPanel) hideForm() {
+	if p.formVisible {
+		p.flex.RemoveItem(p.form)
+		p.formVisible = false
+	}
+}
+
+func (p *listsPanel) formInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyTab, tcell.KeyBacktab:
+		if p.nameInput.HasFocus() {
+			p.nav.app.SetFocus(p.filterInput)
+		} else {
+			p.nav.app.SetFocus(p.nameInput)
+		}
+		return nil
+	case tcell.KeyEnter:
+		p.saveForm()
+		return nil
+	case tcell.KeyEscape:
+		p.hideForm()
+		p.nav.app.SetFocus(p.list)
+		return nil
+	default:
+		return event
+	}
+}
+
+// saveForm creates or updates a list, an invalid filter is reported inline & nothing is saved.
+func (p *listsPanel) saveForm() {
+	l := ftlists.List{Name: p.nameInput.GetText(), Filter: p.filterInput.GetText()}
+	if _, err := ftquery.Parse(l.Filter); err != nil {
+		p.formStatus.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	err := p.nav.updateLists(func(lists []ftlists.List) ([]ftlists.List, error) {
+		return ftlists.Put(lists, p.editing, l)
+	})
+	if err != nil {
+		p.formStatus.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	p.hideForm()
+	p.setLists(p.nav.userLists())
+	name := strings.TrimSpace(l.Name)
+	p.list.SetChangedFunc(nil)
+	p.list.SetCurrentItem(ftlists.IndexOf(p.lists, name))
+	p.list.SetChangedFunc(p.changed)
+	p.nav.showList(name)
+	p.nav.app.SetFocus(p.list)
+}
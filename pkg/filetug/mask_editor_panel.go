@@ -0,0 +1,154 @@
+package filetug
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maskEditorPanel creates & edits user masks with a live preview of matching names in the current dir.
+// Patterns are edited one per line as parsed by masks.ParsePatterns, e.g. "+ \.go$" or "& size:>10MB".
+type maskEditorPanel struct {
+	*sneatv.Boxed
+	nav      *Navigator
+	flex     *tview.Flex
+	form     *tview.Form
+	name     *tview.InputField
+	patterns *tview.TextArea
+	status   *tview.TextView
+	preview  *tview.Table
+	oldName  string // empty for a new mask
+	dir      string
+	entries  []os.DirEntry // files of the current dir to preview matches
+	matching *maskEntries  // git states are read once patterns need them
+}
+
+// showMaskEditor opens the editor for a mask, isNew tells the mask is a template of a new one.
+func (nav *Navigator) showMaskEditor(mask masks.Mask, isNew bool) {
+	p := newMaskEditorPanel(nav, mask, isNew)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newMaskEditorPanel(nav *Navigator, mask masks.Mask, isNew bool) *maskEditorPanel {
+	p := &maskEditorPanel{
+		nav:     nav,
+		flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		form:    tview.NewForm(),
+		status:  tview.NewTextView().SetDynamicColors(true),
+		preview: tview.NewTable(),
+	}
+	name := mask.Name
+	if isNew {
+		name = ""
+	} else {
+		p.oldName = mask.Name
+	}
+	if rows := nav.maskRows(); rows != nil {
+		p.dir = rows.Dir.Path()
+		for _, entry := range rows.AllEntries {
+			if isMaskCandidate(entry, nav.files.filter.ShowHidden) {
+				p.entries = append(p.entries, entry)
+			}
+		}
+	}
+	p.matching = newMaskEntries(p.dir, false)
+
+	p.name = tview.NewInputField().SetLabel("Name").SetText(name)
+	p.patterns = tview.NewTextArea().
+		SetLabel("Patterns").
+		SetSize(5, 0).
+		SetPlaceholder(`+ \.go$`+"\n"+`- glob:**/*_test.go`+"\n"+`& size:>1KB`).
+		SetText(masks.FormatPatterns(mask.Patterns), false)
+	p.patterns.SetChangedFunc(p.updatePreview)
+	p.form.AddFormItem(p.name)
+	p.form.AddFormItem(p.patterns)
+	p.form.AddButton("Save", p.save)
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+
+	p.preview.SetSelectable(false, false)
+
+	p.flex.AddItem(p.form, 11, 0, true)
+	p.flex.AddItem(p.status, 0, 1, false)
+	p.flex.AddItem(p.preview, 0, 3, false)
+
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1))
+	if isNew {
+		p.SetTitle("New mask")
+	} else {
+		p.SetTitle("Edit mask: " + mask.Name)
+	}
+	p.updatePreview()
+	return p
+}
+
+// updatePreview validates patterns & marks names of the current dir matching them.
+// Invalid lines are reported in the status, valid patterns are still previewed.
+func (p *maskEditorPanel) updatePreview() {
+	patterns, err := masks.ParsePatterns(p.patterns.GetText())
+	mask := masks.Mask{Patterns: patterns}
+	if mask.NeedsGitState() && p.matching.git == nil {
+		p.matching = newMaskEntries(p.dir, true)
+	}
+	p.preview.Clear()
+	var matched int
+	for i, entry := range p.entries {
+		isMatch, _ := mask.Match(p.matching.entry(p.dir, entry), nil)
+		cell := tview.NewTableCell(tview.Escape(entry.Name())).SetExpansion(1)
+		if isMatch {
+			matched++
+			cell.SetTextColor(tcell.ColorLightGreen)
+		} else {
+			cell.SetTextColor(tcell.ColorGray)
+		}
+		p.preview.SetCell(i, 0, cell)
+	}
+	if err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	p.setStatus(fmt.Sprintf("%d of %d file(s) match", matched, len(p.entries)))
+}
+
+func (p *maskEditorPanel) setStatus(text string) {
+	p.status.SetText(text)
+}
+
+func (p *maskEditorPanel) save() {
+	patterns, err := masks.ParsePatterns(p.patterns.GetText())
+	if err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	mask := masks.Mask{Name: strings.TrimSpace(p.name.GetText()), Patterns: patterns}
+	if err = mask.Validate(); err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	if err = saveUserMask(p.oldName, mask); err != nil {
+		p.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	p.nav.notify(notificationInfo, "Masks", fmt.Sprintf("Saved mask %q", mask.Name))
+	p.close()
+}
+
+// close goes back to the masks panel.
+func (p *maskEditorPanel) close() {
+	p.nav.showMasks()
+	p.nav.app.SetFocus(p.nav.masks)
+}
+
+func (p *maskEditorPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		p.close()
+		return nil
+	}
+	return event
+}
@@ -0,0 +1,139 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func withUserMasksFile(t *testing.T) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), userMasksFileName)
+	orig := getUserMasksFilePath
+	getUserMasksFilePath = func() (string, error) {
+		return filePath, nil
+	}
+	t.Cleanup(func() {
+		getUserMasksFilePath = orig
+	})
+	return filePath
+}
+
+func maskNames(list []masks.Mask) []string {
+	names := make([]string, len(list))
+	for i, m := range list {
+		names[i] = m.Name
+	}
+	return names
+}
+
+func TestMaskEditorPanel(t *testing.T) {
+	filePath := withUserMasksFile(t)
+	nav, updates, _ := newNavigatorWithLocalDir(t, "a.go", "a_test.go", "b.md")
+	nav.showMasks()
+	nav.onMaskAction(nav.masks.Masks()[0], masks.ActionNew)
+	p, ok := nav.right.content.(*maskEditorPanel)
+	assert.True(t, ok)
+	assert.Equal(t, "New mask", p.GetTitle())
+	assert.Empty(t, p.name.GetText())
+	assert.Contains(t, p.patterns.GetText(), `\.(cpp|cs|js|ts|py)$`, "the current mask is a template")
+
+	t.Run("preview", func(t *testing.T) {
+		p.patterns.SetText("+ \\.go$\n- _test", false)
+		assert.Equal(t, "1 of 3 file(s) match", p.status.GetText(true))
+		assert.Equal(t, 3, p.preview.GetRowCount())
+	})
+
+	t.Run("invalid_regex", func(t *testing.T) {
+		p.patterns.SetText("+ \\.go$\n- (", false)
+		assert.Contains(t, p.status.GetText(true), "line 2:")
+		var matched []string
+		for row := 0; row < p.preview.GetRowCount(); row++ {
+			cell := p.preview.GetCell(row, 0)
+			if fg, _, _ := cell.Style.Decompose(); fg == tcell.ColorLightGreen || cell.Color == tcell.ColorLightGreen {
+				matched = append(matched, cell.Text)
+			}
+		}
+		assert.ElementsMatch(t, []string{"a.go", "a_test.go"}, matched, "valid patterns are previewed")
+		p.save()
+		assert.Contains(t, p.status.GetText(true), "line 2:")
+		assert.NoFileExists(t, filePath)
+	})
+
+	t.Run("no_name", func(t *testing.T) {
+		p.patterns.SetText("+ \\.go$\n- _test", false)
+		p.save()
+		assert.Contains(t, p.status.GetText(true), masks.ErrNoName.Error())
+	})
+
+	t.Run("save", func(t *testing.T) {
+		p.name.SetText(" Go ")
+		p.save()
+		assert.Equal(t, nav.masks, nav.right.content)
+		assert.Equal(t, []string{"Coding", "Data", "Go"}, maskNames(nav.masks.Masks()))
+		waitMaskCounts(t, nav, updates, 2, "1", "0")
+		list, err := masks.LoadMasks(filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Go"}, maskNames(list))
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		nav.onMaskAction(nav.masks.Masks()[2], masks.ActionEdit)
+		p := nav.right.content.(*maskEditorPanel)
+		assert.Equal(t, "Edit mask: Go", p.GetTitle())
+		assert.Equal(t, "+ \\.go$\n- _test", p.patterns.GetText())
+		p.name.SetText("Golang")
+		p.save()
+		assert.Equal(t, []string{"Coding", "Data", "Golang"}, maskNames(nav.masks.Masks()))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		nav.onMaskAction(nav.masks.Masks()[0], masks.ActionDelete)
+		assert.Contains(t, lastNotification(nav).message, "can't be deleted")
+		nav.onMaskAction(nav.masks.Masks()[2], masks.ActionDelete)
+		assert.Equal(t, []string{"Coding", "Data"}, maskNames(nav.masks.Masks()))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		nav.onMaskAction(nav.masks.Masks()[1], masks.ActionEdit)
+		p := nav.right.content.(*maskEditorPanel)
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.masks, nav.right.content)
+		event := keyRune('x')
+		assert.Equal(t, event, p.inputCapture(event))
+	})
+}
+
+func TestNavigator_ShowMasks_ReappliesFilter(t *testing.T) {
+	withUserMasksFile(t)
+	nav, _, _ := newNavigatorWithLocalDir(t, "a.go", "b.md")
+	assert.NoError(t, saveUserMask("", masks.Mask{Name: "Go", Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `\.go$`}}}))
+	nav.showMasks()
+	nav.masks.SetFiltered(2)
+	nav.onMaskAction(nav.masks.Masks()[2], masks.ActionFilter)
+	assert.Len(t, nav.files.rows.VisibleEntries, 1)
+
+	assert.NoError(t, saveUserMask("Go", masks.Mask{Name: "Go", Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: `\.md$`}}}))
+	nav.showMasks()
+	if assert.Len(t, nav.files.rows.VisibleEntries, 1) {
+		assert.Equal(t, "b.md", nav.files.rows.VisibleEntries[0].Name())
+	}
+
+	assert.NoError(t, deleteUserMask("Go"))
+	nav.showMasks()
+	assert.Nil(t, nav.files.filter.MaskFilter)
+	assert.Len(t, nav.files.rows.VisibleEntries, 2)
+}
+
+func TestNavigator_AllMasks_InvalidFile(t *testing.T) {
+	filePath := withUserMasksFile(t)
+	assert.NoError(t, os.WriteFile(filePath, []byte("name: [not a list"), 0o644))
+	nav, _, _ := newNavigatorWithLocalDir(t)
+	assert.Equal(t, []string{"Coding", "Data"}, maskNames(nav.allMasks()))
+	assert.Equal(t, notificationError, lastNotification(nav).level)
+	assert.Error(t, saveUserMask("", masks.Mask{Name: "Go"}))
+}
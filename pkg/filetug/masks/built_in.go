@@ -2,11 +2,16 @@ package masks
 
 func createBuiltInMasks() []Mask {
 	return []Mask{
-		{Name: "Coding", Patterns: []Pattern{
+		{Name: "Coding", BuiltIn: true, Patterns: []Pattern{
 			{Type: Inclusive, Regex: `\.(cpp|cs|js|ts|py)$`},
 		}},
-		{Name: "Data", Patterns: []Pattern{
+		{Name: "Data", BuiltIn: true, Patterns: []Pattern{
 			{Type: Inclusive, Regex: `\.(csv|dbf|json|xml|yaml)$`},
 		}},
 	}
 }
+
+// BuiltInMasks returns masks shipped with the app. Each call returns new instances.
+func BuiltInMasks() []Mask {
+	return createBuiltInMasks()
+}
@@ -1,33 +1,81 @@
 package masks
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 )
 
 type Mask struct {
-	Name     string
-	Patterns []Pattern
+	Name     string    `yaml:"name"`
+	Patterns []Pattern `yaml:"patterns"`
+	BuiltIn  bool      `yaml:"-"` // shipped with the app & not overridden by the user
+}
+
+var (
+	ErrNoName     = errors.New("mask name is required")
+	ErrNoPatterns = errors.New("mask should have at least one pattern")
+)
+
+// Validate checks the mask has a name & valid patterns.
+func (m *Mask) Validate() error {
+	if m.Name == "" {
+		return ErrNoName
+	}
+	if len(m.Patterns) == 0 {
+		return ErrNoPatterns
+	}
+	return m.Compile()
 }
 
 func (m *Mask) String() string {
 	return fmt.Sprintf("Mask{Name: %q, Patterns: %+v}", m.Name, m.Patterns)
 }
 
-func (m *Mask) Match(fileName string) (bool, error) {
-	var result bool
-	for _, pattern := range m.Patterns {
-		matched, err := pattern.Match(fileName)
-		if err != nil {
+// Match reports whether an entry matches the mask. Info can be nil, then it's read from the entry if a pattern needs it.
+// Pass an Entry to match path, glob with slashes & git patterns.
+func (m *Mask) Match(entry os.DirEntry, info os.FileInfo) (bool, error) {
+	t := newTarget(entry, info)
+	var hasInclusive, hasRequired, included bool
+	for i := range m.Patterns {
+		pattern := &m.Patterns[i]
+		if err := pattern.compile(); err != nil {
 			return false, err
 		}
-		if matched {
-			if pattern.Type == Inclusive {
-				result = true
+		matched := pattern.match(t)
+		switch pattern.Type {
+		case Exclusive:
+			if matched {
+				return false, nil
 			}
-			if pattern.Type == Exclusive {
+		case Required:
+			if !matched {
 				return false, nil
 			}
+			hasRequired = true
+		default:
+			hasInclusive = true
+			included = included || matched
+		}
+	}
+	if hasInclusive {
+		return included, nil
+	}
+	return hasRequired, nil
+}
+
+// NeedsGitState reports whether entries should be passed as Entry with a git state.
+func (m *Mask) NeedsGitState() bool {
+	return slices.ContainsFunc(m.Patterns, func(p Pattern) bool { return p.Kind == KindGit })
+}
+
+// Compile prepares all patterns so the mask can be used concurrently afterwards.
+func (m *Mask) Compile() error {
+	for i := range m.Patterns {
+		if err := m.Patterns[i].Compile(); err != nil {
+			return err
 		}
 	}
-	return result, nil
+	return nil
 }
@@ -1,6 +1,10 @@
 package masks
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+)
 
 func TestMask_Match(t *testing.T) {
 	t.Parallel()
@@ -89,7 +93,7 @@ func TestMask_Match(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.mask.Match(tt.fileName)
+			got, err := tt.mask.Match(files.NewDirEntry(tt.fileName, false), nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Match() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -110,8 +114,44 @@ func TestMask_String(t *testing.T) {
 		},
 	}
 	got := m.String()
-	want := `Mask{Name: "Test", Patterns: [{Type:inclusive Regex:.* re:<nil>}]}`
+	want := `Mask{Name: "Test", Patterns: [{Type:inclusive Regex:.*}]}`
 	if got != want {
 		t.Errorf("String() = %v, want %v", got, want)
 	}
 }
+
+func TestMask_Compile(t *testing.T) {
+	t.Parallel()
+	valid := Mask{Patterns: []Pattern{{Type: Inclusive, Regex: `\.go$`}}}
+	if err := valid.Compile(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valid.Patterns[0].re == nil {
+		t.Error("expected regex to be compiled")
+	}
+	invalid := Mask{Patterns: []Pattern{{Type: Inclusive, Regex: `(`}}}
+	if err := invalid.Compile(); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestMask_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		mask    Mask
+		wantErr bool
+	}{
+		{name: "valid", mask: Mask{Name: "Go", Patterns: []Pattern{{Type: Inclusive, Regex: `\.go$`}}}},
+		{name: "no_name", mask: Mask{Patterns: []Pattern{{Type: Inclusive, Regex: `\.go$`}}}, wantErr: true},
+		{name: "no_patterns", mask: Mask{Name: "Go"}, wantErr: true},
+		{name: "invalid_regex", mask: Mask{Name: "Go", Patterns: []Pattern{{Type: Inclusive, Regex: `(`}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.mask.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,15 +1,34 @@
 package masks
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/filetug/filetug/pkg/sneatv"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+// Action is what a user chose to do with a mask in the panel.
+type Action int
+
+const (
+	ActionSelect      Action = iota // Enter: add matches to the selection
+	ActionDeselect                  // Shift+Enter: remove matches from the selection
+	ActionFilter                    // f: show only matches
+	ActionClearFilter               // f on the mask applied as a filter: show all entries
+	ActionNew                       // n: create a mask, the current one is passed as a template
+	ActionEdit                      // e: edit the mask
+	ActionDelete                    // d: delete the user mask
+)
+
 type Panel struct {
 	*sneatv.Boxed
-	table *tview.Table
-	masks []Mask
+	table    *tview.Table
+	masks    []Mask
+	filtered int // index of the mask applied as a filter, -1 if none
+	action   func(mask Mask, action Action)
+	close    func()
 }
 
 func (p *Panel) Focus(delegate func(p tview.Primitive)) {
@@ -19,24 +38,42 @@ func (p *Panel) Focus(delegate func(p tview.Primitive)) {
 func NewPanel() *Panel {
 	p := new(Panel)
 	p.masks = createBuiltInMasks()
+	p.filtered = -1
 
 	p.table = tview.NewTable()
-	p.table.SetSelectable(true, true)
+	p.table.SetSelectable(true, false)
 	p.table.SetFixed(1, 1)
+	p.table.SetInputCapture(p.inputCapture)
+
+	footer := tview.NewTextView().
+		SetText("Enter: select · Shift+Enter: deselect · f: filter · n: new · e: edit · d: delete · Esc: close").
+		SetTextColor(tcell.ColorGray)
 
 	p.Boxed = sneatv.NewBoxed(p.table,
 		sneatv.WithLeftBorder(0, -1),
+		sneatv.WithFooter(footer),
 	)
 	p.SetTitle("Masks")
 
+	p.render()
+	p.table.Select(1, 0)
+
+	return p
+}
+
+func (p *Panel) render() {
+	p.table.Clear()
 	maskCell := tview.NewTableCell("Mask")
 	maskCell.SetExpansion(1)
+	maskCell.SetSelectable(false)
 	p.table.SetCell(0, 0, maskCell)
 	currDirCell := tview.NewTableCell("CurrDir")
 	currDirCell.SetAlign(tview.AlignRight)
+	currDirCell.SetSelectable(false)
 	p.table.SetCell(0, 1, currDirCell)
 	subDirsCell := tview.NewTableCell("SubDirs")
 	subDirsCell.SetAlign(tview.AlignRight)
+	subDirsCell.SetSelectable(false)
 	p.table.SetCell(0, 2, subDirsCell)
 
 	for i, m := range p.masks {
@@ -54,6 +91,144 @@ func NewPanel() *Panel {
 		subDirsCell.SetTextColor(tcell.ColorGray)
 		p.table.SetCell(i+1, 2, subDirsCell)
 	}
+	p.SetFiltered(p.filtered)
+}
 
-	return p
+// SetMasks replaces the listed masks, e.g. after the user edited them.
+// The mask applied as a filter stays marked if it's still listed.
+func (p *Panel) SetMasks(list []Mask) {
+	var filteredName string
+	if p.filtered >= 0 && p.filtered < len(p.masks) {
+		filteredName = p.masks[p.filtered].Name
+	}
+	p.masks = list
+	p.filtered = slices.IndexFunc(list, func(m Mask) bool { return filteredName != "" && m.Name == filteredName })
+	row, _ := p.table.GetSelection()
+	p.render()
+	p.table.Select(max(1, min(row, len(list))), 0)
+}
+
+// Masks returns the masks listed in the panel, in the order of rows.
+func (p *Panel) Masks() []Mask {
+	return p.masks
+}
+
+// SetActionFunc sets a handler called when a user chooses what to do with a mask.
+func (p *Panel) SetActionFunc(f func(mask Mask, action Action)) {
+	p.action = f
+}
+
+// SetCloseFunc sets a handler called on Esc.
+func (p *Panel) SetCloseFunc(f func()) {
+	p.close = f
+}
+
+// SetFiltered marks the mask applied as a filter, -1 for none.
+func (p *Panel) SetFiltered(i int) {
+	p.filtered = i
+	for j, m := range p.masks {
+		name := m.Name
+		if j == i {
+			name = "▼ " + name
+		}
+		p.table.GetCell(j+1, 0).SetText(tview.Escape(name))
+	}
+}
+
+// Filtered returns an index of the mask applied as a filter, -1 if none.
+func (p *Panel) Filtered() int {
+	return p.filtered
+}
+
+// ResetCounts shows counts of all masks are not known yet.
+func (p *Panel) ResetCounts() {
+	for i := range p.masks {
+		p.table.GetCell(i+1, 1).SetText("...")
+		p.table.GetCell(i+1, 2).SetText("...").SetTextColor(tcell.ColorGray)
+	}
+}
+
+// SetCurrDirCount shows the number of entries of the current dir matching the i-th mask.
+func (p *Panel) SetCurrDirCount(i, count int, err error) {
+	p.table.GetCell(i+1, 1).SetText(countText(count, err))
+}
+
+// SetSubDirsCount shows the number of entries in subdirectories matching the i-th mask.
+// While counting is in progress the number is grayed.
+func (p *Panel) SetSubDirsCount(i, count int, done bool, err error) {
+	cell := p.table.GetCell(i+1, 2)
+	cell.SetText(countText(count, err))
+	if done || err != nil {
+		cell.SetTextColor(tview.Styles.PrimaryTextColor)
+	} else {
+		cell.SetTextColor(tcell.ColorGray)
+	}
+}
+
+// CountTexts returns counts shown for the i-th mask, e.g. "..." if they are not known yet.
+func (p *Panel) CountTexts(i int) (currDir, subDirs string) {
+	return p.table.GetCell(i+1, 1).Text, p.table.GetCell(i+1, 2).Text
+}
+
+func countText(count int, err error) string {
+	if err != nil {
+		return "[red]error[-]"
+	}
+	return fmt.Sprintf("%d", count)
+}
+
+func (p *Panel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	row, _ := p.table.GetSelection()
+	i := row - 1
+	switch event.Key() {
+	case tcell.KeyEscape:
+		if p.close != nil {
+			p.close()
+		}
+		return nil
+	case tcell.KeyEnter:
+		if event.Modifiers()&tcell.ModShift != 0 {
+			p.do(i, ActionDeselect)
+		} else {
+			p.do(i, ActionSelect)
+		}
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'f', 'F':
+			p.do(i, ActionFilter)
+		case 'n', 'N':
+			if p.action != nil {
+				var template Mask
+				if i >= 0 && i < len(p.masks) {
+					template = p.masks[i]
+				}
+				p.action(template, ActionNew)
+			}
+		case 'e', 'E':
+			p.do(i, ActionEdit)
+		case 'd', 'D':
+			p.do(i, ActionDelete)
+		default:
+			return event
+		}
+		return nil
+	default:
+		return event
+	}
+}
+
+func (p *Panel) do(i int, action Action) {
+	if i < 0 || i >= len(p.masks) || p.action == nil {
+		return
+	}
+	if action == ActionFilter {
+		if p.filtered == i {
+			action = ActionClearFilter
+			p.SetFiltered(-1)
+		} else {
+			p.SetFiltered(i)
+		}
+	}
+	p.action(p.masks[i], action)
 }
@@ -1,9 +1,12 @@
 package masks
 
 import (
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/filetug/filetug/pkg/sneatv/ttestutils"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -51,3 +54,123 @@ func TestPanel_Focus(t *testing.T) {
 	p.Focus(func(delegate tview.Primitive) {
 	})
 }
+
+func TestPanel_Actions(t *testing.T) {
+	t.Parallel()
+	p := NewPanel()
+	var got []Action
+	p.SetActionFunc(func(mask Mask, action Action) {
+		if mask.Name != p.masks[0].Name {
+			t.Errorf("unexpected mask %q", mask.Name)
+		}
+		got = append(got, action)
+	})
+	closed := false
+	p.SetCloseFunc(func() {
+		closed = true
+	})
+	press := func(event *tcell.EventKey) {
+		p.InputHandler()(event, func(tview.Primitive) {})
+	}
+	press(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	press(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModShift))
+	press(tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone))
+	if p.Filtered() != 0 || p.table.GetCell(1, 0).Text != "▼ "+p.masks[0].Name {
+		t.Errorf("expected the first mask to be marked as filtered, got %d", p.Filtered())
+	}
+	press(tcell.NewEventKey(tcell.KeyRune, 'F', tcell.ModNone))
+	if p.Filtered() != -1 || p.table.GetCell(1, 0).Text != p.masks[0].Name {
+		t.Errorf("expected no filtered mask, got %d", p.Filtered())
+	}
+	press(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone))
+	press(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	expected := []Action{ActionSelect, ActionDeselect, ActionFilter, ActionClearFilter}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected actions %v, got %v", expected, got)
+	}
+	if !closed {
+		t.Error("expected close func to be called on Esc")
+	}
+}
+
+func TestPanel_SetCounts(t *testing.T) {
+	t.Parallel()
+	p := NewPanel()
+	p.SetCurrDirCount(0, 3, nil)
+	p.SetSubDirsCount(0, 5, false, nil)
+	if curr, sub := p.CountTexts(0); curr != "3" || sub != "5" {
+		t.Errorf("unexpected counts %q, %q", curr, sub)
+	}
+	color := func() tcell.Color {
+		cell := p.table.GetCell(1, 2)
+		if cell.Style == tcell.StyleDefault {
+			return cell.Color
+		}
+		fg, _, _ := cell.Style.Decompose()
+		return fg
+	}
+	if color() != tcell.ColorGray {
+		t.Error("expected the count in progress to be gray")
+	}
+	p.SetSubDirsCount(0, 7, true, nil)
+	if color() == tcell.ColorGray {
+		t.Error("expected the final count not to be gray")
+	}
+	p.SetCurrDirCount(1, 0, errors.New("invalid regex"))
+	if curr, _ := p.CountTexts(1); curr != "[red]error[-]" {
+		t.Errorf("unexpected count text %q", curr)
+	}
+}
+
+func TestPanel_EditActions(t *testing.T) {
+	t.Parallel()
+	p := NewPanel()
+	var got []Action
+	var masks []string
+	p.SetActionFunc(func(mask Mask, action Action) {
+		got = append(got, action)
+		masks = append(masks, mask.Name)
+	})
+	for _, r := range []rune{'n', 'e', 'd'} {
+		p.InputHandler()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), func(tview.Primitive) {})
+	}
+	if expected := []Action{ActionNew, ActionEdit, ActionDelete}; !slices.Equal(got, expected) {
+		t.Errorf("expected actions %v, got %v", expected, got)
+	}
+	if expected := []string{"Coding", "Coding", "Coding"}; !slices.Equal(masks, expected) {
+		t.Errorf("expected masks %v, got %v", expected, masks)
+	}
+
+	got = nil
+	p.SetMasks(nil)
+	p.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone), func(tview.Primitive) {})
+	p.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone), func(tview.Primitive) {})
+	if expected := []Action{ActionNew}; !slices.Equal(got, expected) {
+		t.Errorf("expected a new mask to be created without a current one, got %v", got)
+	}
+}
+
+func TestPanel_SetMasks(t *testing.T) {
+	t.Parallel()
+	p := NewPanel()
+	p.SetFiltered(1) // Data
+	p.table.Select(2, 0)
+	docs := Mask{Name: "Docs", Patterns: []Pattern{{Type: Inclusive, Regex: `\.md$`}}}
+	p.SetMasks(append([]Mask{docs}, BuiltInMasks()...))
+	if p.table.GetRowCount() != 4 {
+		t.Errorf("expected 4 rows, got %d", p.table.GetRowCount())
+	}
+	if p.Filtered() != 2 || p.table.GetCell(3, 0).Text != "▼ Data" {
+		t.Errorf("expected the filtered mask to stay marked, got %d", p.Filtered())
+	}
+	if row, _ := p.table.GetSelection(); row != 2 {
+		t.Errorf("expected the selected row to be kept, got %d", row)
+	}
+	p.SetMasks([]Mask{docs})
+	if p.Filtered() != -1 {
+		t.Errorf("expected no filtered mask, got %d", p.Filtered())
+	}
+	if row, _ := p.table.GetSelection(); row != 1 {
+		t.Errorf("expected the last row to be selected, got %d", row)
+	}
+}
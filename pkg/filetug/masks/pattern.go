@@ -1,29 +1,240 @@
 package masks
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"reflect"
 	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/gitutils"
 )
 
 type Type string
 
 const (
-	Inclusive Type = "inclusive"
-	Exclusive Type = "exclusive"
+	Inclusive Type = "inclusive" // an entry matches if any of inclusive patterns matches
+	Exclusive Type = "exclusive" // an entry doesn't match if any of exclusive patterns matches
+	Required  Type = "required"  // an entry matches only if all required patterns match
 )
 
+// Kind is what a pattern matches.
+type Kind string
+
+const (
+	KindRegex Kind = "regex" // Regex on the name, the default
+	KindPath  Kind = "path"  // Regex on the slash-separated path relative to the dir a mask is applied to
+	KindGlob  Kind = "glob"  // shell glob, on the relative path if it has a slash, ** matches any dirs
+	KindSize  Kind = "size"  // e.g. ">10MB", "<=1KiB", "1MB-5MB", dirs don't match
+	KindAge   Kind = "age"   // time since modification, e.g. "<7d", ">1mo", units: s, m, h, d, w, mo, y
+	KindType  Kind = "type"  // comma-separated file, dir, symlink
+	KindGit   Kind = "git"   // comma-separated git states: modified, untracked, ignored
+)
+
+var kinds = []Kind{KindRegex, KindPath, KindGlob, KindSize, KindAge, KindType, KindGit}
+
 type Pattern struct {
-	Type  Type
-	Regex string
+	Type  Type   `yaml:"type"`
+	Kind  Kind   `yaml:"kind,omitempty"`  // regex if empty
+	Regex string `yaml:"regex,omitempty"` // for regex & path kinds
+	Value string `yaml:"value,omitempty"` // for other kinds
 	re    *regexp.Regexp
+	match func(t *target) bool
+}
+
+// Entry adds context of an entry needed by path, glob & git patterns.
+// It can be passed to Mask.Match instead of a plain dir entry.
+type Entry struct {
+	os.DirEntry
+	RelPath  string             // slash-separated path relative to the dir a mask is applied to, the name if empty
+	GitState gitutils.FileState // unmodified if unknown
+}
+
+// target is an entry being matched, info is read once & only if a pattern needs it.
+type target struct {
+	entry    os.DirEntry
+	relPath  string
+	gitState gitutils.FileState
+	info     os.FileInfo
+	infoRead bool
+}
+
+func newTarget(entry os.DirEntry, info os.FileInfo) *target {
+	t := &target{entry: entry, info: info, infoRead: !isNilInfo(info)}
+	if e, ok := entry.(Entry); ok {
+		t.relPath = e.RelPath
+		t.gitState = e.GitState
+	}
+	if t.relPath == "" {
+		t.relPath = entry.Name()
+	}
+	return t
 }
 
-func (p *Pattern) Match(fileName string) (bool, error) {
-	if p.re == nil {
-		var err error
+func (t *target) fileInfo() os.FileInfo {
+	if !t.infoRead {
+		t.infoRead = true
+		if info, err := t.entry.Info(); err == nil && !isNilInfo(info) {
+			t.info = info
+		}
+	}
+	return t.info
+}
+
+// isNilInfo checks for nil including a typed nil pointer in the interface.
+func isNilInfo(info os.FileInfo) bool {
+	if info == nil {
+		return true
+	}
+	v := reflect.ValueOf(info)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// Match reports whether an entry matches the pattern, info can be nil to be read from the entry when needed.
+func (p *Pattern) Match(entry os.DirEntry, info os.FileInfo) (bool, error) {
+	if err := p.compile(); err != nil {
+		return false, err
+	}
+	return p.match(newTarget(entry, info)), nil
+}
+
+// Compile prepares the pattern so it can be used concurrently afterwards.
+func (p *Pattern) Compile() error {
+	return p.compile()
+}
+
+func (p *Pattern) kind() Kind {
+	if p.Kind == "" {
+		return KindRegex
+	}
+	return p.Kind
+}
+
+func (p *Pattern) compile() error {
+	if p.match != nil {
+		return nil
+	}
+	var err error
+	switch p.kind() {
+	case KindRegex, KindPath:
 		if p.re, err = regexp.Compile(p.Regex); err != nil {
-			return false, fmt.Errorf("invalid regex for %s pattern: %q", p.Type, p.Regex)
+			return fmt.Errorf("invalid regex for %s pattern: %q", p.Type, p.Regex)
+		}
+		re := p.re
+		if p.kind() == KindPath {
+			p.match = func(t *target) bool { return re.MatchString(t.relPath) }
+		} else {
+			p.match = func(t *target) bool { return re.MatchString(t.entry.Name()) }
+		}
+	case KindGlob:
+		p.match, err = compileGlob(p.Value)
+	case KindSize:
+		p.match, err = compileSize(p.Value)
+	case KindAge:
+		p.match, err = compileAge(p.Value)
+	case KindType:
+		p.match, err = compileType(p.Value)
+	case KindGit:
+		p.match, err = compileGit(p.Value)
+	default:
+		err = fmt.Errorf("unknown pattern kind: %q", p.Kind)
+	}
+	if err != nil {
+		p.match = nil
+		return err
+	}
+	return nil
+}
+
+// String omits compiled state, e.g. "{Type:inclusive Regex:\.go$}" or "{Type:required Kind:size Value:>10MB}".
+func (p Pattern) String() string {
+	switch p.kind() {
+	case KindRegex:
+		return fmt.Sprintf("{Type:%s Regex:%s}", p.Type, p.Regex)
+	case KindPath:
+		return fmt.Sprintf("{Type:%s Kind:%s Regex:%s}", p.Type, p.Kind, p.Regex)
+	default:
+		return fmt.Sprintf("{Type:%s Kind:%s Value:%s}", p.Type, p.Kind, p.Value)
+	}
+}
+
+// text returns a value of the pattern as written by FormatPatterns, e.g. "size:>10MB".
+func (p *Pattern) text() string {
+	switch p.kind() {
+	case KindRegex:
+		if kind, _, ok := strings.Cut(p.Regex, ":"); ok && slices.Contains(kinds, Kind(kind)) {
+			return string(KindRegex) + ":" + p.Regex
+		}
+		return p.Regex
+	case KindPath:
+		return string(KindPath) + ":" + p.Regex
+	default:
+		return string(p.Kind) + ":" + p.Value
+	}
+}
+
+// parsePattern parses a value of a pattern written as "kind:value", a value without a known kind is a regex.
+func parsePattern(patternType Type, text string) Pattern {
+	pattern := Pattern{Type: patternType, Regex: text}
+	if kind, value, ok := strings.Cut(text, ":"); ok {
+		switch k := Kind(kind); {
+		case k == KindRegex:
+			pattern.Regex = value
+		case k == KindPath:
+			pattern = Pattern{Type: patternType, Kind: k, Regex: value}
+		case slices.Contains(kinds, k):
+			pattern = Pattern{Type: patternType, Kind: k, Value: strings.TrimSpace(value)}
+		}
+	}
+	return pattern
+}
+
+// ParsePatterns parses patterns written one per line as "+ value" for inclusive, "- value" for exclusive
+// & "& value" for required ones. A value is a regex on the name or "kind:value", e.g. "glob:**/*.go" or "size:>10MB".
+// A line without a prefix is inclusive, empty lines are ignored. Errors of all lines are joined.
+func ParsePatterns(text string) ([]Pattern, error) {
+	var patterns []Pattern
+	var errs []error
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		patternType := Inclusive
+		switch line[0] {
+		case '+':
+			line = strings.TrimSpace(line[1:])
+		case '-':
+			patternType = Exclusive
+			line = strings.TrimSpace(line[1:])
+		case '&':
+			patternType = Required
+			line = strings.TrimSpace(line[1:])
+		}
+		pattern := parsePattern(patternType, line)
+		if err := pattern.compile(); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", i+1, err))
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, errors.Join(errs...)
+}
+
+// FormatPatterns writes patterns in the format read by ParsePatterns.
+func FormatPatterns(patterns []Pattern) string {
+	lines := make([]string, len(patterns))
+	for i, p := range patterns {
+		prefix := "+ "
+		switch p.Type {
+		case Exclusive:
+			prefix = "- "
+		case Required:
+			prefix = "& "
 		}
+		lines[i] = prefix + p.text()
 	}
-	return p.re.Match([]byte(fileName)), nil
+	return strings.Join(lines, "\n")
 }
@@ -1,7 +1,10 @@
 package masks
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
 )
 
 func TestPattern_Match(t *testing.T) {
@@ -46,7 +49,7 @@ func TestPattern_Match(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.pattern.Match(tt.fileName)
+			got, err := tt.pattern.Match(files.NewDirEntry(tt.fileName, false), nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Match() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -65,7 +68,7 @@ func TestBuiltInRegex(t *testing.T) {
 	for _, mask := range masks {
 		for _, pattern := range mask.Patterns {
 			t.Run(mask.Name+"_"+string(pattern.Type), func(t *testing.T) {
-				_, err := pattern.Match("any_file")
+				err := pattern.compile()
 				if err != nil {
 					t.Errorf("Built-in pattern %q is invalid: %v", pattern.Regex, err)
 				}
@@ -73,3 +76,38 @@ func TestBuiltInRegex(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePatterns(t *testing.T) {
+	t.Parallel()
+	patterns, err := ParsePatterns("+ \\.go$\n\n- _test\\.go$\n\\.mod$\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Pattern{
+		{Type: Inclusive, Regex: `\.go$`},
+		{Type: Exclusive, Regex: `_test\.go$`},
+		{Type: Inclusive, Regex: `\.mod$`},
+	}
+	if len(patterns) != len(want) {
+		t.Fatalf("expected %d patterns, got %+v", len(want), patterns)
+	}
+	for i, p := range patterns {
+		if p.Type != want[i].Type || p.Regex != want[i].Regex || p.re == nil {
+			t.Errorf("pattern %d: expected %+v, got %+v", i, want[i], p)
+		}
+	}
+	if text := FormatPatterns(want); text != "+ \\.go$\n- _test\\.go$\n+ \\.mod$" {
+		t.Errorf("unexpected text: %q", text)
+	}
+
+	patterns, err = ParsePatterns("+ (\n\\.go$\n- [")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "line 1:") || !strings.Contains(msg, "line 3:") {
+		t.Errorf("expected errors of both invalid lines, got %q", msg)
+	}
+	if len(patterns) != 1 {
+		t.Errorf("expected valid patterns to be returned, got %+v", patterns)
+	}
+}
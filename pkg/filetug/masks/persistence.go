@@ -0,0 +1,80 @@
+package masks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadMasks reads user masks persisted by SaveMasks. A missing file means no masks.
+func LoadMasks(filePath string) ([]Mask, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var list []Mask
+	if err = yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return list, nil
+}
+
+// SaveMasks writes user masks, built-in masks are skipped.
+func SaveMasks(filePath string, list []Mask) error {
+	user := make([]Mask, 0, len(list))
+	for _, m := range list {
+		if !m.BuiltIn {
+			user = append(user, m)
+		}
+	}
+	data, err := yaml.Marshal(user)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
+
+// Merge returns built-in masks followed by user masks, a user mask replaces a built-in one with the same name.
+func Merge(builtIn, user []Mask) []Mask {
+	result := slices.Clone(builtIn)
+	for _, m := range user {
+		m.BuiltIn = false
+		if i := slices.IndexFunc(result, func(b Mask) bool { return b.Name == m.Name }); i >= 0 {
+			result[i] = m
+		} else {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// Upsert replaces a mask named oldName, e.g. on rename, or with the same name as the mask, or appends it.
+// Another mask with the new name is removed.
+func Upsert(list []Mask, oldName string, mask Mask) []Mask {
+	if oldName != "" && oldName != mask.Name {
+		list = Delete(list, mask.Name)
+	}
+	if oldName == "" {
+		oldName = mask.Name
+	}
+	if i := slices.IndexFunc(list, func(m Mask) bool { return m.Name == oldName }); i >= 0 {
+		list[i] = mask
+		return list
+	}
+	return append(list, mask)
+}
+
+// Delete removes a mask by name.
+func Delete(list []Mask, name string) []Mask {
+	return slices.DeleteFunc(list, func(m Mask) bool { return m.Name == name })
+}
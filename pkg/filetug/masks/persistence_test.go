@@ -0,0 +1,113 @@
+package masks
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func maskNames(list []Mask) []string {
+	names := make([]string, len(list))
+	for i, m := range list {
+		names[i] = m.Name
+	}
+	return names
+}
+
+func TestLoadMasks_Missing(t *testing.T) {
+	t.Parallel()
+	list, err := LoadMasks(filepath.Join(t.TempDir(), "masks.yaml"))
+	if err != nil || list != nil {
+		t.Errorf("expected no masks & no error, got %v, %v", list, err)
+	}
+}
+
+func TestLoadMasks_Invalid(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "masks.yaml")
+	if err := os.WriteFile(filePath, []byte("name: [not a list"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMasks(filePath); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestSaveMasks(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "sub", "masks.yaml")
+	user := Mask{Name: "Go", Patterns: []Pattern{
+		{Type: Inclusive, Regex: `\.go$`},
+		{Type: Exclusive, Regex: `_test\.go$`},
+	}}
+	if err := SaveMasks(filePath, append(BuiltInMasks(), user)); err != nil {
+		t.Fatal(err)
+	}
+	list, err := LoadMasks(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "Go" || len(list[0].Patterns) != 2 || list[0].Patterns[1].Type != Exclusive {
+		t.Errorf("expected only the user mask to be saved, got %+v", list)
+	}
+	if list[0].BuiltIn {
+		t.Error("expected a loaded mask not to be built-in")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+	builtIn := BuiltInMasks()
+	user := []Mask{
+		{Name: "Data", BuiltIn: true, Patterns: []Pattern{{Type: Inclusive, Regex: `\.csv$`}}},
+		{Name: "Docs", Patterns: []Pattern{{Type: Inclusive, Regex: `\.md$`}}},
+	}
+	merged := Merge(builtIn, user)
+	if names := maskNames(merged); !slices.Equal(names, []string{"Coding", "Data", "Docs"}) {
+		t.Errorf("unexpected masks: %v", names)
+	}
+	if !merged[0].BuiltIn || merged[1].BuiltIn {
+		t.Error("expected an overridden mask not to be built-in")
+	}
+	if merged[1].Patterns[0].Regex != `\.csv$` {
+		t.Errorf("expected the user mask to replace the built-in one, got %+v", merged[1])
+	}
+	if !builtIn[1].BuiltIn || builtIn[1].Patterns[0].Regex == `\.csv$` {
+		t.Error("expected built-in masks not to be modified")
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	t.Parallel()
+	list := []Mask{{Name: "A"}, {Name: "B"}, {Name: "C"}}
+	tests := []struct {
+		name    string
+		oldName string
+		mask    Mask
+		want    []string
+	}{
+		{name: "new", mask: Mask{Name: "D"}, want: []string{"A", "B", "C", "D"}},
+		{name: "same_name", oldName: "B", mask: Mask{Name: "B"}, want: []string{"A", "B", "C"}},
+		{name: "new_existing_name", mask: Mask{Name: "B"}, want: []string{"A", "B", "C"}},
+		{name: "rename", oldName: "B", mask: Mask{Name: "E"}, want: []string{"A", "E", "C"}},
+		{name: "rename_to_existing", oldName: "B", mask: Mask{Name: "A"}, want: []string{"A", "C"}},
+		{name: "rename_missing", oldName: "X", mask: Mask{Name: "E"}, want: []string{"A", "B", "C", "E"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := maskNames(Upsert(slices.Clone(list), tt.oldName, tt.mask))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+	got := maskNames(Delete([]Mask{{Name: "A"}, {Name: "B"}}, "A"))
+	if !slices.Equal(got, []string{"B"}) {
+		t.Errorf("unexpected masks: %v", got)
+	}
+}
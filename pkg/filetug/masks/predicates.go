@@ -0,0 +1,258 @@
+package masks
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/filetug/filetug/pkg/gitutils"
+)
+
+// timeNow is used by age patterns, tests replace it.
+var timeNow = time.Now
+
+// globToRegex converts a shell glob to a regex: * & ? don't match a slash, ** matches any number of dirs.
+func globToRegex(glob string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated [ in glob: %q", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String(), nil
+}
+
+func compileGlob(glob string) (func(t *target) bool, error) {
+	if glob == "" {
+		return nil, fmt.Errorf("empty glob")
+	}
+	expr, err := globToRegex(glob)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob: %q", glob)
+	}
+	if strings.Contains(glob, "/") {
+		return func(t *target) bool { return re.MatchString(t.relPath) }, nil
+	}
+	return func(t *target) bool { return re.MatchString(t.entry.Name()) }, nil
+}
+
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+var sizeRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([a-zA-Z]*)$`)
+
+// parseSize parses sizes like "10MB" or "1.5k", units are binary.
+func parseSize(s string) (int64, error) {
+	m := sizeRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	unit, ok := sizeUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit: %q", m[2])
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// cutOperator splits a comparison operator from a value, ok is false if there is none.
+func cutOperator(s string) (op, value string, ok bool) {
+	s = strings.TrimSpace(s)
+	for _, op = range []string{"<=", ">=", "<", ">", "="} {
+		if value, ok = strings.CutPrefix(s, op); ok {
+			return op, strings.TrimSpace(value), true
+		}
+	}
+	return "", s, false
+}
+
+func compare(op string, a, b int64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	default:
+		return a == b
+	}
+}
+
+func compileSize(value string) (func(t *target) bool, error) {
+	op, value, hasOp := cutOperator(value)
+	if from, to, isRange := strings.Cut(value, "-"); isRange && !hasOp {
+		lo, err := parseSize(from)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parseSize(to)
+		if err != nil {
+			return nil, err
+		}
+		return sizeMatcher(func(size int64) bool { return size >= lo && size <= hi }), nil
+	}
+	limit, err := parseSize(value)
+	if err != nil {
+		return nil, err
+	}
+	return sizeMatcher(func(size int64) bool { return compare(op, size, limit) }), nil
+}
+
+func sizeMatcher(f func(size int64) bool) func(t *target) bool {
+	return func(t *target) bool {
+		if t.entry.IsDir() {
+			return false
+		}
+		info := t.fileInfo()
+		return info != nil && f(info.Size())
+	}
+}
+
+var ageUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"mo": 30 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+var ageRegex = regexp.MustCompile(`^(\d+)\s*([a-z]+)$`)
+
+func compileAge(value string) (func(t *target) bool, error) {
+	op, value, hasOp := cutOperator(value)
+	if !hasOp || op == "=" {
+		return nil, fmt.Errorf("age should start with <, <=, > or >=: %q", value)
+	}
+	m := ageRegex.FindStringSubmatch(strings.ToLower(value))
+	if m == nil {
+		return nil, fmt.Errorf("invalid age: %q", value)
+	}
+	unit, ok := ageUnits[m[2]]
+	if !ok {
+		return nil, fmt.Errorf("unknown age unit: %q", m[2])
+	}
+	n, _ := strconv.ParseInt(m[1], 10, 64)
+	limit := time.Duration(n) * unit
+	return func(t *target) bool {
+		info := t.fileInfo()
+		if info == nil {
+			return false
+		}
+		return compare(op, int64(timeNow().Sub(info.ModTime())), int64(limit))
+	}, nil
+}
+
+// splitList splits a comma-separated list into lower-case items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func compileType(value string) (func(t *target) bool, error) {
+	var file, dir, symlink bool
+	items := splitList(value)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("type should be one of file, dir, symlink")
+	}
+	for _, item := range items {
+		switch item {
+		case "file":
+			file = true
+		case "dir":
+			dir = true
+		case "symlink":
+			symlink = true
+		default:
+			return nil, fmt.Errorf("unknown type %q, expected file, dir or symlink", item)
+		}
+	}
+	return func(t *target) bool {
+		mode := t.entry.Type()
+		if mode&os.ModeSymlink == 0 && !t.entry.IsDir() {
+			if info := t.fileInfo(); info != nil {
+				mode = info.Mode()
+			}
+		}
+		switch {
+		case mode&os.ModeSymlink != 0:
+			return symlink
+		case t.entry.IsDir():
+			return dir
+		default:
+			return file
+		}
+	}, nil
+}
+
+func compileGit(value string) (func(t *target) bool, error) {
+	states := make(map[gitutils.FileState]bool)
+	items := splitList(value)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("git state should be one of modified, untracked, ignored")
+	}
+	for _, item := range items {
+		switch state := gitutils.FileState(item); state {
+		case gitutils.FileModified, gitutils.FileUntracked, gitutils.FileIgnored:
+			states[state] = true
+		default:
+			return nil, fmt.Errorf("unknown git state %q, expected modified, untracked or ignored", item)
+		}
+	}
+	return func(t *target) bool {
+		return states[t.gitState]
+	}, nil
+}
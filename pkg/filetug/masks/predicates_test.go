@@ -0,0 +1,219 @@
+package masks
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/gitutils"
+)
+
+// linkEntry is a symlink to a file.
+type linkEntry struct {
+	files.DirEntry
+}
+
+func (linkEntry) Type() os.FileMode {
+	return os.ModeSymlink
+}
+
+func TestPattern_Kinds(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	origTimeNow := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		timeNow = origTimeNow
+	})
+	file := func(name string, size int64, age time.Duration) os.DirEntry {
+		return files.NewDirEntry(name, false, files.Size(size), files.ModTime(now.Add(-age)))
+	}
+	day := 24 * time.Hour
+	tests := []struct {
+		name    string
+		pattern Pattern
+		entry   os.DirEntry
+		want    bool
+	}{
+		{name: "glob_name", pattern: Pattern{Kind: KindGlob, Value: "*.go"}, entry: Entry{DirEntry: file("main.go", 1, 0), RelPath: "cmd/main.go"}, want: true},
+		{name: "glob_name_no_match", pattern: Pattern{Kind: KindGlob, Value: "*.go"}, entry: file("main.gox", 1, 0)},
+		{name: "glob_question_class", pattern: Pattern{Kind: KindGlob, Value: "?.[ch]"}, entry: file("a.h", 1, 0), want: true},
+		{name: "glob_negated_class", pattern: Pattern{Kind: KindGlob, Value: "a.[!ch]"}, entry: file("a.h", 1, 0)},
+		{name: "glob_path", pattern: Pattern{Kind: KindGlob, Value: "cmd/*.go"}, entry: Entry{DirEntry: file("main.go", 1, 0), RelPath: "cmd/main.go"}, want: true},
+		{name: "glob_path_deeper", pattern: Pattern{Kind: KindGlob, Value: "cmd/*.go"}, entry: Entry{DirEntry: file("main.go", 1, 0), RelPath: "cmd/app/main.go"}},
+		{name: "glob_double_star", pattern: Pattern{Kind: KindGlob, Value: "**/*.go"}, entry: Entry{DirEntry: file("main.go", 1, 0), RelPath: "cmd/app/main.go"}, want: true},
+		{name: "glob_double_star_top", pattern: Pattern{Kind: KindGlob, Value: "**/*.go"}, entry: file("main.go", 1, 0), want: true},
+		{name: "glob_double_star_middle", pattern: Pattern{Kind: KindGlob, Value: "src/**/test/*"}, entry: Entry{DirEntry: file("a", 1, 0), RelPath: "src/x/y/test/a"}, want: true},
+		{name: "glob_trailing_double_star", pattern: Pattern{Kind: KindGlob, Value: "logs/**"}, entry: Entry{DirEntry: file("a.log", 1, 0), RelPath: "logs/2026/a.log"}, want: true},
+		{name: "path", pattern: Pattern{Kind: KindPath, Regex: `^vendor/`}, entry: Entry{DirEntry: file("a.go", 1, 0), RelPath: "vendor/a.go"}, want: true},
+		{name: "path_name_only", pattern: Pattern{Kind: KindPath, Regex: `^vendor/`}, entry: file("a.go", 1, 0)},
+		{name: "size_greater", pattern: Pattern{Kind: KindSize, Value: ">10MB"}, entry: file("big", 11<<20, 0), want: true},
+		{name: "size_greater_no_match", pattern: Pattern{Kind: KindSize, Value: "> 10MB"}, entry: file("small", 10<<20, 0)},
+		{name: "size_less_or_equal", pattern: Pattern{Kind: KindSize, Value: "<=1.5k"}, entry: file("a", 1536, 0), want: true},
+		{name: "size_exact", pattern: Pattern{Kind: KindSize, Value: "100"}, entry: file("a", 100, 0), want: true},
+		{name: "size_range", pattern: Pattern{Kind: KindSize, Value: "1KiB-2KiB"}, entry: file("a", 2048, 0), want: true},
+		{name: "size_range_no_match", pattern: Pattern{Kind: KindSize, Value: "1KiB-2KiB"}, entry: file("a", 100, 0)},
+		{name: "size_dir", pattern: Pattern{Kind: KindSize, Value: ">=0"}, entry: files.NewDirEntry("dir", true)},
+		{name: "size_no_info", pattern: Pattern{Kind: KindSize, Value: ">=0"}, entry: files.NewDirEntry("a", false)},
+		{name: "age_newer", pattern: Pattern{Kind: KindAge, Value: "<7d"}, entry: file("a", 1, 6*day), want: true},
+		{name: "age_newer_no_match", pattern: Pattern{Kind: KindAge, Value: "<7d"}, entry: file("a", 1, 8*day)},
+		{name: "age_older", pattern: Pattern{Kind: KindAge, Value: ">1mo"}, entry: file("a", 1, 31*day), want: true},
+		{name: "age_hours", pattern: Pattern{Kind: KindAge, Value: ">=2h"}, entry: file("a", 1, 2*time.Hour), want: true},
+		{name: "age_no_info", pattern: Pattern{Kind: KindAge, Value: "<1y"}, entry: files.NewDirEntry("a", false)},
+		{name: "type_file", pattern: Pattern{Kind: KindType, Value: "file"}, entry: file("a", 1, 0), want: true},
+		{name: "type_dir", pattern: Pattern{Kind: KindType, Value: "file, dir"}, entry: files.NewDirEntry("a", true), want: true},
+		{name: "type_dir_no_match", pattern: Pattern{Kind: KindType, Value: "file"}, entry: files.NewDirEntry("a", true)},
+		{name: "type_symlink", pattern: Pattern{Kind: KindType, Value: "symlink"}, entry: linkEntry{files.NewDirEntry("a", false)}, want: true},
+		{name: "type_symlink_not_file", pattern: Pattern{Kind: KindType, Value: "file"}, entry: linkEntry{files.NewDirEntry("a", false)}},
+		{name: "git_modified", pattern: Pattern{Kind: KindGit, Value: "modified,untracked"}, entry: Entry{DirEntry: file("a", 1, 0), GitState: gitutils.FileModified}, want: true},
+		{name: "git_ignored", pattern: Pattern{Kind: KindGit, Value: "ignored"}, entry: Entry{DirEntry: file("a", 1, 0), GitState: gitutils.FileUntracked}},
+		{name: "git_unknown", pattern: Pattern{Kind: KindGit, Value: "modified"}, entry: file("a", 1, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pattern.Type = Inclusive
+			got, err := tt.pattern.Match(tt.entry, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPattern_InvalidValues(t *testing.T) {
+	t.Parallel()
+	for _, p := range []Pattern{
+		{Kind: KindGlob},
+		{Kind: KindGlob, Value: "[a"},
+		{Kind: KindSize, Value: ">ten"},
+		{Kind: KindSize, Value: ">10XB"},
+		{Kind: KindSize, Value: "1MB-x"},
+		{Kind: KindSize, Value: "x-1MB"},
+		{Kind: KindAge, Value: "7d"},
+		{Kind: KindAge, Value: "=7d"},
+		{Kind: KindAge, Value: "<7"},
+		{Kind: KindAge, Value: "<7q"},
+		{Kind: KindType, Value: ""},
+		{Kind: KindType, Value: "pipe"},
+		{Kind: KindGit, Value: " , "},
+		{Kind: KindGit, Value: "staged"},
+		{Kind: KindPath, Regex: "("},
+		{Kind: "color", Value: "red"},
+	} {
+		if _, err := p.Match(files.NewDirEntry("a", false), nil); err == nil {
+			t.Errorf("expected an error for %v", p)
+		}
+	}
+}
+
+func TestMask_Match_Required(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	mask := Mask{Name: "Large old logs", Patterns: []Pattern{
+		{Type: Required, Kind: KindGlob, Value: "*.log"},
+		{Type: Required, Kind: KindSize, Value: ">10MB"},
+		{Type: Required, Kind: KindAge, Value: ">1mo"},
+		{Type: Exclusive, Regex: `^keep`},
+	}}
+	tests := []struct {
+		name  string
+		entry os.DirEntry
+		info  os.FileInfo
+		want  bool
+	}{
+		{name: "all", entry: files.NewDirEntry("app.log", false, files.Size(20<<20), files.ModTime(now.AddDate(0, -2, 0))), want: true},
+		{name: "small", entry: files.NewDirEntry("app.log", false, files.Size(1<<20), files.ModTime(now.AddDate(0, -2, 0)))},
+		{name: "recent", entry: files.NewDirEntry("app.log", false, files.Size(20<<20), files.ModTime(now))},
+		{name: "not_log", entry: files.NewDirEntry("app.txt", false, files.Size(20<<20), files.ModTime(now.AddDate(0, -2, 0)))},
+		{name: "excluded", entry: files.NewDirEntry("keep.log", false, files.Size(20<<20), files.ModTime(now.AddDate(0, -2, 0)))},
+		{
+			name:  "info_passed",
+			entry: files.NewDirEntry("app.log", false),
+			info:  files.NewFileInfo(files.NewDirEntry("app.log", false), files.Size(20<<20), files.ModTime(now.AddDate(-1, 0, 0))),
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mask.Match(tt.entry, tt.info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	withInclusive := Mask{Patterns: []Pattern{
+		{Type: Inclusive, Regex: `\.go$`},
+		{Type: Inclusive, Regex: `\.mod$`},
+		{Type: Required, Kind: KindType, Value: "file"},
+	}}
+	if got, _ := withInclusive.Match(files.NewDirEntry("go.mod", false), nil); !got {
+		t.Error("expected a file matching an inclusive & required patterns to match")
+	}
+	if got, _ := withInclusive.Match(files.NewDirEntry("go.sum", false), nil); got {
+		t.Error("expected a file matching no inclusive pattern not to match")
+	}
+	onlyExclusive := Mask{Patterns: []Pattern{{Type: Exclusive, Regex: `\.go$`}}}
+	if got, _ := onlyExclusive.Match(files.NewDirEntry("a.txt", false), nil); got {
+		t.Error("expected a mask with only exclusive patterns to match nothing")
+	}
+}
+
+func TestMask_NeedsGitState(t *testing.T) {
+	t.Parallel()
+	if (&Mask{Patterns: []Pattern{{Kind: KindGlob, Value: "*"}}}).NeedsGitState() {
+		t.Error("expected a mask without git patterns not to need git state")
+	}
+	if !(&Mask{Patterns: []Pattern{{Kind: KindGit, Value: "modified"}}}).NeedsGitState() {
+		t.Error("expected a mask with a git pattern to need git state")
+	}
+}
+
+func TestParsePatterns_Kinds(t *testing.T) {
+	t.Parallel()
+	text := strings.Join([]string{
+		"& glob:**/*.log",
+		"& size:>10MB",
+		"- path:^vendor/",
+		"+ regex:glob:literally",
+		"+ type:file,symlink",
+		"- git:ignored",
+		"+ age:<7d",
+		"+ unknown:kind",
+	}, "\n")
+	patterns, err := ParsePatterns(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Pattern{
+		{Type: Required, Kind: KindGlob, Value: "**/*.log"},
+		{Type: Required, Kind: KindSize, Value: ">10MB"},
+		{Type: Exclusive, Kind: KindPath, Regex: "^vendor/"},
+		{Type: Inclusive, Regex: "glob:literally"},
+		{Type: Inclusive, Kind: KindType, Value: "file,symlink"},
+		{Type: Exclusive, Kind: KindGit, Value: "ignored"},
+		{Type: Inclusive, Kind: KindAge, Value: "<7d"},
+		{Type: Inclusive, Regex: "unknown:kind"},
+	}
+	if len(patterns) != len(want) {
+		t.Fatalf("expected %d patterns, got %v", len(want), patterns)
+	}
+	for i, p := range patterns {
+		if p.String() != want[i].String() {
+			t.Errorf("pattern %d: expected %v, got %v", i, want[i], p)
+		}
+	}
+	if formatted := FormatPatterns(patterns); formatted != text {
+		t.Errorf("expected patterns to be formatted back, got:\n%s", formatted)
+	}
+	if _, err = ParsePatterns("& size:big\n+ age:7d"); err == nil || !strings.Contains(err.Error(), "line 2:") {
+		t.Errorf("expected errors of invalid values, got %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+package filetug
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/filetug/filetug/pkg/gitutils"
+)
+
+// maskCountsInterval limits how often counts of matches in subdirectories are redrawn while counting.
+var maskCountsInterval = 200 * time.Millisecond
+
+func (nav *Navigator) closeMasks() {
+	nav.stopMaskCounts()
+	nav.right.SetContent(nav.previewer)
+	nav.app.SetFocus(nav.files)
+}
+
+func (nav *Navigator) isMasksShown() bool {
+	return nav.masks != nil && nav.right != nil && nav.right.content == nav.masks
+}
+
+func (nav *Navigator) countMaskMatchesIfVisible() {
+	if nav.isMasksShown() {
+		nav.countMaskMatches()
+	}
+}
+
+func (nav *Navigator) stopMaskCounts() {
+	if nav.cancelMaskCounts != nil {
+		nav.cancelMaskCounts()
+		nav.cancelMaskCounts = nil
+	}
+}
+
+// maskRows returns rows of a real dir that masks are applied to, nil for virtual lists like search results.
+func (nav *Navigator) maskRows() *FileRows {
+	if nav.files == nil || nav.files.rows == nil || nav.files.rows.virtual || nav.files.rows.Dir == nil {
+		return nil
+	}
+	return nav.files.rows
+}
+
+// isMaskCandidate reports whether masks are applied to an entry: files that are not hidden by the view settings.
+// A mask applied as a filter is ignored so counts & selection don't depend on it.
+func isMaskCandidate(entry os.DirEntry, showHidden bool) bool {
+	return !entry.IsDir() && (showHidden || !strings.HasPrefix(entry.Name(), "."))
+}
+
+// getWorktreeStates reads git states of files for masks with git patterns.
+var getWorktreeStates = gitutils.GetWorktreeStates
+
+// maskEntries makes entries for masks & filter queries with paths relative to a root dir
+// & git states if they are needed.
+type maskEntries struct {
+	root string
+	git  *gitutils.WorktreeStates // nil if not needed or not in a repository
+}
+
+func newMaskEntries(root string, needsGitState bool) *maskEntries {
+	e := &maskEntries{root: root}
+	if needsGitState {
+		if repoRoot := gitutils.GetRepositoryRoot(root); repoRoot != "" {
+			e.git, _ = getWorktreeStates(repoRoot)
+		}
+	}
+	return e
+}
+
+func (e *maskEntries) entry(dir string, entry os.DirEntry) masks.Entry {
+	fullPath := path.Join(dir, entry.Name())
+	rel := strings.TrimPrefix(strings.TrimPrefix(fullPath, e.root), "/")
+	result := masks.Entry{DirEntry: entry, RelPath: rel}
+	if e.git != nil {
+		result.GitState = e.git.State(fullPath, entry.IsDir())
+	}
+	return result
+}
+
+// countMaskMatches counts files matching each mask in the current dir & its subdirectories in background,
+// a previous count is cancelled.
+func (nav *Navigator) countMaskMatches() {
+	nav.stopMaskCounts()
+	nav.masks.ResetCounts()
+	rows := nav.maskRows()
+	if rows == nil {
+		return
+	}
+	list := nav.masks.Masks()
+	errs := make([]error, len(list))
+	for i := range list {
+		errs[i] = list[i].Compile()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	nav.cancelMaskCounts = cancel
+	dir := rows.Dir.Path()
+	children := make([]os.DirEntry, len(rows.AllEntries))
+	for i, entry := range rows.AllEntries {
+		children[i] = entry
+	}
+	go nav.countMaskMatchesInBackground(ctx, rows.Dir.Store(), dir, children, list, errs, nav.files.filter.ShowHidden)
+}
+
+func countMatches(list []masks.Mask, errs []error, entry masks.Entry, counts []int) {
+	for i := range list {
+		if errs[i] != nil {
+			continue
+		}
+		if matched, _ := list[i].Match(entry, nil); matched {
+			counts[i]++
+		}
+	}
+}
+
+// countMaskMatchesInBackground counts matches in the dir, then walks subdirectories
+// & periodically reports counts of matches. Unreadable dirs are skipped.
+func (nav *Navigator) countMaskMatchesInBackground(
+	ctx context.Context, store files.Store, dir string, children []os.DirEntry, list []masks.Mask, errs []error, showHidden bool,
+) {
+	entries := newMaskEntries(dir, slices.ContainsFunc(list, func(m masks.Mask) bool { return m.NeedsGitState() }))
+	currDirCounts := make([]int, len(list))
+	var queue []string
+	for _, child := range children {
+		if child.IsDir() {
+			if showHidden || !strings.HasPrefix(child.Name(), ".") {
+				queue = append(queue, path.Join(dir, child.Name()))
+			}
+		} else if isMaskCandidate(child, showHidden) {
+			countMatches(list, errs, entries.entry(dir, child), currDirCounts)
+		}
+	}
+	nav.app.QueueUpdateDraw(func() {
+		if ctx.Err() != nil {
+			return // the dir has changed or the panel is closed
+		}
+		for i := range list {
+			nav.masks.SetCurrDirCount(i, currDirCounts[i], errs[i])
+		}
+	})
+
+	counts := make([]int, len(list))
+	report := func(done bool) {
+		snapshot := append([]int(nil), counts...)
+		nav.app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			for i := range list {
+				nav.masks.SetSubDirsCount(i, snapshot[i], done, errs[i])
+			}
+		})
+	}
+	reportedAt := timeNow()
+	for len(queue) > 0 {
+		if ctx.Err() != nil {
+			return
+		}
+		subDir := queue[0]
+		queue = queue[1:]
+		children, err := store.ReadDir(ctx, subDir)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			if child.IsDir() {
+				if showHidden || !strings.HasPrefix(child.Name(), ".") {
+					queue = append(queue, path.Join(subDir, child.Name()))
+				}
+			} else if isMaskCandidate(child, showHidden) {
+				countMatches(list, errs, entries.entry(subDir, child), counts)
+			}
+		}
+		if now := timeNow(); now.Sub(reportedAt) >= maskCountsInterval {
+			reportedAt = now
+			report(false)
+		}
+	}
+	report(true)
+}
+
+// onMaskAction selects, deselects or filters files of the current dir matching a mask, or edits masks.
+func (nav *Navigator) onMaskAction(mask masks.Mask, action masks.Action) {
+	switch action {
+	case masks.ActionNew, masks.ActionEdit:
+		nav.showMaskEditor(mask, action == masks.ActionNew)
+		return
+	case masks.ActionDelete:
+		nav.deleteMask(mask)
+		return
+	}
+	rows := nav.maskRows()
+	if rows == nil {
+		return
+	}
+	if err := mask.Compile(); err != nil {
+		nav.masks.SetFiltered(-1)
+		nav.notifyError("Masks", err)
+		return
+	}
+	switch action {
+	case masks.ActionFilter:
+		nav.setMaskFilter(&mask)
+	case masks.ActionClearFilter:
+		nav.setMaskFilter(nil)
+	default:
+		selected := action == masks.ActionSelect
+		var n int
+		dir := rows.Dir.Path()
+		entries := newMaskEntries(dir, mask.NeedsGitState())
+		for _, entry := range rows.AllEntries {
+			if !isMaskCandidate(entry, nav.files.filter.ShowHidden) {
+				continue
+			}
+			if matched, _ := mask.Match(entries.entry(dir, entry), nil); matched {
+				rows.setSelected(entry, selected)
+				n++
+			}
+		}
+		rows.endRange()
+		nav.files.updateFooter()
+		verb := "selected"
+		if !selected {
+			verb = "deselected"
+		}
+		nav.notify(notificationInfo, "Masks", fmt.Sprintf("%s: %d file(s) %s", mask.Name, n, verb))
+	}
+}
+
+// setMaskFilter shows only files matching a compiled mask, nil shows all entries.
+// Paths are relative to the dir of an entry, git states are read once per dir.
+func (nav *Navigator) setMaskFilter(mask *masks.Mask) {
+	if mask == nil {
+		nav.files.filter.MaskFilter = nil
+	} else {
+		var entries *maskEntries
+		nav.files.filter.MaskFilter = func(entry os.DirEntry) bool {
+			if entry.IsDir() {
+				return true
+			}
+			dir := nav.currentDirPath()
+			if e, ok := entry.(files.EntryWithDirPath); ok && e.DirPath() != "" {
+				dir = e.DirPath()
+			}
+			if entries == nil || entries.root != dir {
+				entries = newMaskEntries(dir, mask.NeedsGitState())
+			}
+			matched, _ := mask.Match(entries.entry(dir, entry), nil)
+			return matched
+		}
+	}
+	nav.files.SetFilter(nav.files.filter)
+}
+
+// reapplyMaskFilter updates the filter after masks are edited, it's removed with its mask.
+func (nav *Navigator) reapplyMaskFilter() {
+	if nav.files == nil || nav.files.rows == nil || nav.files.filter.MaskFilter == nil {
+		return
+	}
+	i := nav.masks.Filtered()
+	if i < 0 {
+		nav.setMaskFilter(nil)
+		return
+	}
+	mask := nav.masks.Masks()[i]
+	if err := mask.Compile(); err != nil {
+		nav.masks.SetFiltered(-1)
+		nav.setMaskFilter(nil)
+		return
+	}
+	nav.setMaskFilter(&mask)
+}
+
+func (nav *Navigator) deleteMask(mask masks.Mask) {
+	if mask.BuiltIn {
+		nav.notify(notificationWarning, "Masks", fmt.Sprintf("Built-in mask %q can't be deleted", mask.Name))
+		return
+	}
+	if err := deleteUserMask(mask.Name); err != nil {
+		nav.notifyError("Masks", err)
+		return
+	}
+	nav.notify(notificationInfo, "Masks", fmt.Sprintf("Deleted mask %q", mask.Name))
+	nav.showMasks()
+}
@@ -0,0 +1,175 @@
+package filetug
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+	"github.com/gdamore/tcell/v2"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+// newNavigatorWithMasks shows the masks panel for a temp dir with files matching built-in masks in it & in subdirs.
+func newNavigatorWithMasks(t *testing.T) (*Navigator, chan func(), string) {
+	t.Helper()
+	orig := maskCountsInterval
+	maskCountsInterval = time.Hour
+	t.Cleanup(func() {
+		maskCountsInterval = orig
+	})
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.py", "b.json", "c.txt", ".d.py")
+	for _, name := range []string{"sub/x.py", "sub/deeper/y.csv", ".hidden/z.py"} {
+		p := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, nil, 0o644))
+	}
+	children, err := nav.store.ReadDir(context.Background(), dir)
+	assert.NoError(t, err)
+	nav.files.SetRows(NewFileRows(files.NewDirContext(nav.store, dir, children)), true)
+	drainQueuedUpdates(updates)
+
+	nav.showMasks()
+	waitMaskCounts(t, nav, updates, 0, "1", "1")
+	return nav, updates, dir
+}
+
+// waitMaskCounts runs queued updates until the i-th mask shows the expected counts of matches.
+func waitMaskCounts(t *testing.T, nav *Navigator, updates chan func(), i int, currDir, subDirs string) {
+	t.Helper()
+	for {
+		if c, s := nav.masks.CountTexts(i); c == currDir && s == subDirs {
+			return
+		}
+		runQueuedUpdate(t, updates)
+	}
+}
+
+func lastNotification(nav *Navigator) notification {
+	items := nav.notifications.all()
+	if len(items) == 0 {
+		return notification{}
+	}
+	return items[len(items)-1]
+}
+
+func TestNavigator_ShowMasks_Counts(t *testing.T) {
+	nav, updates, _ := newNavigatorWithMasks(t)
+	p := nav.masks
+	assert.Equal(t, p, nav.right.content)
+	currDir, subDirs := p.CountTexts(0) // Coding
+	assert.Equal(t, "1", currDir)
+	assert.Equal(t, "1", subDirs)
+	currDir, subDirs = p.CountTexts(1) // Data
+	assert.Equal(t, "1", currDir)
+	assert.Equal(t, "1", subDirs)
+
+	t.Run("hidden", func(t *testing.T) {
+		nav.files.filter.ShowHidden = true
+		nav.countMaskMatches()
+		waitMaskCounts(t, nav, updates, 0, "2", "2")
+		currDir, subDirs := p.CountTexts(0)
+		assert.Equal(t, "2", currDir)
+		assert.Equal(t, "2", subDirs)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		nav.countMaskMatches()
+		nav.closeMasks()
+		assert.Equal(t, nav.previewer, nav.right.content)
+		drainQueuedUpdates(updates)
+		assert.Nil(t, nav.cancelMaskCounts)
+	})
+}
+
+func TestNavigator_MaskActions(t *testing.T) {
+	nav, updates, dir := newNavigatorWithMasks(t)
+	p := nav.masks
+	rows := nav.files.rows
+	press := func(event *tcell.EventKey) {
+		p.InputHandler()(event, func(tview.Primitive) {})
+		drainQueuedUpdates(updates)
+	}
+	names := func(entries []files.EntryWithDirPath) (result []string) {
+		for _, entry := range entries {
+			result = append(result, entry.Name())
+		}
+		return
+	}
+
+	press(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)) // Coding
+	assert.Equal(t, []string{"a.py"}, names(rows.SelectedEntries()))
+	assert.True(t, rows.selection[filepath.Join(dir, "a.py")])
+	assert.Equal(t, "Coding: 1 file(s) selected", lastNotification(nav).message)
+
+	press(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	press(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)) // Data
+	assert.Equal(t, []string{"a.py", "b.json"}, names(rows.SelectedEntries()))
+	press(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModShift))
+	assert.Equal(t, []string{"a.py"}, names(rows.SelectedEntries()))
+
+	press(keyRune('f'))
+	assert.Equal(t, 1, p.Filtered())
+	assert.ElementsMatch(t, []string{"sub", "b.json"}, names(rows.VisibleEntries))
+	nav.files.SetRows(NewFileRows(rows.Dir), true)
+	assert.NotNil(t, nav.files.rows.filter.MaskFilter, "the filter is kept for other rows")
+	press(keyRune('f'))
+	assert.Equal(t, -1, p.Filtered())
+	assert.Nil(t, nav.files.filter.MaskFilter)
+
+	press(keyRune('z'))
+	press(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	assert.Equal(t, nav.previewer, nav.right.content)
+}
+
+func TestNavigator_MaskActions_InvalidRegex(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t, "a.txt")
+	nav.showMasks()
+	nav.masks.SetFiltered(0)
+	nav.onMaskAction(masks.Mask{Name: "Bad", Patterns: []masks.Pattern{{Type: masks.Inclusive, Regex: "("}}}, masks.ActionFilter)
+	assert.Equal(t, -1, nav.masks.Filtered())
+	assert.Contains(t, lastNotification(nav).message, "invalid regex")
+	assert.Nil(t, nav.files.filter.MaskFilter)
+}
+
+func TestNavigator_MaskActions_Git(t *testing.T) {
+	withUserMasksFile(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "clean.go", "changed.go", "new.go")
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	for _, name := range []string{"clean.go", "changed.go"} {
+		_, err = wt.Add(name)
+		assert.NoError(t, err)
+	}
+	_, err = wt.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "changed.go"), []byte("v2"), 0o644))
+	changed := masks.Mask{Name: "Changed", Patterns: []masks.Pattern{
+		{Type: masks.Inclusive, Kind: masks.KindGit, Value: "modified,untracked"},
+	}}
+	assert.NoError(t, saveUserMask("", changed))
+
+	nav.showMasks()
+	waitMaskCounts(t, nav, updates, 2, "2", "0")
+	nav.onMaskAction(changed, masks.ActionSelect)
+	var selected []string
+	for _, entry := range nav.files.rows.SelectedEntries() {
+		selected = append(selected, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"changed.go", "new.go"}, selected)
+
+	nav.onMaskAction(changed, masks.ActionFilter)
+	var visible []string
+	for _, entry := range nav.files.rows.VisibleEntries {
+		visible = append(visible, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"changed.go", "new.go"}, visible)
+}
@@ -0,0 +1,62 @@
+package filetug
+
+import (
+	"path/filepath"
+
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+	"github.com/filetug/filetug/pkg/filetug/masks"
+)
+
+const userMasksFileName = "masks.yaml"
+
+var getUserMasksFilePath = func() (string, error) {
+	dir, err := ftsettings.GetDatatugUserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, userMasksFileName), nil
+}
+
+func loadUserMasks() ([]masks.Mask, error) {
+	filePath, err := getUserMasksFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return masks.LoadMasks(filePath)
+}
+
+// allMasks returns built-in masks merged with user masks.
+// If user masks can't be loaded the error is reported & built-in masks are returned.
+func (nav *Navigator) allMasks() []masks.Mask {
+	user, err := loadUserMasks()
+	if err != nil {
+		nav.notifyError("Masks", err)
+	}
+	return masks.Merge(masks.BuiltInMasks(), user)
+}
+
+// saveUserMask creates or updates a user mask, oldName is empty for a new mask.
+func saveUserMask(oldName string, mask masks.Mask) error {
+	return updateUserMasks(func(list []masks.Mask) []masks.Mask {
+		return masks.Upsert(list, oldName, mask)
+	})
+}
+
+// deleteUserMask deletes a user mask, a built-in one with the same name is listed again.
+func deleteUserMask(name string) error {
+	return updateUserMasks(func(list []masks.Mask) []masks.Mask {
+		return masks.Delete(list, name)
+	})
+}
+
+func updateUserMasks(update func([]masks.Mask) []masks.Mask) error {
+	filePath, err := getUserMasksFilePath()
+	if err != nil {
+		return err
+	}
+	list, err := masks.LoadMasks(filePath)
+	if err != nil {
+		return err
+	}
+	return masks.SaveMasks(filePath, update(list))
+}
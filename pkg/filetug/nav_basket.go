@@ -0,0 +1,387 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	copyOperation OperationType = "copy"
+	moveOperation OperationType = "move"
+)
+
+var (
+	errCopyNotSupported     = fmt.Errorf("copy: %w", files.ErrNotSupported)
+	errMoveNotSupported     = fmt.Errorf("move: %w", files.ErrNotSupported)
+	errItemFromAnotherStore = errors.New("item is from another store")
+)
+
+var _ files.EntryWithDirPath = basketEntry{}
+
+// basketEntry is an item of the basket or of a list presented in the files panel.
+type basketEntry struct {
+	os.DirEntry
+	item    BasketItem
+	foreign bool // the item is from a store other than the current one
+}
+
+func (e basketEntry) DirPath() string {
+	return path.Dir(e.item.Path)
+}
+
+func (e basketEntry) FullName() string {
+	return e.item.Path
+}
+
+func (e basketEntry) String() string {
+	if e.foreign {
+		return e.item.Store + e.item.Path
+	}
+	return e.item.Path
+}
+
+func newBasketEntry(item BasketItem, currentStore string) basketEntry {
+	e := basketEntry{item: item, foreign: item.Store != currentStore}
+	if strings.HasPrefix(item.Store, "file:") {
+		if info, err := os.Lstat(item.Path); err == nil {
+			e.DirEntry = fs.FileInfoToDirEntry(info)
+			return e
+		}
+	}
+	e.DirEntry = files.NewDirEntry(item.name(), item.IsDir)
+	return e
+}
+
+func basketItemOf(store files.Store, entry files.EntryWithDirPath) BasketItem {
+	if e, ok := entry.(basketEntry); ok {
+		return e.item
+	}
+	rootURL := store.RootURL()
+	return BasketItem{Store: rootURL.String(), Path: entry.FullName(), IsDir: entry.IsDir()}
+}
+
+func (nav *Navigator) loadBasket() {
+	filePath, err := getBasketFilePath()
+	if err == nil {
+		nav.basket, err = loadBasket(filePath)
+	}
+	if nav.basket == nil {
+		nav.basket = &Basket{}
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to load basket: %w", err))
+	}
+}
+
+func (nav *Navigator) saveBasket() {
+	filePath, err := getBasketFilePath()
+	if err == nil {
+		err = saveBasket(filePath, nav.basket)
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to save basket: %w", err))
+	}
+}
+
+// syncBasket updates basket items affected by a mutating action. It is safe to be called from operation goroutines.
+func (nav *Navigator) syncBasket(r ftjournal.Record) {
+	if nav.basket.applyRecord(r) {
+		nav.saveBasket()
+	}
+}
+
+// toggleBasket adds the current entry to the basket or removes it from there.
+// If entries are selected in the files panel, all of them are added & the selection is cleared.
+func (nav *Navigator) toggleBasket() {
+	if selected := nav.filesSelection(); len(selected) > 0 {
+		for _, entry := range selected {
+			nav.basket.AddToBasket(basketItemOf(nav.store, entry))
+		}
+		nav.saveBasket()
+		nav.files.rows.ClearSelection()
+		nav.files.updateFooter()
+		nav.notify(notificationInfo, "Basket", fmt.Sprintf("added %d item(s)", len(selected)))
+		return
+	}
+	b := nav.getCurrentBrowser()
+	if b == nil {
+		return
+	}
+	entry := b.GetCurrentEntry()
+	if entry == nil || entry.FullName() == "/" {
+		return
+	}
+	nav.basket.Toggle(basketItemOf(nav.store, entry))
+	nav.saveBasket()
+	nav.renderBasketIfVisible()
+}
+
+// showBasket displays basket items as a virtual listing in the files panel.
+func (nav *Navigator) showBasket() {
+	nav.basketRows = nav.newBasketRows()
+	nav.files.SetRows(nav.basketRows, true)
+	nav.app.SetFocus(nav.files.table)
+}
+
+func (nav *Navigator) isBasketShown() bool {
+	return nav.basketRows != nil && nav.files.rows == nav.basketRows
+}
+
+// closeBasket returns the files panel to the current directory.
+func (nav *Navigator) closeBasket() {
+	nav.basketRows = nil
+	nav.refreshCurrentDir()
+}
+
+func (nav *Navigator) renderBasketIfVisible() {
+	if !nav.isBasketShown() {
+		return
+	}
+	row, _ := nav.files.table.GetSelection()
+	nav.basketRows = nav.newBasketRows()
+	nav.files.SetRows(nav.basketRows, true)
+	if count := nav.basketRows.GetRowCount(); row >= count {
+		row = count - 1
+	}
+	if row > 0 {
+		nav.files.table.Select(row, 0)
+	}
+}
+
+func (nav *Navigator) newBasketRows() *FileRows {
+	rootURL := nav.store.RootURL()
+	currentStore := rootURL.String()
+	items := nav.basket.Items()
+	rows := NewFileRows(nav.current.Dir())
+	rows.virtual = true
+	rows.onKey = nav.basketInputCapture
+	rows.AllEntries = make([]files.EntryWithDirPath, len(items))
+	for i, item := range items {
+		rows.AllEntries[i] = newBasketEntry(item, currentStore)
+	}
+	rows.VisibleEntries = rows.AllEntries
+	rows.Infos = make([]os.FileInfo, len(items))
+	rows.VisualInfos = make([]os.FileInfo, len(items))
+	return rows
+}
+
+func (nav *Navigator) basketInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event = nav.deleteShownInputCapture(event); event == nil {
+		return nil
+	}
+	switch event.Key() {
+	case tcell.KeyEscape:
+		nav.closeBasket()
+		return nil
+	case tcell.KeyEnter:
+		if entry, ok := nav.files.GetCurrentEntry().(basketEntry); ok {
+			nav.openBasketItem(entry.item)
+		}
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'c':
+			nav.transferBasketTo(nav.currentDirPath(), false)
+			return nil
+		case 'm':
+			nav.transferBasketTo(nav.currentDirPath(), true)
+			return nil
+		case 'x':
+			if entry, ok := nav.files.GetCurrentEntry().(basketEntry); ok {
+				nav.basket.Remove(entry.item)
+				nav.saveBasket()
+				nav.renderBasketIfVisible()
+			}
+			return nil
+		case 'X':
+			nav.basket.Clear()
+			nav.saveBasket()
+			nav.renderBasketIfVisible()
+			return nil
+		}
+	}
+	return event
+}
+
+// openBasketItem navigates to a directory item or to the parent directory of a file item,
+// switching the store if needed. It's used for items of lists & tagged entries as well.
+func (nav *Navigator) openBasketItem(item BasketItem) {
+	store, err := nav.storeFor(item.Store)
+	if err != nil {
+		nav.showError(err)
+		return
+	}
+	nav.basketRows, nav.listRows, nav.tagRows = nil, nil, nil
+	if store != nav.store {
+		nav.SetStore(store)
+	}
+	if item.IsDir {
+		nav.goDirByPath(item.Path)
+		return
+	}
+	nav.goDirByPath(path.Dir(item.Path))
+	nav.files.SetCurrentFile(item.name())
+}
+
+// storeFor returns the current store if it matches the given root URL, otherwise creates a new one.
+func (nav *Navigator) storeFor(storeURL string) (files.Store, error) {
+	rootURL := nav.store.RootURL()
+	if storeURL == rootURL.String() {
+		return nav.store, nil
+	}
+	root, err := url.Parse(storeURL)
+	if err != nil {
+		return nil, err
+	}
+	store := newStoreForURL(*root)
+	if store == nil {
+		return nil, fmt.Errorf("%s: %w", storeURL, files.ErrNotSupported)
+	}
+	return store, nil
+}
+
+// itemEntries returns items of the current store as entries an action can be applied to.
+func (nav *Navigator) itemEntries(items []BasketItem) []files.EntryWithDirPath {
+	rootURL := nav.store.RootURL()
+	currentStore := rootURL.String()
+	var entries []files.EntryWithDirPath
+	for _, item := range items {
+		if item.Store == currentStore {
+			entries = append(entries, newBasketEntry(item, currentStore))
+		}
+	}
+	return entries
+}
+
+// transferBasketTo copies or moves basket items into a directory of the current store.
+func (nav *Navigator) transferBasketTo(dirPath string, isMove bool) *Operation {
+	return nav.transferItemsTo(nav.basket.Items(), dirPath, isMove)
+}
+
+// transferItemsTo copies or moves items into a directory of the current store.
+func (nav *Navigator) transferItemsTo(items []BasketItem, dirPath string, isMove bool) *Operation {
+	if len(items) == 0 || dirPath == "" {
+		return nil
+	}
+	store := nav.store
+	rootURL := store.RootURL()
+	storeURL := rootURL.String()
+	dirs := []string{dirPath}
+	moves := make([]ftjournal.Move, 0, len(items))
+	for _, item := range items {
+		if item.Store != storeURL {
+			nav.showError(fmt.Errorf("%s%s: %w", item.Store, item.Path, errItemFromAnotherStore))
+			return nil
+		}
+		m := ftjournal.Move{From: item.Path, To: path.Join(dirPath, item.name())}
+		if isMove {
+			if m.From == m.To {
+				continue
+			}
+			dirs = append(dirs, path.Dir(item.Path))
+		}
+		moves = append(moves, m)
+	}
+	operationType, action, verb := copyOperation, ftjournal.ActionCopy, "Copy"
+	var transfer func(ctx context.Context, from, to string) error
+	if isMove {
+		operationType, action, verb = moveOperation, ftjournal.ActionMove, "Move"
+		renamer, ok := store.(files.Renamer)
+		if !ok {
+			nav.showError(errMoveNotSupported)
+			return nil
+		}
+		transfer = renamer.Rename
+	} else {
+		copier, ok := store.(files.Copier)
+		if !ok {
+			nav.showError(errCopyNotSupported)
+			return nil
+		}
+		transfer = copier.Copy
+	}
+	title := fmt.Sprintf("%s %d item(s) to %s", verb, len(moves), dirPath)
+	return nav.operations.Start(operationType, title, dirs,
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			done, err := transferEntries(ctx, moves, transfer, reportProgress)
+			if len(done) > 0 {
+				nav.recordHistory(store, ftjournal.Record{Action: action, Moves: done})
+			}
+			return err
+		},
+	)
+}
+
+// transferEntries copies or moves entries one by one reporting progress after each of them.
+// It stops at the first error and returns the moves that succeeded.
+func transferEntries(
+	ctx context.Context,
+	moves []ftjournal.Move,
+	transfer func(ctx context.Context, from, to string) error,
+	reportProgress ProgressReporter,
+) (done []ftjournal.Move, err error) {
+	progress := OperationProgress{Total: len(moves)}
+	for _, m := range moves {
+		if err = ctx.Err(); err != nil {
+			return done, err
+		}
+		progress.Processing = []string{m.From}
+		reportProgress(progress)
+		if err = transfer(ctx, m.From, m.To); err != nil {
+			progress.Failed++
+			progress.Processing = nil
+			progress.Errors = append(progress.Errors, OperationError{Path: m.From, Err: err})
+			reportProgress(progress)
+			return done, err
+		}
+		done = append(done, m)
+		progress.Done++
+	}
+	progress.Processing = nil
+	reportProgress(progress)
+	return done, nil
+}
+
+// deleteItems deletes items of the basket or a list from their stores, one operation per store.
+func (nav *Navigator) deleteItems(items []BasketItem) {
+	var storeURLs []string
+	pathsByStore := make(map[string][]string)
+	for _, item := range items {
+		if _, ok := pathsByStore[item.Store]; !ok {
+			storeURLs = append(storeURLs, item.Store)
+		}
+		pathsByStore[item.Store] = append(pathsByStore[item.Store], item.Path)
+	}
+	for _, storeURL := range storeURLs {
+		store, err := nav.storeFor(storeURL)
+		if err != nil {
+			nav.showError(err)
+			continue
+		}
+		paths := pathsByStore[storeURL]
+		var dirs []string
+		for _, p := range paths {
+			dirs = append(dirs, path.Dir(p))
+		}
+		title := fmt.Sprintf("Delete %d item(s)", len(paths))
+		nav.operations.Start(deleteOperation, title, dirs,
+			func(ctx context.Context, reportProgress ProgressReporter) error {
+				deleted, err := deleteEntries(ctx, store, paths, reportProgress)
+				if deleted > 0 {
+					nav.recordHistory(store, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: paths[:deleted]})
+				}
+				return err
+			},
+		)
+	}
+}
@@ -0,0 +1,236 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// withBasketFile redirects the basket to a temp file. The caller should hold testGlobalLock.
+func withBasketFile(t *testing.T) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), basketFileName)
+	orig := getBasketFilePath
+	getBasketFilePath = func() (string, error) {
+		return filePath, nil
+	}
+	t.Cleanup(func() {
+		getBasketFilePath = orig
+	})
+	return filePath
+}
+
+func basketKey(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func TestNavigator_Basket(t *testing.T) {
+	withRenameJournal(t)
+	withHistoryJournal(t)
+	basketFilePath := withBasketFile(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.txt")
+	target := filepath.Join(dir, "target")
+	assert.NoError(t, os.Mkdir(target, 0o755))
+
+	nav.toggleBasket()
+	nav.files.table.Select(2, 0)
+	nav.files.inputCapture(tcell.NewEventKey(tcell.KeyCtrlB, 0, tcell.ModCtrl))
+	assert.Equal(t, []BasketItem{
+		{Store: "file:", Path: filepath.Join(dir, "a.txt")},
+		{Store: "file:", Path: filepath.Join(dir, "b.txt")},
+	}, nav.basket.Items())
+
+	loaded, err := loadBasket(basketFilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, nav.basket.Items(), loaded.Items())
+
+	nav.inputCapture(tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModAlt))
+	assert.True(t, nav.isBasketShown())
+	assert.Equal(t, 2, nav.files.table.GetRowCount())
+	assert.Contains(t, nav.files.table.GetCell(0, nameColIndex).Text, filepath.Join(dir, "a.txt"))
+	assert.Len(t, nav.selectedEntries(), 2)
+
+	t.Run("copy_here", func(t *testing.T) {
+		nav.current.SetDir(files.NewDirContext(nav.store, target, nil))
+		assert.Nil(t, nav.files.inputCapture(basketKey('c')))
+		operations := nav.operations.Operations()
+		waitOperation(t, operations[len(operations)-1])
+		drainQueuedUpdates(updates)
+		assertFileContent(t, filepath.Join(target, "a.txt"), "a.txt")
+		assertFileContent(t, filepath.Join(dir, "a.txt"), "a.txt")
+	})
+
+	t.Run("move_here", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filepath.Join(target, "a.txt")))
+		assert.NoError(t, os.Remove(filepath.Join(target, "b.txt")))
+		assert.Nil(t, nav.files.inputCapture(basketKey('m')))
+		operations := nav.operations.Operations()
+		waitOperation(t, operations[len(operations)-1])
+		drainQueuedUpdates(updates)
+		assertFileContent(t, filepath.Join(target, "b.txt"), "b.txt")
+		assert.Equal(t, []BasketItem{
+			{Store: "file:", Path: filepath.Join(target, "a.txt")},
+			{Store: "file:", Path: filepath.Join(target, "b.txt")},
+		}, nav.basket.Items(), "basket should follow moved entries")
+
+		waitOperation(t, nav.undo())
+		assertFileContent(t, filepath.Join(dir, "b.txt"), "b.txt")
+		assert.Equal(t, filepath.Join(dir, "a.txt"), nav.basket.Items()[0].Path, "basket should follow undone move")
+	})
+
+	t.Run("remove_and_enter", func(t *testing.T) {
+		nav.renderBasketIfVisible()
+		nav.files.table.Select(1, 0)
+		assert.Nil(t, nav.files.inputCapture(basketKey('x')))
+		assert.Equal(t, 1, nav.basket.Len())
+		assert.Equal(t, "z", string(nav.files.inputCapture(basketKey('z')).Rune()))
+
+		nav.files.table.Select(0, 0)
+		assert.Nil(t, nav.files.inputCapture(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)))
+		assert.False(t, nav.isBasketShown())
+		assert.Equal(t, dir, nav.currentDirPath())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		nav.basket.AddToBasket(BasketItem{Store: "file:", Path: filepath.Join(dir, "b.txt")})
+		nav.showBasket()
+		nav.files.table.Select(0, 0)
+		nav.delete()
+		waitOperation(t, lastOperation(nav))
+		drainQueuedUpdates(updates)
+		_, err := os.Stat(filepath.Join(dir, "a.txt"))
+		assert.True(t, os.IsNotExist(err))
+		assertFileContent(t, filepath.Join(dir, "b.txt"), "b.txt")
+		assert.Equal(t, []BasketItem{{Store: "file:", Path: filepath.Join(dir, "b.txt")}}, nav.basket.Items(),
+			"only the current item is deleted & removed from basket")
+	})
+
+	t.Run("delete_all", func(t *testing.T) {
+		nav.showBasket() // the dir opened by Enter may have been loaded since & replaced it
+		operations := len(nav.operations.Operations())
+		assert.Nil(t, nav.files.inputCapture(basketKey('D')))
+		assert.Equal(t, "Press D again to delete all 1 shown item(s)", lastNotification(nav).message)
+		assert.Equal(t, "z", string(nav.files.inputCapture(basketKey('z')).Rune()), "other keys cancel the delete")
+		assert.Nil(t, nav.files.inputCapture(basketKey('D')))
+		assert.Len(t, nav.operations.Operations(), operations)
+		assert.Nil(t, nav.files.inputCapture(basketKey('D')))
+		waitOperation(t, lastOperation(nav))
+		drainQueuedUpdates(updates)
+		_, err := os.Stat(filepath.Join(dir, "b.txt"))
+		assert.True(t, os.IsNotExist(err))
+		assert.Equal(t, 0, nav.basket.Len(), "deleted entries should be removed from basket")
+	})
+
+	t.Run("clear_and_escape", func(t *testing.T) {
+		nav.basket.AddToBasket(BasketItem{Store: "file:", Path: "/x"})
+		nav.showBasket()
+		assert.Nil(t, nav.files.inputCapture(basketKey('X')))
+		assert.Equal(t, 0, nav.basket.Len())
+		assert.Nil(t, nav.files.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.False(t, nav.isBasketShown())
+		drainQueuedUpdates(updates)
+	})
+}
+
+func TestNavigator_TransferBasketTo_Errors(t *testing.T) {
+	withTestGlobalLock(t)
+	withBasketFile(t)
+	nav, _ := newNavigatorWithQueuedUpdates(t)
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+	assert.Nil(t, nav.transferBasketTo("/dst", false))
+
+	nav.basket.AddToBasket(BasketItem{Store: "ftp://example.com", Path: "/a"})
+	assert.Nil(t, nav.transferBasketTo("/dst", false))
+	assert.ErrorIs(t, shownErr, errItemFromAnotherStore)
+
+	nav.basket.Clear()
+	nav.basket.AddToBasket(BasketItem{Store: "file:", Path: "/a"})
+	store := newMockStore(t)
+	store.EXPECT().RootURL().Return(url.URL{Scheme: "file"}).AnyTimes()
+	nav.store = store
+	assert.Nil(t, nav.transferBasketTo("/dst", false))
+	assert.ErrorIs(t, shownErr, errCopyNotSupported)
+	assert.Nil(t, nav.transferBasketTo("/dst", true))
+	assert.ErrorIs(t, shownErr, errMoveNotSupported)
+}
+
+func TestNavigator_StoreFor(t *testing.T) {
+	t.Parallel()
+	nav, _ := newNavigatorWithQueuedUpdates(t)
+	store, err := nav.storeFor("file:")
+	assert.NoError(t, err)
+	assert.Same(t, nav.store, store)
+
+	store, err = nav.storeFor("ftp://example.com")
+	assert.NoError(t, err)
+	rootURL := store.RootURL()
+	assert.Equal(t, "ftp", rootURL.Scheme)
+
+	_, err = nav.storeFor("unknown://x")
+	assert.ErrorIs(t, err, files.ErrNotSupported)
+
+	_, err = nav.storeFor("%zz")
+	assert.Error(t, err)
+}
+
+func TestNavigator_LoadBasket_Error(t *testing.T) {
+	withTestGlobalLock(t)
+	orig := getBasketFilePath
+	getBasketFilePath = func() (string, error) {
+		return "", errors.New("no home")
+	}
+	t.Cleanup(func() {
+		getBasketFilePath = orig
+	})
+	nav, _ := newNavigatorWithQueuedUpdates(t)
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+	nav.loadBasket()
+	assert.EqualError(t, shownErr, "failed to load basket: no home")
+	assert.NotNil(t, nav.basket)
+	nav.saveBasket()
+	assert.EqualError(t, shownErr, "failed to save basket: no home")
+}
+
+func TestBasketEntry(t *testing.T) {
+	t.Parallel()
+	e := newBasketEntry(BasketItem{Store: "ftp://example.com", Path: "/dir/a.txt"}, "file:")
+	assert.Equal(t, "/dir", e.DirPath())
+	assert.Equal(t, "/dir/a.txt", e.FullName())
+	assert.Equal(t, "ftp://example.com/dir/a.txt", e.String())
+	assert.Equal(t, "a.txt", e.Name())
+	assert.False(t, e.IsDir())
+
+	e = newBasketEntry(BasketItem{Store: "file:", Path: "/missing/dir", IsDir: true}, "file:")
+	assert.Equal(t, "/missing/dir", e.String())
+	assert.True(t, e.IsDir())
+}
+
+func TestTransferEntries_Error(t *testing.T) {
+	t.Parallel()
+	moves := []ftjournal.Move{{From: "/a", To: "/b"}, {From: "/c", To: "/d"}}
+	var calls int
+	done, err := transferEntries(t.Context(), moves, func(_ context.Context, from, _ string) error {
+		calls++
+		if from == "/c" {
+			return errors.New("fail")
+		}
+		return nil
+	}, func(OperationProgress) {})
+	assert.EqualError(t, err, "fail")
+	assert.Equal(t, moves[:1], done)
+	assert.Equal(t, 2, calls)
+}
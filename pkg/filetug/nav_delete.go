@@ -2,32 +2,110 @@ package filetug
 
 import (
 	"context"
+	"fmt"
+	"path"
 
 	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/gdamore/tcell/v2"
 )
 
+// delete deletes the selection or the current entry of the active browser.
+// In the basket, a list or tagged entries only the current item is deleted, see deleteShownInputCapture for all of them.
 func (nav *Navigator) delete() {
+	if _, ok := nav.shownItems(); ok {
+		if entry, ok := nav.files.GetCurrentEntry().(basketEntry); ok {
+			nav.deleteItems([]BasketItem{entry.item})
+		}
+		return
+	}
+	if selected := nav.filesSelection(); len(selected) > 0 {
+		nav.deleteSelection(selected)
+		return
+	}
 	b := nav.getCurrentBrowser()
 	currentItem := b.GetCurrentEntry()
 	if currentItem == nil {
 		return
 	}
-	NewOperation(deleteOperation, func(ctx context.Context, reportProgress ProgressReporter) error {
-		currentItemPath := currentItem.FullName()
-		if err := deleteEntries(ctx, nav.store, []string{currentItemPath}, reportProgress); err != nil {
+	currentItemPath := currentItem.FullName()
+	dirPath, name := path.Split(currentItemPath)
+	dirPath = path.Clean(dirPath)
+	store := nav.store
+	nav.operations.Start(deleteOperation, "Delete "+name, []string{dirPath},
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			if _, err := deleteEntries(ctx, store, []string{currentItemPath}, reportProgress); err != nil {
+				return err
+			}
+			nav.recordHistory(store, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: []string{currentItemPath}})
+			return nil
+		},
+	)
+}
+
+// deleteSelection deletes entries selected in the files panel.
+// Deleted entries drop out of the selection once the dir is refreshed.
+func (nav *Navigator) deleteSelection(selected []files.EntryWithDirPath) *Operation {
+	paths := make([]string, len(selected))
+	for i, entry := range selected {
+		paths[i] = entry.FullName()
+	}
+	store := nav.store
+	title := fmt.Sprintf("Delete %d item(s)", len(paths))
+	return nav.operations.Start(deleteOperation, title, []string{path.Dir(paths[0])},
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			deleted, err := deleteEntries(ctx, store, paths, reportProgress)
+			if deleted > 0 {
+				nav.recordHistory(store, ftjournal.Record{Action: ftjournal.ActionDelete, Paths: paths[:deleted]})
+			}
 			return err
-		}
+		},
+	)
+}
+
+// deleteShownInputCapture deletes all shown items of the basket or a list when D is pressed twice in a row,
+// the first press only warns how many items are going to be deleted.
+func (nav *Navigator) deleteShownInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Rune() != 'D' {
+		nav.deleteShownPending = false
+		return event
+	}
+	items, _ := nav.shownItems()
+	if len(items) == 0 {
 		return nil
-	}, nil)
+	}
+	if !nav.deleteShownPending {
+		nav.deleteShownPending = true
+		nav.notify(notificationWarning, "Delete", fmt.Sprintf("Press D again to delete all %d shown item(s)", len(items)))
+		return nil
+	}
+	nav.deleteShownPending = false
+	nav.deleteItems(items)
+	return nil
 }
 
 const deleteOperation OperationType = "deleteEntries"
 
-func deleteEntries(ctx context.Context, store files.Store, entries []string, _ ProgressReporter) error {
+// deleteEntries deletes entries one by one reporting progress after each of them.
+// It stops at the first error and returns the number of deleted entries.
+func deleteEntries(ctx context.Context, store files.Store, entries []string, reportProgress ProgressReporter) (int, error) {
+	progress := OperationProgress{Total: len(entries)}
 	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return progress.Done, err
+		}
+		progress.Processing = []string{entry}
+		reportProgress(progress)
 		if err := store.Delete(ctx, entry); err != nil {
-			return err
+			progress.Failed++
+			progress.Processing = nil
+			progress.Errors = append(progress.Errors, OperationError{Path: entry, Err: err})
+			reportProgress(progress)
+			return progress.Done, err
 		}
+		progress.Done++
 	}
-	return nil
+	progress.Processing = nil
+	reportProgress(progress)
+	return progress.Done, nil
 }
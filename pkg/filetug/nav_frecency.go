@@ -0,0 +1,105 @@
+package filetug
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/filetug/filetug/pkg/filetug/ftfrecency"
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+	"github.com/filetug/filetug/pkg/fsutils"
+)
+
+const frecencyFileName = "frecency.yaml"
+
+var getFrecencyFilePath = func() (string, error) {
+	dir, err := ftsettings.GetDatatugUserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, frecencyFileName), nil
+}
+
+// loadFrecency loads visited dirs & prunes local ones that no longer exist.
+// Dirs of remote stores are pruned when a jump to them fails.
+func (nav *Navigator) loadFrecency() {
+	filePath, err := getFrecencyFilePath()
+	if err == nil {
+		nav.frecency, err = ftfrecency.Load(filePath)
+	}
+	if nav.frecency == nil {
+		nav.frecency = ftfrecency.NewDB()
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to load visited dirs: %w", err))
+		return
+	}
+	if nav.frecency.Prune(localDirExists) > 0 {
+		nav.saveFrecency()
+	}
+}
+
+// localDirExists reports false only for dirs of the local store that are gone.
+func localDirExists(e ftfrecency.Entry) bool {
+	if root, err := url.Parse(e.Store); err != nil || root.Scheme != "file" {
+		return true
+	}
+	info, err := os.Stat(e.Path)
+	return err == nil && info.IsDir() || err != nil && !os.IsNotExist(err)
+}
+
+func (nav *Navigator) saveFrecency() {
+	filePath, err := getFrecencyFilePath()
+	if err == nil {
+		err = nav.frecency.Save(filePath)
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to save visited dirs: %w", err))
+	}
+}
+
+// recordVisit counts a visit of a dir, it is called by goDir so previews of dirs are not counted.
+func (nav *Navigator) recordVisit(store, dirPath string) {
+	if nav.frecency == nil {
+		return
+	}
+	nav.frecency.Visit(store, fsutils.ExpandHome(dirPath), timeNow())
+	nav.saveFrecency()
+}
+
+// recentDirs returns the most frecent dirs.
+func (nav *Navigator) recentDirs() []ftfrecency.Entry {
+	if nav.frecency == nil {
+		return nil
+	}
+	return nav.frecency.Top(timeNow(), maxRecentDirs)
+}
+
+// forgetVisited removes a dir from visited ones.
+func (nav *Navigator) forgetVisited(e ftfrecency.Entry) {
+	if nav.frecency.Remove(e.Store, e.Path) {
+		nav.saveFrecency()
+	}
+}
+
+// openVisited goes to a visited dir switching a store if needed.
+// A local dir that no longer exists is forgotten instead.
+func (nav *Navigator) openVisited(e ftfrecency.Entry) bool {
+	if !localDirExists(e) {
+		nav.forgetVisited(e)
+		nav.notify(notificationWarning, "Go to", fmt.Sprintf("%s no longer exists", e.Path))
+		return false
+	}
+	store, err := nav.storeFor(e.Store)
+	if err != nil {
+		nav.showError(err)
+		return false
+	}
+	nav.basketRows, nav.listRows, nav.tagRows = nil, nil, nil
+	if store != nav.store {
+		nav.SetStore(store)
+	}
+	nav.goDirByPath(e.Path)
+	return true
+}
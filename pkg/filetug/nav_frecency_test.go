@@ -0,0 +1,119 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/filetug/ftfrecency"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withFrecencyFile redirects visited dirs to a temp file.
+func withFrecencyFile(t *testing.T) string {
+	t.Helper()
+	withTestGlobalLock(t)
+	filePath := filepath.Join(t.TempDir(), frecencyFileName)
+	orig := getFrecencyFilePath
+	getFrecencyFilePath = func() (string, error) {
+		return filePath, nil
+	}
+	t.Cleanup(func() {
+		getFrecencyFilePath = orig
+	})
+	return filePath
+}
+
+func TestNavigator_Frecency(t *testing.T) {
+	frecencyFilePath := withFrecencyFile(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt")
+	projects, photos := filepath.Join(dir, "projects"), filepath.Join(dir, "photos")
+	require.NoError(t, os.Mkdir(projects, 0o755))
+	require.NoError(t, os.Mkdir(photos, 0o755))
+	key := func(k tcell.Key) *tcell.EventKey {
+		return tcell.NewEventKey(k, 0, tcell.ModNone)
+	}
+
+	t.Run("visits", func(t *testing.T) {
+		nav.goDirByPath(projects)
+		nav.goDirByPath(photos)
+		nav.goDirByPath(projects)
+		drainQueuedUpdates(updates)
+		db, err := ftfrecency.Load(frecencyFilePath)
+		require.NoError(t, err)
+		top := db.Top(time.Now(), 2)
+		require.Len(t, top, 2)
+		assert.Equal(t, projects, top[0].Path)
+		assert.Equal(t, 2, top[0].Count)
+		assert.Equal(t, "file:", top[0].Store)
+	})
+
+	t.Run("jump", func(t *testing.T) {
+		assert.Nil(t, nav.inputCapture(tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModAlt)))
+		p, ok := nav.right.content.(*jumpPanel)
+		require.True(t, ok)
+		assert.Equal(t, 2, p.list.GetItemCount())
+		p.input.SetText("pho")
+		require.Len(t, p.matches, 1)
+		assert.Equal(t, photos, p.matches[0].Path)
+		assert.Nil(t, p.inputCapture(key(tcell.KeyEnter)))
+		assert.Equal(t, photos, nav.currentDirPath())
+		assert.Equal(t, nav.previewer, nav.right.content)
+
+		nav.showJumpPanel()
+		p = nav.right.content.(*jumpPanel)
+		assert.Nil(t, p.inputCapture(key(tcell.KeyDown)))
+		assert.Equal(t, 1, p.list.GetCurrentItem())
+		assert.Nil(t, p.inputCapture(key(tcell.KeyCtrlX)))
+		assert.Len(t, nav.frecency.Entries(), 1, "Ctrl+X forgets a dir")
+		assert.Nil(t, p.inputCapture(key(tcell.KeyEscape)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+		drainQueuedUpdates(updates)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		nav.goDirByPath(projects)
+		drainQueuedUpdates(updates)
+		require.NoError(t, os.Remove(projects))
+		nav.showJumpPanel()
+		p := nav.right.content.(*jumpPanel)
+		p.input.SetText("proj")
+		require.Len(t, p.matches, 1)
+		assert.Nil(t, p.inputCapture(key(tcell.KeyEnter)))
+		assert.Equal(t, projects+" no longer exists", lastNotification(nav).message)
+		assert.Empty(t, p.matches)
+		assert.Equal(t, p, nav.right.content)
+
+		nav.frecency.Visit("file:", filepath.Join(dir, "gone"), time.Now())
+		nav.frecency.Visit("ftp://example.com", "/pub", time.Now())
+		nav.saveFrecency()
+		nav.loadFrecency()
+		var paths []string
+		for _, e := range nav.frecency.Entries() {
+			paths = append(paths, e.Path)
+		}
+		assert.Equal(t, []string{photos, "/pub"}, paths, "missing local dirs are pruned on load")
+	})
+
+	t.Run("favorites", func(t *testing.T) {
+		nav.ShowFavorites()
+		f := nav.favorites
+		require.Len(t, f.recentEntries, 2)
+		assert.Equal(t, photos, f.recentEntries[0].Path)
+		main, _ := f.recent.GetItemText(1)
+		assert.Equal(t, "[gray]ftp://example.com[-] /pub", main)
+
+		f.recent.SetCurrentItem(1)
+		assert.Nil(t, f.recentInputCapture(key(tcell.KeyDelete)))
+		assert.Len(t, f.recentEntries, 1)
+
+		nav.goDirByPath(dir)
+		assert.Nil(t, f.recentInputCapture(key(tcell.KeyEnter)))
+		assert.Equal(t, photos, nav.currentDirPath())
+		assert.Equal(t, nav.dirsTree, nav.left.content)
+		drainQueuedUpdates(updates)
+	})
+}
@@ -0,0 +1,283 @@
+package filetug
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftlists"
+	"github.com/filetug/filetug/pkg/filetug/ftquery"
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+	"github.com/gdamore/tcell/v2"
+)
+
+const listsFileName = "lists.yaml"
+
+var getListsFilePath = func() (string, error) {
+	dir, err := ftsettings.GetDatatugUserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, listsFileName), nil
+}
+
+func (nav *Navigator) loadLists() {
+	filePath, err := getListsFilePath()
+	if err == nil {
+		var lists []ftlists.List
+		lists, err = ftlists.Load(filePath)
+		nav.listsMu.Lock()
+		nav.lists = lists
+		nav.listsMu.Unlock()
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to load lists: %w", err))
+	}
+}
+
+// userLists returns a copy of the lists that is safe to be changed.
+func (nav *Navigator) userLists() []ftlists.List {
+	nav.listsMu.Lock()
+	defer nav.listsMu.Unlock()
+	lists := slices.Clone(nav.lists)
+	for i := range lists {
+		lists[i].Items = slices.Clone(lists[i].Items)
+	}
+	return lists
+}
+
+// findList returns a copy of a list by name.
+func (nav *Navigator) findList(name string) (ftlists.List, bool) {
+	lists := nav.userLists()
+	if i := ftlists.IndexOf(lists, name); i >= 0 {
+		return lists[i], true
+	}
+	return ftlists.List{}, false
+}
+
+// updateLists applies a change to a copy of the lists & persists the result.
+// The lists are not changed if the update or saving fails.
+func (nav *Navigator) updateLists(update func(lists []ftlists.List) ([]ftlists.List, error)) error {
+	lists, err := update(nav.userLists())
+	if err != nil {
+		return err
+	}
+	nav.listsMu.Lock()
+	defer nav.listsMu.Unlock()
+	return nav.setLists(lists)
+}
+
+// setLists persists & keeps lists, the caller holds listsMu.
+func (nav *Navigator) setLists(lists []ftlists.List) error {
+	filePath, err := getListsFilePath()
+	if err == nil {
+		err = ftlists.Save(filePath, lists)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save lists: %w", err)
+	}
+	nav.lists = lists
+	return nil
+}
+
+// syncLists updates list items affected by a mutating action. It is safe to be called from operation goroutines.
+func (nav *Navigator) syncLists(r ftjournal.Record) {
+	nav.listsMu.Lock()
+	defer nav.listsMu.Unlock()
+	lists := slices.Clone(nav.lists)
+	for i := range lists {
+		lists[i].Items = slices.Clone(lists[i].Items)
+	}
+	if !ftlists.ApplyRecord(lists, r) {
+		return
+	}
+	if err := nav.setLists(lists); err != nil {
+		nav.showError(err)
+	}
+}
+
+// addToList adds items to a list & returns how many of them were not there yet.
+func (nav *Navigator) addToList(name string, items []BasketItem) (added int, err error) {
+	err = nav.updateLists(func(lists []ftlists.List) ([]ftlists.List, error) {
+		i := ftlists.IndexOf(lists, name)
+		if i < 0 {
+			return nil, fmt.Errorf("list not found: %s", name)
+		}
+		for _, item := range items {
+			added += lists[i].Add(ftlists.Item(item))
+		}
+		return lists, nil
+	})
+	return added, err
+}
+
+// removeFromList removes an item from a list.
+func (nav *Navigator) removeFromList(name string, item BasketItem) error {
+	return nav.updateLists(func(lists []ftlists.List) ([]ftlists.List, error) {
+		if i := ftlists.IndexOf(lists, name); i >= 0 {
+			lists[i].Remove(ftlists.Item(item))
+		}
+		return lists, nil
+	})
+}
+
+// listItems returns items of a list in the form used by actions over the basket.
+func listItems(l ftlists.List) []BasketItem {
+	items := make([]BasketItem, len(l.Items))
+	for i, item := range l.Items {
+		items[i] = BasketItem(item)
+	}
+	return items
+}
+
+// showList displays items of a list as a virtual listing in the files panel.
+func (nav *Navigator) showList(name string) {
+	l, ok := nav.findList(name)
+	if !ok {
+		return
+	}
+	nav.shownList = name
+	nav.basketRows = nil
+	nav.listRows = nav.newListRows(l)
+	nav.files.SetRows(nav.listRows, true)
+}
+
+func (nav *Navigator) isListShown() bool {
+	return nav.listRows != nil && nav.files.rows == nav.listRows
+}
+
+// closeList returns the files panel to the current directory.
+func (nav *Navigator) closeList() {
+	nav.listRows = nil
+	nav.shownList = ""
+	nav.refreshCurrentDir()
+}
+
+func (nav *Navigator) renderListIfVisible() {
+	if !nav.isListShown() {
+		return
+	}
+	l, ok := nav.findList(nav.shownList)
+	if !ok {
+		nav.closeList()
+		return
+	}
+	row, _ := nav.files.table.GetSelection()
+	nav.listRows = nav.newListRows(l)
+	nav.files.SetRows(nav.listRows, true)
+	if count := nav.listRows.GetRowCount(); row >= count {
+		row = count - 1
+	}
+	if row > 0 {
+		nav.files.table.Select(row, 0)
+	}
+}
+
+// shownItems returns all items of the basket, a list or tagged entries if one of them is shown in the files panel.
+// Items of a list hidden by its filter are not returned.
+func (nav *Navigator) shownItems() ([]BasketItem, bool) {
+	var rows *FileRows
+	switch {
+	case nav.isBasketShown():
+		return nav.basket.Items(), true
+	case nav.isListShown():
+		rows = nav.listRows
+	case nav.isTaggedShown():
+		rows = nav.tagRows
+	default:
+		return nil, false
+	}
+	items := make([]BasketItem, 0, len(rows.AllEntries))
+	for _, entry := range rows.AllEntries {
+		if e, ok := entry.(basketEntry); ok {
+			items = append(items, e.item)
+		}
+	}
+	return items, true
+}
+
+// newListRows presents items of a list as entries, the filter saved with the list hides items not matching it.
+func (nav *Navigator) newListRows(l ftlists.List) *FileRows {
+	rootURL := nav.store.RootURL()
+	currentStore := rootURL.String()
+	q, err := ftquery.Parse(l.Filter)
+	if err != nil {
+		nav.notifyError("Lists", fmt.Errorf("filter of %s: %w", l.Name, err))
+		q = nil
+	}
+	rows := NewFileRows(nav.current.Dir())
+	rows.virtual = true
+	rows.onKey = nav.listInputCapture
+	rows.AllEntries = make([]files.EntryWithDirPath, 0, len(l.Items))
+	for _, item := range listItems(l) {
+		entry := newBasketEntry(item, currentStore)
+		if !q.IsEmpty() && (!item.IsDir || q.MatchesDirs()) {
+			dir := path.Dir(item.Path)
+			needsGitState := q.NeedsGitState() && strings.HasPrefix(item.Store, "file:")
+			if !q.Match(newMaskEntries(dir, needsGitState).entry(dir, entry), nil) {
+				continue
+			}
+		}
+		rows.AllEntries = append(rows.AllEntries, entry)
+	}
+	rows.VisibleEntries = rows.AllEntries
+	rows.Infos = make([]os.FileInfo, len(rows.AllEntries))
+	rows.VisualInfos = make([]os.FileInfo, len(rows.AllEntries))
+	return rows
+}
+
+func (nav *Navigator) listInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if event = nav.deleteShownInputCapture(event); event == nil {
+		return nil
+	}
+	switch event.Key() {
+	case tcell.KeyEscape:
+		nav.closeList()
+		return nil
+	case tcell.KeyEnter:
+		if entry, ok := nav.files.GetCurrentEntry().(basketEntry); ok {
+			nav.openBasketItem(entry.item)
+		}
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'c', 'm':
+			if items, ok := nav.shownItems(); ok {
+				nav.transferItemsTo(items, nav.currentDirPath(), event.Rune() == 'm')
+			}
+			return nil
+		case 'x':
+			if entry, ok := nav.files.GetCurrentEntry().(basketEntry); ok {
+				if err := nav.removeFromList(nav.shownList, entry.item); err != nil {
+					nav.showError(err)
+				}
+				nav.renderListIfVisible()
+			}
+			return nil
+		}
+	}
+	return event
+}
+
+// addToListItems returns entries to be added to a list: the selection of the files panel if there is one,
+// otherwise the current entry of the active browser.
+func (nav *Navigator) addToListItems() []BasketItem {
+	var entries []files.EntryWithDirPath
+	if selected := nav.filesSelection(); len(selected) > 0 {
+		entries = selected
+	} else if b := nav.getCurrentBrowser(); b != nil {
+		if entry := b.GetCurrentEntry(); entry != nil && entry.FullName() != "/" {
+			entries = append(entries, entry)
+		}
+	}
+	items := make([]BasketItem, len(entries))
+	for i, entry := range entries {
+		items[i] = basketItemOf(nav.store, entry)
+	}
+	return items
+}
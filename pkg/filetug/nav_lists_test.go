@@ -0,0 +1,115 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftlists"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withListsFile redirects lists to a temp file.
+func withListsFile(t *testing.T) string {
+	t.Helper()
+	withTestGlobalLock(t)
+	filePath := filepath.Join(t.TempDir(), listsFileName)
+	orig := getListsFilePath
+	getListsFilePath = func() (string, error) {
+		return filePath, nil
+	}
+	t.Cleanup(func() {
+		getListsFilePath = orig
+	})
+	return filePath
+}
+
+func TestNavigator_Lists(t *testing.T) {
+	withHistoryJournal(t)
+	listsFilePath := withListsFile(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.md")
+	target := filepath.Join(dir, "target")
+	require.NoError(t, os.Mkdir(target, 0o755))
+	key := func(k tcell.Key) *tcell.EventKey {
+		return tcell.NewEventKey(k, 0, tcell.ModNone)
+	}
+
+	assert.Nil(t, nav.files.inputCapture(keyRune('+')))
+	assert.Nil(t, nav.inputCapture(tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModAlt)))
+	p := nav.listsPanel
+	require.NotNil(t, p)
+	assert.Equal(t, p, nav.left.content)
+	assert.Len(t, p.pending, 2, "the selection is captured to be added")
+	assert.Equal(t, "n new · esc back", p.footer.GetText(true))
+
+	t.Run("create", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(keyRune('n')))
+		assert.True(t, p.formVisible)
+		p.nameInput.SetText(" ")
+		assert.Nil(t, p.formInputCapture(key(tcell.KeyEnter)))
+		assert.Contains(t, p.formStatus.GetText(true), ftlists.ErrEmptyName.Error())
+		p.nameInput.SetText("Docs")
+		assert.Nil(t, p.formInputCapture(key(tcell.KeyEnter)))
+		assert.False(t, p.formVisible)
+		assert.True(t, nav.isListShown())
+		assert.Equal(t, "Docs", nav.shownList)
+		assert.Contains(t, p.footer.GetText(true), "a add 2 item(s)")
+	})
+
+	t.Run("add", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(keyRune('a')))
+		assert.Equal(t, "added 2 item(s) to Docs", lastNotification(nav).message)
+		assert.Equal(t, 2, nav.files.table.GetRowCount())
+		lists, err := ftlists.Load(listsFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, []ftlists.List{{Name: "Docs", Items: []ftlists.Item{
+			{Store: "file:", Path: filepath.Join(dir, "a.txt")},
+			{Store: "file:", Path: filepath.Join(dir, "b.md")},
+		}}}, lists)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(keyRune('e')))
+		p.filterInput.SetText("size>")
+		assert.Nil(t, p.formInputCapture(key(tcell.KeyEnter)))
+		assert.True(t, p.formVisible, "an invalid filter is not saved")
+		assert.Contains(t, p.formStatus.GetText(true), "col ")
+		p.filterInput.SetText("ext:md")
+		assert.Nil(t, p.formInputCapture(key(tcell.KeyEnter)))
+		assert.Equal(t, 1, nav.files.table.GetRowCount())
+		assert.Contains(t, nav.files.table.GetCell(0, nameColIndex).Text, "b.md")
+	})
+
+	t.Run("move_here", func(t *testing.T) {
+		nav.current.SetDir(files.NewDirContext(nav.store, target, nil))
+		assert.Nil(t, nav.files.inputCapture(keyRune('m')))
+		operations := nav.operations.Operations()
+		waitOperation(t, operations[len(operations)-1])
+		drainQueuedUpdates(updates)
+		assertFileContent(t, filepath.Join(target, "b.md"), "b.md")
+		assertFileContent(t, filepath.Join(dir, "a.txt"), "a.txt")
+		l, _ := nav.findList("Docs")
+		assert.Equal(t, filepath.Join(target, "b.md"), l.Items[1].Path, "lists follow moved entries")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		nav.renderListIfVisible()
+		nav.files.table.Select(0, 0)
+		assert.Nil(t, nav.files.inputCapture(keyRune('x')))
+		l, _ := nav.findList("Docs")
+		assert.Equal(t, []ftlists.Item{{Store: "file:", Path: filepath.Join(dir, "a.txt")}}, l.Items)
+		assert.Equal(t, 0, nav.files.table.GetRowCount())
+	})
+
+	t.Run("delete_and_escape", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(keyRune('d')))
+		assert.Empty(t, nav.userLists())
+		assert.False(t, nav.isListShown())
+		assert.Nil(t, p.inputCapture(key(tcell.KeyEscape)))
+		assert.Equal(t, nav.dirsTree, nav.left.content)
+		drainQueuedUpdates(updates)
+	})
+}
@@ -0,0 +1,144 @@
+package filetug
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftmarks"
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+	"github.com/gdamore/tcell/v2"
+)
+
+const marksFileName = "marks.yaml"
+
+var getMarksFilePath = func() (string, error) {
+	dir, err := ftsettings.GetDatatugUserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, marksFileName), nil
+}
+
+// loadMarks loads global marks, session marks start empty.
+func (nav *Navigator) loadMarks() {
+	filePath, err := getMarksFilePath()
+	if err == nil {
+		var marks []ftmarks.Mark
+		marks, err = ftmarks.Load(filePath)
+		nav.marksMu.Lock()
+		nav.marks = marks
+		nav.marksMu.Unlock()
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to load marks: %w", err))
+	}
+}
+
+// userMarks returns a copy of the marks that is safe to be changed.
+func (nav *Navigator) userMarks() []ftmarks.Mark {
+	nav.marksMu.Lock()
+	defer nav.marksMu.Unlock()
+	return slices.Clone(nav.marks)
+}
+
+// updateMarks applies a change to a copy of the marks & persists global ones if any of them has changed.
+func (nav *Navigator) updateMarks(update func(marks []ftmarks.Mark) []ftmarks.Mark) error {
+	marks := update(nav.userMarks())
+	nav.marksMu.Lock()
+	defer nav.marksMu.Unlock()
+	return nav.setMarks(marks)
+}
+
+// setMarks keeps marks & persists global ones if they changed, the caller holds marksMu.
+func (nav *Navigator) setMarks(marks []ftmarks.Mark) error {
+	if !slices.Equal(ftmarks.Global(nav.marks), ftmarks.Global(marks)) {
+		filePath, err := getMarksFilePath()
+		if err == nil {
+			err = ftmarks.Save(filePath, marks)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save marks: %w", err)
+		}
+	}
+	nav.marks = marks
+	return nil
+}
+
+// syncMarks keeps marks pointing to renamed & moved entries. It is safe to be called from operation goroutines.
+func (nav *Navigator) syncMarks(r ftjournal.Record) {
+	nav.marksMu.Lock()
+	defer nav.marksMu.Unlock()
+	marks := slices.Clone(nav.marks)
+	if !ftmarks.ApplyRecord(marks, r) {
+		return
+	}
+	if err := nav.setMarks(marks); err != nil {
+		nav.showError(err)
+	}
+}
+
+// marksInputCapture handles vim-style marks: m<letter> sets a mark & '<letter> jumps to it.
+// Upper-case letters set global marks that are kept across sessions.
+// It is invoked from the dirs tree & the files panel when no search is being typed.
+func (nav *Navigator) marksInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if pending := nav.pendingMark; pending != 0 {
+		nav.pendingMark = 0
+		if event.Key() == tcell.KeyRune && ftmarks.IsLetter(event.Rune()) {
+			if pending == 'm' {
+				nav.setMark(event.Rune())
+			} else {
+				nav.jumpToMark(event.Rune())
+			}
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape {
+			return nil
+		}
+		return event
+	}
+	if event.Key() != tcell.KeyRune || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	switch r := event.Rune(); r {
+	case 'm', '\'':
+		nav.pendingMark = r
+		return nil
+	}
+	return event
+}
+
+// setMark binds a letter to the current dir & the file under the cursor in the files panel.
+func (nav *Navigator) setMark(letter rune) {
+	dirPath := nav.currentDirPath()
+	if dirPath == "" || nav.store == nil {
+		return
+	}
+	rootURL := nav.store.RootURL()
+	m := ftmarks.Mark{Letter: letter, Store: rootURL.String(), Dir: dirPath}
+	if entry := nav.files.GetCurrentEntry(); entry != nil && entry.DirPath() == dirPath {
+		m.File = entry.Name()
+	}
+	if existing, ok := ftmarks.Find(nav.userMarks(), letter); ok && existing.Store == m.Store && existing.Dir == m.Dir {
+		m.Name = existing.Name
+	}
+	if err := nav.updateMarks(func(marks []ftmarks.Mark) []ftmarks.Mark {
+		return ftmarks.Set(marks, m)
+	}); err != nil {
+		nav.notifyError("Marks", err)
+		return
+	}
+	nav.notify(notificationInfo, "Marks", fmt.Sprintf("mark %c set to %s", letter, m.Path()))
+	nav.refreshBookmarksIfVisible()
+}
+
+// jumpToMark goes to the dir of a mark, switching a store if needed, & selects the marked file.
+func (nav *Navigator) jumpToMark(letter rune) {
+	m, ok := ftmarks.Find(nav.userMarks(), letter)
+	if !ok {
+		nav.notify(notificationWarning, "Marks", fmt.Sprintf("mark %c is not set", letter))
+		return
+	}
+	nav.openBasketItem(BasketItem{Store: m.Store, Path: m.Path(), IsDir: m.File == ""})
+}
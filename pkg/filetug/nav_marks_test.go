@@ -0,0 +1,117 @@
+package filetug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftmarks"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withMarksFile redirects global marks to a temp file.
+func withMarksFile(t *testing.T) string {
+	t.Helper()
+	withTestGlobalLock(t)
+	filePath := filepath.Join(t.TempDir(), marksFileName)
+	orig := getMarksFilePath
+	getMarksFilePath = func() (string, error) {
+		return filePath, nil
+	}
+	t.Cleanup(func() {
+		getMarksFilePath = orig
+	})
+	return filePath
+}
+
+func TestNavigator_Marks(t *testing.T) {
+	marksFilePath := withMarksFile(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.txt")
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+	key := func(k tcell.Key) *tcell.EventKey {
+		return tcell.NewEventKey(k, 0, tcell.ModNone)
+	}
+
+	t.Run("set", func(t *testing.T) {
+		assert.Nil(t, nav.files.inputCapture(keyRune('m')))
+		assert.Nil(t, nav.files.inputCapture(keyRune('a')))
+		assert.False(t, nav.files.searching, "the letter of a mark doesn't start a search")
+		assert.Equal(t, "mark a set to "+filepath.Join(dir, "a.txt"), lastNotification(nav).message)
+		nav.files.table.Select(2, 0)
+		assert.Nil(t, nav.files.inputCapture(keyRune('m')))
+		assert.Nil(t, nav.files.inputCapture(keyRune('B')))
+		assert.Equal(t, []ftmarks.Mark{
+			{Letter: 'B', Store: "file:", Dir: dir, File: "b.txt"},
+			{Letter: 'a', Store: "file:", Dir: dir, File: "a.txt"},
+		}, nav.userMarks())
+		global, err := ftmarks.Load(marksFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, []ftmarks.Mark{{Letter: 'B', Store: "file:", Dir: dir, File: "b.txt"}}, global)
+
+		assert.Nil(t, nav.files.inputCapture(keyRune('m')))
+		assert.Nil(t, nav.files.inputCapture(key(tcell.KeyEscape)), "Esc cancels a pending mark")
+		assert.Equal(t, 0, int(nav.pendingMark))
+		assert.Nil(t, nav.files.inputCapture(keyRune('m')))
+		enter := key(tcell.KeyEnter)
+		assert.Equal(t, enter, nav.marksInputCapture(enter), "other keys cancel a pending mark & are passed on")
+		assert.Len(t, nav.userMarks(), 2)
+	})
+
+	t.Run("jump", func(t *testing.T) {
+		nav.current.SetDir(files.NewDirContext(nav.store, sub, nil))
+		assert.Nil(t, nav.dirsTree.inputCapture(keyRune('\'')))
+		assert.Nil(t, nav.dirsTree.inputCapture(keyRune('a')))
+		assert.Equal(t, dir, nav.currentDirPath())
+		assert.Equal(t, "a.txt", nav.files.currentFileName)
+		assert.Empty(t, nav.dirsTree.searchPattern)
+
+		assert.Nil(t, nav.files.inputCapture(keyRune('\'')))
+		assert.Nil(t, nav.files.inputCapture(keyRune('z')))
+		assert.Equal(t, "mark z is not set", lastNotification(nav).message)
+		drainQueuedUpdates(updates)
+	})
+
+	t.Run("bookmarks", func(t *testing.T) {
+		assert.Nil(t, nav.inputCapture(tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt)))
+		p := nav.bookmarksPanel
+		require.NotNil(t, p)
+		assert.Equal(t, p, nav.left.content)
+		main, secondary := p.list.GetItemText(0)
+		assert.Equal(t, "[yellow]B[-] "+filepath.Join(dir, "b.txt"), main)
+		assert.Equal(t, "file:", secondary)
+
+		assert.Nil(t, p.inputCapture(keyRune('r')))
+		p.nameInput.SetText("Bee")
+		assert.Nil(t, p.nameInputCapture(key(tcell.KeyEnter)))
+		main, _ = p.list.GetItemText(0)
+		assert.Equal(t, "[yellow]B[-] Bee", main)
+		global, err := ftmarks.Load(marksFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, "Bee", global[0].Name)
+
+		p.list.SetCurrentItem(1)
+		assert.Nil(t, p.inputCapture(keyRune('d')))
+		assert.Len(t, nav.userMarks(), 1)
+		assert.Equal(t, 1, p.list.GetItemCount())
+
+		nav.current.SetDir(files.NewDirContext(nav.store, sub, nil))
+		assert.Nil(t, p.inputCapture(key(tcell.KeyEnter)))
+		assert.Equal(t, nav.dirsTree, nav.left.content)
+		assert.Equal(t, dir, nav.currentDirPath())
+		assert.Equal(t, "b.txt", nav.files.currentFileName)
+		drainQueuedUpdates(updates)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		b := filepath.Join(dir, "b.txt")
+		nav.syncMarks(ftjournal.Record{Store: "file:", Action: ftjournal.ActionRename, Moves: []ftjournal.Move{{From: b, To: b + ".bak"}}})
+		global, err := ftmarks.Load(marksFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, []ftmarks.Mark{{Letter: 'B', Name: "Bee", Store: "file:", Dir: dir, File: "b.txt.bak"}}, global)
+	})
+}
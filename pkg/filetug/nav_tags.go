@@ -0,0 +1,144 @@
+package filetug
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftsettings"
+	"github.com/filetug/filetug/pkg/filetug/fttags"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const tagsFileName = "tags.yaml"
+
+var getTagsFilePath = func() (string, error) {
+	dir, err := ftsettings.GetDatatugUserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, tagsFileName), nil
+}
+
+func (nav *Navigator) loadTags() {
+	filePath, err := getTagsFilePath()
+	if err == nil {
+		nav.tags, err = fttags.Load(filePath)
+	}
+	if nav.tags == nil {
+		nav.tags = fttags.NewIndex()
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to load tags: %w", err))
+	}
+}
+
+func (nav *Navigator) saveTags() {
+	filePath, err := getTagsFilePath()
+	if err == nil {
+		err = nav.tags.Save(filePath)
+	}
+	if err != nil {
+		nav.showError(fmt.Errorf("failed to save tags: %w", err))
+	}
+}
+
+// syncTags keeps tags & notes with entries affected by a mutating action. It is safe to be called from operation goroutines.
+func (nav *Navigator) syncTags(r ftjournal.Record) {
+	if nav.tags.ApplyRecord(r) {
+		nav.saveTags()
+	}
+}
+
+// entryTagsInfo returns tags & a note of an entry of any store.
+func (nav *Navigator) entryTagsInfo(entry files.EntryWithDirPath) (fttags.Entry, bool) {
+	if nav.tags == nil || nav.store == nil {
+		return fttags.Entry{}, false
+	}
+	item := basketItemOf(nav.store, entry)
+	return nav.tags.Get(item.Store, item.Path)
+}
+
+// entryTags returns tags of an entry to be shown as chips in the files panel.
+func (nav *Navigator) entryTags(entry files.EntryWithDirPath) []string {
+	e, _ := nav.entryTagsInfo(entry)
+	return e.Tags
+}
+
+// tagChips renders tags as colored chips.
+func tagChips(tags []string) string {
+	chips := make([]string, len(tags))
+	for i, tag := range tags {
+		chips[i] = fmt.Sprintf("[black:%s]%s[-:-]", fttags.Color(tag), tview.Escape(tag))
+	}
+	return strings.Join(chips, " ")
+}
+
+// showTagged displays entries having all the given tags as a virtual listing in the files panel.
+func (nav *Navigator) showTagged(tags []string) {
+	nav.shownTags = tags
+	nav.basketRows, nav.listRows = nil, nil
+	nav.tagRows = nav.newTagRows(tags)
+	nav.files.SetRows(nav.tagRows, true)
+}
+
+func (nav *Navigator) isTaggedShown() bool {
+	return nav.tagRows != nil && nav.files.rows == nav.tagRows
+}
+
+// closeTagged returns the files panel to the current directory.
+func (nav *Navigator) closeTagged() {
+	nav.tagRows = nil
+	nav.shownTags = nil
+	nav.refreshCurrentDir()
+}
+
+func (nav *Navigator) renderTaggedIfVisible() {
+	if !nav.isTaggedShown() {
+		return
+	}
+	row, _ := nav.files.table.GetSelection()
+	nav.tagRows = nav.newTagRows(nav.shownTags)
+	nav.files.SetRows(nav.tagRows, true)
+	if count := nav.tagRows.GetRowCount(); row >= count {
+		row = count - 1
+	}
+	if row > 0 {
+		nav.files.table.Select(row, 0)
+	}
+}
+
+func (nav *Navigator) newTagRows(tags []string) *FileRows {
+	rootURL := nav.store.RootURL()
+	currentStore := rootURL.String()
+	tagged := nav.tags.Find(tags...)
+	rows := NewFileRows(nav.current.Dir())
+	rows.virtual = true
+	rows.onKey = nav.taggedInputCapture
+	rows.AllEntries = make([]files.EntryWithDirPath, len(tagged))
+	for i, e := range tagged {
+		rows.AllEntries[i] = newBasketEntry(BasketItem{Store: e.Store, Path: e.Path, IsDir: e.IsDir}, currentStore)
+	}
+	rows.VisibleEntries = rows.AllEntries
+	rows.Infos = make([]os.FileInfo, len(tagged))
+	rows.VisualInfos = make([]os.FileInfo, len(tagged))
+	return rows
+}
+
+func (nav *Navigator) taggedInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		nav.closeTagged()
+		return nil
+	case tcell.KeyEnter:
+		if entry, ok := nav.files.GetCurrentEntry().(basketEntry); ok {
+			nav.openBasketItem(entry.item)
+		}
+		return nil
+	}
+	return event
+}
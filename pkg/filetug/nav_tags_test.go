@@ -0,0 +1,134 @@
+package filetug
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/fttags"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTagsFile redirects the tags index to a temp file.
+func withTagsFile(t *testing.T) string {
+	t.Helper()
+	withTestGlobalLock(t)
+	filePath := filepath.Join(t.TempDir(), tagsFileName)
+	orig := getTagsFilePath
+	getTagsFilePath = func() (string, error) {
+		return filePath, nil
+	}
+	t.Cleanup(func() {
+		getTagsFilePath = orig
+	})
+	return filePath
+}
+
+func TestNavigator_Tags(t *testing.T) {
+	tagsFilePath := withTagsFile(t)
+	nav, updates, dir := newNavigatorWithLocalDir(t, "a.txt", "b.txt", "c.txt")
+	key := func(k tcell.Key) *tcell.EventKey {
+		return tcell.NewEventKey(k, 0, tcell.ModNone)
+	}
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+
+	t.Run("single", func(t *testing.T) {
+		assert.Nil(t, nav.files.inputCapture(key(tcell.KeyCtrlT)))
+		p, ok := nav.right.content.(*tagsPanel)
+		require.True(t, ok)
+		require.NotNil(t, p.note, "a note is edited for a single entry")
+		p.tags.SetText("#Work, todo")
+		p.note.SetText("to review", false)
+		p.save()
+		assert.Equal(t, nav.previewer, nav.right.content)
+		e, ok := nav.tags.Get("file:", a)
+		assert.True(t, ok)
+		assert.Equal(t, fttags.Entry{Store: "file:", Path: a, Tags: []string{"todo", "work"}, Note: "to review"}, e)
+		loaded, err := fttags.Load(tagsFilePath)
+		require.NoError(t, err)
+		assert.Len(t, loaded.Find("work"), 1)
+
+		assert.Equal(t, "📄 a.txt "+tagChips([]string{"todo", "work"}), nav.files.rows.GetCell(1, nameColIndex).Text)
+		nav.previewer.PreviewEntry(nav.files.GetCurrentEntry())
+		assert.Equal(t, tagChips([]string{"todo", "work"})+"\nto review", nav.previewer.noteView.GetText(false))
+	})
+
+	t.Run("selection", func(t *testing.T) {
+		assert.Nil(t, nav.files.inputCapture(keyRune(' ')))
+		assert.Nil(t, nav.files.inputCapture(keyRune(' ')))
+		nav.showTagsPanel()
+		p := nav.right.content.(*tagsPanel)
+		assert.Nil(t, p.note)
+		assert.Empty(t, p.tags.GetText(), "only common tags are shown")
+		p.tags.SetText("work")
+		p.tags.Focus(nil)
+		assert.Nil(t, p.inputCapture(key(tcell.KeyEnter)), "Enter in tags saves")
+		e, _ := nav.tags.Get("file:", a)
+		assert.Equal(t, []string{"todo", "work"}, e.Tags, "tags of an entry are kept")
+		e, _ = nav.tags.Get("file:", b)
+		assert.Equal(t, []string{"work"}, e.Tags)
+
+		nav.showTagsPanel()
+		p = nav.right.content.(*tagsPanel)
+		assert.Equal(t, "work", p.tags.GetText())
+		p.tags.SetText("")
+		p.save()
+		e, _ = nav.tags.Get("file:", a)
+		assert.Equal(t, []string{"todo"}, e.Tags, "a removed common tag is removed from all entries")
+		_, ok := nav.tags.Get("file:", b)
+		assert.False(t, ok)
+		nav.files.rows.ClearSelection()
+
+		nav.showTagsPanel()
+		p = nav.right.content.(*tagsPanel)
+		assert.Nil(t, p.inputCapture(key(tcell.KeyEscape)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+
+	t.Run("browser", func(t *testing.T) {
+		require.NoError(t, nav.tags.Put(fttags.Entry{Store: "file:", Path: b, Tags: []string{"todo", "draft"}}))
+		require.NoError(t, nav.tags.Put(fttags.Entry{Store: "ftp://example.com", Path: "/c", Tags: []string{"draft"}}))
+		assert.Nil(t, nav.inputCapture(tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModAlt)))
+		browser := nav.tagsBrowser
+		assert.Equal(t, browser, nav.left.content)
+		assert.Equal(t, []fttags.TagCount{{Tag: "draft", Count: 2}, {Tag: "todo", Count: 2}}, browser.tags)
+		assert.True(t, nav.isTaggedShown())
+		assert.Equal(t, 2, nav.files.table.GetRowCount())
+		assert.Contains(t, nav.files.table.GetCell(1, nameColIndex).Text, "ftp://example.com/c")
+
+		browser.list.SetCurrentItem(1)
+		assert.Equal(t, []string{"todo"}, nav.shownTags)
+		assert.Nil(t, browser.inputCapture(keyRune(' ')))
+		browser.list.SetCurrentItem(0)
+		assert.Nil(t, browser.inputCapture(keyRune(' ')))
+		assert.Equal(t, []string{"todo", "draft"}, nav.shownTags, "entries having all combined tags are shown")
+		assert.Equal(t, 1, nav.files.table.GetRowCount())
+		main, _ := browser.list.GetItemText(0)
+		assert.Contains(t, main, "✓")
+
+		assert.Len(t, nav.selectedEntries(), 1, "actions apply to shown entries")
+		assert.Nil(t, browser.inputCapture(key(tcell.KeyEnter)))
+		assert.Nil(t, nav.files.inputCapture(key(tcell.KeyEnter)))
+		assert.False(t, nav.isTaggedShown())
+		assert.Equal(t, dir, nav.currentDirPath())
+
+		nav.showTagsBrowser()
+		assert.Nil(t, browser.inputCapture(key(tcell.KeyEscape)))
+		assert.False(t, nav.isTaggedShown())
+		assert.Equal(t, nav.dirsTree, nav.left.content)
+		drainQueuedUpdates(updates)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		nav.syncTags(ftjournal.Record{Store: "file:", Action: ftjournal.ActionRename, Moves: []ftjournal.Move{{From: a, To: a + ".bak"}}})
+		e, ok := nav.tags.Get("file:", a+".bak")
+		assert.True(t, ok)
+		assert.Equal(t, "to review", e.Note)
+		loaded, err := fttags.Load(tagsFilePath)
+		require.NoError(t, err)
+		_, ok = loaded.Get("file:", a+".bak")
+		assert.True(t, ok)
+	})
+}
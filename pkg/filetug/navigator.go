@@ -2,13 +2,21 @@ package filetug
 
 import (
 	"context"
-	"log"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/ftpfile"
+	"github.com/filetug/filetug/pkg/files/httpfile"
 	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/filetug/filetug/pkg/filetug/ftfrecency"
+	"github.com/filetug/filetug/pkg/filetug/ftlists"
+	"github.com/filetug/filetug/pkg/filetug/ftmarks"
 	"github.com/filetug/filetug/pkg/filetug/ftstate"
+	"github.com/filetug/filetug/pkg/filetug/fttags"
 	"github.com/filetug/filetug/pkg/filetug/masks"
 	"github.com/filetug/filetug/pkg/filetug/navigator"
 	"github.com/filetug/filetug/pkg/gitutils"
@@ -60,6 +68,7 @@ type Navigator struct {
 
 	dirsTree  *Tree
 	favorites *favoritesPanel
+	volumes   *volumesPanel
 	masks     *masks.Panel
 	newPanel  *NewPanel
 
@@ -69,6 +78,40 @@ type Navigator struct {
 
 	previewer *previewerPanel
 
+	operations      *OperationsManager
+	operationsPanel *operationsPanel
+	historyPanel    *historyPanel
+
+	notifications      notifications
+	notificationsPanel *notificationsPanel
+
+	basket     *Basket
+	basketRows *FileRows // not nil while the basket is shown in the files panel
+
+	listsMu    sync.Mutex
+	lists      []ftlists.List
+	listsPanel *listsPanel
+	listRows   *FileRows // not nil while a list is shown in the files panel
+	shownList  string    // the name of the list shown in the files panel
+
+	deleteShownPending bool // D was pressed once to delete all shown items of the basket or a list
+
+	tags        *fttags.Index
+	tagsBrowser *tagsBrowser
+	tagRows     *FileRows // not nil while tagged entries are shown in the files panel
+	shownTags   []string  // tags of entries shown in the files panel
+
+	marksMu        sync.Mutex
+	marks          []ftmarks.Mark
+	pendingMark    rune // 'm' or '\'' while a letter of a mark is awaited
+	bookmarksPanel *bookmarksPanel
+
+	frecency *ftfrecency.DB // visited dirs ranked by frequency & recency
+
+	diffMark *diffSource // a file marked to be compared with a file selected next
+
+	undoRedoRunning atomic.Bool // only one undo or redo runs at a time
+
 	bottom *bottom
 
 	saveCurrentDir func(store, currentDir string)
@@ -76,6 +119,7 @@ type Navigator struct {
 	gitStatusCache   map[string]*gitutils.RepoStatus
 	gitStatusCacheMu sync.RWMutex
 	cancel           context.CancelFunc
+	cancelMaskCounts context.CancelFunc // stops counting matches of masks in subdirectories
 
 	showError func(err error)
 }
@@ -86,6 +130,22 @@ func (nav *Navigator) SetStore(store files.Store) {
 	nav.files.onStoreChange()
 }
 
+// newStoreForURL creates a store for a root URL. Returns nil for unsupported schemes.
+func newStoreForURL(root url.URL) files.Store {
+	switch strings.ToLower(root.Scheme) {
+	case "http", "https":
+		return httpfile.NewStore(root)
+	case "ftp", "ftps":
+		return ftpfile.NewStore(root)
+	case "file":
+		if root.Path == "" {
+			root.Path = "/"
+		}
+		return osfile.NewStore(root.Path)
+	}
+	return nil
+}
+
 func (nav *Navigator) SetFocus() {
 	if nav.app != nil {
 		nav.app.SetFocus(nav.dirsTree.tv)
@@ -148,10 +208,16 @@ func NewNavigator(app navigator.App, options ...NavigatorOption) *Navigator {
 		proportions:    make([]int, 3),
 		gitStatusCache: make(map[string]*gitutils.RepoStatus),
 		saveCurrentDir: ftstate.SaveCurrentDir,
-		showError: func(err error) {
-			log.Println(err) // TODO(help-wanted): Show error to user
-		},
 	}
+	nav.showError = func(err error) {
+		nav.notifyError("", err)
+	}
+	nav.operations = NewOperationsManager(nav.onOperationChanged, nav.onOperationDone)
+	nav.loadBasket()
+	nav.loadLists()
+	nav.loadTags()
+	nav.loadMarks()
+	nav.loadFrecency()
 	nav.bottom = newBottom(nav)
 	nav.right = NewContainer(2, nav)
 	nav.favorites = newFavoritesPanel(nav)
@@ -36,6 +36,7 @@ func (nav *Navigator) goDir(dirContext *files.DirContext) {
 	root := nav.store.RootURL()
 	rootValue := root.String()
 	nav.saveCurrentDir(rootValue, dirContext.Path())
+	nav.recordVisit(rootValue, dirContext.Path())
 }
 
 // showDir updates all panels.
@@ -94,6 +95,45 @@ func (nav *Navigator) showDir(ctx context.Context, node *tview.TreeNode, dirCont
 	}()
 }
 
+// refreshCurrentDir re-reads the current directory and updates the files panel
+// without resetting other panels, e.g. after an operation modified the directory.
+func (nav *Navigator) refreshCurrentDir() {
+	dirPath := nav.currentDirPath()
+	if dirPath == "" {
+		return
+	}
+	ctx := context.Background()
+	go func() {
+		dirContext, err := nav.getDirData(ctx, dirPath)
+		nav.app.QueueUpdateDraw(func() {
+			if err != nil {
+				nav.showError(err)
+				return
+			}
+			if nav.currentDirPath() != dirPath {
+				return // user navigated away while we were loading
+			}
+			nav.current.SetDir(dirContext)
+			if nav.isBasketShown() {
+				nav.renderBasketIfVisible()
+				return
+			}
+			if nav.isListShown() {
+				nav.renderListIfVisible()
+				return
+			}
+			if nav.isTaggedShown() {
+				nav.renderTaggedIfVisible()
+				return
+			}
+			if nav.files != nil {
+				dirRecords := NewFileRows(dirContext)
+				nav.files.SetRows(dirRecords, nav.files.filter.ShowDirs)
+			}
+		})
+	}()
+}
+
 func (nav *Navigator) onDataLoaded(ctx context.Context, node *tview.TreeNode, dirContext *files.DirContext, isTreeRootChanged bool) {
 	if nav.previewer != nil {
 		nav.previewer.PreviewEntry(dirContext)
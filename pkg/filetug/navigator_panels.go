@@ -7,9 +7,14 @@ import (
 func (nav *Navigator) showMasks() {
 	if nav.masks == nil {
 		nav.masks = masks.NewPanel()
+		nav.masks.SetActionFunc(nav.onMaskAction)
+		nav.masks.SetCloseFunc(nav.closeMasks)
 	}
 	if nav.right != nil {
+		nav.masks.SetMasks(nav.allMasks())
+		nav.reapplyMaskFilter()
 		nav.right.SetContent(nav.masks)
+		nav.countMaskMatches()
 	}
 }
 
@@ -1,6 +1,7 @@
 package filetug
 
 import (
+	"fmt"
 	"net/url"
 	"strings"
 
@@ -41,6 +42,7 @@ func initNavigatorWithPersistedState(nav *Navigator) {
 		if strings.HasPrefix(state.CurrentDir, "https://") {
 			currentUrl, err := url.Parse(state.CurrentDir)
 			if err != nil {
+				nav.notifyError("State", fmt.Errorf("failed to restore current dir: %w", err))
 				return
 			}
 			dirPath = currentUrl.Path
@@ -19,6 +19,9 @@ func (nav *Navigator) inputCapture(event *tcell.EventKey) *tcell.EventKey {
 	case tcell.KeyF1:
 		showHelpModal(nav)
 		return nil
+	case tcell.KeyF6:
+		nav.showBulkRename()
+		return nil
 	case tcell.KeyF7:
 		nav.showNewPanel()
 		return nil
@@ -42,6 +45,33 @@ func (nav *Navigator) inputCapture(event *tcell.EventKey) *tcell.EventKey {
 			case 'm', 'M':
 				nav.showMasks()
 				return nil
+			case 'v', 'V':
+				nav.showVolumes()
+				return nil
+			case 't', 'T':
+				nav.showOperationsPanel()
+				return nil
+			case 'y', 'Y':
+				nav.showHistoryPanel()
+				return nil
+			case 'k', 'K':
+				nav.showBasket()
+				return nil
+			case 'b', 'B':
+				nav.showBookmarks()
+				return nil
+			case 'o', 'O':
+				nav.showJumpPanel()
+				return nil
+			case 'l', 'L':
+				nav.showLists()
+				return nil
+			case 'a', 'A':
+				nav.showTagsBrowser()
+				return nil
+			case 'n', 'N':
+				nav.showNotificationsPanel()
+				return nil
 			case '0':
 				copy(nav.proportions, defaultProportions)
 				nav.createColumns()
@@ -131,6 +161,30 @@ func macOSOptionRune(r rune) (rune, bool) {
 	return r, false
 }
 
+// entryActionsInputCapture handles Ctrl shortcuts acting on the current entries.
+// It is invoked from the dirs tree & the files panel so the keys keep editing text in input fields.
+func (nav *Navigator) entryActionsInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyCtrlZ:
+		nav.undo()
+	case tcell.KeyCtrlY:
+		nav.redo()
+	case tcell.KeyCtrlB:
+		nav.toggleBasket()
+	case tcell.KeyCtrlA:
+		nav.showArchivePanel()
+	case tcell.KeyCtrlE:
+		nav.showExtractPanel()
+	case tcell.KeyCtrlD:
+		nav.markForDiff()
+	case tcell.KeyCtrlT:
+		nav.showTagsPanel()
+	default:
+		return event
+	}
+	return nil
+}
+
 // globalNavInputCapture should be invoked only from specific boxes like Tree and filesPanel.
 func (nav *Navigator) globalNavInputCapture(event *tcell.EventKey) *tcell.EventKey {
 	if nav.app == nil {
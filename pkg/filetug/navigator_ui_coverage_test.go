@@ -7,16 +7,16 @@ import (
 )
 
 // TestInputCapture_OptionRuneWithoutModAlt covers the `r != event.Rune()`
-// operand of inputCapture's alt-branch guard: a macOS Option-key rune ('å')
+// operand of inputCapture's alt-branch guard: a macOS Option-key rune ('œ')
 // arrives WITHOUT the ModAlt modifier, so the left operand is false and the
-// normalized rune (differing from the original) is what opens the branch. 'a'
+// normalized rune (differing from the original) is what opens the branch. 'q'
 // then hits the inner default, returning the event unchanged.
 func TestInputCapture_OptionRuneWithoutModAlt(t *testing.T) {
 	t.Parallel()
 	nav, _, _ := newNavigatorForTest(t)
-	event := tcell.NewEventKey(tcell.KeyRune, 'å', tcell.ModNone)
+	event := tcell.NewEventKey(tcell.KeyRune, 'œ', tcell.ModNone)
 	if got := nav.inputCapture(event); got != event {
-		t.Errorf("inputCapture(Option-a, no ModAlt) = %v, want the event returned unchanged", got)
+		t.Errorf("inputCapture(Option-q, no ModAlt) = %v, want the event returned unchanged", got)
 	}
 }
 
@@ -4,17 +4,32 @@ import (
 	"context"
 	"fmt"
 	"path"
-	"sync"
+	"strconv"
+	"strings"
 
 	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftgen"
+	"github.com/filetug/filetug/pkg/fsutils"
 	"github.com/filetug/filetug/pkg/sneatv"
 	"github.com/rivo/tview"
 )
 
+const generateOperation OperationType = "generate"
+
 type nestedDirsGeneratorPanel struct {
 	*sneatv.Boxed
-	flex *tview.Flex
-	form *tview.Form
+	nav         *Navigator
+	active      tview.Primitive
+	flex        *tview.Flex
+	form        *tview.Form
+	name        *tview.InputField
+	depth       *tview.InputField
+	subDirs     *tview.InputField
+	filesPerDir *tview.InputField
+	fileSize    *tview.InputField
+	pattern     *tview.DropDown
+	workers     *tview.InputField
+	status      *tview.TextView
 }
 
 func newNestedDirsGeneratorPanel(nav *Navigator, active tview.Primitive) tview.Primitive {
@@ -23,66 +38,134 @@ func newNestedDirsGeneratorPanel(nav *Navigator, active tview.Primitive) tview.P
 	flex.SetDirection(tview.FlexRow)
 
 	form := tview.NewForm()
+	status := tview.NewTextView().SetDynamicColors(true)
 	flex.AddItem(form, 0, 1, true)
+	flex.AddItem(status, 1, 0, false)
 
-	form.AddInputField("Depth", "20", 0, nil, nil)
-	form.AddInputField("SubDirs", "10", 0, nil, nil)
-	form.AddInputField("FilesPerDir", "10", 0, nil, nil)
-	form.AddInputField("File Size (bytes)", "1024", 0, nil, nil)
-	form.AddButton("Generate", func() {})
-	form.AddButton("Cancel", func() {
-		nav.right.SetContent(nav.previewer)
-		if active != nil {
-			nav.app.SetFocus(active)
-		}
-	})
+	p := &nestedDirsGeneratorPanel{
+		Boxed:  sneatv.NewBoxed(flex),
+		nav:    nav,
+		active: active,
+		flex:   flex,
+		form:   form,
+		status: status,
+	}
 
-	//var spacer tview.Primitive = nil
-	//buttons := tview.NewFlex()
-	//rows.AddItem(buttons, 3, 0, false)
-	//
-	//generateBtn := tview.NewButton("Generate")
-	//buttons.AddItem(generateBtn, 0, len(generateBtn.GetLabel()), false)
-	//
-	//buttons.AddItem(spacer, 1, 0, false)
-	//
-	//cancelBtn := tview.NewButton("Cancel")
-	//buttons.AddItem(cancelBtn, 0, len(cancelBtn.GetLabel()), false)
-	//cancelBtn.SetSelectedFunc(func() {
-	//})
+	newIntField := func(label, value string) *tview.InputField {
+		field := tview.NewInputField().SetLabel(label).SetText(value).
+			SetAcceptanceFunc(tview.InputFieldInteger).
+			SetChangedFunc(func(string) {
+				p.updateStatus()
+			})
+		form.AddFormItem(field)
+		return field
+	}
+	p.name = tview.NewInputField().SetLabel("Dir name").SetText("nested-dirs")
+	form.AddFormItem(p.name)
+	p.depth = newIntField("Depth", "3")
+	p.subDirs = newIntField("SubDirs", "10")
+	p.filesPerDir = newIntField("FilesPerDir", "10")
+	p.fileSize = newIntField("File Size (bytes)", "1024")
+	patterns := make([]string, len(ftgen.Patterns))
+	for i, pattern := range ftgen.Patterns {
+		patterns[i] = string(pattern)
+	}
+	p.pattern = tview.NewDropDown().SetLabel("Content").SetOptions(patterns, nil).SetCurrentOption(0)
+	form.AddFormItem(p.pattern)
+	p.workers = newIntField("Workers", "8")
+	form.AddButton("Generate", p.generate)
+	form.AddButton("Cancel", p.close)
+	p.updateStatus()
+	return p
+}
 
-	p := nestedDirsGeneratorPanel{
-		Boxed: sneatv.NewBoxed(flex),
-		flex:  flex,
-		form:  form,
+func (p *nestedDirsGeneratorPanel) options() (ftgen.Options, error) {
+	var opts ftgen.Options
+	fields := []struct {
+		field *tview.InputField
+		value *int
+	}{
+		{p.depth, &opts.Depth},
+		{p.subDirs, &opts.SubDirs},
+		{p.filesPerDir, &opts.FilesPerDir},
+		{p.workers, &opts.Workers},
+	}
+	for _, f := range fields {
+		v, err := strconv.Atoi(f.field.GetText())
+		if err != nil {
+			return opts, fmt.Errorf("%s: %w", f.field.GetLabel(), err)
+		}
+		*f.value = v
+	}
+	var err error
+	if opts.FileSize, err = strconv.ParseInt(p.fileSize.GetText(), 10, 64); err != nil {
+		return opts, fmt.Errorf("%s: %w", p.fileSize.GetLabel(), err)
+	}
+	if i, _ := p.pattern.GetCurrentOption(); i >= 0 && i < len(ftgen.Patterns) {
+		opts.Pattern = ftgen.Patterns[i]
 	}
-	return &p
+	_, _, err = opts.Count()
+	return opts, err
 }
 
-func GeneratedNestedDirs(ctx context.Context, store files.Store, dirPath, subDirNameFormat string, depth, subDirsCount int) (err error) {
-	if err = store.CreateDir(ctx, dirPath); err != nil {
-		return err
+func (p *nestedDirsGeneratorPanel) updateStatus() {
+	opts, err := p.options()
+	if err != nil {
+		p.status.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
 	}
-	if subDirNameFormat == "" {
-		subDirNameFormat = "Directory%d"
+	dirs, fileCount, _ := opts.Count()
+	p.status.SetText(fmt.Sprintf("%d dirs, %d files, %s",
+		dirs, fileCount, fsutils.GetSizeShortText(int64(fileCount)*opts.FileSize)))
+}
+
+func (p *nestedDirsGeneratorPanel) generate() {
+	opts, err := p.options()
+	if err == nil {
+		name := strings.TrimSpace(p.name.GetText())
+		if name == "" || strings.Contains(name, "/") || name == "." || name == ".." {
+			err = fmt.Errorf("invalid dir name: %q", name)
+		}
 	}
-	if depth == 0 {
-		return nil
+	if err != nil {
+		p.status.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
 	}
-	var wg sync.WaitGroup
-	wg.Add(subDirsCount)
-	for i := 0; i < subDirsCount; i++ {
-		i := i
-		go func() {
-			defer wg.Done()
-			subDirName := fmt.Sprintf(subDirNameFormat, i)
-			subDirPath := path.Join(dirPath, subDirName)
-			subDirErr := GeneratedNestedDirs(ctx, store, subDirPath, subDirNameFormat, depth-1, subDirsCount)
-			if subDirErr != nil {
-				err = subDirErr
-			}
-		}()
+	dirPath := path.Join(p.nav.currentDirPath(), strings.TrimSpace(p.name.GetText()))
+	p.close()
+	p.nav.generateNestedDirs(dirPath, opts)
+}
+
+func (p *nestedDirsGeneratorPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	if p.active != nil {
+		p.nav.app.SetFocus(p.active)
 	}
-	wg.Wait()
-	return nil
+}
+
+// generateNestedDirs generates a test tree in background.
+func (nav *Navigator) generateNestedDirs(dirPath string, opts ftgen.Options) *Operation {
+	store := nav.store
+	title := "Generate nested dirs in " + dirPath
+	return nav.operations.Start(generateOperation, title, []string{path.Dir(dirPath)},
+		func(ctx context.Context, reportProgress ProgressReporter) error {
+			return ftgen.Generate(ctx, store, dirPath, opts, func(p ftgen.Progress) {
+				reportProgress(OperationProgress{
+					Total:      p.DirsTotal + p.FilesTotal,
+					Done:       p.DirsDone + p.FilesDone,
+					BytesTotal: p.BytesTotal,
+					BytesDone:  p.BytesDone,
+				})
+			})
+		},
+	)
+}
+
+// GeneratedNestedDirs creates dirPath with depth levels of sub-dirs, subDirsCount in each of them.
+func GeneratedNestedDirs(ctx context.Context, store files.Store, dirPath, subDirNameFormat string, depth, subDirsCount int) error {
+	return ftgen.Generate(ctx, store, dirPath, ftgen.Options{
+		Depth:            depth,
+		SubDirs:          subDirsCount,
+		SubDirNameFormat: subDirNameFormat,
+	}, nil)
 }
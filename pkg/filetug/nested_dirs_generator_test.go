@@ -2,6 +2,9 @@ package filetug
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -80,14 +83,19 @@ func TestGeneratedNestedDirs_DepthZero(t *testing.T) {
 }
 
 func TestNestedDirsGeneratorPanel_GenerateButton(t *testing.T) {
-	t.Parallel()
-	nav, _, _ := newNavigatorForTest(t)
+	nav, _, dir := newNavigatorWithLocalDir(t)
 
 	panel := newNestedDirsGeneratorPanel(nav, nil)
 	p, ok := panel.(*nestedDirsGeneratorPanel)
 	if !ok {
 		t.Fatalf("expected *nestedDirsGeneratorPanel, got %T", panel)
 	}
+	p.depth.SetText("2")
+	p.subDirs.SetText("2")
+	p.filesPerDir.SetText("1")
+	p.fileSize.SetText("300")
+	p.pattern.SetCurrentOption(2) // lorem
+	assert.Equal(t, "7 dirs, 7 files, 2KB", p.status.GetText(true))
 
 	buttonIndex := p.form.GetButtonIndex("Generate")
 	if buttonIndex < 0 {
@@ -97,4 +105,37 @@ func TestNestedDirsGeneratorPanel_GenerateButton(t *testing.T) {
 	button := p.form.GetButton(buttonIndex)
 	handler := button.InputHandler()
 	handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(_ tview.Primitive) {})
+
+	o := lastOperation(nav)
+	waitOperation(t, o)
+	assert.Equal(t, generateOperation, o.Type)
+	progress := o.Progress()
+	assert.Equal(t, 14, progress.Done)
+	assert.Equal(t, int64(7*300), progress.BytesDone)
+	data, err := os.ReadFile(filepath.Join(dir, "nested-dirs", "Directory1", "Directory0", "File0.txt"))
+	assert.NoError(t, err)
+	assert.Len(t, data, 300)
+	assert.True(t, strings.HasPrefix(string(data), "Lorem ipsum"))
+}
+
+func TestNestedDirsGeneratorPanel_InvalidInput(t *testing.T) {
+	nav, _, _ := newNavigatorWithLocalDir(t)
+	p := newNestedDirsGeneratorPanel(nav, nil).(*nestedDirsGeneratorPanel)
+
+	p.depth.SetText("")
+	assert.Contains(t, p.status.GetText(true), "Depth:")
+	p.generate()
+	assert.Empty(t, nav.operations.Operations())
+
+	p.depth.SetText("9")
+	assert.Contains(t, p.status.GetText(true), "too many entries")
+	p.fileSize.SetText("x")
+	assert.Contains(t, p.status.GetText(true), "File Size (bytes):")
+
+	p.depth.SetText("1")
+	p.fileSize.SetText("1")
+	p.name.SetText("a/b")
+	p.generate()
+	assert.Contains(t, p.status.GetText(true), `invalid dir name: "a/b"`)
+	assert.Empty(t, nav.operations.Operations())
 }
@@ -2,9 +2,11 @@ package filetug
 
 import (
 	"context"
+	"fmt"
 	"path"
 
 	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
 	"github.com/filetug/filetug/pkg/sneatv"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -121,9 +123,10 @@ func (p *NewPanel) createDir() {
 	ctx := context.Background()
 	err := p.nav.store.CreateDir(ctx, fullPath)
 	if err != nil {
-		// TODO: show error
+		p.nav.notifyError("New", fmt.Errorf("failed to create dir: %w", err))
 		return
 	}
+	p.nav.recordHistory(p.nav.store, ftjournal.Record{Action: ftjournal.ActionCreateDir, Paths: []string{fullPath}})
 
 	p.nav.right.SetContent(p.nav.previewer)
 	dirContext := files.NewDirContext(p.nav.store, fullPath, nil)
@@ -141,9 +144,10 @@ func (p *NewPanel) createFile() {
 	ctx := context.Background()
 	err := p.nav.store.CreateFile(ctx, fullPath)
 	if err != nil {
-		// TODO: show error
+		p.nav.notifyError("New", fmt.Errorf("failed to create file: %w", err))
 		return
 	}
+	p.nav.recordHistory(p.nav.store, ftjournal.Record{Action: ftjournal.ActionCreateFile, Paths: []string{fullPath}})
 
 	p.nav.right.SetContent(p.nav.previewer)
 	currentDir := p.nav.currentDirPath()
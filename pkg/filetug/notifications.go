@@ -0,0 +1,143 @@
+package filetug
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type notificationLevel int
+
+const (
+	notificationInfo notificationLevel = iota
+	notificationWarning
+	notificationError
+)
+
+func (l notificationLevel) String() string {
+	switch l {
+	case notificationWarning:
+		return "warning"
+	case notificationError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+func (l notificationLevel) color() tcell.Color {
+	switch l {
+	case notificationWarning:
+		return tcell.ColorYellow
+	case notificationError:
+		return tcell.ColorRed
+	default:
+		return tcell.ColorGreen
+	}
+}
+
+func (l notificationLevel) icon() string {
+	switch l {
+	case notificationWarning:
+		return "⚠"
+	case notificationError:
+		return "✖"
+	default:
+		return "ℹ"
+	}
+}
+
+// notification is a message shown as a toast in the bottom bar & kept in the message log.
+type notification struct {
+	id      int
+	time    time.Time
+	level   notificationLevel
+	source  string // e.g. a panel or an operation, can be empty
+	message string
+}
+
+// maxNotifications limits the message log, the oldest messages are dropped.
+const maxNotifications = 500
+
+// toastDuration is how long a toast is shown in the bottom bar.
+var toastDuration = 5 * time.Second
+
+// afterToastDuration calls f once a toast expires, tests replace it to control time.
+var afterToastDuration = func(f func()) {
+	time.AfterFunc(toastDuration, f)
+}
+
+// notifications is a log of messages, it's safe to be used from any goroutine.
+type notifications struct {
+	mu     sync.Mutex
+	items  []notification
+	lastID int
+}
+
+func (n *notifications) add(level notificationLevel, source, message string) notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lastID++
+	item := notification{id: n.lastID, time: timeNow(), level: level, source: source, message: message}
+	n.items = append(n.items, item)
+	if len(n.items) > maxNotifications {
+		n.items = append(n.items[:0], n.items[len(n.items)-maxNotifications:]...)
+	}
+	return item
+}
+
+// all returns a copy of the log, the oldest first.
+func (n *notifications) all() []notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notification(nil), n.items...)
+}
+
+func (n *notifications) clear() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.items = nil
+}
+
+// notify adds a message to the log & shows it as a toast. It is safe to be called from any goroutine.
+func (nav *Navigator) notify(level notificationLevel, source, message string) {
+	n := nav.notifications.add(level, source, message)
+	nav.app.QueueUpdateDraw(func() {
+		nav.bottom.showToast(n)
+		nav.renderNotificationsPanelIfVisible()
+	})
+}
+
+func (nav *Navigator) notifyError(source string, err error) {
+	nav.notify(notificationError, source, err.Error())
+}
+
+// showToast replaces the menu in the bottom bar with a message until it expires or a newer one is shown.
+func (b *bottom) showToast(n notification) {
+	b.toast = &n
+	b.render()
+	afterToastDuration(func() {
+		b.nav.app.QueueUpdateDraw(func() {
+			b.hideToast(n.id)
+		})
+	})
+}
+
+func (b *bottom) hideToast(id int) {
+	if b.toast != nil && b.toast.id == id {
+		b.toast = nil
+		b.render()
+	}
+}
+
+func (b *bottom) renderToast() string {
+	n := b.toast
+	text := tview.Escape(n.message)
+	if n.source != "" {
+		text = tview.Escape(n.source) + ": " + text
+	}
+	return fmt.Sprintf("[#%06x]%s %s[-]  [gray]Alt+N: notifications[-]", n.level.color().Hex(), n.level.icon(), text)
+}
@@ -0,0 +1,91 @@
+package filetug
+
+import (
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// notificationsPanel is a scrollable log of notifications, the most recent first.
+type notificationsPanel struct {
+	*sneatv.Boxed
+	nav   *Navigator
+	table *tview.Table
+}
+
+func (nav *Navigator) showNotificationsPanel() {
+	if nav.notificationsPanel == nil {
+		nav.notificationsPanel = newNotificationsPanel(nav)
+	}
+	nav.notificationsPanel.render()
+	nav.right.SetContent(nav.notificationsPanel)
+	nav.app.SetFocus(nav.notificationsPanel.table)
+}
+
+func newNotificationsPanel(nav *Navigator) *notificationsPanel {
+	table := tview.NewTable()
+	table.SetSelectable(true, false)
+	footer := tview.NewTextView().
+		SetText("c: clear · Esc: close").
+		SetTextColor(tcell.ColorGray)
+	p := &notificationsPanel{
+		nav:   nav,
+		table: table,
+		Boxed: sneatv.NewBoxed(
+			table,
+			sneatv.WithLeftBorder(0, -1),
+			sneatv.WithFooter(footer),
+		),
+	}
+	p.SetTitle("Notifications")
+	table.SetInputCapture(p.inputCapture)
+	table.SetFocusFunc(func() {
+		nav.activeCol = 2
+	})
+	return p
+}
+
+func (p *notificationsPanel) render() {
+	p.table.Clear()
+	items := p.nav.notifications.all()
+	if len(items) == 0 {
+		p.table.SetCell(0, 0, tview.NewTableCell("[::i]No notifications yet[::-]").SetTextColor(tcell.ColorGray))
+		return
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		n := items[i]
+		row := len(items) - 1 - i
+		p.table.SetCell(row, 0, tview.NewTableCell(n.time.Local().Format(historyTimeLayout)).SetTextColor(tcell.ColorGray))
+		p.table.SetCell(row, 1, tview.NewTableCell(n.level.String()).SetTextColor(n.level.color()))
+		p.table.SetCell(row, 2, tview.NewTableCell(tview.Escape(n.source)).SetTextColor(tcell.ColorGray))
+		p.table.SetCell(row, 3, tview.NewTableCell(tview.Escape(n.message)).SetExpansion(1))
+	}
+	p.table.ScrollToBeginning()
+}
+
+func (p *notificationsPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.nav.right.SetContent(p.nav.previewer)
+		p.nav.app.SetFocus(p.nav.files)
+		return nil
+	case tcell.KeyLeft:
+		p.nav.app.SetFocus(p.nav.files)
+		return nil
+	case tcell.KeyRune:
+		if r := event.Rune(); r == 'c' || r == 'C' {
+			p.nav.notifications.clear()
+			p.render()
+			return nil
+		}
+		return event
+	default:
+		return event
+	}
+}
+
+func (nav *Navigator) renderNotificationsPanelIfVisible() {
+	if nav.notificationsPanel != nil && nav.right.content == nav.notificationsPanel {
+		nav.notificationsPanel.render()
+	}
+}
@@ -0,0 +1,42 @@
+package filetug
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationsPanel(t *testing.T) {
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+
+	assert.Nil(t, nav.inputCapture(tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModAlt)))
+	p := nav.notificationsPanel
+	assert.Equal(t, p, nav.right.content)
+	assert.Contains(t, p.table.GetCell(0, 0).Text, "No notifications yet")
+
+	nav.notify(notificationInfo, "Copy", "done")
+	nav.notify(notificationError, "Sync", "[red] failed")
+	drainQueuedUpdates(updates)
+	assert.Equal(t, 2, p.table.GetRowCount(), "rendered while visible")
+	assert.Equal(t, "error", p.table.GetCell(0, 1).Text, "the most recent first")
+	assert.Equal(t, "Sync", p.table.GetCell(0, 2).Text)
+	assert.Equal(t, "[red[] failed", p.table.GetCell(0, 3).Text)
+	assert.Equal(t, "Copy", p.table.GetCell(1, 2).Text)
+
+	event := keyRune('z')
+	assert.Equal(t, event, p.inputCapture(event))
+	event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+	assert.Equal(t, event, p.inputCapture(event))
+	assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone)))
+
+	assert.Nil(t, p.inputCapture(keyRune('c')))
+	assert.Empty(t, nav.notifications.all())
+	assert.Contains(t, p.table.GetCell(0, 0).Text, "No notifications yet")
+
+	assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+	assert.Equal(t, nav.previewer, nav.right.content)
+	nav.notify(notificationInfo, "", "hidden")
+	drainQueuedUpdates(updates)
+	assert.Contains(t, p.table.GetCell(0, 0).Text, "No notifications yet", "not rendered while hidden")
+}
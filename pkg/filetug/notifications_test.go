@@ -0,0 +1,112 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationLevel(t *testing.T) {
+	for level, expected := range map[notificationLevel]struct {
+		name  string
+		color tcell.Color
+		icon  string
+	}{
+		notificationInfo:    {"info", tcell.ColorGreen, "ℹ"},
+		notificationWarning: {"warning", tcell.ColorYellow, "⚠"},
+		notificationError:   {"error", tcell.ColorRed, "✖"},
+	} {
+		assert.Equal(t, expected.name, level.String())
+		assert.Equal(t, expected.color, level.color())
+		assert.Equal(t, expected.icon, level.icon())
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	var n notifications
+	for i := range maxNotifications + 2 {
+		n.add(notificationInfo, "test", fmt.Sprint(i))
+	}
+	items := n.all()
+	assert.Len(t, items, maxNotifications, "the oldest are dropped")
+	assert.Equal(t, "2", items[0].message)
+	assert.Equal(t, maxNotifications+2, items[len(items)-1].id)
+	n.clear()
+	assert.Empty(t, n.all())
+}
+
+func TestNavigator_Notify(t *testing.T) {
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+	var hide func()
+	afterToastDuration = func(f func()) {
+		hide = f
+	}
+	t.Cleanup(func() {
+		afterToastDuration = func(func()) {}
+	})
+	menu := nav.bottom.GetText(false)
+
+	nav.showError(errors.New("disk [full]"))
+	drainQueuedUpdates(updates)
+	first := hide
+	assert.Equal(t, "✖ disk [full]  Alt+N: notifications", nav.bottom.GetText(true))
+
+	nav.notify(notificationWarning, "Sync", "1 failed")
+	drainQueuedUpdates(updates)
+	assert.Equal(t, "⚠ Sync: 1 failed  Alt+N: notifications", nav.bottom.GetText(true))
+
+	first()
+	drainQueuedUpdates(updates)
+	assert.Contains(t, nav.bottom.GetText(true), "1 failed", "an expired toast doesn't hide a newer one")
+	hide()
+	drainQueuedUpdates(updates)
+	assert.Equal(t, menu, nav.bottom.GetText(false))
+
+	items := nav.notifications.all()
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, notificationError, items[0].level)
+		assert.Equal(t, "", items[0].source)
+		assert.Equal(t, "Sync", items[1].source)
+		assert.WithinDuration(t, time.Now(), items[1].time, time.Minute)
+	}
+}
+
+func TestNavigator_NotifyOperationDone(t *testing.T) {
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+	o := nav.operations.Start("test", "Test", nil, func(_ context.Context, reportProgress ProgressReporter) error {
+		reportProgress(OperationProgress{Total: 2, Done: 2, Failed: 1})
+		return nil
+	})
+	waitOperation(t, o)
+	drainQueuedUpdates(updates)
+	o = nav.operations.Start("test", "Test 2", nil, func(context.Context, ProgressReporter) error {
+		return nil
+	})
+	waitOperation(t, o)
+	drainQueuedUpdates(updates)
+	items := nav.notifications.all()
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, notification{id: 1, time: items[0].time, level: notificationWarning, source: "Test", message: "done, 1 failed"}, items[0])
+		assert.Equal(t, notificationInfo, items[1].level)
+		assert.Equal(t, "Test 2", items[1].source)
+	}
+}
+
+func TestNewPanel_NotifiesErrors(t *testing.T) {
+	nav, updates, _ := newNavigatorWithLocalDir(t, "a.txt")
+	nav.newPanel.input.SetText("a.txt/sub")
+	nav.newPanel.createDir()
+	nav.newPanel.createFile()
+	drainQueuedUpdates(updates)
+	items := nav.notifications.all()
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, "New", items[0].source)
+		assert.Contains(t, items[0].message, "failed to create dir")
+		assert.Contains(t, items[1].message, "failed to create file")
+	}
+}
@@ -2,44 +2,319 @@ package filetug
 
 import (
 	"context"
+	"sync"
+	"time"
 )
 
 type OperationType string
 
+// OperationState is a lifecycle state of an Operation.
+type OperationState string
+
+const (
+	OperationRunning   OperationState = "running"
+	OperationPaused    OperationState = "paused"
+	OperationSucceeded OperationState = "done"
+	OperationFailed    OperationState = "failed"
+	OperationCancelled OperationState = "cancelled"
+)
+
+// IsFinished reports whether an operation in this state will make no more progress.
+func (s OperationState) IsFinished() bool {
+	switch s {
+	case OperationSucceeded, OperationFailed, OperationCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// OperationError is an error related to a specific path processed by an operation.
+type OperationError struct {
+	Path string
+	Err  error
+}
+
+func (e OperationError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
 type OperationProgress struct {
 	Total      int
 	Done       int
 	Failed     int
 	Skipped    int
 	Processing []string
+	BytesTotal int64
+	BytesDone  int64
+	Errors     []OperationError // cumulative list of errors for individual paths
 }
 
+type ProgressReporter = func(progress OperationProgress)
+
+// OperationFunc does the actual work. It should stop as soon as ctx is cancelled
+// and report progress regularly - reporting is where a paused operation waits to be resumed.
+type OperationFunc = func(ctx context.Context, reportProgress ProgressReporter) error
+
+// Operation is a long-running cancellable task like copying or deleting files.
+// All getters & controls are safe to be called from any goroutine.
 type Operation struct {
-	Type     OperationType
-	cancel   context.CancelFunc
-	done     chan error
-	progress OperationProgress
+	ID           int
+	Type         OperationType
+	Title        string
+	AffectedDirs []string // directories to be refreshed once the operation completes
+
+	mu             sync.Mutex
+	state          OperationState
+	progress       OperationProgress
+	err            error
+	started        time.Time
+	finished       time.Time
+	pausedAt       time.Time
+	pausedDuration time.Duration
+	resume         chan struct{} // not nil while paused, closed on resume
+	cancel         context.CancelFunc
+	done           chan struct{}
+	onChange       func(o *Operation)
 }
 
-type ProgressReporter = func(progress OperationProgress)
+var timeNow = time.Now
 
 func NewOperation(
 	t OperationType,
-	f func(ctx context.Context, reportProgress ProgressReporter) error,
+	f OperationFunc,
 	reportProgress ProgressReporter,
 ) *Operation {
-	o := &Operation{Type: t}
+	o := &Operation{Type: t, Title: string(t)}
+	o.start(f, reportProgress)
+	return o
+}
+
+func (o *Operation) start(f OperationFunc, reportProgress ProgressReporter) {
+	o.state = OperationRunning
+	o.started = timeNow()
+	o.done = make(chan struct{})
 	ctx := context.Background()
 	ctx, o.cancel = context.WithCancel(ctx)
 	go func() {
 		reportOperProgress := func(progress OperationProgress) {
+			o.mu.Lock()
 			o.progress = progress
+			o.mu.Unlock()
 			if reportProgress != nil {
 				reportProgress(progress)
 			}
+			o.changed()
+			o.waitIfPaused(ctx)
 		}
 		err := f(ctx, reportOperProgress)
-		o.done <- err
+		o.finish(ctx, err)
 	}()
-	return o
+}
+
+func (o *Operation) finish(ctx context.Context, err error) {
+	o.mu.Lock()
+	o.finished = timeNow()
+	o.err = err
+	if o.resume != nil {
+		o.pausedDuration += o.finished.Sub(o.pausedAt)
+		close(o.resume)
+		o.resume = nil
+	}
+	switch {
+	case ctx.Err() != nil:
+		o.state = OperationCancelled
+	case err != nil:
+		o.state = OperationFailed
+	default:
+		o.state = OperationSucceeded
+	}
+	o.mu.Unlock()
+	o.cancel()
+	close(o.done)
+	o.changed()
+}
+
+func (o *Operation) changed() {
+	if o.onChange != nil {
+		o.onChange(o)
+	}
+}
+
+func (o *Operation) waitIfPaused(ctx context.Context) {
+	o.mu.Lock()
+	resume := o.resume
+	o.mu.Unlock()
+	if resume == nil {
+		return
+	}
+	select {
+	case <-resume:
+	case <-ctx.Done():
+	}
+}
+
+// Done returns a channel that is closed when the operation finishes.
+func (o *Operation) Done() <-chan struct{} {
+	return o.done
+}
+
+// Wait blocks until the operation finishes and returns its error.
+func (o *Operation) Wait() error {
+	<-o.done
+	return o.Err()
+}
+
+// Cancel requests the operation to stop.
+func (o *Operation) Cancel() {
+	o.mu.Lock()
+	if o.state.IsFinished() {
+		o.mu.Unlock()
+		return
+	}
+	if o.resume != nil {
+		o.pausedDuration += timeNow().Sub(o.pausedAt)
+		close(o.resume)
+		o.resume = nil
+	}
+	o.mu.Unlock()
+	o.cancel()
+}
+
+// Pause suspends the operation at its next progress report.
+func (o *Operation) Pause() {
+	o.mu.Lock()
+	if o.state != OperationRunning {
+		o.mu.Unlock()
+		return
+	}
+	o.state = OperationPaused
+	o.pausedAt = timeNow()
+	o.resume = make(chan struct{})
+	o.mu.Unlock()
+	o.changed()
+}
+
+// Resume continues a paused operation.
+func (o *Operation) Resume() {
+	o.mu.Lock()
+	if o.state != OperationPaused {
+		o.mu.Unlock()
+		return
+	}
+	o.state = OperationRunning
+	o.pausedDuration += timeNow().Sub(o.pausedAt)
+	close(o.resume)
+	o.resume = nil
+	o.mu.Unlock()
+	o.changed()
+}
+
+// State returns current state of the operation.
+func (o *Operation) State() OperationState {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.state
+}
+
+// Progress returns the last reported progress.
+func (o *Operation) Progress() OperationProgress {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.progress
+}
+
+// Err returns the error the operation finished with, if any.
+func (o *Operation) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
+
+// Errors returns errors for individual paths reported by the operation.
+func (o *Operation) Errors() []OperationError {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	errs := make([]OperationError, len(o.progress.Errors))
+	copy(errs, o.progress.Errors)
+	return errs
+}
+
+// Elapsed returns for how long the operation has been running, excluding pauses.
+func (o *Operation) Elapsed() time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	end := o.finished
+	if end.IsZero() {
+		end = timeNow()
+	}
+	paused := o.pausedDuration
+	if o.resume != nil {
+		paused += end.Sub(o.pausedAt)
+	}
+	return end.Sub(o.started) - paused
+}
+
+// Throughput returns bytes per second if the operation reports bytes, otherwise items per second.
+func (o *Operation) Throughput() (perSecond float64, isBytes bool) {
+	elapsed := o.Elapsed().Seconds()
+	progress := o.Progress()
+	isBytes = progress.BytesTotal > 0
+	if elapsed <= 0 {
+		return 0, isBytes
+	}
+	if isBytes {
+		return float64(progress.BytesDone) / elapsed, true
+	}
+	return float64(progress.Done) / elapsed, false
+}
+
+// ETA estimates remaining time based on average throughput. Returns false if it can not be estimated.
+func (o *Operation) ETA() (time.Duration, bool) {
+	if o.State().IsFinished() {
+		return 0, false
+	}
+	perSecond, isBytes := o.Throughput()
+	if perSecond <= 0 {
+		return 0, false
+	}
+	progress := o.Progress()
+	var remaining float64
+	if isBytes {
+		remaining = float64(progress.BytesTotal - progress.BytesDone)
+	} else {
+		if progress.Total == 0 {
+			return 0, false
+		}
+		remaining = float64(progress.Total - progress.Done - progress.Failed - progress.Skipped)
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	seconds := remaining / perSecond
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// Percent returns completion percentage in the range 0..100.
+// Totals are estimates, e.g. files may grow while being copied, so done is never shown beyond the total.
+func (o *Operation) Percent() int {
+	progress := o.Progress()
+	if progress.BytesTotal > 0 {
+		return clampPercent(progress.BytesDone * 100 / progress.BytesTotal)
+	}
+	if progress.Total > 0 {
+		processed := progress.Done + progress.Failed + progress.Skipped
+		return clampPercent(int64(processed * 100 / progress.Total))
+	}
+	if o.State().IsFinished() {
+		return 100
+	}
+	return 0
+}
+
+func clampPercent(percent int64) int {
+	return int(min(max(percent, 0), 100))
 }
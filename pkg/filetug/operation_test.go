@@ -0,0 +1,156 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperation_States(t *testing.T) {
+	t.Parallel()
+
+	t.Run("succeeded", func(t *testing.T) {
+		o := NewOperation("test", func(ctx context.Context, reportProgress ProgressReporter) error {
+			reportProgress(OperationProgress{Total: 2, Done: 2})
+			return nil
+		}, nil)
+		assert.NoError(t, o.Wait())
+		assert.Equal(t, OperationSucceeded, o.State())
+		assert.Equal(t, 100, o.Percent())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		o := NewOperation("test", func(ctx context.Context, reportProgress ProgressReporter) error {
+			reportProgress(OperationProgress{
+				Total:  1,
+				Failed: 1,
+				Errors: []OperationError{{Path: "/a", Err: errFailed}},
+			})
+			return errFailed
+		}, nil)
+		assert.ErrorIs(t, o.Wait(), errFailed)
+		assert.Equal(t, OperationFailed, o.State())
+		errs := o.Errors()
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "/a: failed", errs[0].Error())
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		started := make(chan struct{})
+		o := NewOperation("test", func(ctx context.Context, reportProgress ProgressReporter) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil)
+		<-started
+		o.Cancel()
+		assert.ErrorIs(t, o.Wait(), context.Canceled)
+		assert.Equal(t, OperationCancelled, o.State())
+		o.Cancel() // no-op for finished operation
+	})
+}
+
+func TestOperation_PauseResume(t *testing.T) {
+	t.Parallel()
+	reported := make(chan struct{})
+	proceed := make(chan struct{})
+	var afterReport bool
+	o := NewOperation("test", func(ctx context.Context, reportProgress ProgressReporter) error {
+		<-proceed
+		reportProgress(OperationProgress{Total: 2, Done: 1})
+		afterReport = true
+		close(reported)
+		return nil
+	}, nil)
+	o.Pause()
+	assert.Equal(t, OperationPaused, o.State())
+	o.Pause() // no-op when already paused
+	close(proceed)
+
+	select {
+	case <-reported:
+		t.Fatal("paused operation should wait at progress report")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Equal(t, 50, o.Percent())
+
+	o.Resume()
+	assert.NoError(t, o.Wait())
+	assert.True(t, afterReport)
+	assert.Equal(t, OperationSucceeded, o.State())
+	o.Resume() // no-op when not paused
+}
+
+func TestOperation_CancelWhilePaused(t *testing.T) {
+	t.Parallel()
+	o := NewOperation("test", func(ctx context.Context, reportProgress ProgressReporter) error {
+		reportProgress(OperationProgress{Total: 1})
+		return ctx.Err()
+	}, nil)
+	o.Pause()
+	o.Cancel()
+	_ = o.Wait()
+	assert.Equal(t, OperationCancelled, o.State())
+}
+
+func TestOperation_ThroughputAndETA(t *testing.T) {
+	t.Parallel()
+
+	t.Run("items", func(t *testing.T) {
+		o := &Operation{
+			state:    OperationRunning,
+			started:  time.Now().Add(-10 * time.Second),
+			progress: OperationProgress{Total: 20, Done: 10},
+		}
+		perSecond, isBytes := o.Throughput()
+		assert.False(t, isBytes)
+		assert.InDelta(t, 1.0, perSecond, 0.1)
+		eta, ok := o.ETA()
+		assert.True(t, ok)
+		assert.InDelta(t, 10*time.Second, eta, float64(time.Second))
+		assert.Equal(t, 50, o.Percent())
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		o := &Operation{
+			state:    OperationRunning,
+			started:  time.Now().Add(-4 * time.Second),
+			progress: OperationProgress{BytesTotal: 400, BytesDone: 100},
+		}
+		perSecond, isBytes := o.Throughput()
+		assert.True(t, isBytes)
+		assert.InDelta(t, 25.0, perSecond, 1)
+		eta, ok := o.ETA()
+		assert.True(t, ok)
+		assert.InDelta(t, 12*time.Second, eta, float64(time.Second))
+		assert.Equal(t, 25, o.Percent())
+	})
+
+	t.Run("excludes_pauses", func(t *testing.T) {
+		now := time.Now()
+		o := &Operation{
+			state:          OperationSucceeded,
+			started:        now.Add(-10 * time.Second),
+			finished:       now,
+			pausedDuration: 6 * time.Second,
+		}
+		assert.Equal(t, 4*time.Second, o.Elapsed())
+		_, ok := o.ETA()
+		assert.False(t, ok, "no ETA for finished operation")
+	})
+
+	t.Run("unknown_total", func(t *testing.T) {
+		o := &Operation{
+			state:    OperationRunning,
+			started:  time.Now().Add(-time.Second),
+			progress: OperationProgress{Done: 5},
+		}
+		_, ok := o.ETA()
+		assert.False(t, ok)
+		assert.Equal(t, 0, o.Percent())
+	})
+}
@@ -0,0 +1,109 @@
+package filetug
+
+import (
+	"sync"
+	"time"
+)
+
+// OperationsManager is a registry of running and finished operations.
+type OperationsManager struct {
+	mu         sync.Mutex
+	nextID     int
+	operations []*Operation
+	lastNotify map[int]time.Time
+	lastState  map[int]OperationState
+
+	// onChange is called from operation goroutines when an unfinished operation makes progress or changes state.
+	// Progress notifications are throttled, state changes are always reported.
+	onChange func(o *Operation)
+	// onDone is called from operation goroutine once an operation is finished (instead of onChange).
+	onDone func(o *Operation)
+}
+
+// operationNotifyInterval limits how often progress updates are propagated to UI.
+const operationNotifyInterval = 100 * time.Millisecond
+
+func NewOperationsManager(onChange, onDone func(o *Operation)) *OperationsManager {
+	return &OperationsManager{
+		onChange:   onChange,
+		onDone:     onDone,
+		lastNotify: make(map[int]time.Time),
+		lastState:  make(map[int]OperationState),
+	}
+}
+
+// Start registers and starts a new operation.
+func (m *OperationsManager) Start(t OperationType, title string, affectedDirs []string, f OperationFunc) *Operation {
+	m.mu.Lock()
+	m.nextID++
+	o := &Operation{
+		ID:           m.nextID,
+		Type:         t,
+		Title:        title,
+		AffectedDirs: affectedDirs,
+	}
+	m.operations = append(m.operations, o)
+	m.mu.Unlock()
+
+	o.onChange = m.operationChanged
+	o.start(f, nil)
+	return o
+}
+
+func (m *OperationsManager) operationChanged(o *Operation) {
+	state := o.State()
+	now := timeNow()
+	m.mu.Lock()
+	stateChanged := m.lastState[o.ID] != state
+	m.lastState[o.ID] = state
+	throttled := !stateChanged && now.Sub(m.lastNotify[o.ID]) < operationNotifyInterval
+	if !throttled {
+		m.lastNotify[o.ID] = now
+	}
+	m.mu.Unlock()
+	if state.IsFinished() {
+		if stateChanged && m.onDone != nil {
+			m.onDone(o)
+		}
+		return
+	}
+	if !throttled && m.onChange != nil {
+		m.onChange(o)
+	}
+}
+
+// Operations returns a snapshot of all registered operations in order of start.
+func (m *OperationsManager) Operations() []*Operation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	operations := make([]*Operation, len(m.operations))
+	copy(operations, m.operations)
+	return operations
+}
+
+// RunningCount returns number of operations that are not finished yet.
+func (m *OperationsManager) RunningCount() int {
+	var count int
+	for _, o := range m.Operations() {
+		if !o.State().IsFinished() {
+			count++
+		}
+	}
+	return count
+}
+
+// ClearFinished removes finished operations from the registry.
+func (m *OperationsManager) ClearFinished() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	running := make([]*Operation, 0, len(m.operations))
+	for _, o := range m.operations {
+		if o.State().IsFinished() {
+			delete(m.lastNotify, o.ID)
+			delete(m.lastState, o.ID)
+			continue
+		}
+		running = append(running, o)
+	}
+	m.operations = running
+}
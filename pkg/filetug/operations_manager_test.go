@@ -0,0 +1,57 @@
+package filetug
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationsManager(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var changes int
+	done := make(chan *Operation, 2)
+	m := NewOperationsManager(func(o *Operation) {
+		mu.Lock()
+		changes++
+		mu.Unlock()
+	}, func(o *Operation) {
+		done <- o
+	})
+
+	proceed := make(chan struct{})
+	first := m.Start("test", "First", []string{"/a"}, func(ctx context.Context, reportProgress ProgressReporter) error {
+		for i := 1; i <= 100; i++ {
+			reportProgress(OperationProgress{Total: 100, Done: i})
+		}
+		return nil
+	})
+	second := m.Start("test", "Second", nil, func(ctx context.Context, reportProgress ProgressReporter) error {
+		<-proceed
+		return nil
+	})
+	assert.Equal(t, 1, first.ID)
+	assert.Equal(t, 2, second.ID)
+	assert.Equal(t, "First", first.Title)
+	assert.Equal(t, []string{"/a"}, first.AffectedDirs)
+
+	assert.Equal(t, first, <-done)
+	assert.NoError(t, first.Wait())
+	assert.Equal(t, 1, m.RunningCount())
+
+	mu.Lock()
+	assert.Less(t, changes, 100, "progress notifications should be throttled")
+	mu.Unlock()
+
+	m.ClearFinished()
+	assert.Equal(t, []*Operation{second}, m.Operations())
+
+	close(proceed)
+	assert.Equal(t, second, <-done)
+	assert.Equal(t, 0, m.RunningCount())
+	m.ClearFinished()
+	assert.Empty(t, m.Operations())
+}
@@ -1,7 +1,265 @@
 package filetug
 
-//import "github.com/filetug/filetug/pkg/sneatv"
-//
-//type operationsPanel struct {
-//	*sneatv.Boxed
-//}
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/filetug/filetug/pkg/fsutils"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// operationsPanel lists running & finished operations with their progress.
+type operationsPanel struct {
+	*sneatv.Boxed
+	nav        *Navigator
+	flex       *tview.Flex
+	table      *tview.Table
+	details    *tview.TextView
+	operations []*Operation
+}
+
+const operationProgressBarWidth = 12
+
+func (nav *Navigator) showOperationsPanel() {
+	if nav.operationsPanel == nil {
+		nav.operationsPanel = newOperationsPanel(nav)
+	}
+	nav.operationsPanel.render()
+	nav.right.SetContent(nav.operationsPanel)
+	nav.app.SetFocus(nav.operationsPanel.table)
+}
+
+func newOperationsPanel(nav *Navigator) *operationsPanel {
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	table := tview.NewTable()
+	table.SetSelectable(true, false)
+	table.SetFixed(1, 0)
+	details := tview.NewTextView()
+	details.SetDynamicColors(true)
+	details.SetWrap(true)
+	footer := tview.NewTextView().
+		SetText("p: pause/resume · c: cancel · x: clear finished").
+		SetTextColor(tcell.ColorGray)
+	flex.AddItem(table, 0, 2, true)
+	flex.AddItem(details, 0, 1, false)
+	p := &operationsPanel{
+		nav:     nav,
+		flex:    flex,
+		table:   table,
+		details: details,
+		Boxed: sneatv.NewBoxed(
+			flex,
+			sneatv.WithLeftBorder(0, -1),
+			sneatv.WithFooter(footer),
+		),
+	}
+	p.SetTitle("Operations")
+	table.SetInputCapture(p.inputCapture)
+	table.SetSelectionChangedFunc(func(row, _ int) {
+		p.showDetails(row)
+	})
+	table.SetFocusFunc(func() {
+		nav.activeCol = 2
+	})
+	return p
+}
+
+func (p *operationsPanel) render() {
+	row, _ := p.table.GetSelection()
+	p.operations = p.nav.operations.Operations()
+	p.table.Clear()
+	headers := []string{"Operation", "State", "Progress", "Speed", "ETA"}
+	for col, header := range headers {
+		cell := tview.NewTableCell(header)
+		cell.SetTextColor(tcell.ColorGray)
+		cell.SetSelectable(false)
+		if col == 0 {
+			cell.SetExpansion(1)
+		}
+		p.table.SetCell(0, col, cell)
+	}
+	for i, o := range p.operations {
+		cells := []string{
+			tview.Escape(o.Title),
+			operationStateText(o.State()),
+			operationProgressText(o),
+			operationThroughputText(o),
+			operationETAText(o),
+		}
+		for col, text := range cells {
+			cell := tview.NewTableCell(text)
+			cell.SetReference(o)
+			if col == 0 {
+				cell.SetExpansion(1)
+			}
+			p.table.SetCell(i+1, col, cell)
+		}
+	}
+	if len(p.operations) == 0 {
+		p.table.SetCell(1, 0, tview.NewTableCell("[::i]No operations[::-]").SetTextColor(tcell.ColorGray))
+	}
+	if row < 1 {
+		row = 1
+	}
+	if row > len(p.operations) {
+		row = len(p.operations)
+	}
+	p.table.Select(row, 0)
+	p.showDetails(row)
+}
+
+func (p *operationsPanel) selectedOperation(row int) *Operation {
+	i := row - 1
+	if i < 0 || i >= len(p.operations) {
+		return nil
+	}
+	return p.operations[i]
+}
+
+func (p *operationsPanel) showDetails(row int) {
+	o := p.selectedOperation(row)
+	if o == nil {
+		p.details.SetText("")
+		return
+	}
+	var sb strings.Builder
+	progress := o.Progress()
+	_, _ = fmt.Fprintf(&sb, "[ghostwhite]%s[-]\n", tview.Escape(o.Title))
+	_, _ = fmt.Fprintf(&sb, "Done: %d, Failed: %d, Skipped: %d, Total: %d\n",
+		progress.Done, progress.Failed, progress.Skipped, progress.Total)
+	for _, processing := range progress.Processing {
+		_, _ = fmt.Fprintf(&sb, "[gray]→ %s[-]\n", tview.Escape(processing))
+	}
+	if err := o.Err(); err != nil {
+		errText := err.Error()
+		_, _ = fmt.Fprintf(&sb, "[red]%s[-]\n", tview.Escape(errText))
+	}
+	for _, opErr := range o.Errors() {
+		errText := opErr.Error()
+		_, _ = fmt.Fprintf(&sb, "[orangered]✗ %s[-]\n", tview.Escape(errText))
+	}
+	p.details.SetText(sb.String())
+}
+
+func (p *operationsPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	row, _ := p.table.GetSelection()
+	o := p.selectedOperation(row)
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.nav.right.SetContent(p.nav.previewer)
+		p.nav.app.SetFocus(p.nav.files)
+		return nil
+	case tcell.KeyLeft:
+		p.nav.app.SetFocus(p.nav.files)
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'p', 'P', ' ':
+			if o != nil {
+				if o.State() == OperationPaused {
+					o.Resume()
+				} else {
+					o.Pause()
+				}
+				p.render()
+			}
+			return nil
+		case 'c', 'C':
+			if o != nil {
+				o.Cancel()
+				p.render()
+			}
+			return nil
+		case 'x', 'X':
+			p.nav.operations.ClearFinished()
+			p.render()
+			return nil
+		}
+		return event
+	default:
+		return event
+	}
+}
+
+func operationStateText(state OperationState) string {
+	switch state {
+	case OperationRunning:
+		return "[lightgreen]running[-]"
+	case OperationPaused:
+		return "[yellow]paused[-]"
+	case OperationFailed:
+		return "[red]failed[-]"
+	case OperationCancelled:
+		return "[gray]cancelled[-]"
+	default:
+		return string(state)
+	}
+}
+
+func operationProgressText(o *Operation) string {
+	percent := o.Percent()
+	filled := min(max(percent*operationProgressBarWidth/100, 0), operationProgressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", operationProgressBarWidth-filled)
+	return fmt.Sprintf("%s %3d%%", bar, percent)
+}
+
+func operationThroughputText(o *Operation) string {
+	perSecond, isBytes := o.Throughput()
+	if perSecond <= 0 {
+		return ""
+	}
+	if isBytes {
+		sizeText := fsutils.GetSizeShortText(int64(perSecond))
+		return sizeText + "/s"
+	}
+	return fmt.Sprintf("%.1f/s", perSecond)
+}
+
+func operationETAText(o *Operation) string {
+	eta, ok := o.ETA()
+	if !ok {
+		return ""
+	}
+	rounded := eta.Round(time.Second)
+	return rounded.String()
+}
+
+// onOperationChanged is called from an operation goroutine.
+func (nav *Navigator) onOperationChanged(_ *Operation) {
+	nav.app.QueueUpdateDraw(nav.renderOperationsPanelIfVisible)
+}
+
+func (nav *Navigator) renderOperationsPanelIfVisible() {
+	if nav.operationsPanel != nil && nav.right.content == nav.operationsPanel {
+		nav.operationsPanel.render()
+	}
+}
+
+// onOperationDone is called from an operation goroutine once it has finished.
+func (nav *Navigator) onOperationDone(o *Operation) {
+	nav.app.QueueUpdateDraw(func() {
+		nav.renderOperationsPanelIfVisible()
+		nav.renderHistoryPanelIfVisible()
+		switch o.State() {
+		case OperationFailed:
+			err := o.Err()
+			nav.showError(fmt.Errorf("%s: %w", o.Title, err))
+		case OperationSucceeded:
+			if failed := o.Progress().Failed; failed > 0 {
+				nav.notify(notificationWarning, o.Title, fmt.Sprintf("done, %d failed", failed))
+			} else {
+				nav.notify(notificationInfo, o.Title, "done")
+			}
+		}
+		currentDirPath := nav.currentDirPath()
+		for _, dir := range o.AffectedDirs {
+			if dir == currentDirPath {
+				nav.refreshCurrentDir()
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package filetug
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// drainQueuedUpdates executes all updates queued so far.
+func drainQueuedUpdates(updates chan func()) {
+	for {
+		select {
+		case f := <-updates:
+			f()
+		default:
+			return
+		}
+	}
+}
+
+func TestOperationsPanel(t *testing.T) {
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+	nav.showOperationsPanel()
+	p := nav.operationsPanel
+	assert.Equal(t, p, nav.right.content)
+	assert.Contains(t, p.table.GetCell(1, 0).Text, "No operations")
+
+	proceed := make(chan struct{})
+	o := nav.operations.Start("test", "Copy [stuff]", nil, func(ctx context.Context, reportProgress ProgressReporter) error {
+		reportProgress(OperationProgress{Total: 4, Done: 1, Processing: []string{"/a.txt"}})
+		select {
+		case <-proceed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+	runQueuedUpdate(t, updates)
+	assert.Equal(t, "Copy [stuff[]", p.table.GetCell(1, 0).Text)
+	assert.Contains(t, p.table.GetCell(1, 1).Text, "running")
+	assert.Contains(t, p.table.GetCell(1, 2).Text, "25%")
+	assert.Contains(t, p.details.GetText(true), "/a.txt")
+
+	key := func(r rune) *tcell.EventKey {
+		return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+
+	t.Run("pause_resume", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(key('p')))
+		assert.Equal(t, OperationPaused, o.State())
+		assert.Contains(t, p.table.GetCell(1, 1).Text, "paused")
+		assert.Nil(t, p.inputCapture(key(' ')))
+		assert.Equal(t, OperationRunning, o.State())
+	})
+
+	t.Run("cancel_and_clear", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(key('c')))
+		_ = o.Wait()
+		drainQueuedUpdates(updates)
+		assert.Equal(t, OperationCancelled, o.State())
+		assert.Contains(t, p.table.GetCell(1, 1).Text, "cancelled")
+		assert.Nil(t, p.inputCapture(key('x')))
+		assert.Empty(t, nav.operations.Operations())
+		assert.Contains(t, p.table.GetCell(1, 0).Text, "No operations")
+	})
+
+	t.Run("other_keys", func(t *testing.T) {
+		event := key('z')
+		assert.Equal(t, event, p.inputCapture(event))
+		event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone)))
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		assert.Nil(t, p.inputCapture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+		assert.Equal(t, nav.previewer, nav.right.content)
+	})
+}
+
+func TestNavigator_OnOperationDone(t *testing.T) {
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+	var shownErr error
+	nav.showError = func(err error) {
+		shownErr = err
+	}
+
+	dir := t.TempDir()
+	nav.store = osfile.NewStore("/")
+	nav.current.SetDir(nav.NewDirContext(dir, nil))
+	nav.files.SetRows(NewFileRows(nav.NewDirContext(dir, nil)), true)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), nil, 0o644))
+
+	o := nav.operations.Start("test", "Failing", []string{dir}, func(ctx context.Context, reportProgress ProgressReporter) error {
+		return errors.New("boom")
+	})
+	_ = o.Wait()
+	runQueuedUpdate(t, updates) // operation is done
+
+	assert.EqualError(t, shownErr, "Failing: boom")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(nav.files.rows.VisibleEntries) == 0 && time.Now().Before(deadline) {
+		runQueuedUpdate(t, updates) // refresh of the affected current dir
+	}
+	assert.Len(t, nav.files.rows.VisibleEntries, 1)
+	assert.Equal(t, "new.txt", nav.files.rows.VisibleEntries[0].Name())
+}
+
+func TestOperationTexts(t *testing.T) {
+	o := &Operation{
+		state:    OperationRunning,
+		started:  time.Now().Add(-2 * time.Second),
+		progress: OperationProgress{BytesTotal: 4096, BytesDone: 2048},
+	}
+	assert.Contains(t, operationProgressText(o), " 50%")
+	assert.Contains(t, operationThroughputText(o), "/s")
+	assert.NotEmpty(t, operationETAText(o))
+
+	o = &Operation{state: OperationRunning, progress: OperationProgress{Total: 3}}
+	assert.Empty(t, operationThroughputText(o))
+	assert.Empty(t, operationETAText(o))
+
+	assert.Contains(t, operationStateText(OperationFailed), "failed")
+	assert.Equal(t, "done", operationStateText(OperationSucceeded))
+}
+
+func TestOperationProgressText_DoneBeyondTotal(t *testing.T) {
+	t.Parallel()
+	o := &Operation{state: OperationRunning, progress: OperationProgress{BytesTotal: 2065, BytesDone: 1 << 20}}
+	assert.Equal(t, 100, o.Percent(), "done beyond an estimated total is clamped")
+	assert.Equal(t, strings.Repeat("█", operationProgressBarWidth)+" 100%", operationProgressText(o))
+	o.progress = OperationProgress{Total: 2, Done: 3}
+	assert.Equal(t, 100, o.Percent())
+}
@@ -26,6 +26,7 @@ type previewerPanel struct {
 	sizeCell     *tview.TableCell
 	modCell      *tview.TableCell
 	separator    *tview.TextView
+	noteView     *tview.TextView // tags & a note of the previewed entry, hidden if it has none
 	previewer    viewers.Previewer
 	textView     *tview.TextView
 	dirPreviewer *viewers.DirPreviewer
@@ -43,17 +44,20 @@ func newPreviewerPanel(nav *Navigator) *previewerPanel {
 		nav.app.SetFocus(nav.files)
 	})
 	queueUpdateDraw := viewers.WithDirSummaryQueueUpdateDraw(nav.app.QueueUpdateDraw)
+	volumeInfo := viewers.WithDirSummaryVolumeInfo(nav.volumeInfoText)
+	gitStageChanged := viewers.WithDirSummaryGitStageChanged(nav.onGitStageChanged)
 	p := previewerPanel{
 		app: nav.app,
 		Boxed: sneatv.NewBoxed(
 			flex,
 			sneatv.WithLeftBorder(0, -1),
 		),
-		dirPreviewer: viewers.NewDirPreviewer(nav.app, filterSetter, focusLeft, queueUpdateDraw),
+		dirPreviewer: viewers.NewDirPreviewer(nav.app, filterSetter, focusLeft, queueUpdateDraw, volumeInfo, gitStageChanged),
 		rows:         flex,
 		attrsRow:     tview.NewFlex(),
 		separator:    separator,
 		textView:     tview.NewTextView(),
+		noteView:     tview.NewTextView().SetDynamicColors(true).SetWrap(true),
 		nav:          nav,
 	}
 	p.attrsRow.SetDirection(tview.FlexRow)
@@ -70,6 +74,7 @@ func newPreviewerPanel(nav *Navigator) *previewerPanel {
 	p.attrsRow.AddItem(p.fsAttrs, 0, 1, false)
 
 	p.rows.AddItem(p.attrsRow, 2, 0, false)
+	p.rows.AddItem(p.noteView, 0, 0, false)
 	p.rows.AddItem(p.separator, 1, 0, false)
 	//p.rows.AddItem(p.textView, 0, 1, false)
 
@@ -179,6 +184,7 @@ func (p *previewerPanel) PreviewEntry(entry files.EntryWithDirPath) {
 		_, name = path.Split(fullName)
 	}
 	p.SetTitle(name)
+	p.showTagsAndNote(entry)
 
 	var previewer viewers.Previewer
 
@@ -192,6 +198,21 @@ func (p *previewerPanel) PreviewEntry(entry files.EntryWithDirPath) {
 	p.previewer.PreviewSingle(entry, nil, nil)
 }
 
+// showTagsAndNote shows tags & a note of an entry above the preview.
+func (p *previewerPanel) showTagsAndNote(entry files.EntryWithDirPath) {
+	var lines []string
+	if e, ok := p.nav.entryTagsInfo(entry); ok {
+		if len(e.Tags) > 0 {
+			lines = append(lines, tagChips(e.Tags))
+		}
+		if e.Note != "" {
+			lines = append(lines, strings.Split(tview.Escape(e.Note), "\n")...)
+		}
+	}
+	p.noteView.SetText(strings.Join(lines, "\n"))
+	p.rows.ResizeItem(p.noteView, len(lines), 0)
+}
+
 func (p *previewerPanel) getFilePreviewer(name string) viewers.Previewer {
 	switch name {
 	case ".DS_Store":
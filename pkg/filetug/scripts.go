@@ -1,6 +1,7 @@
 package filetug
 
 import (
+	"github.com/filetug/filetug/pkg/filetug/ftsync"
 	"github.com/filetug/filetug/pkg/sneatv"
 	"github.com/rivo/tview"
 )
@@ -15,6 +16,24 @@ func (nav *Navigator) showScriptsPanel() {
 	list.AddItem("Nested Dirs Generator", "", '1', func() {
 		showNestedDirsGenerator(nav)
 	})
+	list.AddItem("Find duplicates", "", '2', nav.showFindDuplicates)
+	list.AddItem("Sync directories", "", '3', func() {
+		nav.showSyncPanel(ftsync.Profile{Source: nav.currentDirPath()})
+	})
+	list.AddItem("Compare directories", "", '4', nav.showCompareForm)
+	list.AddItem("Checksums", "", '5', nav.showChecksumPanel)
+	list.AddItem("Encrypt / decrypt", "", '6', nav.showEncryptPanel)
+	profiles, err := loadSyncProfiles()
+	if err != nil {
+		nav.showError(err)
+	}
+	for _, profile := range profiles {
+		list.AddItem("Sync: "+profile.Name, "", 0, func() {
+			if _, err := nav.syncProfile(profile); err != nil {
+				nav.showError(err)
+			}
+		})
+	}
 	p := &scriptsPanel{
 		Boxed: sneatv.NewBoxed(list),
 		list:  list,
@@ -0,0 +1,156 @@
+package filetug
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/filetug/filetug/pkg/filetug/fttags"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tagsBrowser lists all tags in the left container. Entries having the tag under the cursor are shown
+// in the files panel, Space adds a tag to a combination & entries having all combined tags are shown.
+type tagsBrowser struct {
+	*sneatv.Boxed
+	nav      *Navigator
+	list     *tview.List
+	footer   *tview.TextView
+	tags     []fttags.TagCount
+	combined []string
+}
+
+func newTagsBrowser(nav *Navigator) *tagsBrowser {
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	footer := tview.NewTextView().SetDynamicColors(true).SetTextColor(tcell.ColorGray)
+	footer.SetText("space combine · ⏎ browse · esc back")
+	b := &tagsBrowser{
+		nav:    nav,
+		list:   list,
+		footer: footer,
+		Boxed: sneatv.NewBoxed(
+			list,
+			sneatv.WithLeftBorder(1, -1),
+			sneatv.WithFooter(footer),
+		),
+	}
+	b.SetTitle("Tags")
+	list.SetInputCapture(b.inputCapture)
+	list.SetChangedFunc(b.changed)
+	return b
+}
+
+func (nav *Navigator) showTagsBrowser() {
+	if nav.tagsBrowser == nil {
+		nav.tagsBrowser = newTagsBrowser(nav)
+	}
+	b := nav.tagsBrowser
+	b.combined = nil
+	b.setTags(nav.tags.Tags())
+	nav.left.SetContent(b)
+	nav.app.SetFocus(b.list)
+	b.showTagged()
+}
+
+func (nav *Navigator) refreshTagsBrowserIfVisible() {
+	if nav.tagsBrowser != nil && nav.left.content == nav.tagsBrowser {
+		nav.tagsBrowser.setTags(nav.tags.Tags())
+	}
+}
+
+func (b *tagsBrowser) setTags(tags []fttags.TagCount) {
+	current := b.currentTag()
+	b.tags = tags
+	b.combined = slices.DeleteFunc(b.combined, func(tag string) bool {
+		return !slices.ContainsFunc(tags, func(t fttags.TagCount) bool { return t.Tag == tag })
+	})
+	b.list.SetChangedFunc(nil)
+	b.list.Clear()
+	selected := 0
+	for i, t := range tags {
+		if t.Tag == current {
+			selected = i
+		}
+		mark := "  "
+		if slices.Contains(b.combined, t.Tag) {
+			mark = "✓ "
+		}
+		b.list.AddItem(fmt.Sprintf("%s%s [gray](%d)[-]", mark, tagChips([]string{t.Tag}), t.Count), "", 0, nil)
+	}
+	if len(tags) > 0 {
+		b.list.SetCurrentItem(selected)
+	}
+	b.list.SetChangedFunc(b.changed)
+}
+
+// currentTag returns the tag under the cursor, empty if there are no tags.
+func (b *tagsBrowser) currentTag() string {
+	i := b.list.GetCurrentItem()
+	if i < 0 || i >= len(b.tags) {
+		return ""
+	}
+	return b.tags[i].Tag
+}
+
+// selectedTags returns the combined tags, the tag under the cursor if none are combined.
+func (b *tagsBrowser) selectedTags() []string {
+	if len(b.combined) > 0 {
+		return b.combined
+	}
+	if tag := b.currentTag(); tag != "" {
+		return []string{tag}
+	}
+	return nil
+}
+
+func (b *tagsBrowser) showTagged() {
+	if tags := b.selectedTags(); len(tags) > 0 {
+		b.nav.showTagged(tags)
+	}
+}
+
+// changed shows entries having the tag under the cursor unless tags are combined.
+func (b *tagsBrowser) changed(index int, _ string, _ string, _ rune) {
+	if len(b.combined) == 0 && index >= 0 && index < len(b.tags) {
+		b.nav.showTagged([]string{b.tags[index].Tag})
+	}
+}
+
+// toggleCurrent adds the tag under the cursor to the combination or removes it from there.
+func (b *tagsBrowser) toggleCurrent() {
+	tag := b.currentTag()
+	if tag == "" {
+		return
+	}
+	if i := slices.Index(b.combined, tag); i >= 0 {
+		b.combined = slices.Delete(b.combined, i, i+1)
+	} else {
+		b.combined = append(b.combined, tag)
+	}
+	b.setTags(b.tags)
+	b.showTagged()
+}
+
+func (b *tagsBrowser) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEnter, tcell.KeyRight:
+		b.showTagged()
+		b.nav.app.SetFocus(b.nav.files.table)
+		return nil
+	case tcell.KeyEscape:
+		if b.nav.isTaggedShown() {
+			b.nav.closeTagged()
+		}
+		b.nav.left.SetContent(b.nav.dirsTree)
+		b.nav.app.SetFocus(b.nav.dirsTree)
+		return nil
+	case tcell.KeyRune:
+		if event.Rune() == ' ' {
+			b.toggleCurrent()
+			return nil
+		}
+	}
+	return event
+}
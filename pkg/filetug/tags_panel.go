@@ -0,0 +1,136 @@
+package filetug
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/filetug/fttags"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tagsPanel edits tags of the current entry or the selection. A note can be edited for a single entry only.
+// Tags common to all entries are shown, removing one of them removes it from all entries.
+type tagsPanel struct {
+	*sneatv.Boxed
+	nav     *Navigator
+	flex    *tview.Flex
+	form    *tview.Form
+	tags    *tview.InputField
+	note    *tview.TextArea // nil for multiple entries
+	mirror  *tview.Checkbox
+	status  *tview.TextView
+	entries []fttags.Entry
+	common  []string // tags all entries have when the panel was opened
+}
+
+func (nav *Navigator) showTagsPanel() {
+	selected := nav.selectedEntries()
+	if len(selected) == 0 {
+		return
+	}
+	p := newTagsPanel(nav, selected)
+	nav.right.SetContent(p)
+	nav.app.SetFocus(p.form)
+}
+
+func newTagsPanel(nav *Navigator, selected []files.EntryWithDirPath) *tagsPanel {
+	p := &tagsPanel{
+		nav:    nav,
+		flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		form:   tview.NewForm(),
+		status: tview.NewTextView().SetDynamicColors(true),
+	}
+	for i, entry := range selected {
+		e, _ := nav.entryTagsInfo(entry)
+		e.IsDir = entry.IsDir()
+		p.entries = append(p.entries, e)
+		if i == 0 {
+			p.common = e.Tags
+		} else {
+			p.common = slices.DeleteFunc(slices.Clone(p.common), func(tag string) bool {
+				return !slices.Contains(e.Tags, tag)
+			})
+		}
+	}
+	p.tags = tview.NewInputField().SetLabel("Tags").SetText(strings.Join(p.common, ", ")).
+		SetPlaceholder("comma or space separated")
+	p.form.AddFormItem(p.tags)
+	height := 7
+	if len(p.entries) == 1 {
+		p.note = tview.NewTextArea().SetText(p.entries[0].Note, false)
+		p.form.AddFormItem(p.note.SetLabel("Note"))
+		height += 4
+	}
+	p.mirror = tview.NewCheckbox().SetLabel("Mirror to xattrs").SetChecked(nav.tags.MirrorXattrs())
+	p.form.AddFormItem(p.mirror)
+	p.form.AddButton("Save", p.save)
+	p.form.AddButton("Cancel", p.close)
+	p.form.SetInputCapture(p.inputCapture)
+
+	p.flex.AddItem(p.form, height, 0, true)
+	p.flex.AddItem(p.status, 0, 1, false)
+
+	p.Boxed = sneatv.NewBoxed(p.flex, sneatv.WithLeftBorder(0, -1))
+	if len(p.entries) == 1 {
+		p.SetTitle("Tags of " + tview.Escape(p.entries[0].Path))
+	} else {
+		p.SetTitle(fmt.Sprintf("Tags of %d item(s)", len(p.entries)))
+	}
+	p.status.SetText("[gray]Tags are mirrored to " + fttags.XattrName + " of local files if checked[-]")
+	return p
+}
+
+// save applies added & removed tags to all entries & persists the index.
+// Failures to mirror tags into xattrs are reported but don't prevent saving.
+func (p *tagsPanel) save() {
+	typed := fttags.ParseTags(p.tags.GetText())
+	var errs []error
+	if mirror := p.mirror.IsChecked(); mirror != p.nav.tags.MirrorXattrs() {
+		if err := p.nav.tags.SetMirrorXattrs(mirror); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, e := range p.entries {
+		tags := slices.DeleteFunc(e.Tags, func(tag string) bool {
+			return slices.Contains(p.common, tag) && !slices.Contains(typed, tag)
+		})
+		e.Tags = append(tags, typed...)
+		if p.note != nil {
+			e.Note = p.note.GetText()
+		}
+		if err := p.nav.tags.Put(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	p.nav.saveTags()
+	if err := errors.Join(errs...); err != nil {
+		p.nav.notifyError("Tags", err)
+	}
+	p.close()
+	p.nav.renderTaggedIfVisible()
+	p.nav.refreshTagsBrowserIfVisible()
+}
+
+func (p *tagsPanel) close() {
+	p.nav.right.SetContent(p.nav.previewer)
+	p.nav.app.SetFocus(p.nav.files)
+}
+
+func (p *tagsPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.close()
+		return nil
+	case tcell.KeyEnter:
+		if p.tags.HasFocus() {
+			p.save()
+			return nil
+		}
+	}
+	return event
+}
@@ -1,6 +1,11 @@
 package filetug
 
-import "github.com/rivo/tview"
+import (
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
 
 // testApp is a minimal navigator.App implementation for tests that need
 // a deterministic QueueUpdateDraw hook without gomock expectations.
@@ -31,3 +36,27 @@ func (a *testApp) SetRoot(root tview.Primitive, fullscreen bool) {
 func (a *testApp) Stop() {}
 
 func (a *testApp) EnableMouse(_ bool) {}
+
+// newNavigatorWithQueuedUpdates creates a navigator which app queues updates into the returned channel,
+// so tests can wait for results of background goroutines.
+func newNavigatorWithQueuedUpdates(t *testing.T) (*Navigator, chan func()) {
+	t.Helper()
+	updates := make(chan func(), 100)
+	app := &testApp{queueUpdateDraw: func(f func()) {
+		updates <- f
+	}}
+	nav := NewNavigator(app, withSkipAsyncFavoritesLoad())
+	nav.saveCurrentDir = func(string, string) {}
+	return nav, updates
+}
+
+// runQueuedUpdate waits for a single queued update and executes it.
+func runQueuedUpdate(t *testing.T, updates chan func()) {
+	t.Helper()
+	select {
+	case f := <-updates:
+		f()
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for queued update")
+	}
+}
@@ -1,13 +1,67 @@
 package filetug
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+
+	"github.com/filetug/filetug/pkg/filetug/ftjournal"
+	"github.com/filetug/filetug/pkg/filetug/ftrename"
 )
 
 var testGlobalLock sync.Mutex
 
+// TestMain redirects journals to a temp dir so tests never write to the user's ~/.filetug.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "filetug-test-")
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	historyJournal := ftjournal.NewJournal(filepath.Join(dir, "history.jsonl"))
+	getHistoryJournal = func() (*ftjournal.Journal, error) {
+		return historyJournal, nil
+	}
+	renameJournal := ftrename.NewJournal(filepath.Join(dir, "rename-journal.jsonl"))
+	getRenameJournal = func() (*ftrename.Journal, error) {
+		return renameJournal, nil
+	}
+	afterToastDuration = func(func()) {} // toasts are hidden explicitly by tests
+	basketFilePath := filepath.Join(dir, basketFileName)
+	getBasketFilePath = func() (string, error) {
+		return basketFilePath, nil
+	}
+	userMasksFilePath := filepath.Join(dir, userMasksFileName)
+	getUserMasksFilePath = func() (string, error) {
+		return userMasksFilePath, nil
+	}
+	queryHistoryFilePath := filepath.Join(dir, queryHistoryFileName)
+	getQueryHistoryFilePath = func() (string, error) {
+		return queryHistoryFilePath, nil
+	}
+	listsFilePath := filepath.Join(dir, listsFileName)
+	getListsFilePath = func() (string, error) {
+		return listsFilePath, nil
+	}
+	tagsFilePath := filepath.Join(dir, tagsFileName)
+	getTagsFilePath = func() (string, error) {
+		return tagsFilePath, nil
+	}
+	frecencyFilePath := filepath.Join(dir, frecencyFileName)
+	getFrecencyFilePath = func() (string, error) {
+		return frecencyFilePath, nil
+	}
+	marksFilePath := filepath.Join(dir, marksFileName)
+	getMarksFilePath = func() (string, error) {
+		return marksFilePath, nil
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func withTestGlobalLock(t *testing.T) {
 	t.Helper()
 	testGlobalLock.Lock()
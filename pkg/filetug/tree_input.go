@@ -10,6 +10,14 @@ import (
 )
 
 func (t *Tree) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if t.searchPattern == "" {
+		if event = t.nav.marksInputCapture(event); event == nil {
+			return nil
+		}
+	}
+	if event = t.nav.entryActionsInputCapture(event); event == nil {
+		return nil
+	}
 	switch event.Key() {
 	case tcell.KeyRight:
 		t.nav.app.SetFocus(t.nav.files)
@@ -0,0 +1,163 @@
+package filetug
+
+import (
+	"fmt"
+
+	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
+	"github.com/filetug/filetug/pkg/fsutils"
+	"github.com/filetug/filetug/pkg/sneatv"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// volumesPanel lists mounted volumes with their free space and allows to jump to one.
+type volumesPanel struct {
+	*sneatv.Boxed
+	nav        *Navigator
+	flex       *tview.Flex
+	list       *tview.List
+	volumes    []fsutils.Volume
+	showPseudo bool
+}
+
+var listVolumes = fsutils.ListVolumes
+var getDiskUsage = fsutils.GetDiskUsage
+
+func (nav *Navigator) showVolumes() {
+	if nav.volumes == nil {
+		nav.volumes = newVolumesPanel(nav)
+	}
+	nav.prev = nav.current
+	nav.left.SetContent(nav.volumes)
+	nav.volumes.load()
+	nav.app.SetFocus(nav.volumes.list)
+}
+
+func newVolumesPanel(nav *Navigator) *volumesPanel {
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.SetTitle(" Volumes ")
+	list := tview.NewList()
+	list.SetSecondaryTextColor(tcell.ColorGray)
+	footer := tview.NewTextView().
+		SetText("<esc> back · p: pseudo fs").
+		SetTextColor(tcell.ColorGray)
+	p := &volumesPanel{
+		nav:  nav,
+		flex: flex,
+		list: list,
+		Boxed: sneatv.NewBoxed(
+			flex,
+			sneatv.WithLeftBorder(1, -1),
+			sneatv.WithFooter(footer),
+		),
+	}
+	flex.AddItem(list, 0, 1, true)
+	flex.SetFocusFunc(func() {
+		nav.activeCol = 0
+	})
+	list.SetInputCapture(p.inputCapture)
+	return p
+}
+
+// load reads mounted volumes in background as statfs may block on network mounts.
+func (p *volumesPanel) load() {
+	p.list.Clear()
+	p.list.AddItem("Loading...", "", 0, nil)
+	includePseudo := p.showPseudo
+	lister := listVolumes // capture before goroutine to avoid race on global
+	go func() {
+		volumes, err := lister(includePseudo)
+		p.nav.app.QueueUpdateDraw(func() {
+			if err != nil {
+				p.list.Clear()
+				errText := err.Error()
+				p.list.AddItem("[red]"+tview.Escape(errText)+"[-]", "", 0, nil)
+				return
+			}
+			p.setVolumes(volumes)
+		})
+	}()
+}
+
+func (p *volumesPanel) setVolumes(volumes []fsutils.Volume) {
+	p.volumes = volumes
+	p.list.Clear()
+	currentVolume, hasCurrent := fsutils.FindVolume(volumes, p.nav.currentDirPath())
+	for i, v := range volumes {
+		mainText := fmt.Sprintf("%s [darkgray::i]%s", tview.Escape(v.MountPoint), v.FSType)
+		secondaryText := volumeUsageText(v)
+		var shortcut rune
+		if i < 9 {
+			shortcut = '1' + rune(i)
+		}
+		volume := v
+		p.list.AddItem(mainText, secondaryText, shortcut, func() {
+			p.activate(volume)
+		})
+		if hasCurrent && v.MountPoint == currentVolume.MountPoint {
+			p.list.SetCurrentItem(i)
+		}
+	}
+	if len(volumes) == 0 {
+		p.list.AddItem("[::i]No volumes[::-]", "", 0, nil)
+	}
+}
+
+func volumeUsageText(v fsutils.Volume) string {
+	device := tview.Escape(v.Device)
+	if v.UsageErr != nil {
+		return fmt.Sprintf("%s · %v", device, v.UsageErr)
+	}
+	if v.Usage.Total == 0 {
+		return device
+	}
+	usedText := fsutils.GetSizeShortText(int64(v.Usage.Used))
+	return fmt.Sprintf("%s · used %s (%d%%), %s", device, usedText, v.Usage.UsedPercent(), v.Usage.String())
+}
+
+func (p *volumesPanel) activate(v fsutils.Volume) {
+	nav := p.nav
+	if nav.store == nil || nav.store.RootURL().Scheme != "file" {
+		nav.SetStore(osfile.NewStore("/"))
+	}
+	dirContext := files.NewDirContext(nav.store, v.MountPoint, nil)
+	nav.goDir(dirContext)
+	nav.left.SetContent(nav.dirsTree)
+	nav.app.SetFocus(nav.dirsTree)
+}
+
+func (p *volumesPanel) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.nav.left.SetContent(p.nav.dirsTree)
+		p.nav.app.SetFocus(p.nav.dirsTree)
+		return nil
+	case tcell.KeyRight:
+		p.nav.app.SetFocus(p.nav.files)
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'p', 'P':
+			p.showPseudo = !p.showPseudo
+			p.load()
+			return nil
+		}
+		return event
+	default:
+		return event
+	}
+}
+
+// volumeInfoText returns a short free space summary for a directory of a store with the given URL scheme.
+// It's called from a background goroutine, so it gets the scheme instead of reading nav.store.
+func (nav *Navigator) volumeInfoText(scheme, dirPath string) string {
+	if scheme != "file" || dirPath == "" {
+		return ""
+	}
+	usage, err := getDiskUsage(dirPath)
+	if err != nil || usage.Total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("💾 %s (%d%% used)", usage.String(), usage.UsedPercent())
+}
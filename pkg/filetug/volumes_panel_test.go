@@ -0,0 +1,123 @@
+package filetug
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/filetug/filetug/pkg/fsutils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func withListVolumes(t *testing.T, f func(includePseudo bool) ([]fsutils.Volume, error)) {
+	t.Helper()
+	withTestGlobalLock(t)
+	orig := listVolumes
+	listVolumes = f
+	t.Cleanup(func() {
+		listVolumes = orig
+	})
+}
+
+func TestVolumesPanel(t *testing.T) {
+	volumes := []fsutils.Volume{
+		{MountPoint: "/", Device: "/dev/sda1", FSType: "ext4", Usage: fsutils.DiskUsage{Total: 1000, Used: 250, Free: 750}},
+		{MountPoint: "/mnt/broken", Device: "server:/x", FSType: "nfs", UsageErr: errors.New("stale handle")},
+		{MountPoint: "/proc", Device: "proc", FSType: "proc"},
+	}
+	var requestedPseudo []bool
+	withListVolumes(t, func(includePseudo bool) ([]fsutils.Volume, error) {
+		requestedPseudo = append(requestedPseudo, includePseudo)
+		return volumes, nil
+	})
+
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+	nav.showVolumes()
+	runQueuedUpdate(t, updates)
+	p := nav.volumes
+	assert.NotNil(t, p)
+	assert.Equal(t, 3, p.list.GetItemCount())
+	mainText, secondaryText := p.list.GetItemText(0)
+	assert.Contains(t, mainText, "ext4")
+	assert.Contains(t, secondaryText, "used 250B (25%)")
+	_, secondaryText = p.list.GetItemText(1)
+	assert.Contains(t, secondaryText, "stale handle")
+	_, secondaryText = p.list.GetItemText(2)
+	assert.Equal(t, "proc", secondaryText)
+
+	t.Run("toggle_pseudo", func(t *testing.T) {
+		event := tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone)
+		assert.Nil(t, p.inputCapture(event))
+		runQueuedUpdate(t, updates)
+		assert.True(t, p.showPseudo)
+		assert.Equal(t, []bool{false, true}, requestedPseudo)
+	})
+
+	t.Run("other_keys", func(t *testing.T) {
+		event := tcell.NewEventKey(tcell.KeyRune, 'z', tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+		event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		assert.Equal(t, event, p.inputCapture(event))
+		event = tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone)
+		assert.Nil(t, p.inputCapture(event))
+	})
+
+	t.Run("activate", func(t *testing.T) {
+		nav.store = newMockStoreWithRoot(t, url.URL{Scheme: "ftp", Host: "example.com"})
+		p.activate(volumes[0])
+		assert.Equal(t, "file", nav.store.RootURL().Scheme)
+		assert.Equal(t, "/", nav.currentDirPath())
+		assert.Equal(t, nav.dirsTree, nav.left.content)
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		nav.left.SetContent(p)
+		event := tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)
+		assert.Nil(t, p.inputCapture(event))
+		assert.Equal(t, nav.dirsTree, nav.left.content)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		p.setVolumes(nil)
+		mainText, _ := p.list.GetItemText(0)
+		assert.Contains(t, mainText, "No volumes")
+	})
+}
+
+func TestVolumesPanel_LoadError(t *testing.T) {
+	withListVolumes(t, func(bool) ([]fsutils.Volume, error) {
+		return nil, errors.New("not supported")
+	})
+	nav, updates := newNavigatorWithQueuedUpdates(t)
+	nav.showVolumes()
+	runQueuedUpdate(t, updates)
+	mainText, _ := nav.volumes.list.GetItemText(0)
+	assert.Contains(t, mainText, "not supported")
+}
+
+func TestVolumeUsageText_NoTotal(t *testing.T) {
+	t.Parallel()
+	text := volumeUsageText(fsutils.Volume{Device: "dev"})
+	assert.Equal(t, "dev", text)
+}
+
+func TestNavigator_VolumeInfoText(t *testing.T) {
+	withTestGlobalLock(t)
+	orig := getDiskUsage
+	defer func() { getDiskUsage = orig }()
+
+	nav, _, _ := newNavigatorForTest(t)
+
+	getDiskUsage = func(string) (fsutils.DiskUsage, error) {
+		return fsutils.DiskUsage{Total: 2048, Used: 1024, Free: 1024}, nil
+	}
+	assert.Equal(t, "💾 1KB free of 2KB (50% used)", nav.volumeInfoText("file", "/tmp"))
+	assert.Equal(t, "", nav.volumeInfoText("file", ""))
+	assert.Equal(t, "", nav.volumeInfoText("https", "/tmp"))
+
+	getDiskUsage = func(string) (fsutils.DiskUsage, error) {
+		return fsutils.DiskUsage{}, errors.New("statfs failed")
+	}
+	assert.Equal(t, "", nav.volumeInfoText("file", "/tmp"))
+}
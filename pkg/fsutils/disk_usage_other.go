@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package fsutils
+
+// GetDiskUsage is not supported on this platform yet.
+func GetDiskUsage(_ string) (DiskUsage, error) {
+	return DiskUsage{}, ErrVolumesNotSupported
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package fsutils
+
+import "golang.org/x/sys/unix"
+
+var unixStatfs = unix.Statfs
+
+// GetDiskUsage returns total/used/free space of the filesystem containing the given path.
+func GetDiskUsage(path string) (DiskUsage, error) {
+	var st unix.Statfs_t
+	if err := unixStatfs(path, &st); err != nil {
+		return DiskUsage{}, err
+	}
+	blockSize := uint64(st.Bsize)
+	usage := DiskUsage{
+		Total: st.Blocks * blockSize,
+		Used:  (st.Blocks - st.Bfree) * blockSize,
+		Free:  st.Bavail * blockSize,
+	}
+	return usage, nil
+}
@@ -0,0 +1,44 @@
+//go:build linux || darwin
+
+package fsutils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestGetDiskUsage(t *testing.T) {
+	// Not parallel as it replaces package level seams
+	t.Run("real", func(t *testing.T) {
+		usage, err := GetDiskUsage(t.TempDir())
+		assert.NoError(t, err)
+		assert.NotZero(t, usage.Total)
+	})
+
+	origStatfs := unixStatfs
+	defer func() { unixStatfs = origStatfs }()
+
+	t.Run("calculated", func(t *testing.T) {
+		unixStatfs = func(_ string, st *unix.Statfs_t) error {
+			st.Bsize = 1024
+			st.Blocks = 100
+			st.Bfree = 30
+			st.Bavail = 20
+			return nil
+		}
+		usage, err := GetDiskUsage("/")
+		assert.NoError(t, err)
+		assert.Equal(t, DiskUsage{Total: 102400, Used: 71680, Free: 20480}, usage)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		unixStatfs = func(_ string, _ *unix.Statfs_t) error {
+			return errors.New("statfs failed")
+		}
+		_, err := GetDiskUsage("/")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,77 @@
+package fsutils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseMountInfo parses the content of /proc/[pid]/mountinfo.
+// See proc(5) for the format, e.g.:
+//
+//	36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func ParseMountInfo(r io.Reader) ([]Volume, error) {
+	var volumes []Volume
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		v, err := parseMountInfoLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("mountinfo line %d: %w", lineNum, err)
+		}
+		volumes = append(volumes, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return volumes, nil
+}
+
+func parseMountInfoLine(line string) (Volume, error) {
+	fields := strings.Fields(line)
+	separator := -1
+	for i, field := range fields {
+		if field == "-" {
+			separator = i
+			break
+		}
+	}
+	// 6 mandatory fields before the optional ones & the separator, 3 after it.
+	if separator < 6 || len(fields) < separator+3 {
+		return Volume{}, fmt.Errorf("unexpected format: %q", line)
+	}
+	mountPoint := unescapeMountInfo(fields[4])
+	device := unescapeMountInfo(fields[separator+2])
+	v := Volume{
+		MountPoint: mountPoint,
+		FSType:     fields[separator+1],
+		Device:     device,
+	}
+	return v, nil
+}
+
+// unescapeMountInfo decodes octal escapes like `\040` used by the kernel for spaces, tabs, etc.
+func unescapeMountInfo(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if code, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package fsutils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleMountInfo = `22 28 0:21 / /sys rw,nosuid,nodev,noexec,relatime shared:7 - sysfs sysfs rw
+23 28 0:22 / /proc rw,nosuid,nodev,noexec,relatime shared:13 - proc proc rw
+28 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sda2 rw,errors=remount-ro
+
+35 28 8:3 / /mnt/my\040disk rw,relatime - vfat /dev/sdb1 rw,fmask=0022
+`
+
+func TestParseMountInfo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		volumes, err := ParseMountInfo(strings.NewReader(sampleMountInfo))
+		assert.NoError(t, err)
+		assert.Equal(t, []Volume{
+			{MountPoint: "/sys", Device: "sysfs", FSType: "sysfs"},
+			{MountPoint: "/proc", Device: "proc", FSType: "proc"},
+			{MountPoint: "/", Device: "/dev/sda2", FSType: "ext4"},
+			{MountPoint: "/mnt/my disk", Device: "/dev/sdb1", FSType: "vfat"},
+		}, volumes)
+	})
+
+	t.Run("invalid_line", func(t *testing.T) {
+		volumes, err := ParseMountInfo(strings.NewReader("1 2 3 / /x rw ext4"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "mountinfo line 1")
+		assert.Nil(t, volumes)
+	})
+
+	t.Run("missing_fields_after_separator", func(t *testing.T) {
+		_, err := ParseMountInfo(strings.NewReader("1 2 3:4 / /x rw - ext4"))
+		assert.Error(t, err)
+	})
+
+	t.Run("read_error", func(t *testing.T) {
+		_, err := ParseMountInfo(errReader{})
+		assert.Error(t, err)
+	})
+}
+
+type errReader struct{}
+
+func (errReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestUnescapeMountInfo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in, want string
+	}{
+		{in: "/plain", want: "/plain"},
+		{in: `/a\040b`, want: "/a b"},
+		{in: `/tab\011x`, want: "/tab\tx"},
+		{in: `/back\134slash`, want: `/back\slash`},
+		{in: `/bad\09`, want: `/bad\09`},
+		{in: `/end\04`, want: `/end\04`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, unescapeMountInfo(tt.in), tt.in)
+	}
+}
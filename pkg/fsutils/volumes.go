@@ -0,0 +1,138 @@
+package fsutils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrVolumesNotSupported is returned when volumes can not be listed on the current platform.
+var ErrVolumesNotSupported = errors.New("listing of volumes is not supported on this platform")
+
+// Volume describes a mounted filesystem.
+type Volume struct {
+	MountPoint string
+	Device     string
+	FSType     string
+	Usage      DiskUsage
+	UsageErr   error
+}
+
+// DiskUsage holds total/used/free space of a filesystem in bytes.
+// Free is the space available to an unprivileged user.
+type DiskUsage struct {
+	Total uint64
+	Used  uint64
+	Free  uint64
+}
+
+// String returns a short human-readable summary like "12GB free of 100GB".
+func (u DiskUsage) String() string {
+	if u.Total == 0 {
+		return ""
+	}
+	freeText := GetSizeShortText(int64(u.Free))
+	totalText := GetSizeShortText(int64(u.Total))
+	return fmt.Sprintf("%s free of %s", freeText, totalText)
+}
+
+// UsedPercent returns used space as a percentage of total space.
+func (u DiskUsage) UsedPercent() int {
+	if u.Total == 0 {
+		return 0
+	}
+	return int(u.Used * 100 / u.Total)
+}
+
+var pseudoFSTypes = map[string]struct{}{
+	"autofs":      {},
+	"binfmt_misc": {},
+	"bpf":         {},
+	"cgroup":      {},
+	"cgroup2":     {},
+	"configfs":    {},
+	"debugfs":     {},
+	"devfs":       {},
+	"devpts":      {},
+	"devtmpfs":    {},
+	"efivarfs":    {},
+	"fusectl":     {},
+	"hugetlbfs":   {},
+	"mqueue":      {},
+	"nsfs":        {},
+	"proc":        {},
+	"pstore":      {},
+	"ramfs":       {},
+	"rpc_pipefs":  {},
+	"securityfs":  {},
+	"selinuxfs":   {},
+	"squashfs":    {},
+	"sysfs":       {},
+	"tmpfs":       {},
+	"tracefs":     {},
+}
+
+// IsPseudo reports whether the volume is a kernel/virtual filesystem
+// that is usually of no interest to a user browsing files.
+func (v Volume) IsPseudo() bool {
+	_, isPseudo := pseudoFSTypes[v.FSType]
+	return isPseudo
+}
+
+var listMounts = listPlatformMounts
+var getDiskUsage = GetDiskUsage
+
+// ListVolumes returns mounted volumes sorted by mount point, with disk usage populated.
+// Pseudo-filesystems are skipped unless includePseudo is true.
+func ListVolumes(includePseudo bool) ([]Volume, error) {
+	mounts, err := listMounts()
+	if err != nil {
+		return nil, err
+	}
+	// Mounts are listed in the order they were mounted, the last one stacked on a mount point is in effect.
+	inEffect := make(map[string]int, len(mounts))
+	for i, v := range mounts {
+		inEffect[v.MountPoint] = i
+	}
+	volumes := make([]Volume, 0, len(inEffect))
+	for i, v := range mounts {
+		if inEffect[v.MountPoint] != i {
+			continue
+		}
+		if !includePseudo && v.IsPseudo() {
+			continue
+		}
+		v.Usage, v.UsageErr = getDiskUsage(v.MountPoint)
+		volumes = append(volumes, v)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].MountPoint < volumes[j].MountPoint
+	})
+	return volumes, nil
+}
+
+// FindVolume returns the volume with the longest mount point that contains the given path.
+func FindVolume(volumes []Volume, fullPath string) (Volume, bool) {
+	var found Volume
+	var ok bool
+	for _, v := range volumes {
+		if !isUnderMountPoint(fullPath, v.MountPoint) {
+			continue
+		}
+		if !ok || len(v.MountPoint) > len(found.MountPoint) {
+			found = v
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+func isUnderMountPoint(fullPath, mountPoint string) bool {
+	if mountPoint == "/" {
+		return len(fullPath) > 0 && fullPath[0] == '/'
+	}
+	if len(fullPath) < len(mountPoint) || fullPath[:len(mountPoint)] != mountPoint {
+		return false
+	}
+	return len(fullPath) == len(mountPoint) || fullPath[len(mountPoint)] == '/'
+}
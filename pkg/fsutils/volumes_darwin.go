@@ -0,0 +1,24 @@
+package fsutils
+
+import "golang.org/x/sys/unix"
+
+func listPlatformMounts() ([]Volume, error) {
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]unix.Statfs_t, n)
+	if n, err = unix.Getfsstat(buf, unix.MNT_NOWAIT); err != nil {
+		return nil, err
+	}
+	volumes := make([]Volume, 0, n)
+	for _, st := range buf[:n] {
+		v := Volume{
+			MountPoint: unix.ByteSliceToString(st.Mntonname[:]),
+			Device:     unix.ByteSliceToString(st.Mntfromname[:]),
+			FSType:     unix.ByteSliceToString(st.Fstypename[:]),
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
@@ -0,0 +1,18 @@
+package fsutils
+
+import "os"
+
+const mountInfoPath = "/proc/self/mountinfo"
+
+var osOpen = os.Open
+
+func listPlatformMounts() ([]Volume, error) {
+	file, err := osOpen(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ParseMountInfo(file)
+}
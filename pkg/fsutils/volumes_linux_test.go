@@ -0,0 +1,29 @@
+package fsutils
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPlatformMounts(t *testing.T) {
+	// Not parallel as it replaces package level seams
+	t.Run("real", func(t *testing.T) {
+		volumes, err := listPlatformMounts()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, volumes)
+	})
+
+	t.Run("open_error", func(t *testing.T) {
+		origOsOpen := osOpen
+		defer func() { osOpen = origOsOpen }()
+		osOpen = func(string) (*os.File, error) {
+			return nil, errors.New("open failed")
+		}
+		volumes, err := listPlatformMounts()
+		assert.Error(t, err)
+		assert.Nil(t, volumes)
+	})
+}
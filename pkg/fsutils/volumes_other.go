@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package fsutils
+
+func listPlatformMounts() ([]Volume, error) {
+	return nil, ErrVolumesNotSupported
+}
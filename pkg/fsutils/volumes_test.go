@@ -0,0 +1,105 @@
+package fsutils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskUsage(t *testing.T) {
+	t.Parallel()
+	var empty DiskUsage
+	assert.Equal(t, "", empty.String())
+	assert.Equal(t, 0, empty.UsedPercent())
+
+	usage := DiskUsage{Total: 100 * 1024 * 1024, Used: 75 * 1024 * 1024, Free: 25 * 1024 * 1024}
+	assert.Equal(t, "25MB free of 100MB", usage.String())
+	assert.Equal(t, 75, usage.UsedPercent())
+}
+
+func TestVolume_IsPseudo(t *testing.T) {
+	t.Parallel()
+	assert.True(t, Volume{FSType: "proc"}.IsPseudo())
+	assert.True(t, Volume{FSType: "tmpfs"}.IsPseudo())
+	assert.False(t, Volume{FSType: "ext4"}.IsPseudo())
+	assert.False(t, Volume{FSType: "apfs"}.IsPseudo())
+}
+
+func TestListVolumes(t *testing.T) {
+	// Not parallel as it replaces package level seams
+	origListMounts, origGetDiskUsage := listMounts, getDiskUsage
+	defer func() {
+		listMounts, getDiskUsage = origListMounts, origGetDiskUsage
+	}()
+	getDiskUsage = func(path string) (DiskUsage, error) {
+		if path == "/broken" {
+			return DiskUsage{}, errors.New("statfs failed")
+		}
+		return DiskUsage{Total: 10, Used: 4, Free: 6}, nil
+	}
+	listMounts = func() ([]Volume, error) {
+		return []Volume{
+			{MountPoint: "/", FSType: "ext4"},
+			{MountPoint: "/proc", FSType: "proc"},
+			{MountPoint: "/broken", FSType: "nfs"},
+			{MountPoint: "/", FSType: "overlay"},
+			{MountPoint: "/boot", FSType: "vfat"},
+		}, nil
+	}
+
+	t.Run("without_pseudo", func(t *testing.T) {
+		volumes, err := ListVolumes(false)
+		assert.NoError(t, err)
+		var mountPoints []string
+		for _, v := range volumes {
+			mountPoints = append(mountPoints, v.MountPoint)
+		}
+		assert.Equal(t, []string{"/", "/boot", "/broken"}, mountPoints)
+		assert.Equal(t, "overlay", volumes[0].FSType, "the last of stacked mounts is in effect")
+		assert.Equal(t, uint64(6), volumes[0].Usage.Free)
+		assert.Error(t, volumes[2].UsageErr)
+	})
+
+	t.Run("with_pseudo", func(t *testing.T) {
+		volumes, err := ListVolumes(true)
+		assert.NoError(t, err)
+		assert.Len(t, volumes, 4)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		listMounts = func() ([]Volume, error) {
+			return nil, errors.New("no mounts")
+		}
+		volumes, err := ListVolumes(false)
+		assert.Error(t, err)
+		assert.Nil(t, volumes)
+	})
+}
+
+func TestFindVolume(t *testing.T) {
+	t.Parallel()
+	volumes := []Volume{
+		{MountPoint: "/"},
+		{MountPoint: "/home"},
+		{MountPoint: "/home/user/mnt"},
+	}
+	tests := []struct {
+		path    string
+		want    string
+		wantOk  bool
+		volumes []Volume
+	}{
+		{path: "/etc", want: "/", wantOk: true, volumes: volumes},
+		{path: "/home", want: "/home", wantOk: true, volumes: volumes},
+		{path: "/homework", want: "/", wantOk: true, volumes: volumes},
+		{path: "/home/user/mnt/x", want: "/home/user/mnt", wantOk: true, volumes: volumes},
+		{path: "relative", wantOk: false, volumes: volumes},
+		{path: "/x", wantOk: false, volumes: []Volume{{MountPoint: "/home"}}},
+	}
+	for _, tt := range tests {
+		v, ok := FindVolume(tt.volumes, tt.path)
+		assert.Equal(t, tt.wantOk, ok, tt.path)
+		assert.Equal(t, tt.want, v.MountPoint, tt.path)
+	}
+}
@@ -0,0 +1,75 @@
+package gitutils
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// FileState is a state of a file in a git worktree, e.g. to filter files by it.
+type FileState string
+
+const (
+	FileUnmodified FileState = ""
+	FileModified   FileState = "modified" // changed in the worktree or staged
+	FileUntracked  FileState = "untracked"
+	FileIgnored    FileState = "ignored"
+)
+
+// WorktreeStates tells states of files of a worktree read at once, it is safe for concurrent use.
+type WorktreeStates struct {
+	root    string
+	changes map[string]FileState // by slash-separated path relative to the root
+	ignore  gitignore.Matcher
+}
+
+// GetWorktreeStates reads states of changed, untracked & ignored files of a repository.
+func GetWorktreeStates(repoRoot string) (*WorktreeStates, error) {
+	repo, err := gitPlainOpen(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repoWorktree(repo)
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktreeStatus(worktree)
+	if err != nil {
+		return nil, err
+	}
+	states := &WorktreeStates{root: repoRoot, changes: make(map[string]FileState, len(status))}
+	for name, s := range status {
+		switch {
+		case s.Worktree == git.Untracked:
+			states.changes[name] = FileUntracked
+		case s.Worktree != git.Unmodified || s.Staging != git.Unmodified:
+			states.changes[name] = FileModified
+		}
+	}
+	patterns := loadGlobalIgnorePatterns(repoRoot)
+	if repoPatterns, err := gitignore.ReadPatterns(worktree.Filesystem, nil); err == nil {
+		patterns = append(patterns, repoPatterns...)
+	}
+	if len(patterns) > 0 {
+		states.ignore = gitignore.NewMatcher(patterns)
+	}
+	return states, nil
+}
+
+// State returns a state of a file by its path, files outside of the worktree are unmodified.
+func (s *WorktreeStates) State(path string, isDir bool) FileState {
+	rel, err := filepathRel(s.root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return FileUnmodified
+	}
+	rel = filepath.ToSlash(rel)
+	if state, ok := s.changes[rel]; ok {
+		return state
+	}
+	if s.ignore != nil && s.ignore.Match(strings.Split(rel, "/"), isDir) {
+		return FileIgnored
+	}
+	return FileUnmodified
+}
@@ -0,0 +1,76 @@
+package gitutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestGetWorktreeStates(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "*.log\nbuild/\n")
+	write("clean.txt", "clean")
+	write("changed.txt", "v1")
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".gitignore", "clean.txt", "changed.txt"} {
+		if _, err = wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = wt.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("changed.txt", "v2")
+	write("new.txt", "new")
+	write("app.log", "log")
+	write("build/out.bin", "bin")
+
+	states, err := GetWorktreeStates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path  string
+		isDir bool
+		want  FileState
+	}{
+		{path: "clean.txt", want: FileUnmodified},
+		{path: "changed.txt", want: FileModified},
+		{path: "new.txt", want: FileUntracked},
+		{path: "app.log", want: FileIgnored},
+		{path: "build", isDir: true, want: FileIgnored},
+		{path: "../outside.txt", want: FileUnmodified},
+		{path: ".", isDir: true, want: FileUnmodified},
+	}
+	for _, tt := range tests {
+		if got := states.State(filepath.Join(dir, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("State(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err = GetWorktreeStates(t.TempDir()); err == nil {
+		t.Error("expected an error for a dir that is not a repository")
+	}
+}
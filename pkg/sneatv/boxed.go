@@ -201,6 +201,10 @@ func (b Boxed) drawBorders(screen tcell.Screen) {
 			title = fmt.Sprintf("[DarkGray]%s[-]", title)
 			sb.WriteString(title)
 		}
+		if boxTitle := b.GetTitle(); boxTitle != "" { // e.g. an active filter next to tabs
+			sb.WriteString("[gray]|[-]")
+			sb.WriteString(boxTitle)
+		}
 		title = sb.String()
 	}
 
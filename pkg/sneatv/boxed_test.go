@@ -55,6 +55,24 @@ func TestBoxed_Draw(t *testing.T) {
 	screen.Show()
 }
 
+func TestBoxed_DrawTabsWithTitle(t *testing.T) {
+	t.Parallel()
+	screen := ttestutils.NewSimScreen(t, "UTF-8", 40, 5)
+
+	inner := tview.NewBox()
+	boxed := NewBoxed(inner, WithTabs(&PanelTab{Title: "Files", Checked: true}))
+	boxed.SetRect(0, 0, 40, 5)
+	boxed.Blur()
+	boxed.Draw(screen)
+	screen.Show()
+	require.NotContains(t, ttestutils.ReadLine(screen, 0, 40), "|")
+
+	inner.SetTitle("ext:go")
+	boxed.Draw(screen)
+	screen.Show()
+	require.Contains(t, ttestutils.ReadLine(screen, 0, 40), "Files|ext:go")
+}
+
 func TestBoxed_DrawFooter(t *testing.T) {
 	t.Parallel()
 	screen := ttestutils.NewSimScreen(t, "UTF-8", 20, 5)
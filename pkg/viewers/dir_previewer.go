@@ -40,10 +40,13 @@ type DirPreviewer struct {
 	extGroupsByID map[string]*ExtensionsGroup
 	ExtGroups     []*ExtensionsGroup
 
+	footer *tview.TextView
+
 	setFilter       func(ftui.Filter)
 	focusLeft       func()
 	queueUpdateDraw navigator.UpdateDrawQueuer
 	colorByExt      func(string) tcell.Color
+	volumeInfo      func(scheme, dirPath string) string
 }
 
 func NewDirPreviewer(app DirPreviewerApp, options ...DirSummaryOption) *DirPreviewer {
@@ -54,14 +57,20 @@ func NewDirPreviewer(app DirPreviewerApp, options ...DirSummaryOption) *DirPrevi
 	extTable := tview.NewTable()
 	extTable.SetSelectable(true, false)
 
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true)
+	footer.SetTextColor(tcell.ColorGray)
+
 	d := &DirPreviewer{
 		app:      app,
 		flex:     flex,
 		ExtTable: extTable,
+		footer:   footer,
 	}
 	d.Boxed = sneatv.NewBoxed(
 		flex,
 		sneatv.WithLeftBorder(0, -1),
+		sneatv.WithFooter(footer),
 	)
 	d.colorByExt = func(_ string) tcell.Color { return colors.TableHeaderColor }
 	d.GitPreviewer = NewGitDirStatusPreviewer()
@@ -109,11 +118,15 @@ func (d *DirPreviewer) Focus(delegate func(p tview.Primitive)) {
 }
 
 func (d *DirPreviewer) SetDirEntries(dirContext *files.DirContext) {
-	var dirPath string
+	var dirPath, scheme string
 	var entries []os.DirEntry
 	if dirContext != nil {
 		dirPath = dirContext.Path()
 		entries = dirContext.Children()
+		if store := dirContext.Store(); store != nil {
+			rootURL := store.RootURL()
+			scheme = rootURL.Scheme
+		}
 	}
 	d.dirPath = dirPath
 
@@ -195,6 +208,8 @@ func (d *DirPreviewer) SetDirEntries(dirContext *files.DirContext) {
 
 	//d.updateTable()
 
+	d.updateFooter(scheme, dirPath)
+
 	hasRepo := gitutils.GetRepositoryRoot(dirPath) != ""
 	d.setTabs(hasRepo)
 	if hasRepo && dirContext != nil {
@@ -287,6 +302,33 @@ func (d *DirPreviewer) setTabs(hasGit bool) {
 	d.flex.AddItem(tabs, 0, 1, false)
 }
 
+// updateFooter shows volume info (e.g. free space) for the directory in the footer.
+// The scheme of the dir's store is read by the caller in the UI goroutine & passed to the background one.
+func (d *DirPreviewer) updateFooter(scheme, dirPath string) {
+	if d.volumeInfo == nil {
+		return
+	}
+	if d.queueUpdateDraw == nil {
+		d.footer.SetText(d.volumeInfo(scheme, dirPath))
+		return
+	}
+	d.footer.SetText("")
+	go func() {
+		text := d.volumeInfo(scheme, dirPath) // statfs can be slow on network mounts
+		d.queueUpdate(func() {
+			if d.dirPath != dirPath {
+				return
+			}
+			d.footer.SetText(text)
+		})
+	}()
+}
+
+// FooterText returns the text currently displayed in the footer.
+func (d *DirPreviewer) FooterText() string {
+	return d.footer.GetText(false)
+}
+
 func (d *DirPreviewer) queueUpdate(f func()) {
 	if d.queueUpdateDraw != nil {
 		d.queueUpdateDraw(f)
@@ -36,3 +36,18 @@ func WithDirSummaryColorByExt(setter func(string) tcell.Color) DirSummaryOption
 		d.colorByExt = setter
 	}
 }
+
+// WithDirSummaryGitStageChanged sets the function called after a file is staged or unstaged in the git tab.
+func WithDirSummaryGitStageChanged(f func(fullPath string, staged bool)) DirSummaryOption {
+	return func(d *DirPreviewer) {
+		d.GitPreviewer.onStageChanged = f
+	}
+}
+
+// WithDirSummaryVolumeInfo sets the function providing volume info (e.g. free space) for the footer.
+// It gets the URL scheme of the dir's store & is called in background if updates are queued.
+func WithDirSummaryVolumeInfo(getter func(scheme, dirPath string) string) DirSummaryOption {
+	return func(d *DirPreviewer) {
+		d.volumeInfo = getter
+	}
+}
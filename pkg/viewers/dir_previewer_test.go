@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/filetug/filetug/pkg/files"
+	"github.com/filetug/filetug/pkg/files/osfile"
 	"github.com/filetug/filetug/pkg/filetug/ftui"
 	"github.com/filetug/filetug/pkg/sneatv"
 	"github.com/gdamore/tcell/v2"
@@ -858,3 +859,43 @@ func TestDirSummary_SetDirEntries_StaleQueueUpdate(t *testing.T) {
 	default:
 	}
 }
+
+func TestDirSummary_VolumeInfoFooter(t *testing.T) {
+	t.Parallel()
+	getVolumeInfo := WithDirSummaryVolumeInfo(func(scheme, dirPath string) string {
+		return "free space of " + scheme + dirPath
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		ds := NewDirPreviewer(nil, getVolumeInfo)
+		ds.SetDirEntries(newDirContext("/test", nil))
+		assert.Equal(t, "free space of /test", ds.FooterText())
+		ds.SetDirEntries(files.NewDirContext(osfile.NewStore("/"), "/test", nil))
+		assert.Equal(t, "free space of file/test", ds.FooterText(), "the scheme of the dir's store is passed")
+	})
+
+	t.Run("async", func(t *testing.T) {
+		updates := make(chan func(), 10)
+		queueUpdate := WithDirSummaryQueueUpdateDraw(func(f func()) {
+			updates <- f
+		})
+		ds := NewDirPreviewer(nil, getVolumeInfo, queueUpdate)
+		ds.SetDirEntries(newDirContext("/first", nil))
+		ds.dirPath = "/second" // Simulate that user moved to another dir
+		for i := 0; i < 2; i++ {
+			select {
+			case f := <-updates:
+				f()
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for update")
+			}
+		}
+		assert.Equal(t, "", ds.FooterText())
+	})
+
+	t.Run("no_volume_info", func(t *testing.T) {
+		ds := NewDirPreviewer(nil)
+		ds.SetDirEntries(newDirContext("/test", nil))
+		assert.Equal(t, "", ds.FooterText())
+	})
+}
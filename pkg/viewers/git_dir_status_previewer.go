@@ -39,6 +39,7 @@ type GitDirStatusPreviewer struct {
 	statusLoader    func(string) (gitDirStatusResult, error)
 	stageFile       func(string) error
 	unstageFile     func(string) error
+	onStageChanged  func(fullPath string, staged bool)
 }
 
 type gitDirStatusEntry struct {
@@ -127,6 +128,9 @@ func (p *GitDirStatusPreviewer) handleInput(event *tcell.EventKey) *tcell.EventK
 				return nil
 			}
 		}
+		if p.onStageChanged != nil {
+			p.onStageChanged(entry.fullPath, !entry.staged)
+		}
 		go p.refresh()
 		return nil
 	}
@@ -104,6 +104,12 @@ func TestGitDirStatusPreviewer_HandleInput(t *testing.T) {
 		stageCalled = true
 		return nil
 	}
+	var stageChanges []bool
+	opt := WithDirSummaryGitStageChanged(func(fullPath string, staged bool) {
+		assert.Equal(t, "/repo/file.txt", fullPath)
+		stageChanges = append(stageChanges, staged)
+	})
+	opt(&DirPreviewer{GitPreviewer: p})
 	stageDone := make(chan struct{})
 	p.statusLoader = func(_ string) (gitDirStatusResult, error) {
 		close(stageDone)
@@ -133,6 +139,7 @@ func TestGitDirStatusPreviewer_HandleInput(t *testing.T) {
 	assert.Nil(t, p.handleInput(space))
 	<-unstageDone
 	assert.True(t, unstageCalled)
+	assert.Equal(t, []bool{true, false}, stageChanges)
 
 	p.entries = []gitDirStatusEntry{
 		{fullPath: "/repo/file.txt", displayName: "file.txt", staged: true, badge: gitBadge{text: "A"}},